@@ -40,6 +40,7 @@ func TestBasicEncodingRoundTrip(t *testing.T) {
 		"miner post states": {
 			&map[string]uint64{address.TestAddress.String(): 1, address.TestAddress2.String(): 2},
 		},
+		"addresses": {[]address.Address{addrGetter(), addrGetter()}},
 	}
 
 	for tname, tcase := range cases {
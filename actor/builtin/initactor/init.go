@@ -1,12 +1,16 @@
 package initactor
 
 import (
+	"math/big"
+
 	"github.com/filecoin-project/go-filecoin/types"
 	"github.com/ipfs/go-cid"
 	cbor "github.com/ipfs/go-ipld-cbor"
 
 	"github.com/filecoin-project/go-filecoin/abi"
 	"github.com/filecoin-project/go-filecoin/actor"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/multisig"
+	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/exec"
 	"github.com/filecoin-project/go-filecoin/vm/errors"
 )
@@ -33,6 +37,10 @@ var initExports = exec.Exports{
 		Params: []abi.Type{},
 		Return: []abi.Type{abi.String},
 	},
+	"createMultisig": &exec.FunctionSignature{
+		Params: []abi.Type{abi.Addresses, abi.Integer},
+		Return: []abi.Type{abi.Address},
+	},
 }
 
 // Exports makes the available methods for this contract available.
@@ -67,10 +75,6 @@ func (ia *Actor) InitializeState(storage exec.Storage, networkInterface interfac
 
 // GetNetwork returns the network name for this network
 func (sma *Actor) GetNetwork(vmctx exec.VMContext) (string, uint8, error) {
-	if err := vmctx.Charge(actor.DefaultGasCost); err != nil {
-		return "", exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
-
 	var state State
 	err := actor.ReadState(vmctx, &state)
 	if err != nil {
@@ -79,3 +83,28 @@ func (sma *Actor) GetNetwork(vmctx exec.VMContext) (string, uint8, error) {
 
 	return state.Network, 0, nil
 }
+
+// CreateMultisig creates a multisig actor controlled by the given signers, of which
+// threshold must approve each of its transactions. The value of the message is
+// deposited into the new actor.
+func (ia *Actor) CreateMultisig(vmctx exec.VMContext, signers []address.Address, threshold *big.Int) (address.Address, uint8, error) {
+	if !threshold.IsUint64() {
+		err := multisig.Errors[multisig.ErrInvalidThreshold]
+		return address.Undef, errors.CodeError(err), err
+	}
+
+	addr, err := vmctx.AddressForNewActor()
+	if err != nil {
+		return address.Undef, 1, errors.FaultErrorWrap(err, "could not get address for new actor")
+	}
+
+	if err := vmctx.CreateNewActor(addr, types.MultisigActorCodeCid, multisig.NewState(signers, threshold.Uint64())); err != nil {
+		return address.Undef, errors.CodeError(err), err
+	}
+
+	if _, _, err := vmctx.Send(addr, "", vmctx.Message().Value, nil); err != nil {
+		return address.Undef, errors.CodeError(err), err
+	}
+
+	return addr, 0, nil
+}
@@ -26,6 +26,7 @@ func init() {
 	cbor.RegisterCborType(cbor.BigIntAtlasEntry)
 	cbor.RegisterCborType(State{})
 	cbor.RegisterCborType(Ask{})
+	cbor.RegisterCborType(RetrievalAsk{})
 }
 
 // LargestSectorSizeProvingPeriodBlocks defines the number of blocks in a
@@ -74,6 +75,8 @@ const (
 	// ErrInvalidPieceInclusionProof indicates that the piece inclusion proof was
 	// malformed or did not succesfully verify.
 	ErrInvalidPieceInclusionProof = 46
+	// ErrInvalidRetrievalAsk indicates that a retrieval ask has a zero payment interval.
+	ErrInvalidRetrievalAsk = 47
 )
 
 // Errors map error codes to revert errors this actor may return.
@@ -89,6 +92,7 @@ var Errors = map[uint8]error{
 	ErrGetProofsModeFailed:        errors.NewCodedRevertErrorf(ErrGetProofsModeFailed, "failed to get proofs mode"),
 	ErrInsufficientCollateral:     errors.NewCodedRevertErrorf(ErrInsufficientCollateral, "insufficient collateral"),
 	ErrInvalidPieceInclusionProof: errors.NewCodedRevertErrorf(ErrInvalidPieceInclusionProof, "piece inclusion proof did not validate"),
+	ErrInvalidRetrievalAsk:        errors.NewCodedRevertErrorf(ErrInvalidRetrievalAsk, "retrieval ask payment interval must be positive"),
 }
 
 const (
@@ -119,6 +123,19 @@ type Ask struct {
 	ID     *big.Int
 }
 
+// RetrievalAsk is the miner's advertised price for retrieving pieces it stores.
+type RetrievalAsk struct {
+	// Price is the price per byte retrieved.
+	Price types.AttoFIL
+
+	// PaymentInterval is the number of bytes the miner sends before it requires
+	// payment for them.
+	PaymentInterval *types.BytesAmount
+
+	// UnsealPrice is the flat price for unsealing a sector to serve a retrieval.
+	UnsealPrice types.AttoFIL
+}
+
 // State is the miner actors storage.
 type State struct {
 	// Owner is the address of the account that owns this miner. Income and returned
@@ -143,6 +160,10 @@ type State struct {
 	Asks      []*Ask
 	NextAskID *big.Int
 
+	// RetrievalAsk is the miner's current retrieval price, or nil if it has not
+	// advertised one.
+	RetrievalAsk *RetrievalAsk
+
 	// SectorCommitments maps sector id to commitments, for all sectors this
 	// miner has committed.  Sector ids are removed from this collection
 	// when they are included in the done or fault parameters of submitPoSt.
@@ -245,6 +266,11 @@ var minerExports = exec.Exports{
 		Params: []abi.Type{abi.AttoFIL, abi.Integer},
 		Return: []abi.Type{abi.Integer},
 	},
+	// setRetrievalAsk is not in the spec, but there's not yet another mechanism to discover retrieval prices.
+	"setRetrievalAsk": &exec.FunctionSignature{
+		Params: []abi.Type{abi.AttoFIL, abi.BytesAmount, abi.AttoFIL},
+		Return: []abi.Type{},
+	},
 	"getOwner": &exec.FunctionSignature{
 		Params: nil,
 		Return: []abi.Type{abi.Address},
@@ -308,6 +334,10 @@ var minerExports = exec.Exports{
 		Params: []abi.Type{abi.Integer},
 		Return: []abi.Type{abi.Bytes},
 	},
+	"getRetrievalAsk": &exec.FunctionSignature{
+		Params: nil,
+		Return: []abi.Type{abi.Bytes},
+	},
 	"getLastUsedSectorID": &exec.FunctionSignature{
 		Params: nil,
 		Return: []abi.Type{abi.SectorID},
@@ -350,10 +380,6 @@ func (ma *Actor) Exports() exec.Exports {
 // AddAsk adds an ask to this miners ask list
 func (ma *Actor) AddAsk(ctx exec.VMContext, price types.AttoFIL, expiry *big.Int) (*big.Int, uint8,
 	error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return nil, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
-
 	var state State
 	out, err := actor.WithState(ctx, &state, func() (interface{}, error) {
 		if ctx.Message().From != state.Worker {
@@ -400,9 +426,6 @@ func (ma *Actor) AddAsk(ctx exec.VMContext, price types.AttoFIL, expiry *big.Int
 // GetAsks returns all the asks for this miner. (TODO: this isnt a great function signature, it returns the asks in a
 // serialized array. Consider doing this some other way)
 func (ma *Actor) GetAsks(ctx exec.VMContext) ([]uint64, uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return nil, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
 	var state State
 	out, err := actor.WithState(ctx, &state, func() (interface{}, error) {
 		var askids []uint64
@@ -429,10 +452,6 @@ func (ma *Actor) GetAsks(ctx exec.VMContext) ([]uint64, uint8, error) {
 
 // GetAsk returns an ask by ID
 func (ma *Actor) GetAsk(ctx exec.VMContext, askid *big.Int) ([]byte, uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return nil, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
-
 	var state State
 	out, err := actor.WithState(ctx, &state, func() (interface{}, error) {
 		var ask *Ask
@@ -466,12 +485,57 @@ func (ma *Actor) GetAsk(ctx exec.VMContext, askid *big.Int) ([]byte, uint8, erro
 	return ask, 0, nil
 }
 
-// GetOwner returns the miners owner.
-func (ma *Actor) GetOwner(ctx exec.VMContext) (address.Address, uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return address.Undef, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+// SetRetrievalAsk replaces this miner's retrieval ask.
+func (ma *Actor) SetRetrievalAsk(ctx exec.VMContext, price types.AttoFIL, paymentInterval *types.BytesAmount, unsealPrice types.AttoFIL) (uint8, error) {
+	var state State
+	_, err := actor.WithState(ctx, &state, func() (interface{}, error) {
+		if ctx.Message().From != state.Worker {
+			return nil, Errors[ErrCallerUnauthorized]
+		}
+
+		if !paymentInterval.IsPositive() {
+			return nil, Errors[ErrInvalidRetrievalAsk]
+		}
+
+		state.RetrievalAsk = &RetrievalAsk{
+			Price:           price,
+			PaymentInterval: paymentInterval,
+			UnsealPrice:     unsealPrice,
+		}
+
+		return nil, nil
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// GetRetrievalAsk returns this miner's retrieval ask, or no bytes if the miner has not set one.
+func (ma *Actor) GetRetrievalAsk(ctx exec.VMContext) ([]byte, uint8, error) {
+	var state State
+	out, err := actor.WithState(ctx, &state, func() (interface{}, error) {
+		if state.RetrievalAsk == nil {
+			return []byte{}, nil
+		}
+
+		return cbor.DumpObject(state.RetrievalAsk)
+	})
+	if err != nil {
+		return nil, errors.CodeError(err), err
+	}
+
+	ask, ok := out.([]byte)
+	if !ok {
+		return nil, 1, errors.NewRevertErrorf("expected a Bytes return value from call, but got %T instead", out)
 	}
 
+	return ask, 0, nil
+}
+
+// GetOwner returns the miners owner.
+func (ma *Actor) GetOwner(ctx exec.VMContext) (address.Address, uint8, error) {
 	var state State
 	out, err := actor.WithState(ctx, &state, func() (interface{}, error) {
 		return state.Owner, nil
@@ -490,9 +554,6 @@ func (ma *Actor) GetOwner(ctx exec.VMContext) (address.Address, uint8, error) {
 
 // GetLastUsedSectorID returns the last used sector id.
 func (ma *Actor) GetLastUsedSectorID(ctx exec.VMContext) (uint64, uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return 0, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
 	var state State
 	out, err := actor.WithState(ctx, &state, func() (interface{}, error) {
 		return state.LastUsedSectorID, nil
@@ -542,10 +603,6 @@ func (ma *Actor) GetPoStState(ctx exec.VMContext) (*big.Int, uint8, error) {
 
 // GetProvingSetCommitments returns all sector commitments posted by this miner.
 func (ma *Actor) GetProvingSetCommitments(ctx exec.VMContext) (map[string]types.Commitments, uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return nil, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
-
 	var state State
 	err := actor.ReadState(ctx, &state)
 	if err != nil {
@@ -566,10 +623,6 @@ func (ma *Actor) GetProvingSetCommitments(ctx exec.VMContext) (map[string]types.
 // GetSectorSize returns the size of the sectors committed to the network by
 // this miner.
 func (ma *Actor) GetSectorSize(ctx exec.VMContext) (*types.BytesAmount, uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return nil, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
-
 	var state State
 	out, err := actor.WithState(ctx, &state, func() (interface{}, error) {
 		return state.SectorSize, nil
@@ -589,9 +642,6 @@ func (ma *Actor) GetSectorSize(ctx exec.VMContext) (*types.BytesAmount, uint8, e
 // CommitSector adds a commitment to the specified sector. The sector must not
 // already be committed.
 func (ma *Actor) CommitSector(ctx exec.VMContext, sectorID uint64, commD, commR, commRStar []byte, proof types.PoRepProof) (uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
 	if len(commD) != int(types.CommitmentBytesLen) {
 		return 1, errors.NewRevertError("invalid sized commD")
 	}
@@ -680,10 +730,6 @@ func (ma *Actor) CommitSector(ctx exec.VMContext, sectorID uint64, commD, commR,
 // verifies that this miner is not slashable
 // This method returns nothing if the verification succeeds and returns a revert error if verification fails.
 func (ma *Actor) VerifyPieceInclusion(ctx exec.VMContext, commP []byte, pieceSize *types.BytesAmount, sectorID uint64, proof []byte) (uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
-
 	chainHeight := ctx.BlockHeight()
 
 	var state State
@@ -731,10 +777,6 @@ func (ma *Actor) VerifyPieceInclusion(ctx exec.VMContext, commP []byte, pieceSiz
 
 // ChangeWorker alters the worker address in state
 func (ma *Actor) ChangeWorker(ctx exec.VMContext, worker address.Address) (uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
-
 	var state State
 	_, err := actor.WithState(ctx, &state, func() (interface{}, error) {
 		if ctx.Message().From != state.Owner {
@@ -754,10 +796,6 @@ func (ma *Actor) ChangeWorker(ctx exec.VMContext, worker address.Address) (uint8
 
 // GetWorker returns the worker address for this miner.
 func (ma *Actor) GetWorker(ctx exec.VMContext) (address.Address, uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return address.Address{}, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
-
 	var state State
 	out, err := actor.WithState(ctx, &state, func() (interface{}, error) {
 		return state.Worker, nil
@@ -776,10 +814,6 @@ func (ma *Actor) GetWorker(ctx exec.VMContext) (address.Address, uint8, error) {
 
 // GetPeerID returns the libp2p peer ID that this miner can be reached at.
 func (ma *Actor) GetPeerID(ctx exec.VMContext) (peer.ID, uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return peer.ID(""), exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
-
 	var state State
 
 	err := actor.ReadState(ctx, &state)
@@ -792,10 +826,6 @@ func (ma *Actor) GetPeerID(ctx exec.VMContext) (peer.ID, uint8, error) {
 
 // UpdatePeerID is used to update the peerID this miner is operating under.
 func (ma *Actor) UpdatePeerID(ctx exec.VMContext, pid peer.ID) (uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
-
 	var storage State
 	_, err := actor.WithState(ctx, &storage, func() (interface{}, error) {
 		// verify that the caller is authorized to perform update
@@ -816,10 +846,6 @@ func (ma *Actor) UpdatePeerID(ctx exec.VMContext, pid peer.ID) (uint8, error) {
 
 // GetPower returns the amount of proven sectors for this miner.
 func (ma *Actor) GetPower(ctx exec.VMContext) (*types.BytesAmount, uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return nil, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
-
 	var state State
 	ret, err := actor.WithState(ctx, &state, func() (interface{}, error) {
 		return state.Power, nil
@@ -839,9 +865,6 @@ func (ma *Actor) GetPower(ctx exec.VMContext) (*types.BytesAmount, uint8, error)
 // GetActiveCollateral returns the active collateral a miner is holding to
 // protect storage.
 func (ma *Actor) GetActiveCollateral(ctx exec.VMContext) (types.AttoFIL, uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return types.ZeroAttoFIL, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
 	var state State
 	ret, err := actor.WithState(ctx, &state, func() (interface{}, error) {
 		return state.ActiveCollateral, nil
@@ -859,10 +882,6 @@ func (ma *Actor) GetActiveCollateral(ctx exec.VMContext) (types.AttoFIL, uint8,
 }
 
 func (ma *Actor) AddFaults(ctx exec.VMContext, faults types.FaultSet) (uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
-
 	var state State
 	_, err := actor.WithState(ctx, &state, func() (interface{}, error) {
 		challengeBlockHeight := provingWindowStart(state)
@@ -888,10 +907,6 @@ func (ma *Actor) AddFaults(ctx exec.VMContext, faults types.FaultSet) (uint8, er
 // SubmitPoSt is used to submit a coalesced PoST to the chain to convince the chain
 // that you have been actually storing the files you claim to be.
 func (ma *Actor) SubmitPoSt(ctx exec.VMContext, poStProof types.PoStProof, faults types.FaultSet, done types.IntSet) (uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
-
 	chainHeight := ctx.BlockHeight()
 	sender := ctx.Message().From
 	var state State
@@ -1055,10 +1070,6 @@ func (ma *Actor) SubmitPoSt(ctx exec.VMContext, poStProof types.PoStProof, fault
 // take collateral from this miner when the miner has failed to submit a
 // PoSt on time.
 func (ma *Actor) SlashStorageFault(ctx exec.VMContext) (uint8, error) {
-	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
-		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
-
 	chainHeight := ctx.BlockHeight()
 	var state State
 	_, err := actor.WithState(ctx, &state, func() (interface{}, error) {
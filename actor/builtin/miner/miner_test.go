@@ -27,6 +27,7 @@ import (
 	th "github.com/filecoin-project/go-filecoin/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
 	"github.com/filecoin-project/go-filecoin/vm"
 	vmerrors "github.com/filecoin-project/go-filecoin/vm/errors"
 )
@@ -101,6 +102,57 @@ func TestAskFunctions(t *testing.T) {
 	assert.Len(t, askids, 2)
 }
 
+func TestRetrievalAskFunctions(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	st, vms := th.RequireCreateStorages(ctx, t)
+
+	minerAddr := th.CreateTestMiner(t, st, vms, address.TestAddress, th.RequireRandomPeerID(t))
+
+	// a new miner has no retrieval ask
+	msg := types.NewMessage(address.TestAddress, minerAddr, 1, types.ZeroAttoFIL, "getRetrievalAsk", nil)
+	result, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(1))
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+	assert.Empty(t, result.Receipt.Return[0])
+
+	// set an ask, and then make sure it all looks good
+	pdata := actor.MustConvertParams(types.NewAttoFILFromFIL(2), types.NewBytesAmount(1024), types.NewAttoFILFromFIL(7))
+	msg = types.NewMessage(address.TestAddress, minerAddr, 2, types.ZeroAttoFIL, "setRetrievalAsk", pdata)
+	result, err = th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(2))
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+
+	msg = types.NewMessage(address.TestAddress, minerAddr, 3, types.ZeroAttoFIL, "getRetrievalAsk", nil)
+	result, err = th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(3))
+	require.NoError(t, err)
+
+	var ask RetrievalAsk
+	require.NoError(t, actor.UnmarshalStorage(result.Receipt.Return[0], &ask))
+	assert.Equal(t, types.NewAttoFILFromFIL(2), ask.Price)
+	assert.Equal(t, types.NewBytesAmount(1024), ask.PaymentInterval)
+	assert.Equal(t, types.NewAttoFILFromFIL(7), ask.UnsealPrice)
+
+	t.Run("rejects a zero payment interval", func(t *testing.T) {
+		pdata := actor.MustConvertParams(types.NewAttoFILFromFIL(2), types.NewBytesAmount(0), types.ZeroAttoFIL)
+		msg := types.NewMessage(address.TestAddress, minerAddr, 4, types.ZeroAttoFIL, "setRetrievalAsk", pdata)
+		result, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(4))
+		require.NoError(t, err)
+		assert.Equal(t, Errors[ErrInvalidRetrievalAsk], result.ExecutionError)
+	})
+
+	t.Run("only the worker can set an ask", func(t *testing.T) {
+		pdata := actor.MustConvertParams(types.NewAttoFILFromFIL(2), types.NewBytesAmount(1024), types.ZeroAttoFIL)
+		msg := types.NewMessage(address.TestAddress2, minerAddr, 0, types.ZeroAttoFIL, "setRetrievalAsk", pdata)
+		result, err := th.ApplyTestMessage(st, vms, msg, types.NewBlockHeight(4))
+		require.NoError(t, err)
+		assert.Equal(t, Errors[ErrCallerUnauthorized], result.ExecutionError)
+	})
+}
+
 func TestChangeWorker(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -438,7 +490,7 @@ func callQueryMethodSuccess(method string,
 	vms vm.StorageMap,
 	fromAddr address.Address,
 	minerAddr address.Address) [][]byte {
-	res, code, err := consensus.CallQueryMethod(ctx, st, vms, minerAddr, method, []byte{}, fromAddr, nil)
+	res, code, err := consensus.CallQueryMethod(ctx, st, vms, minerAddr, method, []byte{}, fromAddr, nil, version.Protocol0)
 	require.NoError(t, err)
 	require.Equal(t, uint8(0), code)
 	return res
@@ -0,0 +1,497 @@
+package multisig
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+
+	"github.com/filecoin-project/go-filecoin/abi"
+	"github.com/filecoin-project/go-filecoin/actor"
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/exec"
+	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/vm/errors"
+)
+
+const (
+	// ErrNotSigner indicates a caller that is not a signer of the multisig.
+	ErrNotSigner = 33
+	// ErrUnknownTransaction indicates an invalid transaction id.
+	ErrUnknownTransaction = 34
+	// ErrAlreadyApproved indicates a signer approving a transaction a second time.
+	ErrAlreadyApproved = 35
+	// ErrNotProposer indicates an attempt to cancel a transaction proposed by someone else.
+	ErrNotProposer = 36
+	// ErrInvalidThreshold indicates a threshold of zero or more than the number of signers.
+	ErrInvalidThreshold = 37
+	// ErrDuplicateSigner indicates an attempt to add an address that is already a signer.
+	ErrDuplicateSigner = 38
+	// ErrInsufficientFunds indicates a transaction sending more than the multisig holds.
+	ErrInsufficientFunds = 39
+	// ErrInvalidParams indicates transaction params that do not match their types.
+	ErrInvalidParams = 40
+	// ErrUnknownMethod indicates a transaction calling a method the multisig does not have.
+	ErrUnknownMethod = 41
+)
+
+// Errors map error codes to revert errors this actor may return.
+var Errors = map[uint8]error{
+	ErrNotSigner:          errors.NewCodedRevertError(ErrNotSigner, "caller is not a signer of the multisig"),
+	ErrUnknownTransaction: errors.NewCodedRevertError(ErrUnknownTransaction, "transaction is unknown"),
+	ErrAlreadyApproved:    errors.NewCodedRevertError(ErrAlreadyApproved, "signer already approved the transaction"),
+	ErrNotProposer:        errors.NewCodedRevertError(ErrNotProposer, "only the proposer may cancel a transaction"),
+	ErrInvalidThreshold:   errors.NewCodedRevertError(ErrInvalidThreshold, "threshold must be between one and the number of signers"),
+	ErrDuplicateSigner:    errors.NewCodedRevertError(ErrDuplicateSigner, "address is already a signer"),
+	ErrInsufficientFunds:  errors.NewCodedRevertError(ErrInsufficientFunds, "multisig balance is too low for the transaction"),
+	ErrInvalidParams:      errors.NewCodedRevertError(ErrInvalidParams, "transaction params do not match their types"),
+	ErrUnknownMethod:      errors.NewCodedRevertError(ErrUnknownMethod, "multisig does not have the transaction method"),
+}
+
+func init() {
+	cbor.RegisterCborType(State{})
+	cbor.RegisterCborType(Transaction{})
+}
+
+// Transaction is a message proposed by a signer, sent from the multisig once
+// Threshold signers have approved it.
+type Transaction struct {
+	// Proposer is the signer that proposed the transaction.
+	Proposer address.Address `json:"proposer"`
+
+	// To, Value and Method are the recipient, amount and method of the message.
+	To     address.Address `json:"to"`
+	Value  types.AttoFIL   `json:"value"`
+	Method string          `json:"method"`
+
+	// ParamTypes are the abi types of the message params, and Params the params as
+	// encoded by abi.EncodeValues.
+	ParamTypes []uint64 `json:"paramTypes"`
+	Params     []byte   `json:"params"`
+
+	// Approved are the signers that approved the transaction, the proposer included.
+	Approved []address.Address `json:"approved"`
+}
+
+// State is the multisig actor's storage.
+type State struct {
+	// Signers are the addresses that may propose and approve transactions.
+	Signers []address.Address `json:"signers"`
+
+	// Threshold is the number of signers that must approve a transaction.
+	Threshold uint64 `json:"threshold"`
+
+	// NextTxID is the id of the next proposed transaction.
+	NextTxID uint64 `json:"nextTxID"`
+
+	// Transactions are the pending transactions, by their id as a decimal string.
+	Transactions map[string]*Transaction `json:"transactions"`
+}
+
+// NewState creates a multisig state with the given signers and threshold.
+func NewState(signers []address.Address, threshold uint64) *State {
+	return &State{
+		Signers:      signers,
+		Threshold:    threshold,
+		Transactions: make(map[string]*Transaction),
+	}
+}
+
+// Actor holds funds under the control of a set of signers. Funds only leave it
+// through transactions that one signer proposes and Threshold signers approve.
+// The set of signers and the threshold are changed with transactions the
+// multisig sends to itself.
+type Actor struct{}
+
+// Ensure Actor is an ExecutableActor at compile time.
+var _ exec.ExecutableActor = (*Actor)(nil)
+
+// NewActor returns a new multisig actor.
+func NewActor() *actor.Actor {
+	return actor.NewActor(types.MultisigActorCodeCid, types.ZeroAttoFIL)
+}
+
+// InitializeState stores the actor's initial data structure.
+func (ma *Actor) InitializeState(storage exec.Storage, initializerData interface{}) error {
+	st, ok := initializerData.(*State)
+	if !ok {
+		return errors.NewFaultError("Initial state to multisig actor is not a multisig.State struct")
+	}
+
+	if err := validateSigners(st.Signers, st.Threshold); err != nil {
+		return err
+	}
+
+	stateBytes, err := cbor.DumpObject(st)
+	if err != nil {
+		return errors.FaultErrorWrap(err, "failed to cbor marshal object")
+	}
+
+	id, err := storage.Put(stateBytes)
+	if err != nil {
+		return err
+	}
+
+	return storage.Commit(id, cid.Undef)
+}
+
+// Exports returns the actor's exports.
+func (ma *Actor) Exports() exec.Exports {
+	return multisigExports
+}
+
+var multisigExports = exec.Exports{
+	"propose": &exec.FunctionSignature{
+		Params: []abi.Type{abi.Address, abi.AttoFIL, abi.String, abi.UintArray, abi.Bytes},
+		Return: []abi.Type{abi.Integer},
+	},
+	"approve": &exec.FunctionSignature{
+		Params: []abi.Type{abi.Integer},
+		Return: nil,
+	},
+	"cancel": &exec.FunctionSignature{
+		Params: []abi.Type{abi.Integer},
+		Return: nil,
+	},
+	"addSigner": &exec.FunctionSignature{
+		Params: []abi.Type{abi.Address, abi.Boolean},
+		Return: []abi.Type{abi.Integer},
+	},
+	"removeSigner": &exec.FunctionSignature{
+		Params: []abi.Type{abi.Address, abi.Boolean},
+		Return: []abi.Type{abi.Integer},
+	},
+	"changeThreshold": &exec.FunctionSignature{
+		Params: []abi.Type{abi.Integer},
+		Return: []abi.Type{abi.Integer},
+	},
+	"getState": &exec.FunctionSignature{
+		Params: nil,
+		Return: []abi.Type{abi.Bytes},
+	},
+}
+
+// Propose proposes a transaction sending value to `to` with the given method and
+// params, where params were encoded by abi.EncodeValues from values of paramTypes.
+// The proposer approves the transaction, so it is sent right away if the threshold
+// is one. It returns the id of the transaction.
+func (ma *Actor) Propose(vmctx exec.VMContext, to address.Address, value types.AttoFIL, method string, paramTypes []uint64, params []byte) (*big.Int, uint8, error) {
+	txID, err := propose(vmctx, &Transaction{
+		To:         to,
+		Value:      value,
+		Method:     method,
+		ParamTypes: paramTypes,
+		Params:     params,
+	})
+	if err != nil {
+		return nil, errors.CodeError(err), err
+	}
+
+	return txID, 0, nil
+}
+
+// Approve approves the pending transaction with the given id, and sends it once
+// Threshold signers have approved it.
+func (ma *Actor) Approve(vmctx exec.VMContext, txID *big.Int) (uint8, error) {
+	var state State
+	_, err := actor.WithState(vmctx, &state, func() (interface{}, error) {
+		signer := vmctx.Message().From
+		if !isSigner(state.Signers, signer) {
+			return nil, Errors[ErrNotSigner]
+		}
+
+		tx, ok := state.Transactions[txID.String()]
+		if !ok {
+			return nil, Errors[ErrUnknownTransaction]
+		}
+		if isSigner(tx.Approved, signer) {
+			return nil, Errors[ErrAlreadyApproved]
+		}
+		tx.Approved = append(tx.Approved, signer)
+
+		return nil, executeIfApproved(vmctx, &state, txID.String())
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// Cancel removes a pending transaction. Only its proposer may cancel it.
+func (ma *Actor) Cancel(vmctx exec.VMContext, txID *big.Int) (uint8, error) {
+	var state State
+	_, err := actor.WithState(vmctx, &state, func() (interface{}, error) {
+		tx, ok := state.Transactions[txID.String()]
+		if !ok {
+			return nil, Errors[ErrUnknownTransaction]
+		}
+		if tx.Proposer != vmctx.Message().From {
+			return nil, Errors[ErrNotProposer]
+		}
+
+		delete(state.Transactions, txID.String())
+		return nil, nil
+	})
+	if err != nil {
+		return errors.CodeError(err), err
+	}
+
+	return 0, nil
+}
+
+// AddSigner proposes adding `signer` to the signers, raising the threshold by one if
+// increase is true. Like any transaction, the change is made once approved.
+func (ma *Actor) AddSigner(vmctx exec.VMContext, signer address.Address, increase bool) (*big.Int, uint8, error) {
+	return ma.proposeToSelf(vmctx, "addSigner", signer, increase)
+}
+
+// RemoveSigner proposes removing `signer` from the signers, lowering the threshold by
+// one if decrease is true. Like any transaction, the change is made once approved.
+func (ma *Actor) RemoveSigner(vmctx exec.VMContext, signer address.Address, decrease bool) (*big.Int, uint8, error) {
+	return ma.proposeToSelf(vmctx, "removeSigner", signer, decrease)
+}
+
+// ChangeThreshold proposes changing the number of signers that must approve
+// transactions. Like any transaction, the change is made once approved.
+func (ma *Actor) ChangeThreshold(vmctx exec.VMContext, threshold *big.Int) (*big.Int, uint8, error) {
+	return ma.proposeToSelf(vmctx, "changeThreshold", threshold)
+}
+
+// GetState returns the multisig's signers, threshold and pending transactions as a
+// cbor encoded State.
+func (ma *Actor) GetState(vmctx exec.VMContext) ([]byte, uint8, error) {
+	var state State
+	if err := actor.ReadState(vmctx, &state); err != nil {
+		return nil, errors.CodeError(err), err
+	}
+
+	stateBytes, err := actor.MarshalStorage(state)
+	if err != nil {
+		return nil, 1, errors.FaultErrorWrap(err, "Error marshalling multisig state")
+	}
+
+	return stateBytes, 0, nil
+}
+
+// proposeToSelf proposes a transaction the multisig sends to itself to change its
+// signers or threshold.
+func (ma *Actor) proposeToSelf(vmctx exec.VMContext, method string, params ...interface{}) (*big.Int, uint8, error) {
+	paramTypes, encoded, err := EncodeParams(params...)
+	if err != nil {
+		return nil, 1, errors.FaultErrorWrap(err, "failed to encode params")
+	}
+
+	txID, err := propose(vmctx, &Transaction{
+		To:         vmctx.Message().To,
+		Value:      types.ZeroAttoFIL,
+		Method:     method,
+		ParamTypes: paramTypes,
+		Params:     encoded,
+	})
+	if err != nil {
+		return nil, errors.CodeError(err), err
+	}
+
+	return txID, 0, nil
+}
+
+// EncodeParams encodes the params of a transaction along with their abi types.
+func EncodeParams(params ...interface{}) ([]uint64, []byte, error) {
+	vals, err := abi.ToValues(params)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	encoded, err := abi.EncodeValues(vals)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	paramTypes := make([]uint64, len(vals))
+	for i, v := range vals {
+		paramTypes[i] = uint64(v.Type)
+	}
+	return paramTypes, encoded, nil
+}
+
+// DecodeParams decodes the params of a transaction.
+func DecodeParams(paramTypes []uint64, params []byte) ([]interface{}, error) {
+	abiTypes := make([]abi.Type, len(paramTypes))
+	for i, t := range paramTypes {
+		abiTypes[i] = abi.Type(t)
+	}
+
+	vals, err := abi.DecodeValues(params, abiTypes)
+	if err != nil {
+		return nil, err
+	}
+	return abi.FromValues(vals), nil
+}
+
+// propose adds the transaction, approved by its proposer, and sends it if the
+// proposer's approval is enough.
+func propose(vmctx exec.VMContext, tx *Transaction) (*big.Int, error) {
+	var state State
+	ret, err := actor.WithState(vmctx, &state, func() (interface{}, error) {
+		proposer := vmctx.Message().From
+		if !isSigner(state.Signers, proposer) {
+			return nil, Errors[ErrNotSigner]
+		}
+
+		tx.Proposer = proposer
+		tx.Approved = []address.Address{proposer}
+
+		txID := state.NextTxID
+		state.NextTxID++
+		key := strconv.FormatUint(txID, 10)
+		if state.Transactions == nil {
+			state.Transactions = make(map[string]*Transaction)
+		}
+		state.Transactions[key] = tx
+
+		if err := executeIfApproved(vmctx, &state, key); err != nil {
+			return nil, err
+		}
+		return new(big.Int).SetUint64(txID), nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return ret.(*big.Int), nil
+}
+
+// executeIfApproved sends the transaction with the given key, and removes it, once
+// Threshold signers have approved it.
+func executeIfApproved(vmctx exec.VMContext, state *State, key string) error {
+	tx := state.Transactions[key]
+	if uint64(countSigners(state.Signers, tx.Approved)) < state.Threshold {
+		return nil
+	}
+	delete(state.Transactions, key)
+
+	params, err := DecodeParams(tx.ParamTypes, tx.Params)
+	if err != nil {
+		return errors.NewCodedRevertErrorf(ErrInvalidParams, "invalid params for transaction %s: %s", key, err)
+	}
+
+	// The VM cannot send a message from an actor to itself, so changes to the
+	// multisig are applied here.
+	if tx.To == vmctx.Message().To {
+		return applyToSelf(state, tx.Method, params)
+	}
+
+	if vmctx.MyBalance().LessThan(tx.Value) {
+		return Errors[ErrInsufficientFunds]
+	}
+
+	_, _, err = vmctx.Send(tx.To, tx.Method, tx.Value, params)
+	if err != nil {
+		return errors.RevertErrorWrapf(err, "failed to send transaction %s", key)
+	}
+	return nil
+}
+
+// applyToSelf makes the change to the signers or threshold of an approved
+// transaction the multisig sent to itself.
+func applyToSelf(state *State, method string, params []interface{}) error {
+	invalidParams := errors.NewCodedRevertErrorf(ErrInvalidParams, "invalid params for %s", method)
+
+	signers := state.Signers
+	threshold := state.Threshold
+
+	switch method {
+	case "addSigner":
+		signer, ok1 := paramAt(params, 0).(address.Address)
+		increase, ok2 := paramAt(params, 1).(bool)
+		if !ok1 || !ok2 {
+			return invalidParams
+		}
+		if isSigner(signers, signer) {
+			return Errors[ErrDuplicateSigner]
+		}
+		signers = append(signers, signer)
+		if increase {
+			threshold++
+		}
+	case "removeSigner":
+		signer, ok1 := paramAt(params, 0).(address.Address)
+		decrease, ok2 := paramAt(params, 1).(bool)
+		if !ok1 || !ok2 {
+			return invalidParams
+		}
+		if !isSigner(signers, signer) {
+			return Errors[ErrNotSigner]
+		}
+		signers = removeSigner(signers, signer)
+		if decrease && threshold > 1 {
+			threshold--
+		}
+	case "changeThreshold":
+		newThreshold, ok := paramAt(params, 0).(*big.Int)
+		if !ok || !newThreshold.IsUint64() {
+			return invalidParams
+		}
+		threshold = newThreshold.Uint64()
+	default:
+		return Errors[ErrUnknownMethod]
+	}
+
+	if err := validateSigners(signers, threshold); err != nil {
+		return err
+	}
+	state.Signers = signers
+	state.Threshold = threshold
+	return nil
+}
+
+func validateSigners(signers []address.Address, threshold uint64) error {
+	if threshold == 0 || threshold > uint64(len(signers)) {
+		return Errors[ErrInvalidThreshold]
+	}
+	for i, s := range signers {
+		if isSigner(signers[:i], s) {
+			return Errors[ErrDuplicateSigner]
+		}
+	}
+	return nil
+}
+
+func paramAt(params []interface{}, i int) interface{} {
+	if i >= len(params) {
+		return nil
+	}
+	return params[i]
+}
+
+func isSigner(signers []address.Address, addr address.Address) bool {
+	for _, s := range signers {
+		if s == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// countSigners counts the approvals of addresses that are still signers.
+func countSigners(signers, approved []address.Address) int {
+	n := 0
+	for _, a := range approved {
+		if isSigner(signers, a) {
+			n++
+		}
+	}
+	return n
+}
+
+func removeSigner(signers []address.Address, signer address.Address) []address.Address {
+	out := make([]address.Address, 0, len(signers))
+	for _, s := range signers {
+		if s != signer {
+			out = append(out, s)
+		}
+	}
+	return out
+}
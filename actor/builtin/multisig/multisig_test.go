@@ -0,0 +1,221 @@
+package multisig_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/actor/builtin"
+	. "github.com/filecoin-project/go-filecoin/actor/builtin/multisig"
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/consensus"
+	"github.com/filecoin-project/go-filecoin/state"
+	th "github.com/filecoin-project/go-filecoin/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/vm"
+)
+
+func TestMultisigCreate(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	st, vms := th.RequireCreateStorages(ctx, t)
+
+	signers := []address.Address{address.TestAddress, address.TestAddress2}
+	msigAddr := requireCreateMultisig(t, st, vms, signers, 2, 100)
+
+	msig := state.MustGetActor(st, msigAddr)
+	assert.Equal(t, types.MultisigActorCodeCid, msig.Code)
+	assert.Equal(t, types.NewAttoFILFromFIL(100), msig.Balance)
+
+	msigState := requireMultisigState(t, st, vms, msigAddr)
+	assert.Equal(t, signers, msigState.Signers)
+	assert.Equal(t, uint64(2), msigState.Threshold)
+	assert.Empty(t, msigState.Transactions)
+
+	t.Run("threshold must not exceed the signers", func(t *testing.T) {
+		result, err := th.CreateAndApplyTestMessage(t, st, vms, address.InitAddress, 0, 0, "createMultisig", nil, signers, big.NewInt(3))
+		require.NoError(t, err)
+		require.Error(t, result.ExecutionError)
+		assert.Equal(t, uint8(ErrInvalidThreshold), result.Receipt.ExitCode)
+	})
+
+	t.Run("signers must be distinct", func(t *testing.T) {
+		dup := []address.Address{address.TestAddress, address.TestAddress}
+		result, err := th.CreateAndApplyTestMessage(t, st, vms, address.InitAddress, 0, 0, "createMultisig", nil, dup, big.NewInt(1))
+		require.NoError(t, err)
+		require.Error(t, result.ExecutionError)
+		assert.Equal(t, uint8(ErrDuplicateSigner), result.Receipt.ExitCode)
+	})
+}
+
+func TestMultisigProposeAndApprove(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	st, vms := th.RequireCreateStorages(ctx, t)
+
+	signers := []address.Address{address.TestAddress, address.TestAddress2}
+	msigAddr := requireCreateMultisig(t, st, vms, signers, 2, 100)
+	recipient := address.NewForTestGetter()()
+
+	paramTypes, params, err := EncodeParams()
+	require.NoError(t, err)
+	result := requireApply(t, st, vms, address.TestAddress, msigAddr, "propose", recipient, types.NewAttoFILFromFIL(30), "", paramTypes, params)
+	txID := new(big.Int).SetBytes(result.Receipt.Return[0])
+	assert.Equal(t, uint64(0), txID.Uint64())
+
+	t.Log("the proposal waits for a second approval")
+	msigState := requireMultisigState(t, st, vms, msigAddr)
+	require.Len(t, msigState.Transactions, 1)
+	tx := msigState.Transactions["0"]
+	assert.Equal(t, address.TestAddress, tx.Proposer)
+	assert.Equal(t, recipient, tx.To)
+	assert.Equal(t, []address.Address{address.TestAddress}, tx.Approved)
+	assert.Equal(t, types.NewAttoFILFromFIL(100), state.MustGetActor(st, msigAddr).Balance)
+
+	t.Log("signers cannot approve twice")
+	result, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress, msigAddr, 0, 0, "approve", nil, txID)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(ErrAlreadyApproved), result.Receipt.ExitCode)
+
+	t.Log("the transaction is sent once the threshold approves it")
+	requireApply(t, st, vms, address.TestAddress2, msigAddr, "approve", txID)
+
+	assert.Equal(t, types.NewAttoFILFromFIL(70), state.MustGetActor(st, msigAddr).Balance)
+	assert.Equal(t, types.NewAttoFILFromFIL(30), state.MustGetActor(st, recipient).Balance)
+	assert.Empty(t, requireMultisigState(t, st, vms, msigAddr).Transactions)
+
+	t.Log("executed transactions can no longer be approved")
+	result, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, msigAddr, 0, 0, "approve", nil, txID)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(ErrUnknownTransaction), result.Receipt.ExitCode)
+}
+
+func TestMultisigProposeWithThresholdOne(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	st, vms := th.RequireCreateStorages(ctx, t)
+
+	msigAddr := requireCreateMultisig(t, st, vms, []address.Address{address.TestAddress}, 1, 100)
+	recipient := address.NewForTestGetter()()
+
+	paramTypes, params, err := EncodeParams()
+	require.NoError(t, err)
+
+	t.Log("only signers may propose")
+	result, err := th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, msigAddr, 0, 0, "propose", nil, recipient, types.NewAttoFILFromFIL(30), "", paramTypes, params)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(ErrNotSigner), result.Receipt.ExitCode)
+
+	t.Log("the proposer's approval sends the transaction right away")
+	requireApply(t, st, vms, address.TestAddress, msigAddr, "propose", recipient, types.NewAttoFILFromFIL(30), "", paramTypes, params)
+	assert.Equal(t, types.NewAttoFILFromFIL(30), state.MustGetActor(st, recipient).Balance)
+
+	t.Log("transactions cannot send more than the multisig holds")
+	result, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress, msigAddr, 0, 0, "propose", nil, recipient, types.NewAttoFILFromFIL(71), "", paramTypes, params)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(ErrInsufficientFunds), result.Receipt.ExitCode)
+	assert.Equal(t, types.NewAttoFILFromFIL(70), state.MustGetActor(st, msigAddr).Balance)
+}
+
+func TestMultisigCancel(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	st, vms := th.RequireCreateStorages(ctx, t)
+
+	signers := []address.Address{address.TestAddress, address.TestAddress2}
+	msigAddr := requireCreateMultisig(t, st, vms, signers, 2, 100)
+
+	paramTypes, params, err := EncodeParams()
+	require.NoError(t, err)
+	result := requireApply(t, st, vms, address.TestAddress, msigAddr, "propose", address.TestAddress2, types.NewAttoFILFromFIL(30), "", paramTypes, params)
+	txID := new(big.Int).SetBytes(result.Receipt.Return[0])
+
+	t.Log("only the proposer may cancel")
+	result, err = th.CreateAndApplyTestMessageFrom(t, st, vms, address.TestAddress2, msigAddr, 0, 0, "cancel", nil, txID)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(ErrNotProposer), result.Receipt.ExitCode)
+
+	requireApply(t, st, vms, address.TestAddress, msigAddr, "cancel", txID)
+	assert.Empty(t, requireMultisigState(t, st, vms, msigAddr).Transactions)
+}
+
+func TestMultisigChangeSigners(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	st, vms := th.RequireCreateStorages(ctx, t)
+
+	signers := []address.Address{address.TestAddress, address.TestAddress2}
+	msigAddr := requireCreateMultisig(t, st, vms, signers, 2, 0)
+	newSigner := address.NewForTestGetter()()
+
+	t.Log("adding a signer takes the approval of the threshold")
+	result := requireApply(t, st, vms, address.TestAddress, msigAddr, "addSigner", newSigner, true)
+	txID := new(big.Int).SetBytes(result.Receipt.Return[0])
+
+	msigState := requireMultisigState(t, st, vms, msigAddr)
+	assert.Equal(t, signers, msigState.Signers)
+	require.Len(t, msigState.Transactions, 1)
+	params, err := DecodeParams(msigState.Transactions["0"].ParamTypes, msigState.Transactions["0"].Params)
+	require.NoError(t, err)
+	assert.Equal(t, []interface{}{newSigner, true}, params)
+
+	requireApply(t, st, vms, address.TestAddress2, msigAddr, "approve", txID)
+
+	msigState = requireMultisigState(t, st, vms, msigAddr)
+	assert.Equal(t, []address.Address{address.TestAddress, address.TestAddress2, newSigner}, msigState.Signers)
+	assert.Equal(t, uint64(3), msigState.Threshold)
+
+	t.Log("thresholds above the number of signers are refused when executed")
+	_, err = th.CreateAndApplyTestMessage(t, st, vms, newSigner, 1000, 0, "", nil)
+	require.NoError(t, err)
+	result = requireApply(t, st, vms, address.TestAddress, msigAddr, "changeThreshold", big.NewInt(4))
+	txID = new(big.Int).SetBytes(result.Receipt.Return[0])
+	requireApply(t, st, vms, address.TestAddress2, msigAddr, "approve", txID)
+	result, err = th.CreateAndApplyTestMessageFrom(t, st, vms, newSigner, msigAddr, 0, 0, "approve", nil, txID)
+	require.NoError(t, err)
+	assert.Equal(t, uint8(ErrInvalidThreshold), result.Receipt.ExitCode)
+	assert.Len(t, requireMultisigState(t, st, vms, msigAddr).Transactions, 1)
+
+	t.Log("removing a signer lowers the threshold")
+	result = requireApply(t, st, vms, address.TestAddress, msigAddr, "removeSigner", newSigner, true)
+	txID = new(big.Int).SetBytes(result.Receipt.Return[0])
+	requireApply(t, st, vms, address.TestAddress2, msigAddr, "approve", txID)
+	requireApply(t, st, vms, newSigner, msigAddr, "approve", txID)
+
+	msigState = requireMultisigState(t, st, vms, msigAddr)
+	assert.Equal(t, signers, msigState.Signers)
+	assert.Equal(t, uint64(2), msigState.Threshold)
+	assert.Len(t, msigState.Transactions, 1)
+}
+
+func requireCreateMultisig(t *testing.T, st state.Tree, vms vm.StorageMap, signers []address.Address, threshold int64, value uint64) address.Address {
+	result, err := th.CreateAndApplyTestMessage(t, st, vms, address.InitAddress, value, 0, "createMultisig", nil, signers, big.NewInt(threshold))
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+
+	addr, err := address.NewFromBytes(result.Receipt.Return[0])
+	require.NoError(t, err)
+	return addr
+}
+
+func requireApply(t *testing.T, st state.Tree, vms vm.StorageMap, from, to address.Address, method string, params ...interface{}) *consensus.ApplicationResult {
+	result, err := th.CreateAndApplyTestMessageFrom(t, st, vms, from, to, 0, 0, method, nil, params...)
+	require.NoError(t, err)
+	require.NoError(t, result.ExecutionError)
+	return result
+}
+
+func requireMultisigState(t *testing.T, st state.Tree, vms vm.StorageMap, addr address.Address) *State {
+	var msigState State
+	builtin.RequireReadState(t, vms, addr, state.MustGetActor(st, addr), &msigState)
+	return &msigState
+}
@@ -103,18 +103,11 @@ func (ma *FakeActor) Exports() exec.Exports {
 
 // HasReturnValue is a dummy method that does nothing.
 func (ma *FakeActor) HasReturnValue(ctx exec.VMContext) (address.Address, uint8, error) {
-	if err := ctx.Charge(100); err != nil {
-		return address.Undef, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
-
 	return address.Undef, 0, nil
 }
 
-// ChargeGasAndRevertError simply charges gas and returns a revert error
+// ChargeGasAndRevertError simply returns a revert error, after the VM has charged gas for the call
 func (ma *FakeActor) ChargeGasAndRevertError(ctx exec.VMContext) (uint8, error) {
-	if err := ctx.Charge(100); err != nil {
-		panic("Unexpected error charging gas")
-	}
 	return 1, errors.NewRevertError("boom")
 }
 
@@ -201,9 +194,6 @@ func (ma *FakeActor) AttemptMultiSpend2(ctx exec.VMContext, self, target address
 
 // RunsAnotherMessage sends a message
 func (ma *FakeActor) RunsAnotherMessage(ctx exec.VMContext, target address.Address) (uint8, error) {
-	if err := ctx.Charge(100); err != nil {
-		return exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
-	}
 	_, code, err := ctx.Send(target, "hasReturnValue", types.ZeroAttoFIL, []interface{}{})
 	return code, err
 }
@@ -0,0 +1,136 @@
+// Package auth issues and verifies the tokens that authorize calls to the node's HTTP API.
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/pkg/errors"
+)
+
+// Permission is the level of access to the API granted by a token. Each permission includes
+// the permissions below it.
+type Permission string
+
+const (
+	// Read permits calls that inspect the node and the chain.
+	Read = Permission("read")
+	// Write permits calls that change the node's local state, such as importing data.
+	Write = Permission("write")
+	// Sign permits calls that sign and send messages or vouchers with the node's wallet.
+	Sign = Permission("sign")
+	// Admin permits all calls, including those that export keys or control the daemon.
+	Admin = Permission("admin")
+)
+
+// Permissions lists all permissions from least to most privileged.
+var Permissions = []Permission{Read, Write, Sign, Admin}
+
+// secretKey is the datastore key under which the token signing secret is stored.
+var secretKey = datastore.NewKey("/auth/secret")
+
+// ErrInvalidToken is returned when a token is malformed or was not issued by the authority.
+var ErrInvalidToken = errors.New("invalid API token")
+
+// ParsePermission parses the name of a permission.
+func ParsePermission(s string) (Permission, error) {
+	for _, p := range Permissions {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	return "", errors.Errorf("unknown permission %q, expected one of read, write, sign or admin", s)
+}
+
+// Allows returns true if p grants the access that required grants.
+func (p Permission) Allows(required Permission) bool {
+	return p.level() >= required.level()
+}
+
+// level returns the rank of p, or -1 if p is not a known permission.
+func (p Permission) level() int {
+	for i, q := range Permissions {
+		if p == q {
+			return i
+		}
+	}
+	return -1
+}
+
+// tokenPayload is the signed content of a token.
+type tokenPayload struct {
+	Perm Permission `json:"perm"`
+}
+
+// Authority issues tokens signed with a secret and verifies the tokens it issued.
+type Authority struct {
+	secret []byte
+}
+
+// NewAuthority creates an authority that signs tokens with secret.
+func NewAuthority(secret []byte) *Authority {
+	return &Authority{secret: secret}
+}
+
+// LoadAuthority creates an authority with the secret stored in ds, generating and storing a new
+// secret if there is none.
+func LoadAuthority(ds datastore.Datastore) (*Authority, error) {
+	secret, err := ds.Get(secretKey)
+	if err == datastore.ErrNotFound {
+		secret = make([]byte, 32)
+		if _, err := rand.Read(secret); err != nil {
+			return nil, errors.Wrap(err, "failed to generate API token secret")
+		}
+		if err := ds.Put(secretKey, secret); err != nil {
+			return nil, errors.Wrap(err, "failed to store API token secret")
+		}
+	} else if err != nil {
+		return nil, errors.Wrap(err, "failed to load API token secret")
+	}
+	return NewAuthority(secret), nil
+}
+
+// NewToken issues a token granting perm.
+func (a *Authority) NewToken(perm Permission) (string, error) {
+	if perm.level() < 0 {
+		return "", errors.Errorf("unknown permission %q", perm)
+	}
+	raw, err := json.Marshal(tokenPayload{Perm: perm})
+	if err != nil {
+		return "", err
+	}
+	payload := base64.RawURLEncoding.EncodeToString(raw)
+	return payload + "." + base64.RawURLEncoding.EncodeToString(a.sign(payload)), nil
+}
+
+// Verify checks that token was issued by the authority and returns the permission it grants.
+func (a *Authority) Verify(token string) (Permission, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 2 {
+		return "", ErrInvalidToken
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil || !hmac.Equal(sig, a.sign(parts[0])) {
+		return "", ErrInvalidToken
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return "", ErrInvalidToken
+	}
+	var payload tokenPayload
+	if err := json.Unmarshal(raw, &payload); err != nil || payload.Perm.level() < 0 {
+		return "", ErrInvalidToken
+	}
+	return payload.Perm, nil
+}
+
+func (a *Authority) sign(payload string) []byte {
+	mac := hmac.New(sha256.New, a.secret)
+	mac.Write([]byte(payload)) // nolint: errcheck
+	return mac.Sum(nil)
+}
@@ -0,0 +1,87 @@
+package auth_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/auth"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+)
+
+func TestPermissionAllows(t *testing.T) {
+	tf.UnitTest(t)
+
+	assert.True(t, auth.Admin.Allows(auth.Read))
+	assert.True(t, auth.Sign.Allows(auth.Write))
+	assert.True(t, auth.Write.Allows(auth.Write))
+	assert.False(t, auth.Write.Allows(auth.Sign))
+	assert.False(t, auth.Read.Allows(auth.Admin))
+	assert.False(t, auth.Permission("root").Allows(auth.Read))
+
+	perm, err := auth.ParsePermission("sign")
+	require.NoError(t, err)
+	assert.Equal(t, auth.Sign, perm)
+	_, err = auth.ParsePermission("root")
+	assert.Error(t, err)
+}
+
+func TestAuthorityTokens(t *testing.T) {
+	tf.UnitTest(t)
+
+	ds := datastore.NewMapDatastore()
+	authority, err := auth.LoadAuthority(ds)
+	require.NoError(t, err)
+
+	for _, perm := range auth.Permissions {
+		token, err := authority.NewToken(perm)
+		require.NoError(t, err)
+		verified, err := authority.Verify(token)
+		require.NoError(t, err)
+		assert.Equal(t, perm, verified)
+	}
+
+	t.Run("the secret is reloaded from the datastore", func(t *testing.T) {
+		token, err := authority.NewToken(auth.Write)
+		require.NoError(t, err)
+
+		reloaded, err := auth.LoadAuthority(ds)
+		require.NoError(t, err)
+		perm, err := reloaded.Verify(token)
+		require.NoError(t, err)
+		assert.Equal(t, auth.Write, perm)
+	})
+
+	t.Run("tokens from another authority are rejected", func(t *testing.T) {
+		other, err := auth.LoadAuthority(datastore.NewMapDatastore())
+		require.NoError(t, err)
+		token, err := other.NewToken(auth.Admin)
+		require.NoError(t, err)
+
+		_, err = authority.Verify(token)
+		assert.Equal(t, auth.ErrInvalidToken, err)
+	})
+
+	t.Run("tampered and malformed tokens are rejected", func(t *testing.T) {
+		readToken, err := authority.NewToken(auth.Read)
+		require.NoError(t, err)
+		adminToken, err := authority.NewToken(auth.Admin)
+		require.NoError(t, err)
+
+		// Swap the payload of the read token for that of the admin token.
+		readSig := strings.Split(readToken, ".")[1]
+		adminPayload := strings.Split(adminToken, ".")[0]
+		for _, token := range []string{adminPayload + "." + readSig, "", "abc", "a.b.c"} {
+			_, err := authority.Verify(token)
+			assert.Equal(t, auth.ErrInvalidToken, err, token)
+		}
+	})
+
+	t.Run("unknown permissions cannot be issued", func(t *testing.T) {
+		_, err := authority.NewToken(auth.Permission("root"))
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,99 @@
+package chain
+
+import (
+	"context"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-cid"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+// CollectGarbage deletes from bs the states of the tipsets of the chain ending at head that are
+// more than keepDepth tipsets below it, except for blocks that are also reachable from the
+// keepDepth most recent tipsets. Block headers, messages, receipts and the genesis state are
+// kept, so that the chain may still be loaded and searched for messages, and blocks that are
+// not part of the chain, such as imported client data, are never deleted. The caller must
+// ensure that no state is written to bs while it runs, since a new state may share blocks with
+// the old states. It returns the number of blocks deleted.
+func CollectGarbage(ctx context.Context, chain tipSetStateReader, bs bstore.Blockstore, head types.TipSet, keepDepth uint) (int, error) {
+	if keepDepth == 0 {
+		return 0, errors.New("keep depth must be at least 1")
+	}
+
+	var tipsets []types.TipSet
+	var roots []cid.Cid
+	for iter := IterAncestors(ctx, chain, head); !iter.Complete(); {
+		ts := iter.Value()
+		// The state of a tipset may not be available, for example below the recent states of
+		// an imported snapshot.
+		root, err := chain.GetTipSetStateRoot(ts.Key())
+		if err != nil && errors.Cause(err) != ErrStateNotAvailable {
+			return 0, errors.Wrapf(err, "failed to get state root of tipset %s", ts.Key())
+		}
+		tipsets = append(tipsets, ts)
+		roots = append(roots, root)
+
+		if err := iter.Next(); err != nil {
+			return 0, errors.Wrapf(err, "failed to get parent of tipset %s", ts.Key())
+		}
+	}
+	if uint(len(tipsets)) <= keepDepth {
+		return 0, nil
+	}
+
+	// Mark the blocks reachable from the recent tipsets and the genesis state, which is found
+	// from the genesis block if the store does not record it. States may be missing, for example
+	// below the recent states of an imported snapshot.
+	marked := cid.NewSet()
+	mark := func(blocks.Block) error { return nil }
+	for i := uint(0); i < keepDepth; i++ {
+		if err := walkTipSet(ctx, bs, tipsets[i], roots[i], marked, mark); err != nil {
+			return 0, err
+		}
+	}
+	genesisState := roots[len(roots)-1]
+	if !genesisState.Defined() {
+		genesisState = tipsets[len(tipsets)-1].At(0).StateRoot
+	}
+	if err := walkDAG(ctx, bs, genesisState, marked, true, mark); err != nil {
+		return 0, errors.Wrap(err, "failed to mark genesis state")
+	}
+
+	// Sweep the unmarked blocks reachable from the older tipsets. Marked blocks are skipped
+	// along with the sub-DAGs below them, which are marked too.
+	deleted := 0
+	sweep := func(blk blocks.Block) error {
+		if err := bs.DeleteBlock(blk.Cid()); err != nil {
+			return errors.Wrapf(err, "failed to delete %s", blk.Cid())
+		}
+		deleted++
+		return nil
+	}
+	for i := keepDepth; i < uint(len(tipsets)); i++ {
+		if err := walkTipSet(ctx, bs, tipsets[i], roots[i], marked, sweep); err != nil {
+			return deleted, err
+		}
+	}
+	return deleted, nil
+}
+
+// walkTipSet visits the parent states of the blocks of ts and the state of ts, if it is defined,
+// skipping any missing blocks.
+func walkTipSet(ctx context.Context, bs bstore.Blockstore, ts types.TipSet, stateRoot cid.Cid, seen *cid.Set, visit func(blocks.Block) error) error {
+	for i := 0; i < ts.Len(); i++ {
+		blk := ts.At(i)
+		if err := walkDAG(ctx, bs, blk.StateRoot, seen, true, visit); err != nil {
+			return errors.Wrapf(err, "failed to walk state of block %s", blk.Cid())
+		}
+	}
+	if !stateRoot.Defined() {
+		return nil
+	}
+	if err := walkDAG(ctx, bs, stateRoot, seen, true, visit); err != nil {
+		return errors.Wrapf(err, "failed to walk state of tipset %s", ts.Key())
+	}
+	return nil
+}
@@ -0,0 +1,119 @@
+package chain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/actor"
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/chain"
+	"github.com/filecoin-project/go-filecoin/repo"
+	"github.com/filecoin-project/go-filecoin/state"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestCollectGarbage(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	bs, cst := newSnapshotBlockstore()
+
+	// Build a chain with a distinct state for each tipset.
+	builder := chain.NewBuilder(t, address.Undef)
+	gen := builder.NewGenesis()
+	// The messages and receipts of a pruned tipset are kept.
+	mockSigner, _ := types.NewMockSignersAndKeyInfo(1)
+	msgs := types.NewSignedMsgs(1, mockSigner)
+	rcpts := []*types.MessageReceipt{{ExitCode: 0}}
+	link1 := builder.BuildOn(gen, 2, func(b *chain.BlockBuilder, i int) {
+		if i == 0 {
+			b.AddMessages(msgs, rcpts)
+		}
+	})
+	link2 := builder.AppendOn(link1, 1)
+	link3 := builder.AppendOn(link2, 1)
+	tipsets := []types.TipSet{gen, link1, link2, link3}
+
+	newAddress := address.NewForTestGetter()
+	st := state.NewEmptyStateTree(cst)
+	var roots []cid.Cid
+	for _, ts := range tipsets {
+		requirePutBlocksToCborStore(t, cst, ts.ToSlice()...)
+		require.NoError(t, st.SetActor(ctx, newAddress(), actor.NewActor(types.AccountActorCodeCid, types.NewAttoFILFromFIL(1))))
+		root, err := st.Flush(ctx)
+		require.NoError(t, err)
+		roots = append(roots, root)
+	}
+	_, err := cst.Put(ctx, types.MessageCollection{})
+	require.NoError(t, err)
+	_, err = cst.Put(ctx, types.ReceiptCollection{})
+	require.NoError(t, err)
+	messages := chain.NewMessageStore(cst)
+	msgsCid, err := messages.StoreMessages(ctx, msgs)
+	require.NoError(t, err)
+	require.Equal(t, link1.At(0).Messages, msgsCid)
+	rcptsCid, err := messages.StoreReceipts(ctx, rcpts)
+	require.NoError(t, err)
+	require.Equal(t, link1.At(0).MessageReceipts, rcptsCid)
+
+	// Data that is not part of the chain, such as a client import.
+	unrelated, err := cst.Put(ctx, "client data")
+	require.NoError(t, err)
+
+	store := chain.NewStore(repo.NewInMemoryRepo().Datastore(), cst, &state.TreeStateLoader{}, chain.NewStatusReporter(), gen.At(0).Cid())
+	for i, ts := range tipsets {
+		require.NoError(t, store.PutTipSetAndState(ctx, &chain.TipSetAndState{TipSet: ts, TipSetStateRoot: roots[i]}))
+	}
+	require.NoError(t, store.SetHead(ctx, link3))
+
+	requireHas := func(c cid.Cid, expected bool) {
+		has, err := bs.Has(c)
+		require.NoError(t, err)
+		assert.Equal(t, expected, has, "block %s", c)
+	}
+
+	t.Run("zero keep depth is rejected", func(t *testing.T) {
+		_, err := chain.CollectGarbage(ctx, store, bs, link3, 0)
+		assert.Error(t, err)
+	})
+
+	t.Run("nothing is deleted from a chain no longer than the keep depth", func(t *testing.T) {
+		deleted, err := chain.CollectGarbage(ctx, store, bs, link3, 4)
+		require.NoError(t, err)
+		assert.Equal(t, 0, deleted)
+	})
+
+	t.Run("old states are deleted", func(t *testing.T) {
+		deleted, err := chain.CollectGarbage(ctx, store, bs, link3, 2)
+		require.NoError(t, err)
+		assert.Equal(t, 1, deleted)
+
+		requireHas(roots[0], true)
+		requireHas(roots[1], false)
+		requireHas(roots[2], true)
+		requireHas(roots[3], true)
+		for _, ts := range tipsets {
+			for _, blk := range ts.ToSlice() {
+				requireHas(blk.Cid(), true)
+				requireHas(blk.Messages, true)
+				requireHas(blk.MessageReceipts, true)
+			}
+		}
+		requireHas(unrelated, true)
+
+		// The chain still loads and the head state is intact.
+		require.NoError(t, store.Load(ctx))
+		_, err = store.GetTipSetState(ctx, link3.Key())
+		assert.NoError(t, err)
+
+		// Collecting again deletes nothing more.
+		deleted, err = chain.CollectGarbage(ctx, store, bs, link3, 2)
+		require.NoError(t, err)
+		assert.Equal(t, 0, deleted)
+	})
+}
@@ -0,0 +1,325 @@
+package chain
+
+import (
+	"context"
+	"io"
+
+	blocks "github.com/ipfs/go-block-format"
+	"github.com/ipfs/go-car"
+	carutil "github.com/ipfs/go-car/util"
+	"github.com/ipfs/go-cid"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func init() {
+	cbor.RegisterCborType(Snapshot{})
+}
+
+// Snapshot is the root object of a chain snapshot. It records the head of the exported chain
+// and the state roots of the tipsets whose states the snapshot includes, which are not recorded
+// in the blocks of tipsets with more than one block.
+type Snapshot struct {
+	Head types.TipSetKey
+	// StateRoots holds the state root of each of the most recent tipsets, from the head back,
+	// whose states the snapshot includes.
+	StateRoots []cid.Cid
+}
+
+// minSnapshotStates is the number of most recent tipsets whose states a snapshot always
+// includes: the state of the head, which its children are validated against, and that of its
+// parent, which the head is weighed with.
+const minSnapshotStates = 2
+
+// tipSetStateReader is the subset of the Store that ExportSnapshot and CollectGarbage use.
+type tipSetStateReader interface {
+	TipSetProvider
+	GetTipSetStateRoot(key types.TipSetKey) (cid.Cid, error)
+}
+
+// snapshotChainWriter is the subset of the Store that ImportSnapshot uses.
+type snapshotChainWriter interface {
+	tipSetStateReader
+	GenesisCid() cid.Cid
+	GetHead() types.TipSetKey
+	PutTipSetAndState(ctx context.Context, tsas *TipSetAndState) error
+	PutTipSetWithoutState(ctx context.Context, ts types.TipSet) error
+	SetHead(ctx context.Context, ts types.TipSet) error
+}
+
+// snapshotWeigher compares the weights of tipsets applied to the given parent states.
+type snapshotWeigher interface {
+	IsHeavier(ctx context.Context, a, b types.TipSet, aStateID, bStateID cid.Cid) (bool, error)
+}
+
+// ExportSnapshot writes a snapshot of the chain ending at head to out as a CAR file rooted at a
+// Snapshot. The snapshot includes the block headers, messages and receipts of every tipset back
+// to genesis, and the state trees of the recentStates most recent tipsets, and at least those of
+// the head and its parent. States that are not available, such as below the recent states of
+// an imported snapshot, are left out along with the states of the tipsets below them.
+func ExportSnapshot(ctx context.Context, chain tipSetStateReader, bs bstore.Blockstore, head types.TipSet, recentStates uint, out io.Writer) error {
+	if recentStates < minSnapshotStates {
+		recentStates = minSnapshotStates
+	}
+
+	var tipsets []types.TipSet
+	snapshot := Snapshot{Head: head.Key()}
+	for iter := IterAncestors(ctx, chain, head); !iter.Complete(); {
+		ts := iter.Value()
+		tipsets = append(tipsets, ts)
+		if uint(len(snapshot.StateRoots)) == uint(len(tipsets)-1) && uint(len(tipsets)) <= recentStates {
+			root, err := chain.GetTipSetStateRoot(ts.Key())
+			if err != nil && (errors.Cause(err) != ErrStateNotAvailable || len(tipsets) <= minSnapshotStates) {
+				return errors.Wrapf(err, "failed to get state root of tipset %s", ts.Key())
+			}
+			if err == nil {
+				snapshot.StateRoots = append(snapshot.StateRoots, root)
+			}
+		}
+
+		if err := iter.Next(); err != nil {
+			return errors.Wrapf(err, "failed to get parent of tipset %s", ts.Key())
+		}
+	}
+
+	snapshotNode, err := cbor.WrapObject(snapshot, types.DefaultHashFunction, -1)
+	if err != nil {
+		return errors.Wrap(err, "failed to encode snapshot")
+	}
+	header, err := cbor.DumpObject(&car.CarHeader{Roots: []cid.Cid{snapshotNode.Cid()}, Version: 1})
+	if err != nil {
+		return errors.Wrap(err, "failed to encode car header")
+	}
+	if err := carutil.LdWrite(out, header); err != nil {
+		return errors.Wrap(err, "failed to write car header")
+	}
+	if err := carutil.LdWrite(out, snapshotNode.Cid().Bytes(), snapshotNode.RawData()); err != nil {
+		return errors.Wrap(err, "failed to write snapshot")
+	}
+
+	w := &dagWriter{bs: bs, out: out, seen: cid.NewSet()}
+	for i, ts := range tipsets {
+		for j := 0; j < ts.Len(); j++ {
+			blk := ts.At(j)
+			// Write only the header of the block, whose links lead to its ancestors and states.
+			if err := w.writeBlock(blk.Cid()); err != nil {
+				return errors.Wrapf(err, "failed to write block %s", blk.Cid())
+			}
+			if err := w.writeDAG(ctx, blk.Messages); err != nil {
+				return errors.Wrapf(err, "failed to write messages of block %s", blk.Cid())
+			}
+			if err := w.writeDAG(ctx, blk.MessageReceipts); err != nil {
+				return errors.Wrapf(err, "failed to write receipts of block %s", blk.Cid())
+			}
+		}
+		if i < len(snapshot.StateRoots) {
+			if err := w.writeDAG(ctx, snapshot.StateRoots[i]); err != nil {
+				return errors.Wrapf(err, "failed to write state of tipset %s", ts.Key())
+			}
+		}
+	}
+	return nil
+}
+
+// ImportSnapshot loads a snapshot written by ExportSnapshot from in into bs, records the tipsets
+// of the snapshot in chain and sets the head of chain to the head of the snapshot. Only the
+// tipsets whose states the snapshot includes are recorded with a state; the states of the
+// others are not available. The snapshot is trusted: its tipsets and state transitions are not
+// validated, but it must descend from the genesis block of chain, and its head must be heavier
+// than the head of chain as weighed by weigher. It returns the head of the snapshot.
+func ImportSnapshot(ctx context.Context, chain snapshotChainWriter, weigher snapshotWeigher, bs bstore.Blockstore, in io.Reader) (types.TipSet, error) {
+	header, err := car.LoadCar(bs, in)
+	if err != nil {
+		return types.UndefTipSet, errors.Wrap(err, "failed to load snapshot")
+	}
+	if len(header.Roots) != 1 {
+		return types.UndefTipSet, errors.Errorf("expected snapshot with a single root, found %d", len(header.Roots))
+	}
+	raw, err := bs.Get(header.Roots[0])
+	if err != nil {
+		return types.UndefTipSet, errors.Wrap(err, "failed to get snapshot root")
+	}
+	var snapshot Snapshot
+	if err := cbor.DecodeInto(raw.RawData(), &snapshot); err != nil {
+		return types.UndefTipSet, errors.Wrap(err, "failed to decode snapshot root")
+	}
+
+	provider := &blockstoreTipSetProvider{bs: bs}
+	headTs, err := provider.GetTipSet(snapshot.Head)
+	if err != nil {
+		return types.UndefTipSet, errors.Wrap(err, "failed to load snapshot head")
+	}
+
+	var tipsets []types.TipSet
+	for iter := IterAncestors(ctx, provider, headTs); !iter.Complete(); {
+		tipsets = append(tipsets, iter.Value())
+		if err := iter.Next(); err != nil {
+			return types.UndefTipSet, errors.Wrap(err, "failed to load snapshot tipset")
+		}
+	}
+	if len(snapshot.StateRoots) > len(tipsets) {
+		return types.UndefTipSet, errors.Errorf("snapshot has %d tipsets but %d state roots", len(tipsets), len(snapshot.StateRoots))
+	}
+	if len(snapshot.StateRoots) < minSnapshotStates && len(snapshot.StateRoots) < len(tipsets) {
+		return types.UndefTipSet, errors.Errorf("snapshot includes %d states, expected at least %d", len(snapshot.StateRoots), minSnapshotStates)
+	}
+	for i, root := range snapshot.StateRoots {
+		has, err := bs.Has(root)
+		if err != nil {
+			return types.UndefTipSet, err
+		}
+		if !has {
+			return types.UndefTipSet, errors.Errorf("snapshot is missing state %s of tipset %s", root, tipsets[i].Key())
+		}
+	}
+	genesis := tipsets[len(tipsets)-1]
+	if genesis.Len() != 1 || !genesis.At(0).Cid().Equals(chain.GenesisCid()) {
+		return types.UndefTipSet, errors.Errorf("snapshot genesis %s does not match genesis block %s", genesis.Key(), chain.GenesisCid())
+	}
+
+	heavier, err := isHeavierThanHead(ctx, chain, weigher, tipsets, snapshot.StateRoots)
+	if err != nil {
+		return types.UndefTipSet, errors.Wrap(err, "failed to weigh snapshot head")
+	}
+	if !heavier {
+		return types.UndefTipSet, errors.Errorf("snapshot head %s is not heavier than chain head %s", headTs.Key(), chain.GetHead())
+	}
+
+	for i, ts := range tipsets {
+		var err error
+		if i < len(snapshot.StateRoots) {
+			err = chain.PutTipSetAndState(ctx, &TipSetAndState{
+				TipSet:          ts,
+				TipSetStateRoot: snapshot.StateRoots[i],
+			})
+		} else if _, err = chain.GetTipSetStateRoot(ts.Key()); err != nil {
+			// Keep the states already stored, such as that of genesis.
+			err = chain.PutTipSetWithoutState(ctx, ts)
+		}
+		if err != nil {
+			return types.UndefTipSet, errors.Wrapf(err, "failed to put tipset %s", ts.Key())
+		}
+	}
+	if err := chain.SetHead(ctx, headTs); err != nil {
+		return types.UndefTipSet, errors.Wrap(err, "failed to set head")
+	}
+	return headTs, nil
+}
+
+// isHeavierThanHead tests whether the first of tipsets, which run from a snapshot head back to
+// genesis with the given state roots, is heavier than the head of chain. Each head is weighed
+// against the state of its parent.
+func isHeavierThanHead(ctx context.Context, chain snapshotChainWriter, weigher snapshotWeigher, tipsets []types.TipSet, stateRoots []cid.Cid) (bool, error) {
+	var snapshotParentState cid.Cid
+	if len(tipsets) > 1 {
+		snapshotParentState = stateRoots[1]
+	}
+
+	head, err := chain.GetTipSet(chain.GetHead())
+	if err != nil {
+		return false, err
+	}
+	headParentKey, err := head.Parents()
+	if err != nil {
+		return false, err
+	}
+	var headParentState cid.Cid
+	if !headParentKey.Empty() { // head is not genesis
+		headParentState, err = chain.GetTipSetStateRoot(headParentKey)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return weigher.IsHeavier(ctx, tipsets[0], head, snapshotParentState, headParentState)
+}
+
+// dagWriter writes blocks from a blockstore to a CAR file, each at most once.
+type dagWriter struct {
+	bs   bstore.Blockstore
+	out  io.Writer
+	seen *cid.Set
+}
+
+// writeBlock writes the block with CID c, if it has not been written already.
+func (w *dagWriter) writeBlock(c cid.Cid) error {
+	if !w.seen.Visit(c) {
+		return nil
+	}
+	blk, err := w.bs.Get(c)
+	if err != nil {
+		return err
+	}
+	return carutil.LdWrite(w.out, c.Bytes(), blk.RawData())
+}
+
+// writeDAG writes the DAG rooted at root, skipping any sub-DAGs that have been written already.
+func (w *dagWriter) writeDAG(ctx context.Context, root cid.Cid) error {
+	return walkDAG(ctx, w.bs, root, w.seen, false, func(blk blocks.Block) error {
+		return carutil.LdWrite(w.out, blk.Cid().Bytes(), blk.RawData())
+	})
+}
+
+// walkDAG visits each block of the DAG rooted at root in bs, skipping blocks in seen and the
+// sub-DAGs below them, and adds the visited blocks to seen. Missing blocks are skipped if
+// skipMissing is set, as are missing raw blocks, since builtin actor code is linked from
+// states by CID but is not necessarily stored.
+func walkDAG(ctx context.Context, bs bstore.Blockstore, root cid.Cid, seen *cid.Set, skipMissing bool, visit func(blocks.Block) error) error {
+	stack := []cid.Cid{root}
+	for len(stack) > 0 {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		c := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if !seen.Visit(c) {
+			continue
+		}
+
+		blk, err := bs.Get(c)
+		if err == bstore.ErrNotFound && (skipMissing || c.Type() == cid.Raw) {
+			continue
+		}
+		if err != nil {
+			return errors.Wrapf(err, "failed to get %s", c)
+		}
+		if err := visit(blk); err != nil {
+			return err
+		}
+		if c.Type() != cid.DagCBOR {
+			continue
+		}
+		node, err := cbor.DecodeBlock(blk)
+		if err != nil {
+			return errors.Wrapf(err, "failed to decode %s", c)
+		}
+		for _, link := range node.Links() {
+			stack = append(stack, link.Cid)
+		}
+	}
+	return nil
+}
+
+// blockstoreTipSetProvider provides tipsets from the block headers in a blockstore.
+type blockstoreTipSetProvider struct {
+	bs bstore.Blockstore
+}
+
+func (p *blockstoreTipSetProvider) GetTipSet(key types.TipSetKey) (types.TipSet, error) {
+	var blocks []*types.Block
+	for iter := key.Iter(); !iter.Complete(); iter.Next() {
+		raw, err := p.bs.Get(iter.Value())
+		if err != nil {
+			return types.UndefTipSet, errors.Wrapf(err, "failed to get block %s", iter.Value())
+		}
+		blk, err := types.DecodeBlock(raw.RawData())
+		if err != nil {
+			return types.UndefTipSet, errors.Wrapf(err, "failed to decode block %s", iter.Value())
+		}
+		blocks = append(blocks, blk)
+	}
+	return types.NewTipSet(blocks...)
+}
@@ -0,0 +1,153 @@
+package chain_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+
+	bserv "github.com/ipfs/go-blockservice"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-hamt-ipld"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	offline "github.com/ipfs/go-ipfs-exchange-offline"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/actor"
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/chain"
+	"github.com/filecoin-project/go-filecoin/repo"
+	"github.com/filecoin-project/go-filecoin/state"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestSnapshotExportImport(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	bs, cst := newSnapshotBlockstore()
+
+	// Build a chain with a multi-block tipset, with a distinct state for each tipset.
+	builder := chain.NewBuilder(t, address.Undef)
+	gen := builder.NewGenesis()
+	link1 := builder.AppendOn(gen, 2)
+	link2 := builder.AppendOn(link1, 1)
+	link3 := builder.AppendOn(link2, 1)
+	tipsets := []types.TipSet{gen, link1, link2, link3}
+
+	newAddress := address.NewForTestGetter()
+	st := state.NewEmptyStateTree(cst)
+	var roots []cid.Cid
+	for _, ts := range tipsets {
+		requirePutBlocksToCborStore(t, cst, ts.ToSlice()...)
+		require.NoError(t, st.SetActor(ctx, newAddress(), actor.NewActor(types.AccountActorCodeCid, types.NewAttoFILFromFIL(1))))
+		root, err := st.Flush(ctx)
+		require.NoError(t, err)
+		roots = append(roots, root)
+	}
+	_, err := cst.Put(ctx, types.MessageCollection{})
+	require.NoError(t, err)
+	_, err = cst.Put(ctx, types.ReceiptCollection{})
+	require.NoError(t, err)
+
+	store := chain.NewStore(repo.NewInMemoryRepo().Datastore(), cst, &state.TreeStateLoader{}, chain.NewStatusReporter(), gen.At(0).Cid())
+	for i, ts := range tipsets {
+		require.NoError(t, store.PutTipSetAndState(ctx, &chain.TipSetAndState{TipSet: ts, TipSetStateRoot: roots[i]}))
+	}
+	require.NoError(t, store.SetHead(ctx, link3))
+
+	var snapshot bytes.Buffer
+	require.NoError(t, chain.ExportSnapshot(ctx, store, bs, link3, 1, &snapshot))
+
+	// newImportStore returns a store of a new node, whose head is genesis.
+	newImportStore := func(ds repo.Datastore, cst *hamt.CborIpldStore) *chain.Store {
+		requirePutBlocksToCborStore(t, cst, gen.ToSlice()...)
+		importStore := chain.NewStore(ds, cst, &state.TreeStateLoader{}, chain.NewStatusReporter(), gen.At(0).Cid())
+		require.NoError(t, importStore.PutTipSetAndState(ctx, &chain.TipSetAndState{TipSet: gen, TipSetStateRoot: roots[0]}))
+		require.NoError(t, importStore.SetHead(ctx, gen))
+		return importStore
+	}
+	weigher := &chain.FakeStateEvaluator{}
+
+	t.Run("import sets the head and records the imported states", func(t *testing.T) {
+		importBs, importCst := newSnapshotBlockstore()
+		ds := repo.NewInMemoryRepo().Datastore()
+		importStore := newImportStore(ds, importCst)
+
+		head, err := chain.ImportSnapshot(ctx, importStore, weigher, importBs, bytes.NewReader(snapshot.Bytes()))
+		require.NoError(t, err)
+		assert.Equal(t, link3, head)
+		assert.Equal(t, link3.Key(), importStore.GetHead())
+
+		// The states of the head and its parent are exported, and genesis keeps its state.
+		for _, i := range []int{0, 2, 3} {
+			root, err := importStore.GetTipSetStateRoot(tipsets[i].Key())
+			require.NoError(t, err)
+			assert.Equal(t, roots[i], root)
+		}
+		has, err := importBs.Has(roots[3])
+		require.NoError(t, err)
+		assert.True(t, has)
+		_, err = importStore.GetTipSetState(ctx, link3.Key())
+		assert.NoError(t, err)
+
+		// The state of older tipsets is not available.
+		has, err = importBs.Has(roots[1])
+		require.NoError(t, err)
+		assert.False(t, has)
+		_, err = importStore.GetTipSetStateRoot(link1.Key())
+		assert.Equal(t, chain.ErrStateNotAvailable, err)
+		_, err = importStore.GetTipSetState(ctx, link1.Key())
+		assert.Equal(t, chain.ErrStateNotAvailable, err)
+		imported, err := importStore.GetTipSet(link1.Key())
+		require.NoError(t, err)
+		assert.Equal(t, link1, imported)
+
+		// The imported chain is loaded on restart.
+		reloaded := chain.NewStore(ds, importCst, &state.TreeStateLoader{}, chain.NewStatusReporter(), gen.At(0).Cid())
+		require.NoError(t, reloaded.Load(ctx))
+		assert.Equal(t, link3.Key(), reloaded.GetHead())
+		_, err = reloaded.GetTipSetStateRoot(link1.Key())
+		assert.Equal(t, chain.ErrStateNotAvailable, err)
+		root, err := reloaded.GetTipSetStateRoot(link2.Key())
+		require.NoError(t, err)
+		assert.Equal(t, roots[2], root)
+
+		// A snapshot exported from the imported chain includes the available states only.
+		var reexported bytes.Buffer
+		require.NoError(t, chain.ExportSnapshot(ctx, importStore, importBs, link3, 5, &reexported))
+		_, err = chain.ImportSnapshot(ctx, newImportStore(repo.NewInMemoryRepo().Datastore(), importCst), weigher, importBs, bytes.NewReader(reexported.Bytes()))
+		assert.NoError(t, err)
+	})
+
+	t.Run("import rejects a snapshot that is not heavier than the head", func(t *testing.T) {
+		importBs, importCst := newSnapshotBlockstore()
+		importStore := newImportStore(repo.NewInMemoryRepo().Datastore(), importCst)
+		_, err := chain.ImportSnapshot(ctx, importStore, weigher, importBs, bytes.NewReader(snapshot.Bytes()))
+		require.NoError(t, err)
+
+		var older bytes.Buffer
+		require.NoError(t, chain.ExportSnapshot(ctx, store, bs, link2, 1, &older))
+		_, err = chain.ImportSnapshot(ctx, importStore, weigher, importBs, bytes.NewReader(older.Bytes()))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is not heavier than chain head")
+		assert.Equal(t, link3.Key(), importStore.GetHead())
+	})
+
+	t.Run("import rejects a snapshot of another chain", func(t *testing.T) {
+		importBs, importCst := newSnapshotBlockstore()
+		otherGenesis := chain.NewBuilder(t, address.TestAddress).NewGenesis()
+		importStore := chain.NewStore(repo.NewInMemoryRepo().Datastore(), importCst, &state.TreeStateLoader{}, chain.NewStatusReporter(), otherGenesis.At(0).Cid())
+
+		_, err := chain.ImportSnapshot(ctx, importStore, weigher, importBs, bytes.NewReader(snapshot.Bytes()))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "does not match genesis block")
+	})
+}
+
+func newSnapshotBlockstore() (bstore.Blockstore, *hamt.CborIpldStore) {
+	bs := bstore.NewBlockstore(datastore.NewMapDatastore())
+	return bs, &hamt.CborIpldStore{Blocks: bserv.New(bs, offline.Exchange(bs))}
+}
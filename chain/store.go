@@ -160,7 +160,7 @@ func (store *Store) Load(ctx context.Context) (err error) {
 		if err != nil {
 			return err
 		}
-		err = store.PutTipSetAndState(ctx, &TipSetAndState{
+		err = store.tipIndex.Put(&TipSetAndState{
 			TipSet:          iterator.Value(),
 			TipSetStateRoot: stateRoot,
 		})
@@ -213,6 +213,11 @@ func (store *Store) loadStateRoot(ts types.TipSet) (cid.Cid, error) {
 		return cid.Undef, errors.Wrapf(err, "failed to read tipset key %s", ts.String())
 	}
 
+	// A tipset whose state is not available is stored without a state root.
+	if len(bb) == 0 {
+		return cid.Undef, nil
+	}
+
 	var stateRoot cid.Cid
 	err = cbor.DecodeInto(bb, &stateRoot)
 	if err != nil {
@@ -236,6 +241,22 @@ func (store *Store) PutTipSetAndState(ctx context.Context, tsas *TipSetAndState)
 	return nil
 }
 
+// PutTipSetWithoutState persists the tipset index for a tipset whose state is not stored, such
+// as a tipset below the recent states of an imported snapshot. Its state root is reported as
+// not available.
+func (store *Store) PutTipSetWithoutState(ctx context.Context, ts types.TipSet) error {
+	if err := store.tipIndex.Put(&TipSetAndState{TipSet: ts}); err != nil {
+		return err
+	}
+
+	h, err := ts.Height()
+	if err != nil {
+		return err
+	}
+	key := datastore.NewKey(makeKey(ts.String(), h))
+	return store.ds.Put(key, []byte{})
+}
+
 // GetTipSet returns the tipset identified by `key`.
 func (store *Store) GetTipSet(key types.TipSetKey) (types.TipSet, error) {
 	return store.tipIndex.GetTipSet(key)
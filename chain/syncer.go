@@ -314,6 +314,15 @@ func (syncer *Syncer) widen(ctx context.Context, ts types.TipSet) (types.TipSet,
 	return wts, nil
 }
 
+// WhilePaused runs f while no chain is being synced, so that no tipset is processed and no
+// state is written while it runs.
+func (syncer *Syncer) WhilePaused(f func() error) error {
+	syncer.mu.Lock()
+	defer syncer.mu.Unlock()
+
+	return f()
+}
+
 // HandleNewTipSet extends the Syncer's chain store with the given tipset if they
 // represent a valid extension. It limits the length of new chains it will
 // attempt to validate and caches invalid blocks it has encountered to
@@ -13,13 +13,17 @@ import (
 var (
 	// ErrNotFound is returned when the key for a "Get" lookup is not in the index.
 	ErrNotFound = errors.New("Key not found in tipindex")
+	// ErrStateNotAvailable is returned when the state of an indexed tipset is not stored,
+	// such as below the recent states of an imported snapshot.
+	ErrStateNotAvailable = errors.New("state of tipset is not available")
 )
 
 // TipSetAndState (tsas) is the type stored at the leaves of the TipIndex.  It contains
 // a tipset pointing to blocks and the root cid of the chain's state after
 // applying the messages in this tipset to it's parent state.
 type TipSetAndState struct {
-	// root of aggregate state after applying tipset
+	// root of aggregate state after applying tipset, or cid.Undef if the state is not
+	// available
 	TipSetStateRoot cid.Cid
 	TipSet          types.TipSet
 }
@@ -96,11 +100,15 @@ func (ti *TipIndex) GetTipSet(tsKey types.TipSetKey) (types.TipSet, error) {
 }
 
 // GetTipSetStateRoot returns the tipsetStateRoot from func (ti *TipIndex) Get(tsKey string).
+// It returns ErrStateNotAvailable if the state of the tipset is not stored.
 func (ti *TipIndex) GetTipSetStateRoot(tsKey types.TipSetKey) (cid.Cid, error) {
 	tsas, err := ti.Get(tsKey)
 	if err != nil {
 		return cid.Cid{}, err
 	}
+	if !tsas.TipSetStateRoot.Defined() {
+		return cid.Cid{}, ErrStateNotAvailable
+	}
 	return tsas.TipSetStateRoot, nil
 }
 
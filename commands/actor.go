@@ -10,6 +10,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/actor/builtin/account"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/initactor"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/miner"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/multisig"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/paymentbroker"
 	"github.com/filecoin-project/go-filecoin/actor/builtin/storagemarket"
 	"github.com/filecoin-project/go-filecoin/exec"
@@ -80,6 +81,8 @@ var actorLsCmd = &cmds.Command{
 				output = makeActorView(result.Actor, result.Address, &miner.Actor{})
 			case result.Actor.Code.Equals(types.BootstrapMinerActorCodeCid):
 				output = makeActorView(result.Actor, result.Address, &miner.Actor{})
+			case result.Actor.Code.Equals(types.MultisigActorCodeCid):
+				output = makeActorView(result.Actor, result.Address, &multisig.Actor{})
 			default:
 				output = makeActorView(result.Actor, result.Address, nil)
 			}
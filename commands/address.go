@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"io"
+	"time"
 
 	"github.com/ipfs/go-ipfs-cmdkit"
 	"github.com/ipfs/go-ipfs-cmds"
@@ -23,6 +24,10 @@ var walletCmd = &cmds.Command{
 		"balance": balanceCmd,
 		"import":  walletImportCmd,
 		"export":  walletExportCmd,
+		"encrypt": walletEncryptCmd,
+		"lock":    walletLockCmd,
+		"unlock":  walletUnlockCmd,
+		"init-hd": walletInitHDCmd,
 	},
 }
 
@@ -45,11 +50,29 @@ type addressResult struct {
 // AddressLsResult is the result of running the address list command.
 type AddressLsResult struct {
 	Addresses []string
+	// DerivationPaths maps the addresses of an HD wallet to their derivation paths.
+	DerivationPaths map[string]string `json:",omitempty"`
 }
 
 var addrsNewCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Create a new wallet address",
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("type", "The type of address to create: secp256k1 or bls").WithDefault("secp256k1"),
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
-		addr, err := GetPorcelainAPI(env).WalletNewAddress()
+		var protocol address.Protocol
+		switch req.Options["type"].(string) {
+		case "secp256k1":
+			protocol = address.SECP256K1
+		case "bls":
+			protocol = address.BLS
+		default:
+			return errors.New("unsupported address type, must be secp256k1 or bls")
+		}
+
+		addr, err := GetPorcelainAPI(env).WalletNewAddress(protocol)
 		if err != nil {
 			return err
 		}
@@ -66,11 +89,17 @@ var addrsNewCmd = &cmds.Command{
 
 var addrsLsCmd = &cmds.Command{
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
-		addrs := GetPorcelainAPI(env).WalletAddresses()
+		api := GetPorcelainAPI(env)
 
 		var alr AddressLsResult
-		for _, addr := range addrs {
+		for _, addr := range api.WalletAddresses() {
 			alr.Addresses = append(alr.Addresses, addr.String())
+			if path, ok := api.WalletDerivationPath(addr); ok {
+				if alr.DerivationPaths == nil {
+					alr.DerivationPaths = make(map[string]string)
+				}
+				alr.DerivationPaths[addr.String()] = path
+			}
 		}
 
 		return re.Emit(&alr)
@@ -79,7 +108,12 @@ var addrsLsCmd = &cmds.Command{
 	Encoders: cmds.EncoderMap{
 		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, addrs *AddressLsResult) error {
 			for _, addr := range addrs.Addresses {
-				_, err := fmt.Fprintln(w, addr)
+				var err error
+				if path, ok := addrs.DerivationPaths[addr]; ok {
+					_, err = fmt.Fprintf(w, "%s\t%s\n", addr, path)
+				} else {
+					_, err = fmt.Fprintln(w, addr)
+				}
 				if err != nil {
 					return err
 				}
@@ -254,3 +288,119 @@ var walletExportCmd = &cmds.Command{
 		}),
 	},
 }
+
+var walletEncryptCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Encrypt the wallet keys under a passphrase",
+		ShortDescription: `
+Encrypts the keys stored in plaintext in the wallet datastore under the given
+passphrase. The wallet stays unlocked until it is locked or the node restarts,
+after which it must be unlocked before it can sign.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("passphrase", true, false, "Passphrase to encrypt the wallet with").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		if err := GetPorcelainAPI(env).WalletEncrypt([]byte(req.Arguments[0])); err != nil {
+			return err
+		}
+		return re.Emit("wallet encrypted")
+	},
+	Encoders: stringEncoderMap,
+}
+
+var walletLockCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Lock the encrypted wallet so that its keys cannot be used",
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		if err := GetPorcelainAPI(env).WalletLock(); err != nil {
+			return err
+		}
+		return re.Emit("wallet locked")
+	},
+	Encoders: stringEncoderMap,
+}
+
+var walletUnlockCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Unlock the encrypted wallet with its passphrase",
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("passphrase", true, false, "Passphrase the wallet is encrypted with").EnableStdin(),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("timeout", "Lock the wallet again after this long, e.g. 300s, 1.5h, 2h45m. Zero keeps it unlocked until it is locked.").WithDefault("0s"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		timeout, err := time.ParseDuration(req.Options["timeout"].(string))
+		if err != nil {
+			return errors.Wrap(err, "invalid timeout string")
+		}
+
+		if err := GetPorcelainAPI(env).WalletUnlock([]byte(req.Arguments[0]), timeout); err != nil {
+			return err
+		}
+		return re.Emit("wallet unlocked")
+	},
+	Encoders: stringEncoderMap,
+}
+
+type walletInitHDResult struct {
+	Mnemonic  string `json:",omitempty"`
+	Addresses []string
+}
+
+var walletInitHDCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Turn the wallet into an HD wallet that derives its addresses from a mnemonic",
+		ShortDescription: `
+Turns the plaintext wallet into a hierarchical deterministic wallet. New addresses
+are then derived from a single seed along the paths m/44'/461'/0'/0/<n>, so writing
+down its mnemonic backs up every derived address. Keys already in the wallet are
+kept, but are not backed up by the mnemonic.
+
+A new mnemonic is generated and printed unless one is given with --mnemonic, which
+restores the addresses derived from it.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("mnemonic", "Mnemonic to restore the wallet from"),
+		cmdkit.UintOption("count", "Number of addresses to derive").WithDefault(uint(1)),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		restore, _ := req.Options["mnemonic"].(string)
+		count, _ := req.Options["count"].(uint)
+
+		mnemonic, addrs, err := GetPorcelainAPI(env).WalletInitHD(restore, int(count))
+		if err != nil {
+			return err
+		}
+
+		var res walletInitHDResult
+		if restore == "" {
+			res.Mnemonic = mnemonic
+		}
+		for _, addr := range addrs {
+			res.Addresses = append(res.Addresses, addr.String())
+		}
+		return re.Emit(&res)
+	},
+	Type: &walletInitHDResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *walletInitHDResult) error {
+			if res.Mnemonic != "" {
+				if _, err := fmt.Fprintf(w, "Write down this mnemonic, it backs up every derived address:\n%s\n\n", res.Mnemonic); err != nil {
+					return err
+				}
+			}
+			for _, addr := range res.Addresses {
+				if _, err := fmt.Fprintln(w, addr); err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	},
+}
@@ -33,6 +33,23 @@ func TestAddrsNewAndList(t *testing.T) {
 	}
 }
 
+func TestAddrsNewBLS(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	out := d.RunSuccess("address", "new", "--type=bls").ReadStdoutTrimNewlines()
+	addr, err := address.NewFromString(out)
+	require.NoError(t, err)
+	assert.Equal(t, address.BLS, addr.Protocol())
+
+	list := d.RunSuccess("address", "ls").ReadStdout()
+	assert.Contains(t, list, out)
+
+	d.RunFail("unsupported address type", "address", "new", "--type=actor")
+}
+
 func TestWalletBalance(t *testing.T) {
 	tf.IntegrationTest(t)
 
@@ -83,7 +100,7 @@ func TestAddrLookupAndUpdate(t *testing.T) {
 		"miner", "update-peerid",
 		"--from", addr,
 		"--gas-price", "1",
-		"--gas-limit", "300",
+		"--gas-limit", "1000",
 		minerAddr,
 		minerPidForUpdate.Pretty(),
 	)
@@ -172,3 +189,25 @@ func mustDecodeCid(cidStr string) cid.Cid {
 
 	return decode
 }
+
+func TestWalletInitHD(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	mnemonic := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+	out := d.RunSuccess("wallet", "init-hd", "--mnemonic", mnemonic, "--count=2").ReadStdoutTrimNewlines()
+	derived := strings.Split(out, "\n")
+	require.Len(t, derived, 2)
+
+	list := d.RunSuccess("address", "ls").ReadStdout()
+	assert.Contains(t, list, derived[0]+"\tm/44'/461'/0'/0/0")
+	assert.Contains(t, list, derived[1]+"\tm/44'/461'/0'/0/1")
+
+	newAddr := d.CreateAddress()
+	list = d.RunSuccess("address", "ls").ReadStdout()
+	assert.Contains(t, list, newAddr+"\tm/44'/461'/0'/0/2")
+
+	d.RunFail("already an HD wallet", "wallet", "init-hd")
+}
@@ -0,0 +1,255 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	cmdkit "github.com/ipfs/go-ipfs-cmdkit"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/auth"
+	"github.com/filecoin-project/go-filecoin/jsonrpc"
+	"github.com/filecoin-project/go-filecoin/rpcapi"
+)
+
+// commandPermissions maps each daemon command to the permission required to call it over the
+// HTTP API. A command requires the permission of the longest prefix of its path listed here, so
+// an entry for a top level command covers each of its subcommands that is not listed itself.
+// Commands that are not covered require admin permission.
+var commandPermissions = map[string]auth.Permission{
+	"actor":     auth.Read,
+	"address":   auth.Read,
+	"bitswap":   auth.Read,
+	"bootstrap": auth.Read,
+	"dag":       auth.Read,
+	"dht":       auth.Read,
+	"id":        auth.Read,
+	"inspect":   auth.Read,
+	"leb128":    auth.Read,
+	"protocol":  auth.Read,
+	"show":      auth.Read,
+	"stats":     auth.Read,
+	"version":   auth.Read,
+
+	"address new": auth.Write,
+
+	"auth": auth.Admin,
+
+	"chain":          auth.Read,
+	"chain import":   auth.Admin,
+	"chain set-head": auth.Admin,
+	"chain sync":     auth.Write,
+
+	"client":                      auth.Read,
+	"client import":               auth.Write,
+	"client propose-storage-deal": auth.Sign,
+
+	"config": auth.Admin,
+
+	"deals":        auth.Read,
+	"deals redeem": auth.Sign,
+
+	"log":       auth.Read,
+	"log level": auth.Admin,
+
+	"message":         auth.Read,
+	"message replace": auth.Sign,
+	"message send":    auth.Sign,
+
+	"miner":                     auth.Read,
+	"miner create":              auth.Sign,
+	"miner set-price":           auth.Sign,
+	"miner set-retrieval-price": auth.Sign,
+	"miner set-worker":          auth.Sign,
+	"miner update-peerid":       auth.Sign,
+
+	"mining":           auth.Admin,
+	"mining add-piece": auth.Write,
+	"mining address":   auth.Read,
+	"mining status":    auth.Read,
+
+	"mpool":    auth.Read,
+	"mpool rm": auth.Write,
+
+	"multisig":    auth.Sign,
+	"multisig ls": auth.Read,
+
+	"outbox":       auth.Read,
+	"outbox clear": auth.Write,
+
+	"paych":    auth.Sign,
+	"paych ls": auth.Read,
+
+	"ping": auth.Read,
+
+	"repo": auth.Admin,
+
+	"retrieval-client":             auth.Sign,
+	"retrieval-client find-miners": auth.Read,
+	"retrieval-client payments":    auth.Read,
+
+	"swarm":         auth.Read,
+	"swarm connect": auth.Write,
+
+	"wallet":         auth.Admin,
+	"wallet balance": auth.Read,
+}
+
+// requiredPermission returns the permission required to call the command at path.
+func requiredPermission(path []string) auth.Permission {
+	for i := len(path); i > 0; i-- {
+		if perm, ok := commandPermissions[strings.Join(path[:i], " ")]; ok {
+			return perm
+		}
+	}
+	return auth.Admin
+}
+
+// authHandler wraps a command API handler, rejecting requests that lack a token granting the
+// permission that the requested command requires. CORS preflight requests, which carry no
+// credentials and run no command, are passed through.
+func authHandler(authority *auth.Authority, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodOptions {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		path := strings.Split(strings.Trim(strings.TrimPrefix(r.URL.Path, APIPrefix), "/"), "/")
+		if !authorizeRequest(w, r, authority, strings.Join(path, " "), requiredPermission(path)) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// adminHandler wraps a handler, rejecting requests that lack a token granting admin
+// permission.
+func adminHandler(authority *auth.Authority, name string, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !authorizeRequest(w, r, authority, name, auth.Admin) {
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authorizeRequest checks that the API token of r grants the permission required to call name,
+// writing an error response to w and returning false if it does not.
+func authorizeRequest(w http.ResponseWriter, r *http.Request, authority *auth.Authority, name string, required auth.Permission) bool {
+	token := requestToken(r)
+	if token == "" {
+		http.Error(w, "API token required", http.StatusUnauthorized)
+		return false
+	}
+	perm, err := authority.Verify(token)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusUnauthorized)
+		return false
+	}
+	if !perm.Allows(required) {
+		http.Error(w, fmt.Sprintf("%s requires %s permission, but the API token grants %s", name, required, perm), http.StatusForbidden)
+		return false
+	}
+	return true
+}
+
+// requestToken returns the bearer token of the Authorization header of r. Tokens are never read
+// from the URL, where they would end up in logs and browser history.
+func requestToken(r *http.Request) string {
+	return strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+}
+
+// tokenTransport adds an API token to the requests it sends to the API at host.
+type tokenTransport struct {
+	host  string
+	token string
+	next  http.RoundTripper
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *tokenTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	if r.URL.Host == t.host {
+		// A RoundTripper must not modify the request it is given.
+		withToken := new(http.Request)
+		*withToken = *r
+		withToken.Header = make(http.Header, len(r.Header)+1)
+		for k, v := range r.Header {
+			withToken.Header[k] = v
+		}
+		withToken.Header.Set("Authorization", "Bearer "+t.token)
+		r = withToken
+	}
+	return t.next.RoundTrip(r)
+}
+
+// rpcAuthorizer authorizes calls to the JSON-RPC API with the API token of each request, which
+// must grant the permission that rpcapi.Permissions requires for the method called. Methods
+// missing from it require admin permission.
+func rpcAuthorizer(authority *auth.Authority) jsonrpc.Authorizer {
+	return func(r *http.Request, method string) error {
+		token := requestToken(r)
+		if token == "" {
+			return errors.New("API token required")
+		}
+		perm, err := authority.Verify(token)
+		if err != nil {
+			return err
+		}
+		required, ok := rpcapi.Permissions[strings.TrimPrefix(method, rpcapi.Namespace+".")]
+		if !ok {
+			required = auth.Admin
+		}
+		if !perm.Allows(required) {
+			return fmt.Errorf("%s requires %s permission, but the API token grants %s", method, required, perm)
+		}
+		return nil
+	}
+}
+
+var authCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Manage API tokens",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"create-token": authCreateTokenCmd,
+	},
+}
+
+var authCreateTokenCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Create a token for the daemon API",
+		ShortDescription: `
+Creates a token granting the given permission to call the daemon API. Each
+permission includes those below it: read inspects the node and the chain, write
+changes local state, sign sends messages and vouchers from the node's wallet and
+admin permits all commands. Clients pass the token with --token or the
+FIL_API_TOKEN environment variable, which the CLI sends in an
+"Authorization: Bearer" header, as other clients must.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("perm", "Permission granted by the token: read, write, sign or admin").WithDefault(string(auth.Read)),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		perm, err := auth.ParsePermission(req.Options["perm"].(string))
+		if err != nil {
+			return err
+		}
+		token, err := GetAuthority(env).NewToken(perm)
+		if err != nil {
+			return err
+		}
+		return re.Emit(token)
+	},
+	Type: string(""),
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, token string) error {
+			_, err := fmt.Fprintln(w, token)
+			return err
+		}),
+	},
+}
@@ -0,0 +1,36 @@
+package commands_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	th "github.com/filecoin-project/go-filecoin/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+)
+
+func TestAuthCreateToken(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t).Start()
+
+	tokenPath := filepath.Join(d.RepoDir(), "token")
+	assert.FileExists(t, tokenPath)
+
+	readToken := d.RunSuccess("auth", "create-token", "--perm=read").ReadStdoutTrimNewlines()
+	d.RunSuccess("chain", "head", "--token="+readToken)
+	d.RunFail("requires admin permission", "config", "api.address", "--token="+readToken)
+	d.RunFail("invalid API token", "chain", "head", "--token=bogus")
+
+	adminToken := d.RunSuccess("auth", "create-token", "--perm=admin").ReadStdoutTrimNewlines()
+	d.RunSuccess("config", "api.address", "--token="+adminToken)
+
+	d.RunFail("unknown permission", "auth", "create-token", "--perm=root")
+
+	d.ShutdownEasy()
+
+	_, err := os.Lstat(tokenPath)
+	assert.True(t, os.IsNotExist(err), "Expect token file to be deleted on shutdown")
+}
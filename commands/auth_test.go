@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/auth"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+)
+
+func TestCommandPermissions(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("every daemon command has a permission", func(t *testing.T) {
+		for name := range rootSubcmdsDaemon {
+			_, ok := commandPermissions[name]
+			assert.True(t, ok, "no permission for command %s", name)
+		}
+	})
+
+	t.Run("subcommands inherit the permission of their parent", func(t *testing.T) {
+		assert.Equal(t, auth.Read, requiredPermission([]string{"chain", "head"}))
+		assert.Equal(t, auth.Write, requiredPermission([]string{"chain", "sync"}))
+		assert.Equal(t, auth.Admin, requiredPermission([]string{"chain", "import"}))
+		assert.Equal(t, auth.Sign, requiredPermission([]string{"message", "send"}))
+		assert.Equal(t, auth.Admin, requiredPermission([]string{"wallet", "export"}))
+		assert.Equal(t, auth.Read, requiredPermission([]string{"wallet", "balance"}))
+		assert.Equal(t, auth.Admin, requiredPermission([]string{"mining", "stop"}))
+	})
+
+	t.Run("unknown commands require admin", func(t *testing.T) {
+		assert.Equal(t, auth.Admin, requiredPermission([]string{"unknown"}))
+		assert.Equal(t, auth.Admin, requiredPermission([]string{}))
+	})
+}
+
+func TestAuthHandler(t *testing.T) {
+	tf.UnitTest(t)
+
+	authority, err := auth.LoadAuthority(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	readToken, err := authority.NewToken(auth.Read)
+	require.NoError(t, err)
+	adminToken, err := authority.NewToken(auth.Admin)
+	require.NoError(t, err)
+
+	var handled *http.Request
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handled = r
+	})
+	handler := authHandler(authority, next)
+	serveWith := func(h http.Handler, r *http.Request, token string) int {
+		handled = nil
+		if token != "" {
+			r.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, r)
+		return rec.Code
+	}
+	serve := func(r *http.Request, token string) int {
+		return serveWith(handler, r, token)
+	}
+
+	t.Run("requests without a valid token are rejected", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(httptest.NewRequest("POST", "/api/chain/head", nil), ""))
+		assert.Equal(t, http.StatusUnauthorized, serve(httptest.NewRequest("POST", "/api/chain/head", nil), "bogus"))
+		assert.Nil(t, handled)
+	})
+
+	t.Run("bearer tokens are accepted", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(httptest.NewRequest("POST", "/api/chain/head", nil), readToken))
+		assert.NotNil(t, handled)
+	})
+
+	t.Run("tokens in the URL are ignored", func(t *testing.T) {
+		assert.Equal(t, http.StatusUnauthorized, serve(httptest.NewRequest("POST", "/api/chain/head?token="+readToken, nil), ""))
+		assert.Nil(t, handled)
+	})
+
+	t.Run("commands requiring more permission than granted are forbidden", func(t *testing.T) {
+		assert.Equal(t, http.StatusForbidden, serve(httptest.NewRequest("POST", "/api/wallet/export", nil), readToken))
+		assert.Nil(t, handled)
+		assert.Equal(t, http.StatusOK, serve(httptest.NewRequest("POST", "/api/wallet/export", nil), adminToken))
+		assert.NotNil(t, handled)
+	})
+
+	t.Run("preflight requests are passed through", func(t *testing.T) {
+		assert.Equal(t, http.StatusOK, serve(httptest.NewRequest("OPTIONS", "/api/wallet/export", nil), ""))
+		assert.NotNil(t, handled)
+	})
+
+	t.Run("admin handlers require admin permission", func(t *testing.T) {
+		admin := adminHandler(authority, "pprof", next)
+		assert.Equal(t, http.StatusUnauthorized, serveWith(admin, httptest.NewRequest("GET", "/debug/pprof/", nil), ""))
+		assert.Equal(t, http.StatusForbidden, serveWith(admin, httptest.NewRequest("GET", "/debug/pprof/", nil), readToken))
+		assert.Nil(t, handled)
+		assert.Equal(t, http.StatusOK, serveWith(admin, httptest.NewRequest("GET", "/debug/pprof/", nil), adminToken))
+		assert.NotNil(t, handled)
+	})
+}
+
+func TestTokenTransport(t *testing.T) {
+	tf.UnitTest(t)
+
+	var header string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header = r.Header.Get("Authorization")
+	}))
+	defer server.Close()
+
+	api, err := url.Parse(server.URL)
+	require.NoError(t, err)
+	client := &http.Client{Transport: &tokenTransport{host: api.Host, token: "secret", next: http.DefaultTransport}}
+
+	t.Run("the token is sent to the API in a header", func(t *testing.T) {
+		req, err := http.NewRequest("POST", server.URL+"/api/chain/head", nil)
+		require.NoError(t, err)
+		res, err := client.Do(req)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Equal(t, "Bearer secret", header)
+		assert.Empty(t, req.Header.Get("Authorization"))
+	})
+
+	t.Run("the token is not sent to other hosts", func(t *testing.T) {
+		other := &http.Client{Transport: &tokenTransport{host: "example.com:80", token: "secret", next: http.DefaultTransport}}
+		res, err := other.Get(server.URL)
+		require.NoError(t, err)
+		require.NoError(t, res.Body.Close())
+		assert.Empty(t, header)
+	})
+}
@@ -10,8 +10,11 @@ import (
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-ipfs-cmdkit"
 	"github.com/ipfs/go-ipfs-cmds"
+	"github.com/ipfs/go-ipfs-files"
 	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/types"
 )
 
@@ -20,8 +23,11 @@ var chainCmd = &cmds.Command{
 		Tagline: "Inspect the filecoin blockchain",
 	},
 	Subcommands: map[string]*cmds.Command{
+		"export":   storeExportCmd,
 		"head":     storeHeadCmd,
+		"import":   storeImportCmd,
 		"ls":       storeLsCmd,
+		"notify":   storeNotifyCmd,
 		"status":   storeStatusCmd,
 		"set-head": storeSetHeadCmd,
 		"sync":     storeSyncCmd,
@@ -113,6 +119,110 @@ var storeLsCmd = &cmds.Command{
 	},
 }
 
+var storeNotifyCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Stream changes to the blockchain as its head changes",
+		ShortDescription: `
+Outputs an event for each tipset applied to or reverted from the chain as the
+head changes. When the head moves to another fork, the tipsets back to the
+common ancestor are reverted, highest first, before the tipsets of the new
+fork are applied, lowest first.
+`,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		changes, err := GetPorcelainAPI(env).ChainNotify(req.Context)
+		if err != nil {
+			return err
+		}
+		for change := range changes {
+			if err := re.Emit(change); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Type: porcelain.ChainHeadChange{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, change *porcelain.ChainHeadChange) error {
+			_, err := fmt.Fprintf(w, "%s\t%d\t%s\n", change.Type, change.Height, change.Key)
+			return err
+		}),
+	},
+}
+
+var storeExportCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Export a snapshot of the blockchain as a CAR file",
+		ShortDescription: `
+Writes the block headers, messages and receipts of the chain back to genesis,
+and the state trees of the most recent tipsets, to stdout as a CAR file, which
+'chain import' loads into another node. The state trees of the exported tipset
+and its parent are always included.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("tipset", "Comma-separated CIDs of the blocks of the tipset to export the chain from (default: the chain head)"),
+		cmdkit.UintOption("recent-states", "Number of most recent tipsets to export the state trees of").WithDefault(uint(1)),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		api := GetPorcelainAPI(env)
+		key := api.ChainHeadKey()
+		if o, ok := req.Options["tipset"].(string); ok {
+			tsCids, err := cidsFromSlice(strings.Split(o, ","))
+			if err != nil {
+				return errors.Wrap(err, "invalid tipset")
+			}
+			key = types.NewTipSetKey(tsCids...)
+		}
+		recentStates, _ := req.Options["recent-states"].(uint)
+
+		r, w := io.Pipe()
+		go func() {
+			w.CloseWithError(api.ChainExport(req.Context, key, recentStates, w)) // nolint: errcheck
+		}()
+		return re.Emit(r)
+	},
+}
+
+var storeImportCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Import a snapshot of the blockchain from a CAR file",
+		ShortDescription: `
+Loads a CAR file written by 'chain export' and sets the chain head to the head
+of the snapshot. The snapshot is trusted: its blocks are not validated, but it
+must share this node's genesis block and its head must be heavier than the
+current chain head. Only the state trees included in the snapshot are
+available after the import.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.FileArg("file", true, false, "Path to the snapshot CAR file").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		iter := req.Files.Entries()
+		if !iter.Next() {
+			return fmt.Errorf("no file given: %s", iter.Err())
+		}
+
+		fi, ok := iter.Node().(files.File)
+		if !ok {
+			return fmt.Errorf("given file was not a files.File")
+		}
+
+		head, err := GetPorcelainAPI(env).ChainImport(req.Context, fi)
+		if err != nil {
+			return err
+		}
+		return re.Emit(head.Key())
+	},
+	Type: types.TipSetKey{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, key *types.TipSetKey) error {
+			return PrintString(w, key)
+		}),
+	},
+}
+
 var storeStatusCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Show status of chain sync operation.",
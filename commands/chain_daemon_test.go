@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"encoding/json"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/ipfs/go-cid"
@@ -127,3 +128,23 @@ func TestChainLs(t *testing.T) {
 		assert.Contains(t, chainLsResult, `"height":"1"`)
 	})
 }
+
+func TestChainExportImport(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	miner := makeTestDaemonWithMinerAndStart(t)
+	defer miner.ShutdownSuccess()
+
+	miner.RunSuccess("mining", "once")
+	miner.RunSuccess("mining", "once")
+	head := miner.RunSuccess("chain", "head").ReadStdoutTrimNewlines()
+
+	snapshot := miner.RunSuccess("chain", "export", "--recent-states", "2").ReadStdout()
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	imported := d.RunWithStdin(strings.NewReader(snapshot), "chain", "import").ReadStdoutTrimNewlines()
+	assert.Contains(t, imported, head)
+	assert.Equal(t, head, d.RunSuccess("chain", "head").ReadStdoutTrimNewlines())
+}
@@ -28,6 +28,7 @@ var clientCmd = &cmds.Command{
 		"query-storage-deal":   clientQueryStorageDealCmd,
 		"verify-storage-deal":  clientVerifyStorageDealCmd,
 		"list-asks":            clientListAsksCmd,
+		"list-retrieval-asks":  clientListRetrievalAsksCmd,
 		"payments":             paymentsCmd,
 	},
 }
@@ -269,6 +270,37 @@ respectively.
 	},
 }
 
+var clientListRetrievalAsksCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List all asks in the retrieval market",
+		ShortDescription: `
+Lists the retrieval ask of every miner that has set one. This command takes no
+arguments. Results will be returned as a space separated table with miner, price
+per byte, payment interval in bytes and unseal price respectively.
+`,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		asksCh := GetPorcelainAPI(env).ClientListRetrievalAsks(req.Context)
+
+		for a := range asksCh {
+			if a.Error != nil {
+				return a.Error
+			}
+			if err := re.Emit(a); err != nil {
+				return err
+			}
+		}
+		return nil
+	},
+	Type: porcelain.RetrievalAsk{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, ask *porcelain.RetrievalAsk) error {
+			fmt.Fprintf(w, "%s %s %s %s\n", ask.Miner, ask.Price, ask.PaymentInterval, ask.UnsealPrice) // nolint: errcheck
+			return nil
+		}),
+	},
+}
+
 var paymentsCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline:          "List payments for a given deal",
@@ -18,12 +18,16 @@ import (
 	manet "github.com/multiformats/go-multiaddr-net"
 	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/go-filecoin/auth"
 	"github.com/filecoin-project/go-filecoin/clock"
 	"github.com/filecoin-project/go-filecoin/config"
 	"github.com/filecoin-project/go-filecoin/consensus"
+	"github.com/filecoin-project/go-filecoin/jsonrpc"
 	"github.com/filecoin-project/go-filecoin/node"
 	"github.com/filecoin-project/go-filecoin/paths"
 	"github.com/filecoin-project/go-filecoin/repo"
+	"github.com/filecoin-project/go-filecoin/rpcapi"
+	"github.com/filecoin-project/go-filecoin/rpcapi/impl"
 )
 
 var daemonCmd = &cmds.Command{
@@ -152,7 +156,13 @@ func getRepo(req *cmds.Request) (repo.Repo, error) {
 // saved to the node's repo.
 // A message sent to or closure of the `terminate` channel signals the server to stop.
 func RunAPIAndWait(ctx context.Context, nd *node.Node, config *config.APIConfig, ready chan interface{}, terminate chan os.Signal) error {
+	authority, err := auth.LoadAuthority(nd.Repo.Datastore())
+	if err != nil {
+		return errors.Wrap(err, "failed to load API token authority")
+	}
+
 	servenv := &Env{
+		authority:      authority,
 		blockMiningAPI: nd.BlockMining.BlockMiningAPI,
 		ctx:            ctx,
 		inspectorAPI:   NewInspectorAPI(nd.Repo),
@@ -179,9 +189,15 @@ func RunAPIAndWait(ctx context.Context, nd *node.Node, config *config.APIConfig,
 		return err
 	}
 
+	rpcServer := jsonrpc.NewServer(rpcAuthorizer(authority))
+	if err := rpcServer.Register(rpcapi.Namespace, impl.New(nd.PorcelainAPI)); err != nil {
+		return errors.Wrap(err, "failed to register JSON-RPC API")
+	}
+
 	handler := http.NewServeMux()
-	handler.Handle("/debug/pprof/", http.DefaultServeMux)
-	handler.Handle(APIPrefix+"/", cmdhttp.NewHandler(servenv, rootCmdDaemon, cfg))
+	handler.Handle("/debug/pprof/", adminHandler(authority, "pprof", http.DefaultServeMux))
+	handler.Handle(APIPrefix+"/", authHandler(authority, cmdhttp.NewHandler(servenv, rootCmdDaemon, cfg)))
+	handler.Handle(rpcapi.Path, rpcServer)
 
 	apiserv := http.Server{
 		Handler: handler,
@@ -194,7 +210,14 @@ func RunAPIAndWait(ctx context.Context, nd *node.Node, config *config.APIConfig,
 		}
 	}()
 
-	// Write the resolved API address to the repo
+	// Write an admin token for the local CLI and the resolved API address to the repo
+	token, err := authority.NewToken(auth.Admin)
+	if err != nil {
+		return errors.Wrap(err, "Could not create API token")
+	}
+	if err := nd.Repo.SetAPIToken(token); err != nil {
+		return errors.Wrap(err, "Could not save API token to repo")
+	}
 	config.Address = apiListener.Multiaddr().String()
 	if err := nd.Repo.SetAPIAddr(config.Address); err != nil {
 		return errors.Wrap(err, "Could not save API address to repo")
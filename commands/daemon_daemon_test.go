@@ -54,10 +54,14 @@ func TestDaemonCORS(t *testing.T) {
 		_, host, err := manet.DialArgs(maddr)
 		assert.NoError(t, err)
 
+		token, err := td.APIToken()
+		assert.NoError(t, err)
+
 		url := fmt.Sprintf("http://%s/api/id", host)
 		req, err := http.NewRequest("GET", url, nil)
 		assert.NoError(t, err)
 		req.Header.Add("Origin", "http://localhost:8080")
+		req.Header.Set("Authorization", "Bearer "+token)
 		res, err := http.DefaultClient.Do(req)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, res.StatusCode)
@@ -65,6 +69,7 @@ func TestDaemonCORS(t *testing.T) {
 		req, err = http.NewRequest("GET", url, nil)
 		assert.NoError(t, err)
 		req.Header.Add("Origin", "https://localhost:8080")
+		req.Header.Set("Authorization", "Bearer "+token)
 		res, err = http.DefaultClient.Do(req)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, res.StatusCode)
@@ -72,6 +77,7 @@ func TestDaemonCORS(t *testing.T) {
 		req, err = http.NewRequest("GET", url, nil)
 		assert.NoError(t, err)
 		req.Header.Add("Origin", "http://127.0.0.1:8080")
+		req.Header.Set("Authorization", "Bearer "+token)
 		res, err = http.DefaultClient.Do(req)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, res.StatusCode)
@@ -79,6 +85,7 @@ func TestDaemonCORS(t *testing.T) {
 		req, err = http.NewRequest("GET", url, nil)
 		assert.NoError(t, err)
 		req.Header.Add("Origin", "https://127.0.0.1:8080")
+		req.Header.Set("Authorization", "Bearer "+token)
 		res, err = http.DefaultClient.Do(req)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusOK, res.StatusCode)
@@ -94,10 +101,14 @@ func TestDaemonCORS(t *testing.T) {
 		_, host, err := manet.DialArgs(maddr)
 		assert.NoError(t, err)
 
+		token, err := td.APIToken()
+		assert.NoError(t, err)
+
 		url := fmt.Sprintf("http://%s/api/id", host)
 		req, err := http.NewRequest("GET", url, nil)
 		assert.NoError(t, err)
 		req.Header.Add("Origin", "http://disallowed.origin")
+		req.Header.Set("Authorization", "Bearer "+token)
 		res, err := http.DefaultClient.Do(req)
 		assert.NoError(t, err)
 		assert.Equal(t, http.StatusForbidden, res.StatusCode)
@@ -116,9 +127,13 @@ func TestDaemonOverHttp(t *testing.T) {
 	_, host, err := manet.DialArgs(maddr)
 	require.NoError(t, err)
 
+	token, err := td.APIToken()
+	require.NoError(t, err)
+
 	url := fmt.Sprintf("http://%s/api/daemon", host)
 	req, err := http.NewRequest("POST", url, nil)
 	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
 	res, err := http.DefaultClient.Do(req)
 	require.NoError(t, err)
 	require.Equal(t, http.StatusNotFound, res.StatusCode)
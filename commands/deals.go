@@ -116,7 +116,7 @@ Redeem vouchers for FIL on the storage deal specified with the given deal CID.
 			return err
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		gasPrice, gasLimit, preview, err := parseGasOptions(req, env)
 		if err != nil {
 			return err
 		}
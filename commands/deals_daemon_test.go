@@ -3,7 +3,6 @@ package commands_test
 import (
 	"context"
 	"crypto/rand"
-	"fmt"
 	"io"
 	"math/big"
 	"strings"
@@ -107,24 +106,22 @@ func TestDealsRedeem(t *testing.T) {
 	// our math will be off due to commitSector message gas and possible
 	// block rewards.  In practice sealing takes much longer so we never
 	// lose the race.
-	redeemCid, err := minerDaemon.DealsRedeem(ctx, dealResponse.ProposalCid, fast.AOPrice(big.NewFloat(0.001)), fast.AOLimit(100))
+	redeemCid, err := minerDaemon.DealsRedeem(ctx, dealResponse.ProposalCid, fast.AOPrice(big.NewFloat(0.001)), fast.AOLimit(1000))
 	require.NoError(t, err)
 
 	_, err = clientDaemon.MiningOnce(ctx)
 	require.NoError(t, err)
 
-	_, err = minerDaemon.MessageWait(ctx, redeemCid)
+	result, err := minerDaemon.MessageWait(ctx, redeemCid)
 	require.NoError(t, err)
 
 	newWalletBalance, err := minerDaemon.WalletBalance(ctx, minerOwnerAddress)
 	require.NoError(t, err)
 
-	expectedGasCost := 0.1
-	expectedBalanceDiff := float64(dealDuration*dataPriceOneBlock) - expectedGasCost
-	expectedBalanceStr := fmt.Sprintf("%.1f", expectedBalanceDiff)
-
-	actualBalanceDiff := newWalletBalance.Sub(oldWalletBalance)
-	assert.Equal(t, expectedBalanceStr, actualBalanceDiff.String())
+	// add the gas charged back to the balance, which leaves the change from redeeming the deal
+	expectedBalanceDiff := types.NewAttoFILFromFIL(dealDuration * dataPriceOneBlock)
+	actualBalanceDiff := newWalletBalance.Add(result.Receipt.GasAttoFIL).Sub(oldWalletBalance)
+	assert.Equal(t, expectedBalanceDiff.String(), actualBalanceDiff.String())
 }
 
 func TestDealsList(t *testing.T) {
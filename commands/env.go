@@ -5,6 +5,7 @@ import (
 
 	"github.com/ipfs/go-ipfs-cmds"
 
+	"github.com/filecoin-project/go-filecoin/auth"
 	"github.com/filecoin-project/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/protocol/block"
 	"github.com/filecoin-project/go-filecoin/protocol/retrieval"
@@ -19,6 +20,7 @@ type Env struct {
 	retrievalAPI   *retrieval.API
 	storageAPI     *storage.API
 	inspectorAPI   *Inspector
+	authority      *auth.Authority
 }
 
 var _ cmds.Environment = (*Env)(nil)
@@ -63,3 +65,9 @@ func GetInspectorAPI(env cmds.Environment) *Inspector {
 	ce := env.(*Env)
 	return ce.inspectorAPI
 }
+
+// GetAuthority returns the API token authority from the given environment.
+func GetAuthority(env cmds.Environment) *auth.Authority {
+	ce := env.(*Env)
+	return ce.authority
+}
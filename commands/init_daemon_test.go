@@ -27,9 +27,13 @@ func TestInitOverHttp(t *testing.T) {
 	_, host, err := manet.DialArgs(maddr)
 	require.NoError(t, err)
 
+	token, err := td.APIToken()
+	require.NoError(t, err)
+
 	url := fmt.Sprintf("http://%s/api/init", host)
 	req, err := http.NewRequest("POST", url, nil)
 	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+token)
 	res, err := http.DefaultClient.Do(req)
 	require.NoError(t, err)
 	require.Equal(t, http.StatusNotFound, res.StatusCode)
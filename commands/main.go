@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net"
+	"net/http"
 	"net/url"
 	"os"
 	"syscall"
@@ -28,6 +29,9 @@ const (
 	// OptionRepoDir is the name of the option for specifying the directory of the repo.
 	OptionRepoDir = "repodir"
 
+	// OptionToken is the name of the option for specifying the token to authorize API calls with.
+	OptionToken = "token"
+
 	// OptionSectorDir is the name of the option for specifying the directory into which staged and sealed sectors will be written.
 	OptionSectorDir = "sectordir"
 
@@ -128,16 +132,19 @@ MESSAGE COMMANDS
   go-filecoin outbox                 - Manage the outbound message queue
 
 TOOL COMMANDS
+  go-filecoin auth                   - Manage API tokens
   go-filecoin inspect                - Show info about the go-filecoin node
   go-filecoin leb128                 - Leb128 cli encode/decode
   go-filecoin log                    - Interact with the daemon event log output
   go-filecoin protocol               - Show protocol parameter details
+  go-filecoin repo                   - Manage the filecoin repo
   go-filecoin version                - Show go-filecoin version information
 `,
 	},
 	Options: []cmdkit.Option{
 		cmdkit.StringOption(OptionAPI, "set the api port to use"),
 		cmdkit.StringOption(OptionRepoDir, "set the repo directory, defaults to ~/.filecoin/repo"),
+		cmdkit.StringOption(OptionToken, "set the api token to use, defaults to the token in the repo directory"),
 		cmds.OptionEncodingType,
 		cmdkit.BoolOption("help", "Show the full command help text."),
 		cmdkit.BoolOption("h", "Show a short version of the command help text."),
@@ -162,6 +169,7 @@ var rootSubcmdsLocal = map[string]*cmds.Command{
 var rootSubcmdsDaemon = map[string]*cmds.Command{
 	"actor":            actorCmd,
 	"address":          addrsCmd,
+	"auth":             authCmd,
 	"bitswap":          bitswapCmd,
 	"bootstrap":        bootstrapCmd,
 	"chain":            chainCmd,
@@ -178,10 +186,12 @@ var rootSubcmdsDaemon = map[string]*cmds.Command{
 	"miner":            minerCmd,
 	"mining":           miningCmd,
 	"mpool":            mpoolCmd,
+	"multisig":         multisigCmd,
 	"outbox":           outboxCmd,
 	"paych":            paymentChannelCmd,
 	"ping":             pingCmd,
 	"protocol":         protocolCmd,
+	"repo":             repoCmd,
 	"retrieval-client": retrievalClientCmd,
 	"show":             showCmd,
 	"stats":            statsCmd,
@@ -218,8 +228,9 @@ func buildEnv(ctx context.Context, _ *cmds.Request) (cmds.Environment, error) {
 }
 
 type executor struct {
-	api  string
-	exec cmds.Executor
+	api   string
+	token string
+	exec  cmds.Executor
 }
 
 func (e *executor) Execute(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
@@ -227,8 +238,23 @@ func (e *executor) Execute(req *cmds.Request, re cmds.ResponseEmitter, env cmds.
 		return e.exec.Execute(req, re, env)
 	}
 
+	// Options are sent in the URL, so the token is sent in a header instead. The HTTP client
+	// the commands library uses cannot be configured, so its transport adds the header.
+	delete(req.Options, OptionToken)
+	if e.token != "" {
+		http.DefaultClient.Transport = &tokenTransport{host: e.api, token: e.token, next: http.DefaultTransport}
+	}
 	client := cmdhttp.NewClient(e.api, cmdhttp.ClientWithAPIPrefix(APIPrefix))
 
+	// PreRun and PostRun run here in the CLI, around the request to the daemon, so that
+	// commands can work with the caller's files.
+	cmd := req.Command
+	if cmd.PreRun != nil {
+		if err := cmd.PreRun(req, env); err != nil {
+			return err
+		}
+	}
+
 	res, err := client.Send(req)
 	if err != nil {
 		if isConnectionRefused(err) {
@@ -237,6 +263,14 @@ func (e *executor) Execute(req *cmds.Request, re cmds.ResponseEmitter, env cmds.
 		return cmdkit.Errorf(cmdkit.ErrFatal, err.Error())
 	}
 
+	if typer, ok := re.(interface{ Type() cmds.PostRunType }); ok && cmd.PostRun[typer.Type()] != nil {
+		err := re.CloseWithError(cmd.PostRun[typer.Type()](res, re))
+		if err == cmds.ErrClosingClosedEmitter {
+			return nil
+		}
+		return err
+	}
+
 	// copy received result into cli emitter
 	err = cmds.Copy(re, res)
 	if err != nil {
@@ -247,13 +281,17 @@ func (e *executor) Execute(req *cmds.Request, re cmds.ResponseEmitter, env cmds.
 
 func makeExecutor(req *cmds.Request, env interface{}) (cmds.Executor, error) {
 	isDaemonRequired := requiresDaemon(req)
-	var api string
+	var api, token string
 	if isDaemonRequired {
 		var err error
 		api, err = getAPIAddress(req)
 		if err != nil {
 			return nil, err
 		}
+		token, err = getAPIToken(req)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if api == "" && isDaemonRequired {
@@ -261,11 +299,35 @@ func makeExecutor(req *cmds.Request, env interface{}) (cmds.Executor, error) {
 	}
 
 	return &executor{
-		api:  api,
-		exec: cmds.NewExecutor(rootCmd),
+		api:   api,
+		token: token,
+		exec:  cmds.NewExecutor(rootCmd),
 	}, nil
 }
 
+// getAPIToken returns the token to authorize API calls with, from the command line, the
+// environment or the local repo, or an empty string if there is none.
+func getAPIToken(req *cmds.Request) (string, error) {
+	if token, ok := req.Options[OptionToken].(string); ok && token != "" {
+		return token, nil
+	}
+	if token := os.Getenv("FIL_API_TOKEN"); token != "" {
+		return token, nil
+	}
+
+	repoDir, _ := req.Options[OptionRepoDir].(string)
+	repoDir, err := paths.GetRepoPath(repoDir)
+	if err != nil {
+		return "", err
+	}
+	token, err := repo.APITokenFromRepoPath(repoDir)
+	if err != nil {
+		// The daemon may be remote, or the caller may not be permitted to read its token file.
+		return "", nil
+	}
+	return token, nil
+}
+
 func getAPIAddress(req *cmds.Request) (string, error) {
 	var rawAddr string
 	var err error
@@ -332,19 +394,14 @@ func isConnectionRefused(err error) bool {
 	return syscallErr.Err == syscall.ECONNREFUSED
 }
 
-var priceOption = cmdkit.StringOption("gas-price", "Price (FIL e.g. 0.00013) to pay for each GasUnit consumed mining this message")
+var priceOption = cmdkit.StringOption("gas-price", "Price (FIL e.g. 0.00013) to pay for each GasUnit consumed mining this message, or auto to estimate it from recent blocks and the message pool")
 var limitOption = cmdkit.Uint64Option("gas-limit", "Maximum GasUnits this message is allowed to consume")
 var previewOption = cmdkit.BoolOption("preview", "Preview the Gas cost of this command without actually executing it")
 
-func parseGasOptions(req *cmds.Request) (types.AttoFIL, types.GasUnits, bool, error) {
-	priceOption := req.Options["gas-price"]
-	if priceOption == nil {
-		return types.ZeroAttoFIL, types.NewGasUnits(0), false, errors.New("gas-price option is required")
-	}
-
-	price, ok := types.NewAttoFILFromFILString(priceOption.(string))
-	if !ok {
-		return types.ZeroAttoFIL, types.NewGasUnits(0), false, errors.New("invalid gas price (specify FIL as a decimal number)")
+func parseGasOptions(req *cmds.Request, env cmds.Environment) (types.AttoFIL, types.GasUnits, bool, error) {
+	price, err := parseGasPrice(req, env)
+	if err != nil {
+		return types.ZeroAttoFIL, types.NewGasUnits(0), false, err
 	}
 
 	limitOption := req.Options["gas-limit"]
@@ -362,3 +419,25 @@ func parseGasOptions(req *cmds.Request) (types.AttoFIL, types.GasUnits, bool, er
 
 	return price, types.NewGasUnits(gasLimitInt), preview, nil
 }
+
+// parseGasPrice parses the gas-price option, estimating the gas price if it is "auto".
+func parseGasPrice(req *cmds.Request, env cmds.Environment) (types.AttoFIL, error) {
+	priceOption, ok := req.Options["gas-price"].(string)
+	if !ok {
+		return types.ZeroAttoFIL, errors.New("gas-price option is required")
+	}
+
+	if priceOption == "auto" {
+		price, err := GetPorcelainAPI(env).MessageEstimateGasPrice(req.Context)
+		if err != nil {
+			return types.ZeroAttoFIL, errors.Wrap(err, "failed to estimate gas price")
+		}
+		return price, nil
+	}
+
+	price, ok := types.NewAttoFILFromFILString(priceOption)
+	if !ok {
+		return types.ZeroAttoFIL, errors.New("invalid gas price (specify FIL as a decimal number)")
+	}
+	return price, nil
+}
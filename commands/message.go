@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ipfs/go-cid"
@@ -19,7 +20,9 @@ import (
 	"github.com/filecoin-project/go-filecoin/message"
 	"github.com/filecoin-project/go-filecoin/plumbing/cst"
 	"github.com/filecoin-project/go-filecoin/plumbing/msg"
+	"github.com/filecoin-project/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/vm"
 )
 
 var msgCmd = &cmds.Command{
@@ -27,9 +30,12 @@ var msgCmd = &cmds.Command{
 		Tagline: "Send and monitor messages",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"send":   msgSendCmd,
-		"status": msgStatusCmd,
-		"wait":   msgWaitCmd,
+		"estimate": msgEstimateCmd,
+		"replace":  msgReplaceCmd,
+		"send":     msgSendCmd,
+		"status":   msgStatusCmd,
+		"trace":    msgTraceCmd,
+		"wait":     msgWaitCmd,
 	},
 }
 
@@ -76,7 +82,7 @@ var msgSendCmd = &cmds.Command{
 			return err
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		gasPrice, gasLimit, preview, err := parseGasOptions(req, env)
 		if err != nil {
 			return err
 		}
@@ -135,6 +141,90 @@ var msgSendCmd = &cmds.Command{
 	},
 }
 
+var msgEstimateCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Suggest a gas price and gas limit for a message",
+		ShortDescription: `Previews the message to estimate its gas limit, and suggests a gas price from the gas
+prices of the messages in recent blocks and in the message pool.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("target", true, false, "Address of the actor to send the message to"),
+		cmdkit.StringArg("method", false, false, "The method to invoke on the target actor"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send message from"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		target, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		method := ""
+		if len(req.Arguments) > 1 {
+			method = req.Arguments[1]
+		}
+
+		estimate, err := GetPorcelainAPI(env).MessageEstimateGas(req.Context, fromAddr, target, method)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(estimate)
+	},
+	Type: &porcelain.GasEstimate{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, estimate *porcelain.GasEstimate) error {
+			_, err := fmt.Fprintf(w, "gas price: %s\ngas limit: %d\n", estimate.GasPrice, estimate.GasLimit)
+			return err
+		}),
+	},
+}
+
+var msgReplaceCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Replace a pending message with one paying a higher gas price",
+		ShortDescription: `Re-signs a message sent from this node that has yet to be mined with a new gas price,
+and sends it in place of the original. The new gas price must exceed the original by at least
+the message pool's replaceByFeePercent.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("cid", true, false, "CID of the message to replace"),
+	},
+	Options: []cmdkit.Option{
+		priceOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		msgCid, err := cid.Parse(req.Arguments[0])
+		if err != nil {
+			return errors.Wrap(err, "invalid cid "+req.Arguments[0])
+		}
+
+		gasPrice, err := parseGasPrice(req, env)
+		if err != nil {
+			return err
+		}
+
+		c, err := GetPorcelainAPI(env).MessageReplace(req.Context, msgCid, gasPrice)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(c)
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, c cid.Cid) error {
+			return PrintString(w, c)
+		}),
+	},
+}
+
 // WaitResult is the result of a message wait call.
 type WaitResult struct {
 	Message   *types.SignedMessage
@@ -310,6 +400,55 @@ var msgStatusCmd = &cmds.Command{
 	},
 }
 
+var msgTraceCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show how a message on chain executed",
+		ShortDescription: `Re-executes the tipset in which a message appears against the state of its parent and
+shows the calls the message made: each message sent, with its method, value, gas used,
+exit code and error, nested under the message that sent it.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("cid", true, false, "CID of the message to trace"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		msgCid, err := cid.Parse(req.Arguments[0])
+		if err != nil {
+			return errors.Wrap(err, "invalid cid "+req.Arguments[0])
+		}
+
+		execTrace, err := GetPorcelainAPI(env).MessageTrace(req.Context, msgCid)
+		if err != nil {
+			return err
+		}
+		return re.Emit(execTrace)
+	},
+	Type: vm.ExecutionTrace{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *vm.ExecutionTrace) error {
+			sw := NewSilentWriter(w)
+			printExecutionTrace(sw, res, 0)
+			return sw.Error()
+		}),
+	},
+}
+
+// printExecutionTrace prints a call of an execution trace and, indented below it, the calls
+// that it made.
+func printExecutionTrace(sw *SilentWriter, t *vm.ExecutionTrace, depth int) {
+	indent := strings.Repeat("  ", depth)
+	method := t.Method
+	if method == "" {
+		method = "(transfer)"
+	}
+	sw.Printf("%s%s -> %s %s value=%s gas=%d exit=%d\n", indent, t.From, t.To, method, t.Value.String(), t.GasUsed, t.ExitCode)
+	if t.Error != "" {
+		sw.Printf("%s  error: %s\n", indent, t.Error)
+	}
+	for _, sub := range t.Subcalls {
+		printExecutionTrace(sw, sub, depth+1)
+	}
+}
+
 func appendJSON(val interface{}, out []byte) ([]byte, error) {
 	m, err := json.MarshalIndent(val, "", "\t")
 	if err != nil {
@@ -68,6 +68,25 @@ func TestMessageSend(t *testing.T) {
 		"--value", "5.5",
 		fixtures.TestAddresses[3],
 	)
+
+	t.Log("[success] with estimated gas price")
+	d.RunSuccess("message", "send",
+		"--from", from,
+		"--gas-price", "auto",
+		"--gas-limit", "300",
+		fixtures.TestAddresses[3],
+	)
+}
+
+func TestMessageEstimate(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	out := d.RunSuccess("message", "estimate", fixtures.TestAddresses[3]).ReadStdoutTrimNewlines()
+	assert.Contains(t, out, "gas price: ")
+	assert.Contains(t, out, "gas limit: ")
 }
 
 func TestMessageWait(t *testing.T) {
@@ -188,3 +207,28 @@ func TestMessageStatus(t *testing.T) {
 		assert.NotContains(t, status, "On chain")
 	})
 }
+
+func TestMessageTrace(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	msg := d.RunSuccess(
+		"message", "send",
+		"--from", fixtures.TestAddresses[0],
+		"--gas-price", "1", "--gas-limit", "300",
+		"--value=1234",
+		fixtures.TestAddresses[1],
+	)
+	msgcid := strings.Trim(msg.ReadStdout(), "\n")
+
+	d.RunFail("not found on chain", "message", "trace", msgcid)
+
+	d.RunSuccess("mining once")
+
+	out := d.RunSuccess("message", "trace", msgcid).ReadStdout()
+	assert.Contains(t, out, fixtures.TestAddresses[0]+" -> "+fixtures.TestAddresses[1])
+	assert.Contains(t, out, "value=1234")
+	assert.Contains(t, out, "exit=0")
+}
@@ -13,8 +13,10 @@ import (
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/pkg/errors"
 
+	minerActor "github.com/filecoin-project/go-filecoin/actor/builtin/miner"
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/protocol/retrieval"
 	"github.com/filecoin-project/go-filecoin/types"
 )
 
@@ -23,15 +25,16 @@ var minerCmd = &cmds.Command{
 		Tagline: "Manage a single miner actor",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"create":         minerCreateCmd,
-		"owner":          minerOwnerCmd,
-		"power":          minerPowerCmd,
-		"set-price":      minerSetPriceCmd,
-		"update-peerid":  minerUpdatePeerIDCmd,
-		"collateral":     minerCollateralCmd,
-		"proving-window": minerProvingWindowCmd,
-		"set-worker":     minerSetWorkerAddressCmd,
-		"worker":         minerWorkerAddressCmd,
+		"create":              minerCreateCmd,
+		"owner":               minerOwnerCmd,
+		"power":               minerPowerCmd,
+		"set-price":           minerSetPriceCmd,
+		"set-retrieval-price": minerSetRetrievalPriceCmd,
+		"update-peerid":       minerUpdatePeerIDCmd,
+		"collateral":          minerCollateralCmd,
+		"proving-window":      minerProvingWindowCmd,
+		"set-worker":          minerSetWorkerAddressCmd,
+		"worker":              minerWorkerAddressCmd,
 	},
 }
 
@@ -110,7 +113,7 @@ additional sectors.`,
 			return ErrInvalidCollateral
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		gasPrice, gasLimit, preview, err := parseGasOptions(req, env)
 		if err != nil {
 			return err
 		}
@@ -212,7 +215,7 @@ This command waits for the ask to be mined.`,
 			return fmt.Errorf("expiry must be a valid integer")
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		gasPrice, gasLimit, preview, err := parseGasOptions(req, env)
 		if err != nil {
 			return err
 		}
@@ -274,6 +277,92 @@ This command waits for the ask to be mined.`,
 	},
 }
 
+var minerSetRetrievalPriceCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Set the price for retrieval",
+		ShortDescription: `Sets mining.retrievalPrice in config and publishes a retrieval ask for the given price.
+This command waits for the ask to be mined.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("retrievalprice", true, false, "The new price of retrieval in FIL per byte"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to send from"),
+		cmdkit.StringOption("miner", "The address of the miner owning the ask"),
+		cmdkit.Uint64Option("payment-interval", "Number of bytes sent before payment is required, at least one retrieval chunk").WithDefault(uint64(retrieval.DefaultPaymentInterval)),
+		cmdkit.StringOption("unseal-price", "Price in FIL for unsealing a sector to serve a retrieval").WithDefault("0"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		price, ok := types.NewAttoFILFromFILString(req.Arguments[0])
+		if !ok {
+			return ErrInvalidPrice
+		}
+
+		unsealPrice, ok := types.NewAttoFILFromFILString(req.Options["unseal-price"].(string))
+		if !ok {
+			return ErrInvalidPrice
+		}
+
+		// Clients refuse to retrieve from a miner that requires payment within a chunk.
+		paymentInterval := req.Options["payment-interval"].(uint64)
+		if paymentInterval < retrieval.RetrievePieceChunkSize {
+			return fmt.Errorf("payment interval must be at least %d bytes", retrieval.RetrievePieceChunkSize)
+		}
+
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		var minerAddr address.Address
+		if req.Options["miner"] != nil {
+			minerAddr, err = address.NewFromString(req.Options["miner"].(string))
+			if err != nil {
+				return errors.Wrap(err, "miner must be an address")
+			}
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req, env)
+		if err != nil {
+			return err
+		}
+
+		res, err := GetPorcelainAPI(env).MinerSetRetrievalPrice(
+			req.Context,
+			fromAddr,
+			minerAddr,
+			gasPrice,
+			gasLimit,
+			minerActor.RetrievalAsk{
+				Price:           price,
+				PaymentInterval: types.NewBytesAmount(paymentInterval),
+				UnsealPrice:     unsealPrice,
+			})
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(&res)
+	},
+	Type: &porcelain.MinerSetRetrievalPriceResponse{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *porcelain.MinerSetRetrievalPriceResponse) error {
+			_, err := fmt.Fprintf(w, `Set retrieval price for miner %s to %s.
+	Published retrieval ask, cid: %s.
+	Ask confirmed on chain in block: %s.
+`,
+				res.MinerAddr.String(),
+				res.Ask.Price.String(),
+				res.SetAskCid.String(),
+				res.BlockCid.String(),
+			)
+			return err
+		}),
+	},
+}
+
 // MinerUpdatePeerIDResult is the return type for miner update-peerid command
 type MinerUpdatePeerIDResult struct {
 	Cid     cid.Cid
@@ -312,7 +401,7 @@ var minerUpdatePeerIDCmd = &cmds.Command{
 			return err
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		gasPrice, gasLimit, preview, err := parseGasOptions(req, env)
 		if err != nil {
 			return err
 		}
@@ -511,7 +600,7 @@ var minerSetWorkerAddressCmd = &cmds.Command{
 			return err
 		}
 
-		gasPrice, gasLimit, _, err := parseGasOptions(req)
+		gasPrice, gasLimit, _, err := parseGasOptions(req, env)
 		if err != nil {
 			return err
 		}
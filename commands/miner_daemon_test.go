@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/big"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
@@ -134,7 +135,7 @@ func TestMinerCreate(t *testing.T) {
 
 			d1.ConnectSuccess(d)
 
-			args := []string{"miner", "create", "--from", fromAddress.String(), "--gas-price", "1", "--gas-limit", "300"}
+			args := []string{"miner", "create", "--from", fromAddress.String(), "--gas-price", "1", "--gas-limit", "1000"}
 
 			if pid.Pretty() != peer.ID("").Pretty() {
 				args = append(args, "--peerid", pid.Pretty())
@@ -218,7 +219,7 @@ func TestMinerSetPrice(t *testing.T) {
 
 	d1.RunSuccess("mining", "start")
 
-	setPrice := d1.RunSuccess("miner", "set-price", "62", "6", "--gas-price", "1", "--gas-limit", "300")
+	setPrice := d1.RunSuccess("miner", "set-price", "62", "6", "--gas-price", "1", "--gas-limit", "1000")
 	assert.Contains(t, setPrice.ReadStdoutTrimNewlines(), fmt.Sprintf("Set price for miner %s to 62.", fixtures.TestMiners[0]))
 
 	configuredPrice := d1.RunSuccess("config", "mining.storagePrice")
@@ -252,7 +253,7 @@ func requireMinerCreate(ctx context.Context, t *testing.T, env *fastesting.TestE
 
 	sinfo := pparams.SupportedSectors[0]
 
-	minerAddress, err := minerNode.MinerCreate(ctx, big.NewInt(1), fast.AOSectorSize(sinfo.Size), fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(300))
+	minerAddress, err := minerNode.MinerCreate(ctx, big.NewInt(1), fast.AOSectorSize(sinfo.Size), fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(1000))
 	require.NoError(t, err)
 
 	return minerAddress
@@ -274,9 +275,14 @@ func TestMinerCreateChargesGas(t *testing.T) {
 	// make sure the FIL shows up in the MinerOwnerAccount
 	startingBalance := queryBalance(t, d, miningMinerOwnerAddr)
 
+	// the gas charged depends on the size of the miner's state, so preview it
+	preview := d.RunSuccess("miner", "create", "--from", fixtures.TestAddresses[2], "--gas-price", "333", "--gas-limit", "1000", "--preview", "200")
+	gasUsed, err := strconv.ParseInt(strings.Trim(preview.ReadStdout(), "\n"), 10, 64)
+	require.NoError(t, err)
+
 	wg.Add(1)
 	go func() {
-		testMiner := d.RunSuccess("miner", "create", "--from", fixtures.TestAddresses[2], "--gas-price", "333", "--gas-limit", "100", "200")
+		testMiner := d.RunSuccess("miner", "create", "--from", fixtures.TestAddresses[2], "--gas-price", "333", "--gas-limit", "1000", "200")
 		addr, err := address.NewFromString(strings.Trim(testMiner.ReadStdout(), "\n"))
 		assert.NoError(t, err)
 		assert.NotEqual(t, addr, address.Undef)
@@ -288,7 +294,7 @@ func TestMinerCreateChargesGas(t *testing.T) {
 
 	expectedBlockReward := consensus.NewDefaultBlockRewarder().BlockRewardAmount()
 	expectedPrice := types.NewAttoFILFromFIL(333)
-	expectedGasCost := big.NewInt(100)
+	expectedGasCost := big.NewInt(gasUsed)
 	expectedBalance := expectedBlockReward.Add(expectedPrice.MulBigInt(expectedGasCost))
 	newBalance := queryBalance(t, d, miningMinerOwnerAddr)
 	assert.Equal(t, expectedBalance.String(), newBalance.Sub(startingBalance).String())
@@ -501,7 +507,7 @@ func TestMinerSetWorker(t *testing.T) {
 	newAddr := address.NewForTestGetter()()
 
 	t.Run("fails if there is no miner worker", func(t *testing.T) {
-		_, err := minerNode.MinerSetWorker(ctx, newAddr, fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(300))
+		_, err := minerNode.MinerSetWorker(ctx, newAddr, fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(1000))
 		require.NotNil(t, err)
 		lastErr, err := minerNode.LastCmdStdErrStr()
 		require.NoError(t, err)
@@ -511,7 +517,7 @@ func TestMinerSetWorker(t *testing.T) {
 	t.Run("succceeds if there is a miner", func(t *testing.T) {
 		_ = requireMinerCreate(ctx, t, env, minerNode)
 
-		msgCid, err := minerNode.MinerSetWorker(ctx, newAddr, fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(300))
+		msgCid, err := minerNode.MinerSetWorker(ctx, newAddr, fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(1000))
 		require.NoError(t, err)
 
 		resp, err := minerNode.MessageWait(ctx, msgCid)
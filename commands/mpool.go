@@ -11,6 +11,8 @@ import (
 	"github.com/ipfs/go-ipfs-cmds"
 	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/message"
 	"github.com/filecoin-project/go-filecoin/types"
 )
 
@@ -19,9 +21,10 @@ var mpoolCmd = &cmds.Command{
 		Tagline: "Manage the message pool",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"ls":   mpoolLsCmd,
-		"show": mpoolShowCmd,
-		"rm":   mpoolRemoveCmd,
+		"ls":    mpoolLsCmd,
+		"show":  mpoolShowCmd,
+		"rm":    mpoolRemoveCmd,
+		"watch": mpoolWatchCmd,
 	},
 }
 
@@ -123,3 +126,64 @@ var mpoolRemoveCmd = &cmds.Command{
 		return nil
 	},
 }
+
+var mpoolWatchCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Stream messages being added to and removed from the message pool",
+		ShortDescription: `
+Outputs an event each time a message is added to the pool, removed from it,
+included in a block or expired. Events may be filtered by the sender, target
+or method of the message.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Only show messages from this address"),
+		cmdkit.StringOption("to", "Only show messages to this address"),
+		cmdkit.StringOption("method", "Only show messages calling this method"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		var from, to address.Address
+		var err error
+		if o, ok := req.Options["from"].(string); ok {
+			if from, err = address.NewFromString(o); err != nil {
+				return errors.Wrap(err, "invalid from address")
+			}
+		}
+		if o, ok := req.Options["to"].(string); ok {
+			if to, err = address.NewFromString(o); err != nil {
+				return errors.Wrap(err, "invalid to address")
+			}
+		}
+		method, hasMethod := req.Options["method"].(string)
+
+		api := GetPorcelainAPI(env)
+		ch := api.MessagePoolSubscribe()
+		defer api.MessagePoolUnsubscribe(ch)
+
+		for {
+			select {
+			case <-req.Context.Done():
+				return nil
+			case e, ok := <-ch:
+				if !ok {
+					return nil
+				}
+				event := e.(*message.PoolEvent)
+				msg := event.Message
+				if (!from.Empty() && msg.From != from) || (!to.Empty() && msg.To != to) || (hasMethod && msg.Method != method) {
+					continue
+				}
+				if err := re.Emit(event); err != nil {
+					return err
+				}
+			}
+		}
+	},
+	Type: message.PoolEvent{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, event *message.PoolEvent) error {
+			_, err := fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", event.Type, event.Cid, event.Message.From, event.Message.To, event.Message.Method)
+			return err
+		}),
+	},
+}
@@ -0,0 +1,279 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+	"math/big"
+	"strconv"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-ipfs-cmdkit"
+	"github.com/ipfs/go-ipfs-cmds"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+var multisigCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Manage multisig wallet actors",
+		ShortDescription: `A multisig actor holds funds that it only sends once a threshold of its signers
+have approved the transaction.`,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"approve": multisigApproveCmd,
+		"create":  multisigCreateCmd,
+		"ls":      multisigLsCmd,
+		"propose": multisigProposeCmd,
+	},
+}
+
+// MultisigCreateResult is the result of the multisig create command
+type MultisigCreateResult struct {
+	Address address.Address
+}
+
+var multisigCreateCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Create a new multisig actor",
+		ShortDescription: `Issues a new message to the network to create a multisig actor, then waits for the
+message to be mined to get the address of the actor.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("threshold", true, false, "Number of signers that must approve each transaction"),
+		cmdkit.StringArg("signers", true, true, "Addresses of the signers"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("value", "Value in FIL to deposit into the multisig actor"),
+		cmdkit.StringOption("from", "Address to send from"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		threshold, err := strconv.ParseUint(req.Arguments[0], 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "invalid threshold")
+		}
+
+		var signers []address.Address
+		for _, arg := range req.Arguments[1:] {
+			signer, err := address.NewFromString(arg)
+			if err != nil {
+				return err
+			}
+			signers = append(signers, signer)
+		}
+
+		value, err := optionalFIL(req.Options["value"])
+		if err != nil {
+			return err
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req, env)
+		if err != nil {
+			return err
+		}
+
+		addr, err := GetPorcelainAPI(env).MultisigCreate(req.Context, fromAddr, gasPrice, gasLimit, signers, threshold, value)
+		if err != nil {
+			return errors.Wrap(err, "could not create multisig")
+		}
+
+		return re.Emit(&MultisigCreateResult{Address: addr})
+	},
+	Type: &MultisigCreateResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *MultisigCreateResult) error {
+			return PrintString(w, res.Address)
+		}),
+	},
+}
+
+// MultisigProposeResult is the result of the multisig propose command
+type MultisigProposeResult struct {
+	TxID uint64
+}
+
+var multisigProposeCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Propose a transaction of a multisig actor",
+		ShortDescription: `Proposes that the multisig actor sends a message to target, and waits for the proposal
+to be mined to get the id of the transaction. The proposer approves the transaction, which
+is sent once enough signers have approved it.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("multisig", true, false, "Address of the multisig actor"),
+		cmdkit.StringArg("target", true, false, "Address the multisig actor will send the message to"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("value", "Value in FIL the multisig actor will send"),
+		cmdkit.StringOption("method", "Method the multisig actor will invoke on the target"),
+		cmdkit.StringOption("from", "Address of the signer proposing the transaction"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		msigAddr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		target, err := address.NewFromString(req.Arguments[1])
+		if err != nil {
+			return err
+		}
+
+		value, err := optionalFIL(req.Options["value"])
+		if err != nil {
+			return err
+		}
+
+		method, ok := req.Options["method"].(string)
+		if !ok {
+			method = ""
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req, env)
+		if err != nil {
+			return err
+		}
+
+		txID, err := GetPorcelainAPI(env).MultisigPropose(req.Context, fromAddr, msigAddr, gasPrice, gasLimit, target, value, method)
+		if err != nil {
+			return errors.Wrap(err, "could not propose transaction")
+		}
+
+		return re.Emit(&MultisigProposeResult{TxID: txID})
+	},
+	Type: &MultisigProposeResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *MultisigProposeResult) error {
+			_, err := fmt.Fprintln(w, res.TxID)
+			return err
+		}),
+	},
+}
+
+var multisigApproveCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline:          "Approve a pending transaction of a multisig actor",
+		ShortDescription: `Issues a new message approving the transaction, which the multisig actor sends once enough signers have approved it.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("multisig", true, false, "Address of the multisig actor"),
+		cmdkit.StringArg("txid", true, false, "Id of the transaction to approve"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address of the signer approving the transaction"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		msigAddr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		txID, err := strconv.ParseUint(req.Arguments[1], 10, 64)
+		if err != nil {
+			return errors.Wrap(err, "invalid transaction id")
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req, env)
+		if err != nil {
+			return err
+		}
+
+		c, err := GetPorcelainAPI(env).MessageSend(
+			req.Context,
+			fromAddr,
+			msigAddr,
+			types.ZeroAttoFIL,
+			gasPrice,
+			gasLimit,
+			"approve",
+			new(big.Int).SetUint64(txID),
+		)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(c)
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, c cid.Cid) error {
+			return PrintString(w, c)
+		}),
+	},
+}
+
+var multisigLsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the signers and pending transactions of a multisig actor",
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("multisig", true, false, "Address of the multisig actor"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		msigAddr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		info, err := GetPorcelainAPI(env).MultisigLs(req.Context, msigAddr)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(info)
+	},
+	Type: &porcelain.MultisigInfo{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, info *porcelain.MultisigInfo) error {
+			if _, err := fmt.Fprintf(w, "threshold: %d\n", info.Threshold); err != nil {
+				return err
+			}
+			for _, signer := range info.Signers {
+				if _, err := fmt.Fprintf(w, "signer: %s\n", signer); err != nil {
+					return err
+				}
+			}
+			for _, tx := range info.Pending {
+				_, err := fmt.Fprintf(w, "%d: to: %s, value: %s, method: %q, params: %v, approved: %v\n", tx.ID, tx.To, tx.Value, tx.Method, tx.Params, tx.Approved)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	},
+}
+
+func optionalFIL(opt interface{}) (types.AttoFIL, error) {
+	if opt == nil {
+		return types.ZeroAttoFIL, nil
+	}
+	value, ok := types.NewAttoFILFromFILString(opt.(string))
+	if !ok {
+		return types.ZeroAttoFIL, ErrInvalidAmount
+	}
+	return value, nil
+}
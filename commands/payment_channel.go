@@ -74,7 +74,7 @@ message to be mined to get the channelID.`,
 			return ErrInvalidBlockHeight
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		gasPrice, gasLimit, preview, err := parseGasOptions(req, env)
 		if err != nil {
 			return err
 		}
@@ -258,7 +258,7 @@ var redeemCmd = &cmds.Command{
 			return err
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		gasPrice, gasLimit, preview, err := parseGasOptions(req, env)
 		if err != nil {
 			return err
 		}
@@ -351,7 +351,7 @@ var reclaimCmd = &cmds.Command{
 			return fmt.Errorf("invalid channel id")
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		gasPrice, gasLimit, preview, err := parseGasOptions(req, env)
 		if err != nil {
 			return err
 		}
@@ -433,7 +433,7 @@ var closeCmd = &cmds.Command{
 			return err
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		gasPrice, gasLimit, preview, err := parseGasOptions(req, env)
 		if err != nil {
 			return err
 		}
@@ -538,7 +538,7 @@ var extendCmd = &cmds.Command{
 			return ErrInvalidBlockHeight
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		gasPrice, gasLimit, preview, err := parseGasOptions(req, env)
 		if err != nil {
 			return err
 		}
@@ -625,7 +625,7 @@ var cancelCmd = &cmds.Command{
 			return fmt.Errorf("invalid channel id")
 		}
 
-		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		gasPrice, gasLimit, preview, err := parseGasOptions(req, env)
 		if err != nil {
 			return err
 		}
@@ -187,7 +187,7 @@ func TestPaymentChannelRedeemSuccess(t *testing.T) {
 	voucherStr, err := rsrc.payer.PaychVoucher(ctx, chanid, voucherAmount, fast.AOFromAddr(rsrc.payerAddr), fast.AOValidAt(voucherValidAt))
 	require.NoError(t, err)
 
-	mcid, err := rsrc.target.PaychRedeem(ctx, voucherStr, fast.AOFromAddr(rsrc.targetAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(300))
+	mcid, err := rsrc.target.PaychRedeem(ctx, voucherStr, fast.AOFromAddr(rsrc.targetAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(1000))
 	require.NoError(t, err)
 
 	series.CtxMiningOnce(ctx)
@@ -228,7 +228,7 @@ func TestPaymentChannelRedeemTooEarlyFails(t *testing.T) {
 	voucherStr, err := rsrc.payer.PaychVoucher(ctx, chanid, voucherAmount, fast.AOFromAddr(rsrc.payerAddr), fast.AOValidAt(voucherValidAt))
 	require.NoError(t, err)
 
-	mcid, err := rsrc.target.PaychRedeem(ctx, voucherStr, fast.AOFromAddr(rsrc.targetAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(300))
+	mcid, err := rsrc.target.PaychRedeem(ctx, voucherStr, fast.AOFromAddr(rsrc.targetAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(1000))
 	require.NoError(t, err)
 
 	series.CtxMiningOnce(ctx)
@@ -279,7 +279,7 @@ func TestPaymentChannelReclaimSuccess(t *testing.T) {
 	voucherStr, err := rsrc.payer.PaychVoucher(ctx, chanid, voucherAmount, fast.AOFromAddr(rsrc.payerAddr), fast.AOValidAt(voucherValidAt))
 	require.NoError(t, err)
 
-	mcid, err := rsrc.target.PaychRedeem(ctx, voucherStr, fast.AOFromAddr(rsrc.targetAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(300))
+	mcid, err := rsrc.target.PaychRedeem(ctx, voucherStr, fast.AOFromAddr(rsrc.targetAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(1000))
 	require.NoError(t, err)
 
 	series.CtxMiningOnce(ctx)
@@ -297,7 +297,7 @@ func TestPaymentChannelReclaimSuccess(t *testing.T) {
 
 	series.CtxMiningOnce(ctx)
 
-	mcid, err = rsrc.payer.PaychReclaim(ctx, chanid, fast.AOFromAddr(rsrc.payerAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(300))
+	mcid, err = rsrc.payer.PaychReclaim(ctx, chanid, fast.AOFromAddr(rsrc.payerAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(1000))
 	require.NoError(t, err)
 
 	series.CtxMiningOnce(ctx)
@@ -347,7 +347,7 @@ func TestPaymentChannelCloseSuccess(t *testing.T) {
 	voucherStr, err := rsrc.payer.PaychVoucher(ctx, chanid, voucherAmount, fast.AOFromAddr(rsrc.payerAddr), fast.AOValidAt(voucherValidAt))
 	require.NoError(t, err)
 
-	mcid, err := rsrc.target.PaychClose(ctx, voucherStr, fast.AOFromAddr(rsrc.targetAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(300))
+	mcid, err := rsrc.target.PaychClose(ctx, voucherStr, fast.AOFromAddr(rsrc.targetAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(1000))
 	require.NoError(t, err)
 
 	series.CtxMiningOnce(ctx)
@@ -403,7 +403,7 @@ func TestPaymentChannelExtendSuccess(t *testing.T) {
 	extendAmount := types.NewAttoFILFromFIL(100)
 	extendExpiry := types.NewBlockHeight(100)
 
-	mcid, err := rsrc.payer.PaychExtend(ctx, chanid, extendAmount, extendExpiry, fast.AOFromAddr(rsrc.payerAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(300))
+	mcid, err := rsrc.payer.PaychExtend(ctx, chanid, extendAmount, extendExpiry, fast.AOFromAddr(rsrc.payerAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(1000))
 	require.NoError(t, err)
 
 	series.CtxMiningOnce(ctx)
@@ -452,7 +452,7 @@ func TestPaymentChannelCancelSuccess(t *testing.T) {
 	assert.Equal(t, channelExpiry, channel.AgreedEol)
 	assert.Equal(t, channelExpiry, channel.Eol)
 
-	mcid, err := rsrc.payer.PaychCancel(ctx, chanid, fast.AOFromAddr(rsrc.payerAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(300))
+	mcid, err := rsrc.payer.PaychCancel(ctx, chanid, fast.AOFromAddr(rsrc.payerAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(1000))
 	require.NoError(t, err)
 
 	series.CtxMiningOnce(ctx)
@@ -505,7 +505,7 @@ func requireNewPaychResource(ctx context.Context, t *testing.T, env *fastesting.
 }
 
 func (rsrc *paychResources) requirePaymentChannel(ctx context.Context, t *testing.T, amt types.AttoFIL, eol *types.BlockHeight) (*types.ChannelID, types.AttoFIL) {
-	mcid, err := rsrc.payer.PaychCreate(ctx, rsrc.targetAddr, amt, eol, fast.AOFromAddr(rsrc.payerAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(300))
+	mcid, err := rsrc.payer.PaychCreate(ctx, rsrc.targetAddr, amt, eol, fast.AOFromAddr(rsrc.payerAddr), fast.AOPrice(big.NewFloat(1)), fast.AOLimit(1000))
 	require.NoError(t, err)
 
 	series.CtxMiningOnce(ctx)
@@ -0,0 +1,62 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	cmdkit "github.com/ipfs/go-ipfs-cmdkit"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+)
+
+// RepoGCResult is the result of the repo gc command.
+type RepoGCResult struct {
+	Deleted int
+}
+
+var repoCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Manage the filecoin repo",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"gc": repoGCCmd,
+	},
+}
+
+var repoGCCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Delete old chain data from the repo",
+		ShortDescription: `
+Deletes the states of tipsets more than keep-depth tipsets below the chain
+head, unless they are also reachable from the more recent tipsets. Block
+headers, messages, receipts and the genesis state are kept, as is any data
+that is not part of the chain. Chain sync is paused while garbage is collected.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.UintOption("keep-depth", "Number of most recent tipsets to keep the data of (default: datastore.gcKeepDepth)"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		api := GetPorcelainAPI(env)
+		keepDepth, ok := req.Options["keep-depth"].(uint)
+		if !ok {
+			configured, err := api.ConfigGet("datastore.gcKeepDepth")
+			if err != nil {
+				return err
+			}
+			keepDepth = configured.(uint)
+		}
+
+		deleted, err := api.ChainCollectGarbage(req.Context, keepDepth)
+		if err != nil {
+			return err
+		}
+		return re.Emit(&RepoGCResult{Deleted: deleted})
+	},
+	Type: &RepoGCResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *RepoGCResult) error {
+			_, err := fmt.Fprintf(w, "removed %d blocks\n", res.Deleted)
+			return err
+		}),
+	},
+}
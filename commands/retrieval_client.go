@@ -1,11 +1,21 @@
 package commands
 
 import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-ipfs-cmdkit"
 	"github.com/ipfs/go-ipfs-cmds"
+	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/protocol/retrieval"
+	"github.com/filecoin-project/go-filecoin/types"
 )
 
 var retrievalClientCmd = &cmds.Command{
@@ -13,6 +23,9 @@ var retrievalClientCmd = &cmds.Command{
 		Tagline: "Manage retrieval client operations",
 	},
 	Subcommands: map[string]*cmds.Command{
+		"find-miners":    clientFindRetrievalMinersCmd,
+		"payments":       clientRetrievalPaymentsCmd,
+		"retrieve-dag":   clientRetrieveDAGCmd,
 		"retrieve-piece": clientRetrievePieceCmd,
 	},
 }
@@ -20,32 +33,523 @@ var retrievalClientCmd = &cmds.Command{
 var clientRetrievePieceCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Read out piece data stored by a miner on the network",
+		ShortDescription: `
+Retrieves a piece from the miner given by --miner. Without --miner, the miners
+holding the piece are found as by find-miners and tried cheapest and closest first
+until one retrieval succeeds, and a transfer that fails part way resumes with the
+next miner.
+
+By default the piece is retrieved for free. When --pay is given, the piece is paid
+for with vouchers as it arrives, through a payment channel to the miner's owner that
+holds that amount. The miners tried in turn share the amount, each being offered
+what the miners before it left, so the retrieval pays at most that amount in total.
+The channel is kept for later retrievals from the same miner and topped up only as
+needed. With --max-price, miners asking more are not tried.
+
+--offset and --length select a byte range of the piece. When --output is given the
+piece is written to that file instead. If the file already exists, the retrieval
+resumes after the bytes it already holds, so an interrupted transfer can be
+completed by running the same command again.
+`,
 	},
 	Arguments: []cmdkit.Argument{
-		cmdkit.StringArg("miner", true, false, "Retrieval miner actor address"),
 		cmdkit.StringArg("cid", true, false, "Content identifier of piece to read"),
 	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("miner", "Retrieval miner actor address"),
+		cmdkit.Uint64Option("offset", "Number of bytes to skip at the start of the piece"),
+		cmdkit.Uint64Option("length", "Maximum number of bytes to retrieve (defaults to the rest of the piece)"),
+		cmdkit.StringOption("output", "File to write the piece to, resuming if it already exists"),
+		cmdkit.StringOption("pay", "Amount in FIL to pay at most for a paid retrieval"),
+		cmdkit.StringOption("max-price", "Highest price in FIL per byte to accept for a paid retrieval (defaults to any price)"),
+		cmdkit.StringOption("eol", "Block height at which the payment channel should expire"),
+		cmdkit.StringOption("from", "Address to pay from"),
+		priceOption,
+		limitOption,
+	},
+	PreRun: retrievePiecePreRun,
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		pieceCID, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		if o := req.Options["miner"]; o != nil {
+			minerAddr, err := address.NewFromString(o.(string))
+			if err != nil {
+				return errors.Wrap(err, "miner must be an address")
+			}
+
+			mpid, err := GetPorcelainAPI(env).MinerGetPeerID(req.Context, minerAddr)
+			if err != nil {
+				return err
+			}
+
+			return retrievePiece(req, re, env, pieceCID, []porcelain.RetrievalMiner{{Address: minerAddr, PeerID: mpid}})
+		}
+
+		miners, err := GetPorcelainAPI(env).ClientFindRetrievalMiners(req.Context, pieceCID)
+		if err != nil {
+			return err
+		}
+
+		if o := req.Options["max-price"]; o != nil && req.Options["pay"] != nil {
+			maxPrice, ok := types.NewAttoFILFromFILString(o.(string))
+			if !ok {
+				return errors.New("invalid max price (specify FIL as a decimal number)")
+			}
+
+			var affordable []porcelain.RetrievalMiner
+			for _, m := range miners {
+				if !m.Price.GreaterThan(maxPrice) {
+					affordable = append(affordable, m)
+				}
+			}
+			miners = affordable
+		}
+
+		if len(miners) == 0 {
+			return fmt.Errorf("no reachable miner found for piece %s", pieceCID)
+		}
+
+		return retrievePiece(req, re, env, pieceCID, miners)
+	},
+	PostRun: retrievePiecePostRun,
+}
+
+var clientFindRetrievalMinersCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Find the miners that can serve a piece",
+		ShortDescription: `
+Lists the reachable miners that hold a piece, cheapest first and then by ping
+latency. Miners are found through completed deals in the local deal store and
+through DHT provider records matched to miner peer IDs on chain.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("cid", true, false, "Content identifier of piece to find"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		pieceCID, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		miners, err := GetPorcelainAPI(env).ClientFindRetrievalMiners(req.Context, pieceCID)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(miners)
+	},
+	Type: []porcelain.RetrievalMiner{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, miners []porcelain.RetrievalMiner) error {
+			for _, m := range miners {
+				if _, err := fmt.Fprintf(w, "%s %s %s %s\n", m.Address, m.PeerID.Pretty(), m.Price, m.Latency); err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	},
+}
+
+// RetrieveDAGResult is the result of retrieving a DAG into the local blockstore
+type RetrieveDAGResult struct {
+	Root  cid.Cid         `json:"root"`
+	Cid   cid.Cid         `json:"cid"`
+	Miner address.Address `json:"minerAddress"`
+}
+
+var clientRetrieveDAGCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Retrieve a DAG, or part of one, into the local blockstore",
+		ShortDescription: `
+Retrieves the DAG stored under a payload root CID over graphsync and writes its
+blocks into the local blockstore, so that client cat and dag get can read it
+afterwards. --path selects a node below the root by link names, such as a file
+in a directory; only the nodes along the path and the DAG below its end are
+transferred. Without --miner, the miners holding the root are tried as by
+find-miners until one of them sends the whole DAG. DAG retrieval is unpaid.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("cid", true, false, "Payload root CID of the DAG to retrieve"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("path", "Slash separated link names to follow from the root"),
+		cmdkit.StringOption("miner", "Retrieval miner actor address"),
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
-		minerAddr, err := address.NewFromString(req.Arguments[0])
+		root, err := cid.Decode(req.Arguments[0])
 		if err != nil {
 			return err
 		}
 
-		pieceCID, err := cid.Decode(req.Arguments[1])
+		var path []string
+		if o, ok := req.Options["path"].(string); ok {
+			for _, name := range strings.Split(o, "/") {
+				if name != "" {
+					path = append(path, name)
+				}
+			}
+		}
+
+		var miners []porcelain.RetrievalMiner
+		if o := req.Options["miner"]; o != nil {
+			minerAddr, err := address.NewFromString(o.(string))
+			if err != nil {
+				return errors.Wrap(err, "miner must be an address")
+			}
+
+			mpid, err := GetPorcelainAPI(env).MinerGetPeerID(req.Context, minerAddr)
+			if err != nil {
+				return err
+			}
+			miners = []porcelain.RetrievalMiner{{Address: minerAddr, PeerID: mpid}}
+		} else {
+			miners, err = GetPorcelainAPI(env).ClientFindRetrievalMiners(req.Context, root)
+			if err != nil {
+				return err
+			}
+			if len(miners) == 0 {
+				return fmt.Errorf("no reachable miner found for %s", root)
+			}
+		}
+
+		var lastErr error
+		for _, m := range miners {
+			target, err := GetRetrievalAPI(env).RetrieveDAG(req.Context, root, path, m.PeerID)
+			if err != nil {
+				lastErr = errors.Wrapf(err, "retrieval from miner %s failed", m.Address)
+				continue
+			}
+
+			return re.Emit(&RetrieveDAGResult{
+				Root:  root,
+				Cid:   target,
+				Miner: m.Address,
+			})
+		}
+
+		return lastErr
+	},
+	Type: RetrieveDAGResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *RetrieveDAGResult) error {
+			_, err := fmt.Fprintf(w, "retrieved %s from miner %s\n", res.Cid, res.Miner)
+			return err
+		}),
+	},
+}
+
+// retrievePiece streams a piece from the first of the given miners that serves it,
+// according to the options of retrieve-piece. If a miner fails part way,
+// the rest of the range is retrieved from the next miner.
+func retrievePiece(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment, pieceCID cid.Cid, miners []porcelain.RetrievalMiner) error {
+	offset, _ := req.Options["offset"].(uint64)
+	length, _ := req.Options["length"].(uint64)
+
+	var budget *paymentBudget
+	if o := req.Options["pay"]; o != nil {
+		params, err := parseRetrievalPaymentParams(req, env, o.(string))
 		if err != nil {
 			return err
 		}
+		budget = newPaymentBudget(params)
+	}
+
+	fr := &failoverReader{
+		open: func(m porcelain.RetrievalMiner, offset, length uint64) (io.ReadCloser, error) {
+			if budget == nil {
+				return GetRetrievalAPI(env).RetrievePiece(req.Context, pieceCID, m.PeerID, m.Address, offset, length)
+			}
+			return budget.open(func(params retrieval.PaymentParams) (retrieval.PaidReader, error) {
+				return GetRetrievalAPI(env).RetrievePieceForPayment(req.Context, pieceCID, m.PeerID, m.Address, offset, length, params)
+			})
+		},
+		miners: miners,
+		offset: offset,
+		length: length,
+		ranged: length > 0,
+	}
+	if err := fr.next(nil); err != nil {
+		return err
+	}
+	return re.Emit(fr)
+}
+
+// paymentBudget shares the amount that a retrieval may pay between the miners it is
+// retrieved from in turn.
+type paymentBudget struct {
+	params retrieval.PaymentParams
+	spent  types.AttoFIL
+	// current is the open retrieval, whose payments are not yet counted in spent.
+	current retrieval.PaidReader
+}
+
+func newPaymentBudget(params retrieval.PaymentParams) *paymentBudget {
+	return &paymentBudget{params: params, spent: types.ZeroAttoFIL}
+}
+
+// open opens a paid retrieval with open, offering the miner what the earlier retrievals
+// left of the budget. The earlier retrieval must be closed first, so that its payments are
+// final.
+func (b *paymentBudget) open(open func(retrieval.PaymentParams) (retrieval.PaidReader, error)) (io.ReadCloser, error) {
+	if b.current != nil {
+		b.spent = b.spent.Add(b.current.Paid())
+		b.current = nil
+	}
+
+	params := b.params
+	params.ChannelAmount = b.params.ChannelAmount.Sub(b.spent)
+	if b.spent.IsPositive() && !params.ChannelAmount.IsPositive() {
+		return nil, fmt.Errorf("payment amount (%s) spent", b.params.ChannelAmount)
+	}
+
+	pr, err := open(params)
+	if err != nil {
+		return nil, err
+	}
+	b.current = pr
+	return pr, nil
+}
+
+// failoverReader reads a range of a piece from a retrieval miner. When a retrieval fails
+// part way, it continues from where that retrieval stopped with the next miner.
+type failoverReader struct {
+	open func(m porcelain.RetrievalMiner, offset, length uint64) (io.ReadCloser, error)
+	// miners are the miners yet to be tried.
+	miners  []porcelain.RetrievalMiner
+	current porcelain.RetrievalMiner
+	rc      io.ReadCloser
+
+	// offset and length are the range left to read. A length of zero with ranged unset
+	// means up to the end of the piece.
+	offset uint64
+	length uint64
+	ranged bool
+	read   uint64
+}
+
+// Read implements io.Reader.
+func (fr *failoverReader) Read(p []byte) (int, error) {
+	if fr.ranged && fr.length == 0 {
+		return 0, io.EOF
+	}
+
+	n, err := fr.rc.Read(p)
+	fr.offset += uint64(n)
+	fr.read += uint64(n)
+	if fr.ranged {
+		fr.length -= uint64(n)
+	}
+	if err == nil || err == io.EOF {
+		return n, err
+	}
 
-		mpid, err := GetPorcelainAPI(env).MinerGetPeerID(req.Context, minerAddr)
+	fr.rc.Close() // nolint: errcheck
+	if nextErr := fr.next(errors.Wrapf(err, "retrieval from miner %s interrupted after %d bytes", fr.current.Address, fr.read)); nextErr != nil {
+		return n, nextErr
+	}
+	return n, nil
+}
+
+// next opens a retrieval of the rest of the range from the next miner that serves it,
+// or returns the last error if none does.
+func (fr *failoverReader) next(lastErr error) error {
+	for len(fr.miners) > 0 {
+		m := fr.miners[0]
+		fr.miners = fr.miners[1:]
+
+		rc, err := fr.open(m, fr.offset, fr.length)
+		if err != nil {
+			lastErr = errors.Wrapf(err, "retrieval from miner %s failed", m.Address)
+			continue
+		}
+		fr.current, fr.rc = m, rc
+		return nil
+	}
+	return lastErr
+}
+
+// retrievePiecePreRun adjusts the requested range to skip the bytes that the --output file
+// already holds. Like retrievePiecePostRun it runs in the CLI, so that --output names a file
+// on the caller's machine rather than the daemon's.
+func retrievePiecePreRun(req *cmds.Request, env cmds.Environment) error {
+	path, ok := req.Options["output"].(string)
+	if !ok {
+		return nil
+	}
+	offset, _ := req.Options["offset"].(uint64)
+	length, _ := req.Options["length"].(uint64)
+
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return errors.Wrap(err, "failed to stat output file")
+	}
+
+	existing := uint64(info.Size())
+	if length > 0 {
+		if existing >= length {
+			return fmt.Errorf("%s already holds the requested range", path)
+		}
+		req.Options["length"] = length - existing
+	}
+	req.Options["offset"] = offset + existing
+	return nil
+}
+
+// retrievePiecePostRun appends the retrieved bytes to the --output file, if given.
+var retrievePiecePostRun = cmds.PostRunMap{
+	cmds.CLI: func(res cmds.Response, re cmds.ResponseEmitter) error {
+		path, ok := res.Request().Options["output"].(string)
+		if !ok {
+			return cmds.Copy(re, res)
+		}
+
+		v, err := res.Next()
 		if err != nil {
 			return err
 		}
+		reader, ok := v.(io.Reader)
+		if !ok {
+			return fmt.Errorf("unexpected retrieval response type %T", v)
+		}
 
-		readCloser, err := GetRetrievalAPI(env).RetrievePiece(req.Context, pieceCID, mpid, minerAddr)
+		f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			return errors.Wrap(err, "failed to open output file")
+		}
+		n, err := io.Copy(f, reader)
+		closeErr := f.Close()
+		if err != nil {
+			return errors.Wrapf(err, "retrieval interrupted after writing %d bytes to %s, run the command again to resume", n, path)
+		}
+		if closeErr != nil {
+			return errors.Wrap(closeErr, "failed to close output file")
+		}
+
+		return re.Emit(strings.NewReader(fmt.Sprintf("wrote %d bytes to %s\n", n, path)))
+	},
+}
+
+func parseRetrievalPaymentParams(req *cmds.Request, env cmds.Environment, pay string) (retrieval.PaymentParams, error) {
+	var params retrieval.PaymentParams
+
+	amount, ok := types.NewAttoFILFromFILString(pay)
+	if !ok {
+		return params, ErrInvalidAmount
+	}
+	params.ChannelAmount = amount
+	params.MaxPricePerByte = amount
+
+	if o := req.Options["max-price"]; o != nil {
+		maxPrice, ok := types.NewAttoFILFromFILString(o.(string))
+		if !ok {
+			return params, errors.New("invalid max price (specify FIL as a decimal number)")
+		}
+		params.MaxPricePerByte = maxPrice
+	}
+
+	if o := req.Options["eol"]; o != nil {
+		eol, ok := types.NewBlockHeightFromString(o.(string), 10)
+		if !ok {
+			return params, ErrInvalidBlockHeight
+		}
+		params.ChannelExpiry = eol
+	}
+
+	fromAddr, err := fromAddrOrDefault(req, env)
+	if err != nil {
+		return params, err
+	}
+	params.Payer = fromAddr
+
+	params.GasPrice, params.GasLimit, _, err = parseGasOptions(req, env)
+	if err != nil {
+		return params, err
+	}
+
+	return params, nil
+}
+
+// RetrievalPaymentResult represents a payment made for a paid retrieval
+type RetrievalPaymentResult struct {
+	Miner            address.Address  `json:"minerAddress"`
+	PieceCid         cid.Cid          `json:"pieceCid"`
+	Payer            address.Address  `json:"payer"`
+	Channel          *types.ChannelID `json:"channel"`
+	BytesTransferred uint64           `json:"bytesTransferred"`
+	Amount           types.AttoFIL    `json:"amount"`
+	Voucher          string           `json:"voucher"`
+}
+
+var clientRetrievalPaymentsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List payments for paid retrievals",
+		ShortDescription: `
+Lists the latest voucher of every paid retrieval made by or with this node. A miner
+can redeem the listed voucher with 'paych redeem'.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.BoolOption(clientOnly, "c", "only return retrievals made as a client"),
+		cmdkit.BoolOption(minerOnly, "m", "only return retrievals made as a miner"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		minerAddress, _ := GetPorcelainAPI(env).ConfigGet("mining.minerAddress")
+		records, err := GetRetrievalAPI(env).Payments()
 		if err != nil {
 			return err
 		}
 
-		return re.Emit(readCloser)
+		filterForMiner, _ := req.Options[minerOnly].(bool)
+		filterForClient, _ := req.Options[clientOnly].(bool)
+
+		for _, record := range records {
+			// A client records a channel when it creates it, before anything is paid.
+			if record.Voucher == nil {
+				continue
+			}
+			if filterForMiner && record.Miner != minerAddress {
+				continue
+			}
+			if filterForClient && record.Miner == minerAddress {
+				continue
+			}
+
+			voucher, err := record.Voucher.Encode()
+			if err != nil {
+				return err
+			}
+
+			out := &RetrievalPaymentResult{
+				Miner:            record.Miner,
+				PieceCid:         record.PieceRef,
+				Payer:            record.Payment.Payer,
+				Channel:          record.Payment.Channel,
+				BytesTransferred: record.BytesTransferred,
+				Amount:           record.Voucher.Amount,
+				Voucher:          voucher,
+			}
+			if err = re.Emit(out); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	},
+	Type: RetrievalPaymentResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *RetrievalPaymentResult) error {
+			encoder := json.NewEncoder(w)
+			encoder.SetIndent("", "\t")
+			return encoder.Encode(res)
+		}),
 	},
 }
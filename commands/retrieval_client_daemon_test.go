@@ -32,7 +32,7 @@ func TestSelfDialRetrievalGoodError(t *testing.T) {
 	require.NoError(t, err)
 	details, err := env.GenesisMiner.ID(ctx)
 	require.NoError(t, err)
-	msgCid, err := env.GenesisMiner.MinerUpdatePeerid(ctx, minerAddr, details.ID, fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(300))
+	msgCid, err := env.GenesisMiner.MinerUpdatePeerid(ctx, minerAddr, details.ID, fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(1000))
 	require.NoError(t, err)
 
 	series.CtxMiningOnce(ctx)
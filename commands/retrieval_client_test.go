@@ -0,0 +1,159 @@
+package commands
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ipfs/go-ipfs-cmdkit"
+	"github.com/ipfs/go-ipfs-cmds"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/protocol/retrieval"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+// brokenReader returns its data and then fails.
+type brokenReader struct {
+	data io.Reader
+}
+
+func (br *brokenReader) Read(p []byte) (int, error) {
+	n, err := br.data.Read(p)
+	if err == io.EOF {
+		return n, errors.New("connection lost")
+	}
+	return n, err
+}
+
+func (br *brokenReader) Close() error {
+	return nil
+}
+
+func TestFailoverReader(t *testing.T) {
+	tf.UnitTest(t)
+
+	piece := []byte("0123456789abcdefghij")
+	addrs := address.NewForTestGetter()
+	miners := []porcelain.RetrievalMiner{{Address: addrs()}, {Address: addrs()}, {Address: addrs()}}
+
+	t.Run("resumes with the next miner where a retrieval stopped", func(t *testing.T) {
+		var opened []uint64
+		fr := &failoverReader{
+			open: func(m porcelain.RetrievalMiner, offset, length uint64) (io.ReadCloser, error) {
+				opened = append(opened, offset)
+				switch m.Address {
+				case miners[0].Address:
+					return &brokenReader{bytes.NewReader(piece[offset : offset+5])}, nil
+				case miners[1].Address:
+					return nil, errors.New("miner offline")
+				default:
+					return ioutil.NopCloser(bytes.NewReader(piece[offset:])), nil
+				}
+			},
+			miners: miners,
+		}
+		require.NoError(t, fr.next(nil))
+
+		got, err := ioutil.ReadAll(fr)
+		require.NoError(t, err)
+		assert.Equal(t, piece, got)
+		assert.Equal(t, []uint64{0, 5, 5}, opened)
+	})
+
+	t.Run("fails once no miner is left", func(t *testing.T) {
+		fr := &failoverReader{
+			open: func(m porcelain.RetrievalMiner, offset, length uint64) (io.ReadCloser, error) {
+				return &brokenReader{bytes.NewReader(piece[offset : offset+5])}, nil
+			},
+			miners: miners[:2],
+		}
+		require.NoError(t, fr.next(nil))
+
+		got, err := ioutil.ReadAll(fr)
+		assert.Error(t, err)
+		assert.Equal(t, piece[:10], got)
+	})
+}
+
+// paidReader is a retrieval that has paid a fixed amount.
+type paidReader struct {
+	io.ReadCloser
+	paid types.AttoFIL
+}
+
+func (pr *paidReader) Paid() types.AttoFIL {
+	return pr.paid
+}
+
+func TestPaymentBudget(t *testing.T) {
+	tf.UnitTest(t)
+
+	budget := newPaymentBudget(retrieval.PaymentParams{ChannelAmount: types.NewAttoFILFromFIL(10)})
+
+	var offered []types.AttoFIL
+	openPaying := func(paid uint64) func(retrieval.PaymentParams) (retrieval.PaidReader, error) {
+		return func(params retrieval.PaymentParams) (retrieval.PaidReader, error) {
+			offered = append(offered, params.ChannelAmount)
+			return &paidReader{ioutil.NopCloser(bytes.NewReader(nil)), types.NewAttoFILFromFIL(paid)}, nil
+		}
+	}
+	failing := func(params retrieval.PaymentParams) (retrieval.PaidReader, error) {
+		offered = append(offered, params.ChannelAmount)
+		return nil, errors.New("miner offline")
+	}
+
+	_, err := budget.open(openPaying(4))
+	require.NoError(t, err)
+	_, err = budget.open(failing)
+	assert.Error(t, err)
+	_, err = budget.open(openPaying(6))
+	require.NoError(t, err)
+
+	assert.Equal(t, []types.AttoFIL{types.NewAttoFILFromFIL(10), types.NewAttoFILFromFIL(6), types.NewAttoFILFromFIL(6)}, offered)
+
+	// Nothing is left for another miner.
+	_, err = budget.open(openPaying(0))
+	assert.Error(t, err)
+	assert.Len(t, offered, 3)
+}
+
+func TestRetrievePiecePreRun(t *testing.T) {
+	tf.UnitTest(t)
+
+	dir, err := ioutil.TempDir("", "retrieval")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) // nolint: errcheck
+	path := filepath.Join(dir, "piece")
+	require.NoError(t, ioutil.WriteFile(path, []byte("01234"), 0644))
+
+	newRequest := func(opts cmdkit.OptMap) *cmds.Request {
+		return &cmds.Request{Options: opts}
+	}
+
+	t.Run("skips the bytes the output file holds", func(t *testing.T) {
+		req := newRequest(cmdkit.OptMap{"output": path, "offset": uint64(10), "length": uint64(8)})
+		require.NoError(t, retrievePiecePreRun(req, nil))
+		assert.Equal(t, uint64(15), req.Options["offset"])
+		assert.Equal(t, uint64(3), req.Options["length"])
+	})
+
+	t.Run("fails when the output file holds the whole range", func(t *testing.T) {
+		req := newRequest(cmdkit.OptMap{"output": path, "length": uint64(5)})
+		assert.Error(t, retrievePiecePreRun(req, nil))
+	})
+
+	t.Run("leaves the range of a new output file", func(t *testing.T) {
+		req := newRequest(cmdkit.OptMap{"output": filepath.Join(dir, "new")})
+		require.NoError(t, retrievePiecePreRun(req, nil))
+		assert.Nil(t, req.Options["offset"])
+	})
+}
@@ -55,6 +55,13 @@ func newDefaultAPIConfig() *APIConfig {
 type DatastoreConfig struct {
 	Type string `json:"type"`
 	Path string `json:"path"`
+	// GCKeepDepth is the number of most recent tipsets whose states are kept by garbage
+	// collection.
+	GCKeepDepth uint `json:"gcKeepDepth"`
+	// GCPeriod represents how frequently the daemon collects garbage, or is empty to disable
+	// periodic garbage collection.
+	// Golang duration units are accepted.
+	GCPeriod string `json:"gcPeriod"`
 }
 
 // Validators hold the list of validation functions for each configuration
@@ -68,8 +75,10 @@ var Validators = map[string]func(string, string) error{
 
 func newDefaultDatastoreConfig() *DatastoreConfig {
 	return &DatastoreConfig{
-		Type: "badgerds",
-		Path: "badger",
+		Type:        "badgerds",
+		Path:        "badger",
+		GCKeepDepth: 2000,
+		GCPeriod:    "",
 	}
 }
 
@@ -106,6 +115,7 @@ type MiningConfig struct {
 	MinerAddress            address.Address `json:"minerAddress"`
 	AutoSealIntervalSeconds uint            `json:"autoSealIntervalSeconds"`
 	StoragePrice            types.AttoFIL   `json:"storagePrice"`
+	RetrievalPrice          types.AttoFIL   `json:"retrievalPrice"`
 }
 
 func newDefaultMiningConfig() *MiningConfig {
@@ -113,12 +123,18 @@ func newDefaultMiningConfig() *MiningConfig {
 		MinerAddress:            address.Undef,
 		AutoSealIntervalSeconds: 120,
 		StoragePrice:            types.ZeroAttoFIL,
+		RetrievalPrice:          types.ZeroAttoFIL,
 	}
 }
 
 // WalletConfig holds all configuration options related to the wallet.
 type WalletConfig struct {
 	DefaultAddress address.Address `json:"defaultAddress,omitempty"`
+	// RemoteSignerAPI is where the external signer holding the keys of RemoteAddresses
+	// listens, either "unix:" followed by a socket path or an http URL.
+	RemoteSignerAPI string `json:"remoteSignerAPI,omitempty"`
+	// RemoteAddresses are the addresses whose keys are held by the remote signer.
+	RemoteAddresses []address.Address `json:"remoteAddresses,omitempty"`
 }
 
 func newDefaultWalletConfig() *WalletConfig {
@@ -206,12 +222,20 @@ type MessagePoolConfig struct {
 	MaxPoolSize uint `json:"maxPoolSize"`
 	// MaxNonceGap is the maximum nonce of a message past the last received on chain
 	MaxNonceGap types.Uint64 `json:"maxNonceGap"`
+	// ReplaceByFeePercent is the minimum percentage by which the gas price of a message must
+	// exceed that of a pending message with the same sender and nonce in order to replace it
+	ReplaceByFeePercent uint `json:"replaceByFeePercent"`
+	// MaxSenderMessages is the maximum number of pending messages from a single sender that
+	// the pool accepts from the network. Messages originating from this node are not limited.
+	MaxSenderMessages uint `json:"maxSenderMessages"`
 }
 
 func newDefaultMessagePoolConfig() *MessagePoolConfig {
 	return &MessagePoolConfig{
-		MaxPoolSize: 10000,
-		MaxNonceGap: 100,
+		MaxPoolSize:         10000,
+		MaxNonceGap:         100,
+		ReplaceByFeePercent: 10,
+		MaxSenderMessages:   32,
 	}
 }
 
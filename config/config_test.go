@@ -64,7 +64,9 @@ func TestWriteFile(t *testing.T) {
 	},
 	"datastore": {
 		"type": "badgerds",
-		"path": "badger"
+		"path": "badger",
+		"gcKeepDepth": 2000,
+		"gcPeriod": ""
 	},
 	"heartbeat": {
 		"beatTarget": "",
@@ -75,11 +77,14 @@ func TestWriteFile(t *testing.T) {
 	"mining": {
 		"minerAddress": "empty",
 		"autoSealIntervalSeconds": 120,
-		"storagePrice": "0"
+		"storagePrice": "0",
+		"retrievalPrice": "0"
 	},
 	"mpool": {
 		"maxPoolSize": 10000,
-		"maxNonceGap": "100"
+		"maxNonceGap": "100",
+		"replaceByFeePercent": 10,
+		"maxSenderMessages": 32
 	},
 	"observability": {
 		"metrics": {
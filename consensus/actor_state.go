@@ -11,6 +11,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/state"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
 	"github.com/filecoin-project/go-filecoin/vm"
 )
 
@@ -29,11 +30,13 @@ type ActorStateStore struct {
 	cst *hamt.CborIpldStore
 	// For vm storage.
 	bs bstore.Blockstore
+	// To get the protocol version at which queries run.
+	protocolVersions *version.ProtocolVersionTable
 }
 
 // NewActorStateStore constructs a ActorStateStore.
-func NewActorStateStore(chainReader chainStateChainReader, cst *hamt.CborIpldStore, bs bstore.Blockstore) *ActorStateStore {
-	return &ActorStateStore{chainReader, cst, bs}
+func NewActorStateStore(chainReader chainStateChainReader, cst *hamt.CborIpldStore, bs bstore.Blockstore, pvt *version.ProtocolVersionTable) *ActorStateStore {
+	return &ActorStateStore{chainReader, cst, bs, pvt}
 }
 
 // ActorStateSnapshot permits queries to chain state at a particular tip set.
@@ -59,24 +62,27 @@ func (cs ActorStateStore) Snapshot(ctx context.Context, baseKey types.TipSetKey)
 	return cs.StateTreeSnapshot(st, types.NewBlockHeight(h)), nil
 }
 
-// StateTreeSnapshot returns a snapshot representation of a state tree at an optional block height
+// StateTreeSnapshot returns a snapshot representation of a state tree at block height bh. Queries
+// of the snapshot run at the protocol version in effect at bh.
 func (cs ActorStateStore) StateTreeSnapshot(st state.Tree, bh *types.BlockHeight) ActorStateSnapshot {
-	return newProcessorQueryer(st, vm.NewStorageMap(cs.bs), bh)
+	return newProcessorQueryer(st, vm.NewStorageMap(cs.bs), bh, cs.protocolVersions)
 }
 
 // processorSnapshot queries the chain at a particular tipset
 type processorSnapshot struct {
-	st     state.Tree
-	vms    vm.StorageMap
-	height *types.BlockHeight
+	st               state.Tree
+	vms              vm.StorageMap
+	height           *types.BlockHeight
+	protocolVersions *version.ProtocolVersionTable
 }
 
 // newProcessorQueryer creates an ActorStateSnapshot
-func newProcessorQueryer(st state.Tree, vms vm.StorageMap, height *types.BlockHeight) ActorStateSnapshot {
+func newProcessorQueryer(st state.Tree, vms vm.StorageMap, height *types.BlockHeight, pvt *version.ProtocolVersionTable) ActorStateSnapshot {
 	return &processorSnapshot{
-		st:     st,
-		vms:    vms,
-		height: height,
+		st:               st,
+		vms:              vms,
+		height:           height,
+		protocolVersions: pvt,
 	}
 }
 
@@ -87,7 +93,12 @@ func (q *processorSnapshot) Query(ctx context.Context, optFrom, to address.Addre
 		return nil, errors.Wrap(err, "failed to encode message params")
 	}
 
-	r, ec, err := CallQueryMethod(ctx, q.st, q.vms, to, method, encodedParams, optFrom, q.height)
+	protocolVersion, err := q.protocolVersions.VersionAt(q.height)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get protocol version at height %s", q.height)
+	}
+
+	r, ec, err := CallQueryMethod(ctx, q.st, q.vms, to, method, encodedParams, optFrom, q.height, protocolVersion)
 	if err != nil {
 		return nil, errors.Wrap(err, "query method returned an error")
 	} else if ec != 0 {
@@ -19,6 +19,7 @@ import (
 	th "github.com/filecoin-project/go-filecoin/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
 	"github.com/filecoin-project/go-filecoin/vm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -56,7 +57,7 @@ func TestQuery(t *testing.T) {
 		chainStore, err := chain.Init(context.Background(), r, bs, cst, testGen)
 		require.NoError(t, err)
 
-		chainState := NewActorStateStore(chainStore, cst, bs)
+		chainState := NewActorStateStore(chainStore, cst, bs, version.NewSingleVersionTable(version.Protocol0))
 		snapshot, err := chainState.Snapshot(ctx, chainStore.GetHead())
 		require.NoError(t, err)
 
@@ -98,7 +99,7 @@ func TestQuery(t *testing.T) {
 		chainStore, err := chain.Init(context.Background(), r, bs, cst, testGen)
 		require.NoError(t, err)
 
-		chainState := NewActorStateStore(chainStore, cst, bs)
+		chainState := NewActorStateStore(chainStore, cst, bs, version.NewSingleVersionTable(version.Protocol0))
 		snapshot, err := chainState.Snapshot(ctx, chainStore.GetHead())
 		require.NoError(t, err)
 
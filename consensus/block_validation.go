@@ -5,8 +5,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/clock"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
+	wutil "github.com/filecoin-project/go-filecoin/wallet/util"
 )
 
 // BlockValidator defines an interface used to validate a blocks syntax and
@@ -27,6 +30,7 @@ type SyntaxValidator interface {
 // semantics.
 type BlockSemanticValidator interface {
 	ValidateSemantic(ctx context.Context, child *types.Block, parents *types.TipSet) error
+	ValidateMessagesSemantic(ctx context.Context, blk *types.Block, messages []*types.SignedMessage) error
 }
 
 // BlockSyntaxValidator defines an interface used to validate a blocks
@@ -45,15 +49,19 @@ type MessageSyntaxValidator interface {
 // DefaultBlockValidator implements the BlockValidator interface.
 type DefaultBlockValidator struct {
 	clock.Clock
-	blockTime time.Duration
+	blockTime        time.Duration
+	protocolVersions *version.ProtocolVersionTable
 }
 
 // NewDefaultBlockValidator returns a new DefaultBlockValidator. It uses `blkTime`
-// to validate blocks and uses the DefaultBlockValidationClock.
-func NewDefaultBlockValidator(blkTime time.Duration, c clock.Clock) *DefaultBlockValidator {
+// to validate blocks and uses the DefaultBlockValidationClock. Blocks are
+// validated by the rules of the protocol version that `pvt` puts in effect at
+// their height.
+func NewDefaultBlockValidator(blkTime time.Duration, c clock.Clock, pvt *version.ProtocolVersionTable) *DefaultBlockValidator {
 	return &DefaultBlockValidator{
-		Clock:     c,
-		blockTime: blkTime,
+		Clock:            c,
+		blockTime:        blkTime,
+		protocolVersions: pvt,
 	}
 }
 
@@ -73,6 +81,16 @@ func (dv *DefaultBlockValidator) ValidateSemantic(ctx context.Context, child *ty
 		return fmt.Errorf("block %s has invalid height %d", child.Cid().String(), child.Height)
 	}
 
+	// A block at a height where the network has upgraded past this implementation cannot be
+	// validated by it.
+	protocolVersion, err := dv.protocolVersions.VersionAt(types.NewBlockHeight(uint64(child.Height)))
+	if err != nil {
+		return fmt.Errorf("block %s has no protocol version: %s", child.Cid().String(), err)
+	}
+	if protocolVersion > version.Latest {
+		return fmt.Errorf("block %s requires protocol version %d, newer than supported version %d", child.Cid().String(), protocolVersion, version.Latest)
+	}
+
 	// check that child is appropriately delayed from its parents including
 	// null blocks.
 	// TODO replace check on height when #2222 lands
@@ -83,6 +101,41 @@ func (dv *DefaultBlockValidator) ValidateSemantic(ctx context.Context, child *ty
 	return nil
 }
 
+// ValidateMessagesSemantic validates the signatures of the messages a block carries
+// without their own signatures. Messages from BLS addresses must be unsigned and the
+// block's BLSAggregateSig must aggregate their signatures. Signatures of other messages
+// are checked when the messages are applied.
+func (dv *DefaultBlockValidator) ValidateMessagesSemantic(ctx context.Context, blk *types.Block, messages []*types.SignedMessage) error {
+	var pubKeys, data [][]byte
+	for _, msg := range messages {
+		if msg.From.Protocol() != address.BLS {
+			continue
+		}
+		if len(msg.Signature) != 0 {
+			return fmt.Errorf("block %s carries a BLS message with its own signature", blk.Cid().String())
+		}
+
+		bmsg, err := msg.MeteredMessage.Marshal()
+		if err != nil {
+			return err
+		}
+		pubKeys = append(pubKeys, msg.From.Payload())
+		data = append(data, bmsg)
+	}
+
+	if len(pubKeys) == 0 {
+		if len(blk.BLSAggregateSig) != 0 {
+			return fmt.Errorf("block %s has a BLS aggregate signature but no BLS messages", blk.Cid().String())
+		}
+		return nil
+	}
+
+	if !wutil.VerifyBLSAggregate(pubKeys, data, blk.BLSAggregateSig) {
+		return fmt.Errorf("block %s has an invalid BLS aggregate signature", blk.Cid().String())
+	}
+	return nil
+}
+
 // ValidateSyntax validates a single block is correctly formed.
 // TODO this is an incomplete implementation #3277
 func (dv *DefaultBlockValidator) ValidateSyntax(ctx context.Context, blk *types.Block) error {
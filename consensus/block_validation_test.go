@@ -14,6 +14,7 @@ import (
 	th "github.com/filecoin-project/go-filecoin/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/types"
+	wutil "github.com/filecoin-project/go-filecoin/wallet/util"
 )
 
 func TestBlockValidSemantic(t *testing.T) {
@@ -24,7 +25,7 @@ func TestBlockValidSemantic(t *testing.T) {
 	mclock := th.NewFakeClock(ts)
 	ctx := context.Background()
 
-	validator := consensus.NewDefaultBlockValidator(blockTime, mclock)
+	validator := consensus.NewTestBlockValidator(blockTime, mclock)
 
 	t.Run("reject block with same height as parents", func(t *testing.T) {
 		// passes with valid height
@@ -90,7 +91,7 @@ func TestBlockValidSyntax(t *testing.T) {
 
 	ctx := context.Background()
 
-	validator := consensus.NewDefaultBlockValidator(blockTime, mclock)
+	validator := consensus.NewTestBlockValidator(blockTime, mclock)
 
 	validTs := types.Uint64(ts.Unix())
 	validSt := types.NewCidForTestGetter()()
@@ -134,3 +135,70 @@ func TestBlockValidSyntax(t *testing.T) {
 	require.NoError(t, validator.ValidateSyntax(ctx, blk))
 
 }
+
+func TestBlockValidMessagesSemantic(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	validator := consensus.NewTestBlockValidator(consensus.DefaultBlockTime, th.NewFakeClock(time.Unix(1234567890, 0)))
+
+	blsSigner := types.NewMockSigner(types.MustGenerateBLSKeyInfo(2))
+	secpSigner := types.NewMockSigner(types.MustGenerateKeyInfo(1, 42))
+	to := secpSigner.Addresses[0]
+
+	// signedBLS returns the BLS messages as a block carries them along with their
+	// aggregate signature.
+	signedBLS := func(t *testing.T) ([]*types.SignedMessage, types.Signature) {
+		var unsigned []*types.SignedMessage
+		var sigs [][]byte
+		for _, from := range blsSigner.Addresses {
+			msg := types.NewMessage(from, to, 0, types.ZeroAttoFIL, "", nil)
+			smsg, err := types.NewSignedMessage(*msg, blsSigner, types.NewGasPrice(1), types.NewGasUnits(0))
+			require.NoError(t, err)
+			sigs = append(sigs, smsg.Signature)
+			unsigned = append(unsigned, &types.SignedMessage{MeteredMessage: smsg.MeteredMessage})
+		}
+		agg, err := wutil.AggregateBLS(sigs)
+		require.NoError(t, err)
+		return unsigned, agg
+	}
+
+	secpMsg := types.NewMessage(to, blsSigner.Addresses[0], 0, types.ZeroAttoFIL, "", nil)
+	secpSigned, err := types.NewSignedMessage(*secpMsg, secpSigner, types.NewGasPrice(1), types.NewGasUnits(0))
+	require.NoError(t, err)
+
+	t.Run("accepts block without BLS messages", func(t *testing.T) {
+		blk := &types.Block{}
+		assert.NoError(t, validator.ValidateMessagesSemantic(ctx, blk, []*types.SignedMessage{secpSigned}))
+	})
+
+	t.Run("accepts valid aggregate signature", func(t *testing.T) {
+		msgs, agg := signedBLS(t)
+		blk := &types.Block{BLSAggregateSig: agg}
+		assert.NoError(t, validator.ValidateMessagesSemantic(ctx, blk, append(msgs, secpSigned)))
+	})
+
+	t.Run("rejects aggregate signature over other messages", func(t *testing.T) {
+		msgs, agg := signedBLS(t)
+		blk := &types.Block{BLSAggregateSig: agg}
+		assert.Error(t, validator.ValidateMessagesSemantic(ctx, blk, msgs[:1]))
+	})
+
+	t.Run("rejects missing aggregate signature", func(t *testing.T) {
+		msgs, _ := signedBLS(t)
+		assert.Error(t, validator.ValidateMessagesSemantic(ctx, &types.Block{}, msgs))
+	})
+
+	t.Run("rejects aggregate signature without BLS messages", func(t *testing.T) {
+		_, agg := signedBLS(t)
+		blk := &types.Block{BLSAggregateSig: agg}
+		assert.Error(t, validator.ValidateMessagesSemantic(ctx, blk, []*types.SignedMessage{secpSigned}))
+	})
+
+	t.Run("rejects BLS message carrying its own signature", func(t *testing.T) {
+		msgs, agg := signedBLS(t)
+		msgs[0].Signature = agg
+		blk := &types.Block{BLSAggregateSig: agg}
+		assert.Error(t, validator.ValidateMessagesSemantic(ctx, blk, msgs))
+	})
+}
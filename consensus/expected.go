@@ -168,7 +168,11 @@ func (c *Expected) Weight(ctx context.Context, ts types.TipSet, pSt state.Tree)
 		return uint64(0), err
 	}
 
-	powerTableView := c.createPowerTableView(pSt)
+	h, err := ts.Height()
+	if err != nil {
+		return uint64(0), err
+	}
+	powerTableView := c.createPowerTableView(pSt, types.NewBlockHeight(h))
 
 	// Each block in the tipset adds ECV + ECPrm * miner_power to parent weight.
 	totalBytes, err := powerTableView.Total(ctx)
@@ -269,6 +273,9 @@ func (c *Expected) RunStateTransition(ctx context.Context, ts types.TipSet, tsMe
 		if err := c.BlockValidator.ValidateSemantic(ctx, ts.At(i), &ancestors[0]); err != nil {
 			return cid.Undef, err
 		}
+		if err := c.BlockValidator.ValidateMessagesSemantic(ctx, ts.At(i), tsMessages[i]); err != nil {
+			return cid.Undef, err
+		}
 	}
 
 	priorState, err := c.loadStateTree(ctx, priorStateID)
@@ -313,7 +320,11 @@ func (c *Expected) validateMining(ctx context.Context, st state.Tree, ts types.T
 		return errors.Wrap(err, "failed to read parent height")
 	}
 
-	pwrTableView := c.createPowerTableView(st)
+	height, err := ts.Height()
+	if err != nil {
+		return errors.Wrap(err, "failed to read height")
+	}
+	pwrTableView := c.createPowerTableView(st, types.NewBlockHeight(height))
 
 	for i := 0; i < ts.Len(); i++ {
 		blk := ts.At(i)
@@ -410,8 +421,10 @@ func (c *Expected) runMessages(ctx context.Context, st state.Tree, vms vm.Storag
 	return st, nil
 }
 
-func (c *Expected) createPowerTableView(st state.Tree) PowerTableView {
-	snapshot := c.actorState.StateTreeSnapshot(st, nil)
+// createPowerTableView returns a view of the power table in st, queried at the protocol version
+// in effect at height.
+func (c *Expected) createPowerTableView(st state.Tree, height *types.BlockHeight) PowerTableView {
+	snapshot := c.actorState.StateTreeSnapshot(st, height)
 	return NewPowerTableView(snapshot)
 }
 
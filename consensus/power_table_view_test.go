@@ -15,6 +15,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/state"
 	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -27,7 +28,7 @@ func TestTotal(t *testing.T) {
 	numCommittedSectors := uint64(19)
 	cst, bs, _, st := requireMinerWithNumCommittedSectors(ctx, t, numCommittedSectors)
 
-	as := consensus.NewActorStateStore(nil, cst, bs)
+	as := consensus.NewActorStateStore(nil, cst, bs, version.NewSingleVersionTable(version.Protocol0))
 	snapshot := as.StateTreeSnapshot(st, types.NewBlockHeight(0))
 
 	actual, err := consensus.NewPowerTableView(snapshot).Total(ctx)
@@ -46,7 +47,7 @@ func TestMiner(t *testing.T) {
 	numCommittedSectors := uint64(12)
 	cst, bs, addr, st := requireMinerWithNumCommittedSectors(ctx, t, numCommittedSectors)
 
-	as := consensus.NewActorStateStore(nil, cst, bs)
+	as := consensus.NewActorStateStore(nil, cst, bs, version.NewSingleVersionTable(version.Protocol0))
 	snapshot := as.StateTreeSnapshot(st, types.NewBlockHeight(0))
 
 	actual, err := consensus.NewPowerTableView(snapshot).Miner(ctx, addr)
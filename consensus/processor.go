@@ -15,6 +15,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/metrics/tracing"
 	"github.com/filecoin-project/go-filecoin/state"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
 	"github.com/filecoin-project/go-filecoin/vm"
 	"github.com/filecoin-project/go-filecoin/vm/errors"
 )
@@ -43,6 +44,8 @@ type BlockRewarder interface {
 type ApplicationResult struct {
 	Receipt        *types.MessageReceipt
 	ExecutionError error
+	// Trace records the execution of the message, if the processor traces messages.
+	Trace *vm.ExecutionTrace
 }
 
 // ProcessTipSetResponse records the results of successfully applied messages,
@@ -59,23 +62,39 @@ type ProcessTipSetResponse struct {
 type DefaultProcessor struct {
 	signedMessageValidator SignedMessageValidator
 	blockRewarder          BlockRewarder
+	// protocolVersions determines the protocol version at which messages are applied at each
+	// block height.
+	protocolVersions *version.ProtocolVersionTable
+	// traceMessages makes the processor record an execution trace of each message it applies.
+	traceMessages bool
 }
 
 var _ Processor = (*DefaultProcessor)(nil)
 
-// NewDefaultProcessor creates a default processor from the given state tree and vms.
-func NewDefaultProcessor() *DefaultProcessor {
+// NewDefaultProcessor creates a default processor that applies messages at the protocol
+// versions of pvt.
+func NewDefaultProcessor(pvt *version.ProtocolVersionTable) *DefaultProcessor {
 	return &DefaultProcessor{
-		signedMessageValidator: NewDefaultMessageValidator(),
+		signedMessageValidator: NewBlockMessageValidator(),
 		blockRewarder:          NewDefaultBlockRewarder(),
+		protocolVersions:       pvt,
 	}
 }
 
+// NewTracingProcessor creates a default processor that also records an execution trace of each
+// message it applies, which is useful to debug their execution but costs memory.
+func NewTracingProcessor(pvt *version.ProtocolVersionTable) *DefaultProcessor {
+	p := NewDefaultProcessor(pvt)
+	p.traceMessages = true
+	return p
+}
+
 // NewConfiguredProcessor creates a default processor with custom validation and rewards.
-func NewConfiguredProcessor(validator SignedMessageValidator, rewarder BlockRewarder) *DefaultProcessor {
+func NewConfiguredProcessor(validator SignedMessageValidator, rewarder BlockRewarder, pvt *version.ProtocolVersionTable) *DefaultProcessor {
 	return &DefaultProcessor{
 		signedMessageValidator: validator,
 		blockRewarder:          rewarder,
+		protocolVersions:       pvt,
 	}
 }
 
@@ -116,13 +135,14 @@ func (p *DefaultProcessor) ProcessBlock(ctx context.Context, st state.Tree, vms
 
 	var emptyResults []*ApplicationResult
 
+	bh := types.NewBlockHeight(uint64(blk.Height))
+
 	// find miner's owner address
-	minerOwnerAddr, err := minerOwnerAddress(ctx, st, vms, blk.Miner)
+	minerOwnerAddr, err := p.minerOwnerAddress(ctx, st, vms, blk.Miner, bh)
 	if err != nil {
 		return nil, err
 	}
 
-	bh := types.NewBlockHeight(uint64(blk.Height))
 	res, faultErr := p.ApplyMessagesAndPayRewards(ctx, st, vms, blkMessages, minerOwnerAddr, bh, ancestors)
 	if faultErr != nil {
 		return emptyResults, faultErr
@@ -167,7 +187,7 @@ func (p *DefaultProcessor) ProcessTipSet(ctx context.Context, st state.Tree, vms
 	for i := 0; i < ts.Len(); i++ {
 		blk := ts.At(i)
 		// find miner's owner address
-		minerOwnerAddr, err := minerOwnerAddress(ctx, st, vms, blk.Miner)
+		minerOwnerAddr, err := p.minerOwnerAddress(ctx, st, vms, blk.Miner, bh)
 		if err != nil {
 			return &ProcessTipSetResponse{}, err
 		}
@@ -302,9 +322,32 @@ func (p *DefaultProcessor) ApplyMessage(ctx context.Context, st state.Tree, vms
 	amsw := amTimer.Start(ctx)
 	defer amsw.Stop(ctx)
 
+	protocolVersion, err := p.protocolVersions.VersionAt(bh)
+	if err != nil {
+		return nil, errors.FaultErrorWrapf(err, "could not get protocol version at height %s", bh)
+	}
+
+	var execTrace *vm.ExecutionTrace
+	if p.traceMessages {
+		execTrace = &vm.ExecutionTrace{
+			From:   msg.From,
+			To:     msg.To,
+			Value:  msg.Value,
+			Method: msg.Method,
+			Params: msg.Params,
+		}
+	}
+
 	cachedStateTree := state.NewCachedStateTree(st)
 
-	r, err := p.attemptApplyMessage(ctx, cachedStateTree, vms, msg, bh, gasTracker, ancestors)
+	r, err := p.attemptApplyMessage(ctx, cachedStateTree, vms, msg, bh, protocolVersion, gasTracker, ancestors, execTrace)
+	if err != nil && execTrace != nil && execTrace.Error == "" {
+		// The message failed before the VM recorded its execution.
+		execTrace.Error = err.Error()
+		if r != nil {
+			execTrace.ExitCode = r.ExitCode
+		}
+	}
 	if err == nil {
 		err = cachedStateTree.Commit(ctx)
 		if err != nil {
@@ -350,7 +393,7 @@ func (p *DefaultProcessor) ApplyMessage(ctx context.Context, st state.Tree, vms
 		return nil, errors.FaultErrorWrap(err, "could not set from actor after inc nonce")
 	}
 
-	return &ApplicationResult{Receipt: r, ExecutionError: executionError}, nil
+	return &ApplicationResult{Receipt: r, ExecutionError: executionError, Trace: execTrace}, nil
 }
 
 var (
@@ -372,8 +415,10 @@ var (
 
 // CallQueryMethod calls a method on an actor in the given state tree. It does
 // not make any changes to the state/blockchain and is useful for interrogating
-// actor state. Block height bh is optional; some methods will ignore it.
-func CallQueryMethod(ctx context.Context, st state.Tree, vms vm.StorageMap, to address.Address, method string, params []byte, from address.Address, optBh *types.BlockHeight) ([][]byte, uint8, error) {
+// actor state. Block height bh is optional; some methods will ignore it. The
+// method is called at protocolVersion, which should be the version in effect at
+// the height of the state queried.
+func CallQueryMethod(ctx context.Context, st state.Tree, vms vm.StorageMap, to address.Address, method string, params []byte, from address.Address, optBh *types.BlockHeight, protocolVersion uint64) ([][]byte, uint8, error) {
 	toActor, err := st.GetActor(ctx, to)
 	if err != nil {
 		return nil, 1, errors.ApplyErrorPermanentWrapf(err, "failed to get To actor")
@@ -396,12 +441,13 @@ func CallQueryMethod(ctx context.Context, st state.Tree, vms vm.StorageMap, to a
 	gasTracker.MsgGasLimit = types.BlockGasLimit
 
 	vmCtxParams := vm.NewContextParams{
-		To:          toActor,
-		Message:     msg,
-		State:       cachedSt,
-		StorageMap:  vms,
-		GasTracker:  gasTracker,
-		BlockHeight: optBh,
+		To:              toActor,
+		Message:         msg,
+		State:           cachedSt,
+		StorageMap:      vms,
+		GasTracker:      gasTracker,
+		BlockHeight:     optBh,
+		ProtocolVersion: protocolVersion,
 	}
 
 	vmCtx := vm.NewVMContext(vmCtxParams)
@@ -411,7 +457,7 @@ func CallQueryMethod(ctx context.Context, st state.Tree, vms vm.StorageMap, to a
 
 // PreviewQueryMethod estimates the amount of gas that will be used by a method
 // call. It accepts all the same arguments as CallQueryMethod.
-func PreviewQueryMethod(ctx context.Context, st state.Tree, vms vm.StorageMap, to address.Address, method string, params []byte, from address.Address, optBh *types.BlockHeight) (types.GasUnits, error) {
+func PreviewQueryMethod(ctx context.Context, st state.Tree, vms vm.StorageMap, to address.Address, method string, params []byte, from address.Address, optBh *types.BlockHeight, protocolVersion uint64) (types.GasUnits, error) {
 	toActor, err := st.GetActor(ctx, to)
 	if err != nil {
 		return types.NewGasUnits(0), errors.ApplyErrorPermanentWrapf(err, "failed to get To actor")
@@ -434,12 +480,13 @@ func PreviewQueryMethod(ctx context.Context, st state.Tree, vms vm.StorageMap, t
 	gasTracker.MsgGasLimit = types.BlockGasLimit
 
 	vmCtxParams := vm.NewContextParams{
-		To:          toActor,
-		Message:     msg,
-		State:       cachedSt,
-		StorageMap:  vms,
-		GasTracker:  gasTracker,
-		BlockHeight: optBh,
+		To:              toActor,
+		Message:         msg,
+		State:           cachedSt,
+		StorageMap:      vms,
+		GasTracker:      gasTracker,
+		BlockHeight:     optBh,
+		ProtocolVersion: protocolVersion,
 	}
 	vmCtx := vm.NewVMContext(vmCtxParams)
 	_, _, err = vm.Send(ctx, vmCtx)
@@ -452,7 +499,7 @@ func PreviewQueryMethod(ctx context.Context, st state.Tree, vms vm.StorageMap, t
 // should deal with trying to apply the message to the state tree whereas
 // ApplyMessage should deal with any side effects and how it should be presented
 // to the caller. attemptApplyMessage should only be called from ApplyMessage.
-func (p *DefaultProcessor) attemptApplyMessage(ctx context.Context, st *state.CachedTree, store vm.StorageMap, msg *types.SignedMessage, bh *types.BlockHeight, gasTracker *vm.GasTracker, ancestors []types.TipSet) (*types.MessageReceipt, error) {
+func (p *DefaultProcessor) attemptApplyMessage(ctx context.Context, st *state.CachedTree, store vm.StorageMap, msg *types.SignedMessage, bh *types.BlockHeight, protocolVersion uint64, gasTracker *vm.GasTracker, ancestors []types.TipSet, execTrace *vm.ExecutionTrace) (*types.MessageReceipt, error) {
 	gasTracker.ResetForNewMessage(msg.MeteredMessage)
 	if err := blockGasLimitError(gasTracker); err != nil {
 		return &types.MessageReceipt{
@@ -498,14 +545,16 @@ func (p *DefaultProcessor) attemptApplyMessage(ctx context.Context, st *state.Ca
 	}
 
 	vmCtxParams := vm.NewContextParams{
-		From:        fromActor,
-		To:          toActor,
-		Message:     &msg.Message,
-		State:       st,
-		StorageMap:  store,
-		GasTracker:  gasTracker,
-		BlockHeight: bh,
-		Ancestors:   ancestors,
+		From:            fromActor,
+		To:              toActor,
+		Message:         &msg.Message,
+		State:           st,
+		StorageMap:      store,
+		GasTracker:      gasTracker,
+		BlockHeight:     bh,
+		ProtocolVersion: protocolVersion,
+		Ancestors:       ancestors,
+		Trace:           execTrace,
 	}
 	vmCtx := vm.NewVMContext(vmCtxParams)
 
@@ -658,9 +707,14 @@ func isPermanentError(err error) bool {
 		err == errGasAboveBlockLimit
 }
 
-// minerOwnerAddress finds the address of the owner of the given miner
-func minerOwnerAddress(ctx context.Context, st state.Tree, vms vm.StorageMap, minerAddr address.Address) (address.Address, error) {
-	ret, code, err := CallQueryMethod(ctx, st, vms, minerAddr, "getOwner", []byte{}, address.Undef, types.NewBlockHeight(0))
+// minerOwnerAddress finds the address of the owner of the given miner at the protocol version
+// in effect at height bh.
+func (p *DefaultProcessor) minerOwnerAddress(ctx context.Context, st state.Tree, vms vm.StorageMap, minerAddr address.Address, bh *types.BlockHeight) (address.Address, error) {
+	protocolVersion, err := p.protocolVersions.VersionAt(bh)
+	if err != nil {
+		return address.Undef, errors.FaultErrorWrapf(err, "could not get protocol version at height %s", bh)
+	}
+	ret, code, err := CallQueryMethod(ctx, st, vms, minerAddr, "getOwner", []byte{}, address.Undef, bh, protocolVersion)
 	if err != nil {
 		return address.Undef, errors.FaultErrorWrap(err, "could not get miner owner")
 	}
@@ -2,7 +2,9 @@ package consensus_test
 
 import (
 	"context"
+	"math/big"
 	"testing"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore"
@@ -17,10 +19,12 @@ import (
 	"github.com/filecoin-project/go-filecoin/actor/builtin/account"
 	"github.com/filecoin-project/go-filecoin/address"
 	. "github.com/filecoin-project/go-filecoin/consensus"
+	"github.com/filecoin-project/go-filecoin/exec"
 	"github.com/filecoin-project/go-filecoin/state"
 	th "github.com/filecoin-project/go-filecoin/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
 	"github.com/filecoin-project/go-filecoin/vm"
 	"github.com/filecoin-project/go-filecoin/vm/errors"
 )
@@ -74,7 +78,7 @@ func TestProcessBlockSuccess(t *testing.T) {
 		StateRoot: stCid,
 		Miner:     minerAddr,
 	}
-	results, err := NewDefaultProcessor().ProcessBlock(ctx, st, vms, blk, msgs, nil)
+	results, err := NewTestProcessor().ProcessBlock(ctx, st, vms, blk, msgs, nil)
 	assert.NoError(t, err)
 	assert.Len(t, results, 1)
 
@@ -150,7 +154,7 @@ func TestProcessTipSetSuccess(t *testing.T) {
 	}
 
 	tsMsgs := [][]*types.SignedMessage{msgs1, msgs2}
-	res, err := NewDefaultProcessor().ProcessTipSet(ctx, st, vms, th.RequireNewTipSet(t, blk1, blk2), tsMsgs, nil)
+	res, err := NewTestProcessor().ProcessTipSet(ctx, st, vms, th.RequireNewTipSet(t, blk1, blk2), tsMsgs, nil)
 	assert.NoError(t, err)
 	assert.Len(t, res.Results, 2)
 
@@ -220,7 +224,7 @@ func TestProcessTipsConflicts(t *testing.T) {
 	}
 
 	tsMsgs := [][]*types.SignedMessage{msgs1, msgs2}
-	res, err := NewDefaultProcessor().ProcessTipSet(ctx, st, vms, th.RequireNewTipSet(t, blk1, blk2), tsMsgs, nil)
+	res, err := NewTestProcessor().ProcessTipSet(ctx, st, vms, th.RequireNewTipSet(t, blk1, blk2), tsMsgs, nil)
 	assert.NoError(t, err)
 	assert.Len(t, res.Results, 1)
 
@@ -277,7 +281,7 @@ func TestProcessBlockBadMsgSig(t *testing.T) {
 		StateRoot: stCid,
 		Miner:     minerAddr,
 	}
-	results, err := NewDefaultProcessor().ProcessBlock(ctx, st, vms, blk, msgs, nil)
+	results, err := NewTestProcessor().ProcessBlock(ctx, st, vms, blk, msgs, nil)
 	require.Nil(t, results)
 	assert.EqualError(t, err, "apply message failed: invalid signature by sender over message data")
 }
@@ -311,7 +315,7 @@ func TestProcessBlockReward(t *testing.T) {
 		Height:    20,
 		StateRoot: stCid,
 	}
-	ret, err := NewDefaultProcessor().ProcessBlock(ctx, st, vms, blk, []*types.SignedMessage{}, nil)
+	ret, err := NewTestProcessor().ProcessBlock(ctx, st, vms, blk, []*types.SignedMessage{}, nil)
 	require.NoError(t, err)
 	assert.Nil(t, ret)
 
@@ -344,7 +348,8 @@ func TestProcessBlockVMErrors(t *testing.T) {
 	// Stick one empty actor and one fake actor in the state tree so they can talk.
 	fromAddr, toAddr := mockSigner.Addresses[0], mockSigner.Addresses[1]
 
-	act1, act2 := th.RequireNewEmptyActor(types.NewAttoFILFromFIL(0)), th.RequireNewFakeActor(t, vms, toAddr, fakeActorCodeCid)
+	senderBalance := types.NewAttoFILFromFIL(1)
+	act1, act2 := th.RequireNewEmptyActor(senderBalance), th.RequireNewFakeActor(t, vms, toAddr, fakeActorCodeCid)
 	_, st := th.RequireMakeStateTree(t, cst, map[address.Address]*actor.Actor{
 		address.NetworkAddress: th.RequireNewAccountActor(t, startingNetworkBalance),
 		fromAddr:               act1,
@@ -354,7 +359,7 @@ func TestProcessBlockVMErrors(t *testing.T) {
 	stCid, miner := mustCreateStorageMiner(ctx, t, st, vms, minerAddr, minerOwnerAddr)
 
 	msg := types.NewMessage(fromAddr, toAddr, 0, types.ZeroAttoFIL, "returnRevertError", nil)
-	smsg, err := types.NewSignedMessage(*msg, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
+	smsg, err := types.NewSignedMessage(*msg, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(1000))
 	require.NoError(t, err)
 	msgs := []*types.SignedMessage{smsg}
 	blk := &types.Block{
@@ -365,23 +370,25 @@ func TestProcessBlockVMErrors(t *testing.T) {
 
 	// The "foo" message will cause a vm error and
 	// we're going to check four things...
-	results, err := NewDefaultProcessor().ProcessBlock(ctx, st, vms, blk, msgs, nil)
+	results, err := NewTestProcessor().ProcessBlock(ctx, st, vms, blk, msgs, nil)
 
 	// 1. That a VM error is not a message failure (err).
 	assert.NoError(t, err)
 
 	// 2. That the VM error is faithfully recorded.
-	assert.Len(t, results, 1)
+	require.Len(t, results, 1)
 	assert.Len(t, results[0].Receipt.Return, 0)
 	assert.Contains(t, results[0].ExecutionError.Error(), "boom")
 
-	// 3 & 4. That on VM error the state is rolled back and nonce is inc'd.
-	expectedAct1, expectedAct2 := th.RequireNewEmptyActor(types.NewAttoFILFromFIL(0)), th.RequireNewFakeActor(t, vms, toAddr, fakeActorCodeCid)
+	// 3 & 4. That on VM error the state is rolled back, except for the gas paid, and nonce is inc'd.
+	gasPaid := results[0].Receipt.GasAttoFIL
+	assert.True(t, gasPaid.IsPositive())
+	expectedAct1, expectedAct2 := th.RequireNewEmptyActor(senderBalance.Sub(gasPaid)), th.RequireNewFakeActor(t, vms, toAddr, fakeActorCodeCid)
 	expectedAct1.IncNonce()
 	blockRewardAmount := NewDefaultBlockRewarder().BlockRewardAmount()
 	expectedStCid, _ := th.RequireMakeStateTree(t, cst, map[address.Address]*actor.Actor{
 		address.NetworkAddress: th.RequireNewAccountActor(t, startingNetworkBalance.Sub(blockRewardAmount)),
-		minerOwnerAddr:         th.RequireNewEmptyActor(blockRewardAmount),
+		minerOwnerAddr:         th.RequireNewEmptyActor(blockRewardAmount.Add(gasPaid)),
 		minerAddr:              miner,
 		fromAddr:               expectedAct1,
 		toAddr:                 expectedAct2,
@@ -465,7 +472,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 		require.NoError(t, err)
 
-		_, err = NewDefaultProcessor().ApplyMessage(ctx, st, th.VMStorage(), smsg, addr2, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
+		_, err = NewTestProcessor().ApplyMessage(ctx, st, th.VMStorage(), smsg, addr2, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
 		assert.Error(t, err)
 		assert.Equal(t, "nonce too high", err.(*errors.ApplyErrorTemporary).Cause().Error())
 	})
@@ -490,7 +497,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 		require.NoError(t, err)
 
-		_, err = NewDefaultProcessor().ApplyMessage(ctx, st, th.VMStorage(), smsg, addr2, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
+		_, err = NewTestProcessor().ApplyMessage(ctx, st, th.VMStorage(), smsg, addr2, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
 		assert.Error(t, err)
 		assert.Equal(t, "nonce too low", err.(*errors.ApplyErrorPermanent).Cause().Error())
 	})
@@ -502,7 +509,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 		require.NoError(t, err)
 
 		// the maximum gas charge (10*50 = 500) is greater than the sender balance minus the message value (1000-550 = 450)
-		_, err = NewDefaultProcessor().ApplyMessage(context.Background(), st, th.VMStorage(), smsg, addr2, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
+		_, err = NewTestProcessor().ApplyMessage(context.Background(), st, th.VMStorage(), smsg, addr2, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
 		require.Error(t, err)
 		assert.Equal(t, "balance insufficient to cover transfer+gas", err.(*errors.ApplyErrorPermanent).Cause().Error())
 	})
@@ -520,7 +527,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewAttoFILFromFIL(10), types.NewGasUnits(50))
 		require.NoError(t, err)
 
-		_, err = NewDefaultProcessor().ApplyMessage(context.Background(), st, th.VMStorage(), smsg, addr2, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
+		_, err = NewTestProcessor().ApplyMessage(context.Background(), st, th.VMStorage(), smsg, addr2, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
 		require.Error(t, err)
 		assert.Equal(t, "message from non-account actor", err.(*errors.ApplyErrorPermanent).Cause().Error())
 	})
@@ -539,7 +546,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewAttoFILFromFIL(10), types.NewGasUnits(50))
 		require.NoError(t, err)
 
-		_, err = NewDefaultProcessor().ApplyMessage(context.Background(), st, th.VMStorage(), smsg, addr2,
+		_, err = NewTestProcessor().ApplyMessage(context.Background(), st, th.VMStorage(), smsg, addr2,
 			types.NewBlockHeight(0), vm.NewGasTracker(), nil)
 		require.Error(t, err)
 		assert.Equal(t, "from (sender) account not found", err.(*errors.ApplyErrorTemporary).Cause().Error())
@@ -568,7 +575,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 		require.NoError(t, err)
 
-		_, err = NewDefaultProcessor().ApplyMessage(ctx, st, th.VMStorage(), smsg, addr2, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
+		_, err = NewTestProcessor().ApplyMessage(ctx, st, th.VMStorage(), smsg, addr2, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
 		assert.Error(t, err)
 		assert.Contains(t, "negative value", err.(*errors.ApplyErrorPermanent).Cause().Error())
 	})
@@ -580,7 +587,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 		require.NoError(t, err)
 
 		// the maximum gas charge (10*50 = 500) is greater than the sender balance minus the message value (1000-550 = 450)
-		_, err = NewDefaultProcessor().ApplyMessage(context.Background(), st, th.VMStorage(), smsg, addr2, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
+		_, err = NewTestProcessor().ApplyMessage(context.Background(), st, th.VMStorage(), smsg, addr2, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
 		require.Error(t, err)
 		assert.Equal(t, "cannot send to self", err.(*errors.ApplyErrorPermanent).Cause().Error())
 	})
@@ -592,7 +599,7 @@ func TestApplyMessagesValidation(t *testing.T) {
 		require.NoError(t, err)
 
 		// the maximum gas charge (10*50 = 500) is greater than the sender balance minus the message value (1000-550 = 450)
-		_, err = NewDefaultProcessor().ApplyMessage(context.Background(), st, th.VMStorage(), smsg, address.Undef, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
+		_, err = NewTestProcessor().ApplyMessage(context.Background(), st, th.VMStorage(), smsg, address.Undef, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
 		require.Error(t, err)
 		assert.Equal(t, "balance insufficient to cover transfer+gas", err.(*errors.ApplyErrorPermanent).Cause().Error())
 	})
@@ -716,14 +723,14 @@ func TestSendToNonexistentAddressThenSpendFromIt(t *testing.T) {
 	msg := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(500), "", []byte{})
 	smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
-	_, err = NewDefaultProcessor().ApplyMessage(ctx, st, th.VMStorage(), smsg, addr4, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
+	_, err = NewTestProcessor().ApplyMessage(ctx, st, th.VMStorage(), smsg, addr4, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
 	require.NoError(t, err)
 
 	// send 250 along from addr2 to addr3
 	msg = types.NewMessage(addr2, addr3, 0, types.NewAttoFILFromFIL(300), "", []byte{})
 	smsg, err = types.NewSignedMessage(*msg, mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
 	require.NoError(t, err)
-	_, err = NewDefaultProcessor().ApplyMessage(ctx, st, th.VMStorage(), smsg, addr4, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
+	_, err = NewTestProcessor().ApplyMessage(ctx, st, th.VMStorage(), smsg, addr4, types.NewBlockHeight(0), vm.NewGasTracker(), nil)
 	require.NoError(t, err)
 
 	// get all 3 actors
@@ -774,7 +781,7 @@ func TestApplyQueryMessageWillNotAlterState(t *testing.T) {
 	args1, err := abi.ToEncodedValues(addr2)
 	assert.NoError(t, err)
 
-	_, exitCode, err := CallQueryMethod(ctx, st, vms, addr1, "nestedBalance", args1, addr0, types.NewBlockHeight(0))
+	_, exitCode, err := CallQueryMethod(ctx, st, vms, addr1, "nestedBalance", args1, addr0, types.NewBlockHeight(0), version.Protocol0)
 	require.Equal(t, uint8(0), exitCode)
 	require.NoError(t, err)
 
@@ -897,13 +904,13 @@ func TestApplyMessageChargesGas(t *testing.T) {
 		minerActor, err := st.GetActor(ctx, minerAddr)
 		require.NoError(t, err)
 
-		// miner receives (3 FIL/gas * 100 gas * 2 messages)
-		assert.Equal(t, types.NewAttoFILFromFIL(1600), minerActor.Balance)
+		// miner receives (3 FIL/gas * (100 gas * 2 messages + 10 gas for the send))
+		assert.Equal(t, types.NewAttoFILFromFIL(1630), minerActor.Balance)
 
 		accountActor, err := st.GetActor(ctx, addr0)
 		require.NoError(t, err)
 		// sender's resulting balance of FIL
-		assert.Equal(t, types.NewAttoFILFromFIL(1400), accountActor.Balance)
+		assert.Equal(t, types.NewAttoFILFromFIL(1370), accountActor.Balance)
 	})
 
 	t.Run("ApplyMessage when it sends another message with insufficient gas fails with correct message", func(t *testing.T) {
@@ -939,6 +946,114 @@ func TestApplyMessageChargesGas(t *testing.T) {
 		assert.Equal(t, types.NewAttoFILFromFIL(850), accountActor.Balance)
 
 	})
+
+	t.Run("ApplyMessage reverts rather than faults a message whose method fails after running out of gas", func(t *testing.T) {
+		// provide a gas limit that is sufficient for the outer method's call, but not for its send, after which
+		// the method returns a fault error.
+		addresses, st, mockSigner := setupActorsForGasTest(t, vms, fakeActorCodeCid, 1000)
+		addr0 := addresses[0]
+		addr1 := addresses[1]
+		addr2 := addresses[2]
+		minerAddr := addresses[3]
+
+		params, err := abi.ToEncodedValues(addr1, addr2)
+		require.NoError(t, err)
+
+		msg := types.NewMessage(addr0, addr1, 0, types.ZeroAttoFIL, "attemptMultiSpend1", params)
+
+		gasPrice := types.NewAttoFILFromFIL(uint64(3))
+		gasLimit := types.NewGasUnits(105)
+
+		appResult, err := th.ApplyTestMessageWithGas(st, th.VMStorage(), msg, types.NewBlockHeight(0), mockSigner,
+			gasPrice, gasLimit, minerAddr)
+		require.NoError(t, err)
+		require.Error(t, appResult.ExecutionError)
+		assert.False(t, errors.IsFault(appResult.ExecutionError))
+		assert.Equal(t, uint8(exec.ErrInsufficientGas), appResult.Receipt.ExitCode)
+
+		minerActor, err := st.GetActor(ctx, minerAddr)
+		require.NoError(t, err)
+
+		// miner receives (3 FIL/gasUnit * 105 gasUnits) FIL from the sender
+		assert.Equal(t, types.NewAttoFILFromFIL(1315), minerActor.Balance)
+	})
+}
+
+func TestTracingProcessorRecordsExecution(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	vms := th.VMStorage()
+
+	// Install the fake actor so we can execute it.
+	fakeActorCodeCid := types.CidFromString(t, "tracefakeactor")
+	builtin.Actors[fakeActorCodeCid] = &actor.FakeActor{}
+	defer delete(builtin.Actors, fakeActorCodeCid)
+
+	apply := func(t *testing.T, processor *DefaultProcessor) ([]address.Address, []byte, *ApplicationResult) {
+		addresses, st, mockSigner := setupActorsForGasTest(t, vms, fakeActorCodeCid, 2000)
+		params, err := abi.ToEncodedValues(addresses[2])
+		require.NoError(t, err)
+		msg := types.NewMessage(addresses[0], addresses[1], 0, types.ZeroAttoFIL, "runsAnotherMessage", params)
+		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewGasPrice(1), types.NewGasUnits(600))
+		require.NoError(t, err)
+
+		res, err := processor.ApplyMessagesAndPayRewards(ctx, st, vms, []*types.SignedMessage{smsg}, addresses[3], types.NewBlockHeight(0), nil)
+		require.NoError(t, err)
+		require.Len(t, res.Results, 1)
+		return addresses, params, res.Results[0]
+	}
+
+	t.Run("records the message and the messages it sends", func(t *testing.T) {
+		addresses, params, res := apply(t, NewTracingProcessor(version.NewSingleVersionTable(version.Protocol0)))
+		require.NoError(t, res.ExecutionError)
+
+		execTrace := res.Trace
+		require.NotNil(t, execTrace)
+		assert.Equal(t, addresses[0], execTrace.From)
+		assert.Equal(t, addresses[1], execTrace.To)
+		assert.Equal(t, "runsAnotherMessage", execTrace.Method)
+		assert.Equal(t, params, execTrace.Params)
+		assert.Equal(t, res.Receipt.GasAttoFIL, types.NewGasPrice(1).MulBigInt(big.NewInt(int64(execTrace.GasUsed))))
+		assert.Equal(t, uint8(0), execTrace.ExitCode)
+		assert.Empty(t, execTrace.Error)
+
+		require.Len(t, execTrace.Subcalls, 1)
+		sub := execTrace.Subcalls[0]
+		assert.Equal(t, addresses[1], sub.From)
+		assert.Equal(t, addresses[2], sub.To)
+		assert.Equal(t, "hasReturnValue", sub.Method)
+		assert.True(t, sub.GasUsed > 0 && sub.GasUsed < execTrace.GasUsed)
+		assert.Empty(t, sub.Subcalls)
+	})
+
+	t.Run("records a message that fails before it is executed", func(t *testing.T) {
+		addresses, st, mockSigner := setupActorsForGasTest(t, vms, fakeActorCodeCid, 2000)
+		// A message with no gas price fails validation, which precedes executing it.
+		msg := types.NewMessage(addresses[0], addresses[1], 0, types.ZeroAttoFIL, "hasReturnValue", nil)
+		smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewGasPrice(0), types.NewGasUnits(600))
+		require.NoError(t, err)
+
+		res, err := NewTracingProcessor(version.NewSingleVersionTable(version.Protocol0)).ApplyMessagesAndPayRewards(ctx, st, vms, []*types.SignedMessage{smsg}, addresses[3], types.NewBlockHeight(0), nil)
+		require.NoError(t, err)
+		require.Len(t, res.Results, 1)
+		require.Error(t, res.Results[0].ExecutionError)
+
+		execTrace := res.Results[0].Trace
+		require.NotNil(t, execTrace)
+		assert.Equal(t, addresses[0], execTrace.From)
+		assert.Equal(t, addresses[1], execTrace.To)
+		assert.Equal(t, "hasReturnValue", execTrace.Method)
+		assert.Equal(t, res.Results[0].Receipt.ExitCode, execTrace.ExitCode)
+		assert.Equal(t, res.Results[0].ExecutionError.Error(), execTrace.Error)
+		assert.Empty(t, execTrace.Subcalls)
+	})
+
+	t.Run("default processor records no trace", func(t *testing.T) {
+		_, _, res := apply(t, NewTestProcessor())
+		require.NoError(t, res.ExecutionError)
+		assert.Nil(t, res.Trace)
+	})
 }
 
 func TestBlockGasLimitBehavior(t *testing.T) {
@@ -1081,3 +1196,203 @@ func mustCreateStorageMiner(ctx context.Context, t *testing.T, st state.Tree, vm
 	require.NoError(t, err)
 	return stCid, miner
 }
+
+func TestProcessorActivatesProtocolUpgrades(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	cst := hamt.NewCborStore()
+	vms := th.VMStorage()
+
+	// Install an actor with a method that the upgrade adds.
+	upgradeActorCodeCid := types.CidFromString(t, "upgradeactor")
+	builtin.Actors[upgradeActorCodeCid] = &upgradeActor{}
+	defer func() {
+		delete(builtin.Actors, upgradeActorCodeCid)
+	}()
+
+	signer, _ := types.NewMockSignersAndKeyInfo(1)
+	sender := signer.Addresses[0]
+	upgradeAddr := address.NewForTestGetter()()
+	_, st := requireMakeStateTree(t, cst, map[address.Address]*actor.Actor{
+		sender:      th.RequireNewAccountActor(t, types.NewAttoFILFromFIL(1000)),
+		upgradeAddr: actor.NewActor(upgradeActorCodeCid, types.ZeroAttoFIL),
+	})
+
+	// Protocol version 1 activates at height 3 of the test network.
+	pvt, err := version.NewProtocolVersionTableBuilder(version.TEST).
+		Add(version.TEST, version.Protocol0, types.NewBlockHeight(0)).
+		Add(version.TEST, 1, types.NewBlockHeight(3)).
+		Build()
+	require.NoError(t, err)
+	processor := NewConfiguredProcessor(&th.FakeSignedMessageValidator{}, &th.FakeBlockRewarder{}, pvt)
+
+	apply := func(height uint64, method string) *ApplicationResult {
+		msg := types.NewMessage(sender, upgradeAddr, 0, types.ZeroAttoFIL, method, nil)
+		smsg, err := types.NewSignedMessage(*msg, &signer, types.NewGasPrice(1), types.NewGasUnits(300))
+		require.NoError(t, err)
+		res, err := processor.ApplyMessagesAndPayRewards(ctx, st, vms, []*types.SignedMessage{smsg}, address.Undef, types.NewBlockHeight(height), nil)
+		require.NoError(t, err)
+		require.Len(t, res.Results, 1)
+		return res.Results[0]
+	}
+
+	for height := uint64(1); height <= 5; height++ {
+		expectedVersion := uint64(version.Protocol0)
+		if height >= 3 {
+			expectedVersion = 1
+		}
+
+		res := apply(height, "protocolVersion")
+		require.NoError(t, res.ExecutionError)
+		v, err := abi.Deserialize(res.Receipt.Return[0], abi.Integer)
+		require.NoError(t, err)
+		assert.Equal(t, expectedVersion, v.Val.(*big.Int).Uint64(), "protocol version at height %d", height)
+
+		res = apply(height, "upgraded")
+		if expectedVersion == 1 {
+			assert.NoError(t, res.ExecutionError, "upgraded method at height %d", height)
+			assert.Equal(t, uint8(0), res.Receipt.ExitCode)
+		} else {
+			assert.Equal(t, errors.Errors[errors.ErrMissingExport], res.ExecutionError, "upgraded method at height %d", height)
+		}
+	}
+}
+
+func TestProtocolUpgradeMidChain(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	cst := hamt.NewCborStore()
+	bs := blockstore.NewBlockstore(datastore.NewMapDatastore())
+	vms := vm.NewStorageMap(bs)
+
+	upgradeActorCodeCid := types.CidFromString(t, "upgradeactor")
+	builtin.Actors[upgradeActorCodeCid] = &upgradeActor{}
+	defer func() {
+		delete(builtin.Actors, upgradeActorCodeCid)
+	}()
+
+	signer, _ := types.NewMockSignersAndKeyInfo(1)
+	sender := signer.Addresses[0]
+	newAddress := address.NewForTestGetter()
+	upgradeAddr, minerAddr := newAddress(), newAddress()
+	_, st := requireMakeStateTree(t, cst, map[address.Address]*actor.Actor{
+		address.NetworkAddress: th.RequireNewAccountActor(t, types.NewAttoFILFromFIL(1000000)),
+		sender:                 th.RequireNewAccountActor(t, types.NewAttoFILFromFIL(1000)),
+		upgradeAddr:            actor.NewActor(upgradeActorCodeCid, types.ZeroAttoFIL),
+	})
+	minerOwner, err := address.NewActorAddress([]byte("mo"))
+	require.NoError(t, err)
+	stCid, _ := mustCreateStorageMiner(ctx, t, st, vms, minerAddr, minerOwner)
+
+	// Protocol version 1 activates at height 3 of the test network.
+	pvt, err := version.NewProtocolVersionTableBuilder(version.TEST).
+		Add(version.TEST, version.Protocol0, types.NewBlockHeight(0)).
+		Add(version.TEST, 1, types.NewBlockHeight(3)).
+		Build()
+	require.NoError(t, err)
+	processor := NewConfiguredProcessor(&th.FakeSignedMessageValidator{}, NewDefaultBlockRewarder(), pvt)
+	actorState := NewActorStateStore(nil, cst, bs, pvt)
+
+	blockTime := DefaultBlockTime
+	genesisTime := time.Unix(1234567890, 0)
+	validator := NewDefaultBlockValidator(blockTime, th.NewFakeClock(genesisTime), pvt)
+
+	parent := th.RequireNewTipSet(t, &types.Block{Timestamp: types.Uint64(genesisTime.Unix()), StateRoot: stCid})
+	nonce := uint64(0)
+	for height := uint64(1); height <= 5; height++ {
+		upgraded := height >= 3
+		blk := &types.Block{
+			Parents:   parent.Key(),
+			Height:    types.Uint64(height),
+			Timestamp: types.Uint64(genesisTime.Add(time.Duration(height) * blockTime).Unix()),
+			StateRoot: stCid,
+			Miner:     minerAddr,
+			Tickets:   []types.Ticket{{VRFProof: []byte{byte(height)}}},
+		}
+
+		// Blocks past the upgrade are rejected by an implementation that does not support it.
+		err := validator.ValidateSemantic(ctx, blk, &parent)
+		if upgraded {
+			require.Error(t, err, "validation at height %d", height)
+			assert.Contains(t, err.Error(), "requires protocol version 1")
+		} else {
+			assert.NoError(t, err, "validation at height %d", height)
+		}
+
+		var msgs []*types.SignedMessage
+		for _, method := range []string{"protocolVersion", "upgraded"} {
+			msg := types.NewMessage(sender, upgradeAddr, nonce, types.ZeroAttoFIL, method, nil)
+			smsg, err := types.NewSignedMessage(*msg, &signer, types.NewGasPrice(1), types.NewGasUnits(300))
+			require.NoError(t, err)
+			msgs = append(msgs, smsg)
+			nonce++
+		}
+		res, err := processor.ProcessTipSet(ctx, st, vms, th.RequireNewTipSet(t, blk), [][]*types.SignedMessage{msgs}, nil)
+		require.NoError(t, err)
+		require.Len(t, res.Results, 2)
+
+		expectedVersion := uint64(version.Protocol0)
+		if upgraded {
+			expectedVersion = 1
+		}
+		require.NoError(t, res.Results[0].ExecutionError)
+		v, err := abi.Deserialize(res.Results[0].Receipt.Return[0], abi.Integer)
+		require.NoError(t, err)
+		assert.Equal(t, expectedVersion, v.Val.(*big.Int).Uint64(), "applied protocol version at height %d", height)
+		if upgraded {
+			assert.NoError(t, res.Results[1].ExecutionError, "upgraded method at height %d", height)
+		} else {
+			assert.Equal(t, errors.Errors[errors.ErrMissingExport], res.Results[1].ExecutionError, "upgraded method at height %d", height)
+		}
+
+		// Queries of the state at this height run at the version in effect at it.
+		snapshot := actorState.StateTreeSnapshot(st, types.NewBlockHeight(height))
+		ret, err := snapshot.Query(ctx, sender, upgradeAddr, "protocolVersion")
+		require.NoError(t, err)
+		v, err = abi.Deserialize(ret[0], abi.Integer)
+		require.NoError(t, err)
+		assert.Equal(t, expectedVersion, v.Val.(*big.Int).Uint64(), "queried protocol version at height %d", height)
+		_, err = snapshot.Query(ctx, sender, upgradeAddr, "upgraded")
+		assert.Equal(t, upgraded, err == nil, "query of upgraded method at height %d", height)
+
+		stCid, err = st.Flush(ctx)
+		require.NoError(t, err)
+		blk.StateRoot = stCid
+		parent = th.RequireNewTipSet(t, blk)
+	}
+}
+
+// upgradeActor is an actor whose upgraded method is added by protocol version 1.
+type upgradeActor struct{}
+
+var _ exec.ExecutableActor = (*upgradeActor)(nil)
+
+var upgradeActorExports = exec.Exports{
+	"protocolVersion": &exec.FunctionSignature{
+		Params: nil,
+		Return: []abi.Type{abi.Integer},
+	},
+	"upgraded": &exec.FunctionSignature{
+		Params: nil,
+		Return: nil,
+		Since:  1,
+	},
+}
+
+func (a *upgradeActor) Exports() exec.Exports {
+	return upgradeActorExports
+}
+
+func (a *upgradeActor) InitializeState(storage exec.Storage, initializerData interface{}) error {
+	return nil
+}
+
+func (a *upgradeActor) ProtocolVersion(ctx exec.VMContext) (*big.Int, uint8, error) {
+	return new(big.Int).SetUint64(ctx.ProtocolVersion()), 0, nil
+}
+
+func (a *upgradeActor) Upgraded(ctx exec.VMContext) (uint8, error) {
+	return 0, nil
+}
@@ -5,13 +5,16 @@ import (
 	"errors"
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/go-filecoin/actor"
 	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/clock"
 	"github.com/filecoin-project/go-filecoin/state"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
 )
 
 // RequireNewTipSet instantiates and returns a new tipset of the given blocks
@@ -22,6 +25,18 @@ func RequireNewTipSet(require *require.Assertions, blks ...*types.Block) types.T
 	return ts
 }
 
+// NewTestProcessor returns a default processor that applies messages at the first protocol version
+// at every height.
+func NewTestProcessor() *DefaultProcessor {
+	return NewDefaultProcessor(version.NewSingleVersionTable(version.Protocol0))
+}
+
+// NewTestBlockValidator returns a default block validator that validates blocks at the first
+// protocol version at every height.
+func NewTestBlockValidator(blockTime time.Duration, c clock.Clock) *DefaultBlockValidator {
+	return NewDefaultBlockValidator(blockTime, c, version.NewSingleVersionTable(version.Protocol0))
+}
+
 // FakeActorStateStore provides a snapshot that responds to power table view queries with the given parameters
 type FakeActorStateStore struct {
 	minerPower    *types.BytesAmount
@@ -116,6 +131,7 @@ func NewFakeProcessor() *DefaultProcessor {
 	return &DefaultProcessor{
 		signedMessageValidator: &FakeSignedMessageValidator{},
 		blockRewarder:          &FakeBlockRewarder{},
+		protocolVersions:       version.NewSingleVersionTable(version.Protocol0),
 	}
 }
 
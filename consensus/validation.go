@@ -36,7 +36,9 @@ type SignedMessageValidator interface {
 }
 
 type defaultMessageValidator struct {
-	allowHighNonce bool
+	allowHighNonce   bool
+	allowUnsignedBLS bool
+	skipSignature    bool
 }
 
 // NewDefaultMessageValidator creates a new default validator.
@@ -53,10 +55,19 @@ func NewOutboundMessageValidator() SignedMessageValidator {
 	return &defaultMessageValidator{allowHighNonce: true}
 }
 
+// NewBlockMessageValidator creates a new default validator for messages loaded from blocks.
+// Blocks carry BLS-signed messages without their signatures, so this validator matches the
+// default behaviour but accepts unsigned messages from BLS addresses. Their signatures are
+// checked in aggregate by the BlockValidator.
+func NewBlockMessageValidator() SignedMessageValidator {
+	return &defaultMessageValidator{allowUnsignedBLS: true}
+}
+
 var _ SignedMessageValidator = (*defaultMessageValidator)(nil)
 
 func (v *defaultMessageValidator) Validate(ctx context.Context, msg *types.SignedMessage, fromActor *actor.Actor) error {
-	if !msg.VerifySignature() {
+	unsignedBLS := msg.From.Protocol() == address.BLS && len(msg.Signature) == 0
+	if !v.skipSignature && !(v.allowUnsignedBLS && unsignedBLS) && !msg.VerifySignature() {
 		return errInvalidSignature
 	}
 
@@ -126,20 +137,34 @@ type IngestionValidator struct {
 	api       ingestionValidatorAPI
 	cfg       *config.MessagePoolConfig
 	validator defaultMessageValidator
+	// revertedValidator validates messages from reverted blocks, whose signatures were
+	// checked when the blocks were validated.
+	revertedValidator defaultMessageValidator
 }
 
 // NewIngestionValidator creates a new validator with an api
 func NewIngestionValidator(api ingestionValidatorAPI, cfg *config.MessagePoolConfig) *IngestionValidator {
 	return &IngestionValidator{
-		api:       api,
-		cfg:       cfg,
-		validator: defaultMessageValidator{allowHighNonce: true},
+		api:               api,
+		cfg:               cfg,
+		validator:         defaultMessageValidator{allowHighNonce: true},
+		revertedValidator: defaultMessageValidator{allowHighNonce: true, skipSignature: true},
 	}
 }
 
 // Validate validates the signed message.
 // Errors probably mean the validation failed, but possibly indicate a failure to retrieve state
 func (v *IngestionValidator) Validate(ctx context.Context, msg *types.SignedMessage) error {
+	return v.validate(ctx, msg, &v.validator)
+}
+
+// ValidateReverted validates a message from a reverted block like Validate, but does not check
+// its signature, which may have been aggregated into the block's BLS signature.
+func (v *IngestionValidator) ValidateReverted(ctx context.Context, msg *types.SignedMessage) error {
+	return v.validate(ctx, msg, &v.revertedValidator)
+}
+
+func (v *IngestionValidator) validate(ctx context.Context, msg *types.SignedMessage, validator *defaultMessageValidator) error {
 	// retrieve from actor
 	fromActor, err := v.api.GetActor(ctx, msg.From)
 	if err != nil {
@@ -155,5 +180,5 @@ func (v *IngestionValidator) Validate(ctx context.Context, msg *types.SignedMess
 		return errors.NewRevertErrorf("message nonce (%d) is too much greater than actor nonce (%d)", msg.Nonce, fromActor.Nonce)
 	}
 
-	return v.validator.Validate(ctx, msg, fromActor)
+	return validator.Validate(ctx, msg, fromActor)
 }
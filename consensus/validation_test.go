@@ -43,6 +43,22 @@ func TestMessageValidator(t *testing.T) {
 		assert.NoError(t, validator.Validate(ctx, msg, actor))
 	})
 
+	t.Run("valid BLS signed message", func(t *testing.T) {
+		blsSigner := types.NewMockSigner(types.MustGenerateBLSKeyInfo(1))
+		carol := blsSigner.Addresses[0]
+		require.Equal(t, address.BLS, carol.Protocol())
+
+		val, ok := types.NewAttoFILFromString("5", 10)
+		require.True(t, ok)
+		msg := types.NewMessage(carol, bob, 100, val, "method", []byte("params"))
+		smsg, err := types.NewSignedMessage(*msg, blsSigner, types.NewGasPrice(1), types.NewGasUnits(0))
+		require.NoError(t, err)
+		assert.NoError(t, validator.Validate(ctx, smsg, actor))
+
+		smsg.Signature[0] ^= 0xFF
+		assert.Errorf(t, validator.Validate(ctx, smsg, actor), "signature")
+	})
+
 	t.Run("invalid signature fails", func(t *testing.T) {
 		msg := newMessage(t, alice, bob, 100, 5, 1, 0)
 		msg.Signature = []byte{}
@@ -109,6 +125,41 @@ func TestOutboundMessageValidator(t *testing.T) {
 	})
 }
 
+func TestBlockMessageValidator(t *testing.T) {
+	tf.UnitTest(t)
+
+	bob := addresses[1]
+	actor := newActor(t, 1000, 100)
+	ctx := context.Background()
+
+	blsSigner := types.NewMockSigner(types.MustGenerateBLSKeyInfo(1))
+	carol := blsSigner.Addresses[0]
+	msg := types.NewMessage(carol, bob, 100, types.ZeroAttoFIL, "method", []byte("params"))
+	signed, err := types.NewSignedMessage(*msg, blsSigner, types.NewGasPrice(1), types.NewGasUnits(0))
+	require.NoError(t, err)
+	unsigned := &types.SignedMessage{MeteredMessage: signed.MeteredMessage}
+
+	t.Run("allows unsigned BLS message", func(t *testing.T) {
+		assert.NoError(t, consensus.NewBlockMessageValidator().Validate(ctx, unsigned, actor))
+		assert.Error(t, consensus.NewDefaultMessageValidator().Validate(ctx, unsigned, actor))
+	})
+
+	t.Run("checks signature of signed BLS message", func(t *testing.T) {
+		validator := consensus.NewBlockMessageValidator()
+		assert.NoError(t, validator.Validate(ctx, signed, actor))
+
+		corrupted := &types.SignedMessage{MeteredMessage: signed.MeteredMessage, Signature: append(types.Signature{}, signed.Signature...)}
+		corrupted.Signature[0] ^= 0xFF
+		assert.Error(t, validator.Validate(ctx, corrupted, actor))
+	})
+
+	t.Run("requires signature of secp256k1 message", func(t *testing.T) {
+		msg := newMessage(t, addresses[0], bob, 100, 5, 1, 0)
+		msg.Signature = nil
+		assert.Error(t, consensus.NewBlockMessageValidator().Validate(ctx, msg, actor))
+	})
+}
+
 func TestIngestionValidator(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -138,6 +189,17 @@ func TestIngestionValidator(t *testing.T) {
 		msg := newMessage(t, bob, alice, 0, 0, 1, 0)
 		assert.NoError(t, validator.Validate(ctx, msg))
 	})
+
+	t.Run("Does not check signatures of reverted messages", func(t *testing.T) {
+		msg := newMessage(t, alice, bob, 100, 5, 1, 0)
+		msg.Signature = nil
+		assert.Error(t, validator.Validate(ctx, msg))
+		assert.NoError(t, validator.ValidateReverted(ctx, msg))
+
+		highNonce := uint64(act.Nonce + mpoolCfg.MaxNonceGap + 10)
+		msg = newMessage(t, alice, bob, highNonce, 5, 1, 0)
+		assert.Error(t, validator.ValidateReverted(ctx, msg))
+	})
 }
 
 func newActor(t *testing.T, balanceAF int, nonce uint64) *actor.Actor {
@@ -44,6 +44,13 @@ func (e Exports) Has(method string) bool {
 	return ok
 }
 
+// HasAt checks if the given method is an exported method that may be called at the given
+// protocol version.
+func (e Exports) HasAt(method string, version uint64) bool {
+	sig, ok := e[method]
+	return ok && sig.Since <= version
+}
+
 // TODO fritz require actors to define their exit codes and associate
 // an error string with them.
 
@@ -63,6 +70,9 @@ type FunctionSignature struct {
 	Params []abi.Type
 	// Return is the type of the return value of the function.
 	Return []abi.Type
+	// Since is the protocol version from which the function may be called. Functions added to an
+	// actor by a protocol upgrade set it to the upgrade's version.
+	Since uint64
 }
 
 // VMContext defines the ABI interface exposed to actors.
@@ -72,6 +82,7 @@ type VMContext interface {
 	Send(to address.Address, method string, value types.AttoFIL, params []interface{}) ([][]byte, uint8, error)
 	AddressForNewActor() (address.Address, error)
 	BlockHeight() *types.BlockHeight
+	ProtocolVersion() uint64
 	MyBalance() types.AttoFIL
 	IsFromAccountActor() bool
 	Charge(cost types.GasUnits) error
@@ -103,6 +103,9 @@ func MustStartFaucet(t *testing.T, ctx context.Context, node *iptbtester.TestNod
 	filWallet := ""
 	node.MustRunCmdJSON(ctx, &filWallet, "go-filecoin", "config", "wallet.defaultAddress")
 
+	filToken := ""
+	node.MustRunCmdJSON(ctx, &filToken, "go-filecoin", "auth", "create-token", "--perm=sign")
+
 	parts := strings.Split(api, "/")
 	filAPI := fmt.Sprintf("%s:%s", parts[2], parts[4])
 
@@ -113,6 +116,7 @@ func MustStartFaucet(t *testing.T, ctx context.Context, node *iptbtester.TestNod
 		faucetBinary,
 		"-fil-api="+filAPI,
 		"-fil-wallet="+filWallet,
+		"-fil-token="+filToken,
 		"-limiter-expiry="+limiterExpiryStr,
 		"-faucet-val="+faucetValStr,
 	)
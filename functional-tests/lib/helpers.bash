@@ -204,12 +204,12 @@ function wait_mpool_size {
 }
 
 function set_price {
-  ./go-filecoin miner set-price --repodir="$3" --gas-price=1 --gas-limit=300 "$1" "$2" --enc=json | jq -r .MinerSetPriceResponse.AddAskCid.'"\/"'
+  ./go-filecoin miner set-price --repodir="$3" --gas-price=1 --gas-limit=1000 "$1" "$2" --enc=json | jq -r .MinerSetPriceResponse.AddAskCid.'"\/"'
 }
 
 function miner_update_pid {
   ./go-filecoin miner update-peerid "$1" "$2" \
-    --gas-price=1 --gas-limit=300 \
+    --gas-price=1 --gas-limit=1000 \
     --repodir="$3"
 }
 
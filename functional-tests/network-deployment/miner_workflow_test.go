@@ -137,7 +137,7 @@ func TestMinerWorkflow(t *testing.T) {
 
 		t.Logf("Balance Before %s", balanceBefore)
 
-		mcid, err := miner.DealsRedeem(ctx, deal.ProposalCid, fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(300))
+		mcid, err := miner.DealsRedeem(ctx, deal.ProposalCid, fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(1000))
 		require.NoError(t, err)
 
 		t.Logf("Redeem mcid    %s", mcid)
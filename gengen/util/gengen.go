@@ -15,6 +15,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/crypto"
 	"github.com/filecoin-project/go-filecoin/state"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
 	"github.com/filecoin-project/go-filecoin/vm"
 
 	bserv "github.com/ipfs/go-blockservice"
@@ -366,8 +367,9 @@ func applyMessageDirect(ctx context.Context, st state.Tree, vms vm.StorageMap, f
 		return nil, err
 	}
 
-	// create new processor that doesn't reward and doesn't validate
-	applier := consensus.NewConfiguredProcessor(&messageValidator{}, &blockRewarder{})
+	// create new processor that doesn't reward and doesn't validate, applying messages at the
+	// first protocol version, which every network starts at
+	applier := consensus.NewConfiguredProcessor(&messageValidator{}, &blockRewarder{}, version.NewSingleVersionTable(version.Protocol0))
 
 	res, err := applier.ApplyMessagesAndPayRewards(ctx, st, vms, []*types.SignedMessage{smsg}, address.Undef, types.NewBlockHeight(0), nil)
 	if err != nil {
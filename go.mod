@@ -33,6 +33,7 @@ require (
 	github.com/google/pprof v0.0.0-20190723021845-34ac40c74b70 // indirect
 	github.com/gopherjs/gopherjs v0.0.0-20190430165422-3e4dfb77656c // indirect
 	github.com/gorilla/mux v1.7.0 // indirect
+	github.com/gorilla/websocket v1.4.0
 	github.com/grpc-ecosystem/grpc-gateway v1.9.5 // indirect
 	github.com/hashicorp/go-multierror v1.0.0 // indirect
 	github.com/ipfs/go-bitswap v0.1.5
@@ -117,6 +118,7 @@ require (
 	github.com/spf13/viper v1.4.0 // indirect
 	github.com/stretchr/objx v0.2.0 // indirect
 	github.com/stretchr/testify v1.3.0
+	github.com/tyler-smith/go-bip39 v1.0.2
 	github.com/ugorji/go v1.1.7 // indirect
 	github.com/whyrusleeping/cbor-gen v0.0.0-20190910031516-c1cbffdb01bb
 	github.com/whyrusleeping/go-logging v0.0.0-20170515211332-0457bb6b88fc
@@ -127,6 +129,7 @@ require (
 	github.com/xeipuuv/gojsonschema v1.1.0
 	go.etcd.io/bbolt v1.3.3 // indirect
 	go.opencensus.io v0.22.0
+	golang.org/x/crypto v0.0.0-20190308221718-c2843e01d9a2
 	golang.org/x/exp v0.0.0-20190718202018-cfdd5522f6f6 // indirect
 	golang.org/x/image v0.0.0-20190703141733-d6a02ce849c9 // indirect
 	golang.org/x/mobile v0.0.0-20190719004257-d2bd2a29d028 // indirect
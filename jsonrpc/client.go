@@ -0,0 +1,386 @@
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+)
+
+// ClientCloser closes the connection of a client.
+type ClientCloser func()
+
+// NewClient connects to the JSON-RPC websocket endpoint at addr, sending header with the upgrade
+// request, and sets each func field of the struct that out points to to a func that calls the
+// method with the field's name in namespace. Each func must take a context as its first
+// parameter and return an error as its last result, preceded by at most one other result. A
+// func whose other result is a receive-only channel streams the values the server sends on it,
+// closing it when the server ends the stream or the context is done.
+func NewClient(ctx context.Context, addr string, namespace string, out interface{}, header http.Header) (ClientCloser, error) {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, errors.New("expected a pointer to a struct of funcs")
+	}
+	v = v.Elem()
+
+	funcs := make([]reflect.Value, v.NumField())
+	c := &client{
+		namespace: namespace,
+		pending:   make(map[uint64]*pendingCall),
+		streams:   make(map[uint64]*stream),
+		done:      make(chan struct{}),
+	}
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		if field.Type.Kind() != reflect.Func {
+			return nil, errors.Errorf("field %s is not a func", field.Name)
+		}
+		sig, err := newSignature(field.Type)
+		if err != nil {
+			return nil, errors.Wrapf(err, "field %s cannot be called", field.Name)
+		}
+		if !sig.hasCtx {
+			return nil, errors.Errorf("field %s must take a context as its first parameter", field.Name)
+		}
+		funcs[i] = c.makeFunc(field.Name, field.Type, sig)
+	}
+
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, addr, header)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to connect to %s", addr)
+	}
+	c.conn = conn
+	go c.read()
+
+	for i, fn := range funcs {
+		v.Field(i).Set(fn)
+	}
+	return func() {
+		conn.Close() // nolint: errcheck
+		<-c.done
+	}, nil
+}
+
+// client is the client side of a websocket connection.
+type client struct {
+	namespace string
+	conn      *websocket.Conn
+	// done is closed when the connection is closed.
+	done chan struct{}
+
+	// writeLk serializes writes to conn.
+	writeLk sync.Mutex
+
+	// lk protects the fields below.
+	lk      sync.Mutex
+	nextID  uint64
+	pending map[uint64]*pendingCall
+	streams map[uint64]*stream
+	err     error
+}
+
+// pendingCall is a call awaiting its response.
+type pendingCall struct {
+	stream bool
+	// res receives the response.
+	res chan *callResult
+}
+
+type callResult struct {
+	result json.RawMessage
+	err    error
+	// stream receives the values of the result of a call to a streaming method.
+	stream   *stream
+	streamID uint64
+}
+
+// stream queues the values of a streamed result until they are received.
+type stream struct {
+	lk     sync.Mutex
+	values []json.RawMessage
+	closed bool
+	// notify signals that a value has been queued or the stream has closed.
+	notify chan struct{}
+}
+
+func newStream() *stream {
+	return &stream{notify: make(chan struct{}, 1)}
+}
+
+func (s *stream) push(value json.RawMessage) {
+	s.lk.Lock()
+	s.values = append(s.values, value)
+	s.lk.Unlock()
+	s.signal()
+}
+
+func (s *stream) close() {
+	s.lk.Lock()
+	s.closed = true
+	s.lk.Unlock()
+	s.signal()
+}
+
+func (s *stream) signal() {
+	select {
+	case s.notify <- struct{}{}:
+	default:
+	}
+}
+
+// next returns the next value of the stream, or false if the stream has closed or ctx is done.
+func (s *stream) next(ctx context.Context) (json.RawMessage, bool) {
+	for {
+		s.lk.Lock()
+		if len(s.values) > 0 {
+			value := s.values[0]
+			s.values = s.values[1:]
+			s.lk.Unlock()
+			return value, true
+		}
+		closed := s.closed
+		s.lk.Unlock()
+		if closed {
+			return nil, false
+		}
+
+		select {
+		case <-s.notify:
+		case <-ctx.Done():
+			return nil, false
+		}
+	}
+}
+
+func (c *client) makeFunc(name string, t reflect.Type, sig *signature) reflect.Value {
+	method := c.namespace + "." + name
+	return reflect.MakeFunc(t, func(args []reflect.Value) []reflect.Value {
+		ctx := args[0].Interface().(context.Context)
+		fail := func(err error) []reflect.Value {
+			if !sig.hasResult {
+				return []reflect.Value{reflect.ValueOf(&err).Elem()}
+			}
+			return []reflect.Value{reflect.Zero(sig.result), reflect.ValueOf(&err).Elem()}
+		}
+
+		params := make([]json.RawMessage, len(args)-1)
+		for i, arg := range args[1:] {
+			raw, err := json.Marshal(arg.Interface())
+			if err != nil {
+				return fail(errors.Wrapf(err, "failed to encode param %d of %s", i, method))
+			}
+			params[i] = raw
+		}
+		res, err := c.call(ctx, method, params, sig.stream)
+		if err != nil {
+			return fail(err)
+		}
+
+		nilErr := reflect.Zero(errorType)
+		if !sig.hasResult {
+			return []reflect.Value{nilErr}
+		}
+		if sig.stream {
+			return []reflect.Value{c.receive(ctx, res, sig.result), nilErr}
+		}
+		result := reflect.New(sig.result)
+		if err := json.Unmarshal(res.result, result.Interface()); err != nil {
+			return fail(errors.Wrapf(err, "failed to decode result of %s", method))
+		}
+		return []reflect.Value{result.Elem(), nilErr}
+	})
+}
+
+// call sends a request to call method and waits for its response.
+func (c *client) call(ctx context.Context, method string, params []json.RawMessage, isStream bool) (*callResult, error) {
+	pc := &pendingCall{stream: isStream, res: make(chan *callResult, 1)}
+	c.lk.Lock()
+	if c.err != nil {
+		c.lk.Unlock()
+		return nil, c.err
+	}
+	c.nextID++
+	id := c.nextID
+	c.pending[id] = pc
+	c.lk.Unlock()
+
+	rawID, _ := json.Marshal(id)
+	if err := c.write(&request{Jsonrpc: version, ID: rawID, Method: method, Params: params}); err != nil {
+		c.removePending(id)
+		return nil, errors.Wrapf(err, "failed to send call to %s", method)
+	}
+
+	select {
+	case res := <-pc.res:
+		return res, res.err
+	case <-ctx.Done():
+		if !c.removePending(id) {
+			// The response arrived, and may have started a stream that must be cancelled.
+			if res := <-pc.res; res.stream != nil {
+				c.cancelStream(res.streamID)
+			}
+		}
+		return nil, ctx.Err()
+	}
+}
+
+// receive returns a channel of type t that receives the values of the stream started by res.
+func (c *client) receive(ctx context.Context, res *callResult, t reflect.Type) reflect.Value {
+	ch := reflect.MakeChan(reflect.ChanOf(reflect.BothDir, t.Elem()), 0)
+	go func() {
+		defer ch.Close()
+		for {
+			raw, ok := res.stream.next(ctx)
+			if !ok {
+				break
+			}
+			value := reflect.New(t.Elem())
+			if err := json.Unmarshal(raw, value.Interface()); err != nil {
+				log.Errorf("failed to decode streamed value: %s", err)
+				break
+			}
+			chosen, _, _ := reflect.Select([]reflect.SelectCase{
+				{Dir: reflect.SelectSend, Chan: ch, Send: value.Elem()},
+				{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+			})
+			if chosen == 1 {
+				break
+			}
+		}
+		c.cancelStream(res.streamID)
+	}()
+	return ch.Convert(t)
+}
+
+// cancelStream stops receiving the values of a stream and asks the server to end it, if it has
+// not already.
+func (c *client) cancelStream(id uint64) {
+	c.lk.Lock()
+	_, open := c.streams[id]
+	delete(c.streams, id)
+	c.lk.Unlock()
+	if !open {
+		return
+	}
+	rawID, _ := json.Marshal(id)
+	if err := c.write(&request{Jsonrpc: version, Method: streamCancelMethod, Params: []json.RawMessage{rawID}}); err != nil {
+		log.Debugf("failed to cancel stream: %s", err)
+	}
+}
+
+// removePending removes the call with ID id, returning false if it had already been removed.
+func (c *client) removePending(id uint64) bool {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	_, ok := c.pending[id]
+	delete(c.pending, id)
+	return ok
+}
+
+func (c *client) write(v interface{}) error {
+	c.writeLk.Lock()
+	defer c.writeLk.Unlock()
+	return c.conn.WriteJSON(v)
+}
+
+// read dispatches the messages received from the server until the connection is closed.
+func (c *client) read() {
+	defer close(c.done)
+	for {
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			c.closeAll(errors.Wrap(err, "connection closed"))
+			return
+		}
+		var msg message
+		if err := json.Unmarshal(data, &msg); err != nil {
+			log.Warningf("failed to decode message: %s", err)
+			continue
+		}
+		if msg.Method != "" {
+			c.handleNotification(&msg)
+		} else {
+			c.handleResponse(&msg)
+		}
+	}
+}
+
+func (c *client) handleResponse(msg *message) {
+	var id uint64
+	if err := json.Unmarshal(msg.ID, &id); err != nil {
+		if msg.Error != nil {
+			log.Warningf("received error: %s", msg.Error)
+		}
+		return
+	}
+
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	pc, ok := c.pending[id]
+	if !ok {
+		return
+	}
+	delete(c.pending, id)
+
+	res := &callResult{result: msg.Result}
+	if msg.Error != nil {
+		res.err = msg.Error
+	} else if pc.stream {
+		// Register the stream before reading any more messages, which may carry its values.
+		if err := json.Unmarshal(msg.Result, &res.streamID); err != nil {
+			res.err = errors.Wrap(err, "failed to decode stream ID")
+		} else {
+			res.stream = newStream()
+			c.streams[res.streamID] = res.stream
+		}
+	}
+	pc.res <- res
+}
+
+func (c *client) handleNotification(msg *message) {
+	var id uint64
+	if len(msg.Params) == 0 || json.Unmarshal(msg.Params[0], &id) != nil {
+		log.Warningf("received invalid %s notification", msg.Method)
+		return
+	}
+
+	c.lk.Lock()
+	s, ok := c.streams[id]
+	if ok && msg.Method == streamCloseMethod {
+		delete(c.streams, id)
+	}
+	c.lk.Unlock()
+	if !ok {
+		return
+	}
+
+	switch msg.Method {
+	case streamValueMethod:
+		if len(msg.Params) != 2 {
+			log.Warningf("received invalid %s notification", msg.Method)
+			return
+		}
+		s.push(msg.Params[1])
+	case streamCloseMethod:
+		s.close()
+	}
+}
+
+// closeAll fails the pending calls and closes the streams after the connection fails.
+func (c *client) closeAll(err error) {
+	c.lk.Lock()
+	defer c.lk.Unlock()
+	c.err = err
+	for id, pc := range c.pending {
+		pc.res <- &callResult{err: err}
+		delete(c.pending, id)
+	}
+	for id, s := range c.streams {
+		s.close()
+		delete(c.streams, id)
+	}
+}
@@ -0,0 +1,124 @@
+// Package jsonrpc serves the methods of Go values over JSON-RPC 2.0, on HTTP and websocket
+// connections, and implements Go clients of them. Methods whose result is a channel stream the
+// values sent on it to websocket clients as notifications.
+package jsonrpc
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+
+	logging "github.com/ipfs/go-log"
+	"github.com/pkg/errors"
+)
+
+var log = logging.Logger("jsonrpc")
+
+const version = "2.0"
+
+// Error codes defined by the JSON-RPC 2.0 specification, and those in the range it reserves for
+// implementations.
+const (
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
+	// CodeServerError is the code of errors returned by methods.
+	CodeServerError = -32000
+	// CodeUnauthorized is the code of errors for calls the caller may not make.
+	CodeUnauthorized = -32001
+)
+
+// Methods of the streaming protocol. The server answers a call to a streaming method with the ID
+// of a stream, then sends each value of the stream in a streamValueMethod notification and ends
+// the stream with a streamCloseMethod notification. The client may end a stream early with a
+// streamCancelMethod notification.
+const (
+	streamValueMethod  = "xrpc.ch.val"
+	streamCloseMethod  = "xrpc.ch.close"
+	streamCancelMethod = "xrpc.ch.cancel"
+)
+
+// Error is a JSON-RPC error.
+type Error struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// request is a JSON-RPC request, or a notification if it has no ID.
+type request struct {
+	Jsonrpc string            `json:"jsonrpc"`
+	ID      json.RawMessage   `json:"id,omitempty"`
+	Method  string            `json:"method"`
+	Params  []json.RawMessage `json:"params"`
+}
+
+// response is a JSON-RPC response.
+type response struct {
+	Jsonrpc string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *Error          `json:"error,omitempty"`
+}
+
+// message is either a request or a response, as received by a client.
+type message struct {
+	ID     json.RawMessage   `json:"id"`
+	Method string            `json:"method"`
+	Params []json.RawMessage `json:"params"`
+	Result json.RawMessage   `json:"result"`
+	Error  *Error            `json:"error"`
+}
+
+var (
+	contextType = reflect.TypeOf((*context.Context)(nil)).Elem()
+	errorType   = reflect.TypeOf((*error)(nil)).Elem()
+)
+
+// signature describes a func that may be called over JSON-RPC: it may take a context as its
+// first parameter, and returns an error as its last result, preceded by at most one other
+// result, which is streamed if it is a receive-only channel.
+type signature struct {
+	hasCtx    bool
+	params    []reflect.Type
+	hasResult bool
+	result    reflect.Type
+	stream    bool
+}
+
+func newSignature(t reflect.Type) (*signature, error) {
+	sig := &signature{}
+	first := 0
+	if t.NumIn() > 0 && t.In(0) == contextType {
+		sig.hasCtx = true
+		first = 1
+	}
+	for i := first; i < t.NumIn(); i++ {
+		sig.params = append(sig.params, t.In(i))
+	}
+	if t.IsVariadic() {
+		return nil, errors.New("variadic parameters are not supported")
+	}
+
+	switch t.NumOut() {
+	case 1:
+	case 2:
+		sig.hasResult = true
+		sig.result = t.Out(0)
+		sig.stream = sig.result.Kind() == reflect.Chan
+		if sig.stream && sig.result.ChanDir() != reflect.RecvDir {
+			return nil, errors.New("streamed results must be receive-only channels")
+		}
+	default:
+		return nil, errors.New("expected an error result, preceded by at most one other result")
+	}
+	if t.Out(t.NumOut()-1) != errorType {
+		return nil, errors.New("expected an error as the last result")
+	}
+	return sig, nil
+}
@@ -0,0 +1,257 @@
+package jsonrpc_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/jsonrpc"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+)
+
+type testHandler struct {
+	// foreverDone is closed when the stream of Forever ends.
+	foreverDone chan struct{}
+}
+
+func (h *testHandler) Add(a, b int) (int, error) {
+	return a + b, nil
+}
+
+func (h *testHandler) Fail(ctx context.Context) error {
+	return errors.New("boom")
+}
+
+func (h *testHandler) Panic(ctx context.Context) error {
+	panic("boom")
+}
+
+func (h *testHandler) Double(ctx context.Context, n *int) (int, error) {
+	return 2 * *n, nil
+}
+
+func (h *testHandler) Secret(ctx context.Context) (string, error) {
+	return "secret", nil
+}
+
+func (h *testHandler) Count(ctx context.Context, n int) (<-chan int, error) {
+	if n < 0 {
+		return nil, errors.New("negative count")
+	}
+	out := make(chan int)
+	go func() {
+		defer close(out)
+		for i := 0; i < n; i++ {
+			select {
+			case out <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (h *testHandler) Forever(ctx context.Context) (<-chan int, error) {
+	out := make(chan int)
+	go func() {
+		defer close(h.foreverDone)
+		defer close(out)
+		for i := 0; ; i++ {
+			select {
+			case out <- i:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+type testClient struct {
+	Add     func(ctx context.Context, a, b int) (int, error)
+	Fail    func(ctx context.Context) error
+	Panic   func(ctx context.Context) error
+	Double  func(ctx context.Context, n *int) (int, error)
+	Secret  func(ctx context.Context) (string, error)
+	Count   func(ctx context.Context, n int) (<-chan int, error)
+	Forever func(ctx context.Context) (<-chan int, error)
+	Missing func(ctx context.Context) error
+}
+
+func newTestServer(t *testing.T) (*httptest.Server, *testHandler) {
+	handler := &testHandler{foreverDone: make(chan struct{})}
+	server := jsonrpc.NewServer(func(r *http.Request, method string) error {
+		if method == "Test.Secret" && r.Header.Get("X-Allow") == "" {
+			return errors.New("not allowed")
+		}
+		return nil
+	})
+	require.NoError(t, server.Register("Test", handler))
+	return httptest.NewServer(server), handler
+}
+
+func TestWebsocketClient(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	ts, handler := newTestServer(t)
+	defer ts.Close()
+	addr := "ws" + strings.TrimPrefix(ts.URL, "http")
+
+	var c testClient
+	closer, err := jsonrpc.NewClient(ctx, addr, "Test", &c, nil)
+	require.NoError(t, err)
+	defer closer()
+
+	t.Run("calls return results and errors", func(t *testing.T) {
+		sum, err := c.Add(ctx, 2, 3)
+		require.NoError(t, err)
+		assert.Equal(t, 5, sum)
+
+		err = c.Fail(ctx)
+		require.Error(t, err)
+		assert.Equal(t, "boom", err.Error())
+
+		err = c.Missing(ctx)
+		require.Error(t, err)
+		assert.Equal(t, jsonrpc.CodeMethodNotFound, err.(*jsonrpc.Error).Code)
+	})
+
+	t.Run("panics and null pointer params are errors", func(t *testing.T) {
+		err := c.Panic(ctx)
+		require.Error(t, err)
+		assert.Equal(t, jsonrpc.CodeInternalError, err.(*jsonrpc.Error).Code)
+
+		_, err = c.Double(ctx, nil)
+		require.Error(t, err)
+		assert.Equal(t, jsonrpc.CodeInvalidParams, err.(*jsonrpc.Error).Code)
+
+		n := 4
+		doubled, err := c.Double(ctx, &n)
+		require.NoError(t, err)
+		assert.Equal(t, 8, doubled)
+	})
+
+	t.Run("calls are authorized", func(t *testing.T) {
+		_, err := c.Secret(ctx)
+		require.Error(t, err)
+		assert.Equal(t, jsonrpc.CodeUnauthorized, err.(*jsonrpc.Error).Code)
+
+		var allowed testClient
+		closer, err := jsonrpc.NewClient(ctx, addr, "Test", &allowed, http.Header{"X-Allow": []string{"yes"}})
+		require.NoError(t, err)
+		defer closer()
+		secret, err := allowed.Secret(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "secret", secret)
+	})
+
+	t.Run("streams deliver each value and close", func(t *testing.T) {
+		ch, err := c.Count(ctx, 100)
+		require.NoError(t, err)
+		var values []int
+		for v := range ch {
+			values = append(values, v)
+		}
+		require.Len(t, values, 100)
+		for i, v := range values {
+			assert.Equal(t, i, v)
+		}
+
+		_, err = c.Count(ctx, -1)
+		require.Error(t, err)
+		assert.Equal(t, "negative count", err.Error())
+	})
+
+	t.Run("cancelling the context ends a stream on the server", func(t *testing.T) {
+		streamCtx, cancel := context.WithCancel(ctx)
+		ch, err := c.Forever(streamCtx)
+		require.NoError(t, err)
+		assert.Equal(t, 0, <-ch)
+		assert.Equal(t, 1, <-ch)
+		cancel()
+
+		select {
+		case <-handler.foreverDone:
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for the stream to end")
+		}
+		for range ch {
+		}
+	})
+
+	t.Run("calls fail after the connection closes", func(t *testing.T) {
+		var closed testClient
+		closer, err := jsonrpc.NewClient(ctx, addr, "Test", &closed, nil)
+		require.NoError(t, err)
+		closer()
+		_, err = closed.Add(ctx, 1, 2)
+		assert.Error(t, err)
+	})
+}
+
+func TestHTTPRequests(t *testing.T) {
+	tf.UnitTest(t)
+
+	ts, _ := newTestServer(t)
+	defer ts.Close()
+
+	post := func(body string) map[string]interface{} {
+		res, err := http.Post(ts.URL, "application/json", strings.NewReader(body))
+		require.NoError(t, err)
+		defer res.Body.Close() // nolint: errcheck
+		var out map[string]interface{}
+		require.NoError(t, json.NewDecoder(res.Body).Decode(&out))
+		return out
+	}
+
+	out := post(`{"jsonrpc":"2.0","id":7,"method":"Test.Add","params":[2,3]}`)
+	assert.Equal(t, float64(7), out["id"])
+	assert.Equal(t, float64(5), out["result"])
+
+	out = post(`{"jsonrpc":"2.0","id":"a","method":"Test.Fail","params":[]}`)
+	assert.Equal(t, "a", out["id"])
+	assert.Equal(t, "boom", out["error"].(map[string]interface{})["message"])
+
+	out = post(`{"jsonrpc":"2.0","id":1,"method":"Test.Add","params":[2]}`)
+	assert.Equal(t, float64(jsonrpc.CodeInvalidParams), out["error"].(map[string]interface{})["code"])
+
+	out = post(`{"jsonrpc":"2.0","id":1,"method":"Test.Panic","params":[]}`)
+	assert.Equal(t, float64(jsonrpc.CodeInternalError), out["error"].(map[string]interface{})["code"])
+
+	out = post(`{"jsonrpc":"2.0","id":1,"method":"Test.Double","params":[null]}`)
+	assert.Equal(t, float64(jsonrpc.CodeInvalidParams), out["error"].(map[string]interface{})["code"])
+
+	out = post(`{"jsonrpc":"2.0","id":1,"method":"Test.Count","params":[3]}`)
+	assert.Contains(t, out["error"].(map[string]interface{})["message"], "must be called over a websocket")
+
+	out = post(`{"jsonrpc":"2.0",`)
+	assert.Equal(t, float64(jsonrpc.CodeParseError), out["error"].(map[string]interface{})["code"])
+
+	big := strings.Repeat(" ", jsonrpc.MaxRequestSize)
+	out = post(`{"jsonrpc":"2.0","id":1,"method":"Test.Add","params":[2,3]` + big + `}`)
+	assert.Equal(t, float64(jsonrpc.CodeParseError), out["error"].(map[string]interface{})["code"])
+	assert.Contains(t, out["error"].(map[string]interface{})["message"], "too large")
+}
+
+func TestRegisterRejectsUnsupportedMethods(t *testing.T) {
+	tf.UnitTest(t)
+
+	server := jsonrpc.NewServer(func(*http.Request, string) error { return nil })
+	assert.Error(t, server.Register("Bad", &badHandler{}))
+}
+
+type badHandler struct{}
+
+func (h *badHandler) NoError() int {
+	return 0
+}
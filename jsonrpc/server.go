@@ -0,0 +1,325 @@
+package jsonrpc
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"sync"
+
+	"github.com/gorilla/websocket"
+	"github.com/pkg/errors"
+)
+
+// MaxRequestSize is the maximum size in bytes of an HTTP request body or websocket message that
+// the server reads.
+const MaxRequestSize = 1 << 20
+
+// Authorizer returns an error if the HTTP request, or the websocket upgrade request, r does not
+// authorize calls to method.
+type Authorizer func(r *http.Request, method string) error
+
+// method is a registered method of a handler.
+type method struct {
+	*signature
+	fn reflect.Value
+}
+
+// call calls the method with args, returning its result, if any, and error. A panic in the method
+// is recovered and returned as an internal error rather than taking down the server.
+func (m *method) call(ctx context.Context, args []reflect.Value) (result reflect.Value, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			log.Errorf("method panicked: %v\n%s", r, debug.Stack())
+			result, err = reflect.Value{}, &Error{Code: CodeInternalError, Message: fmt.Sprintf("method panicked: %v", r)}
+		}
+	}()
+	if m.hasCtx {
+		args = append([]reflect.Value{reflect.ValueOf(ctx)}, args...)
+	}
+	out := m.fn.Call(args)
+	if err := out[len(out)-1].Interface(); err != nil {
+		return reflect.Value{}, err.(error)
+	}
+	if m.hasResult {
+		return out[0], nil
+	}
+	return reflect.Value{}, nil
+}
+
+// Server serves the methods of registered handlers to JSON-RPC clients. Each HTTP POST request
+// carries a single call. A websocket connection carries any number of concurrent calls, including
+// calls to streaming methods, which HTTP requests may not call.
+type Server struct {
+	methods   map[string]*method
+	authorize Authorizer
+	upgrader  websocket.Upgrader
+}
+
+// NewServer creates a server that authorizes each call with authorize.
+func NewServer(authorize Authorizer) *Server {
+	return &Server{
+		methods:   make(map[string]*method),
+		authorize: authorize,
+	}
+}
+
+// Register serves the exported methods of handler as "<namespace>.<method name>". Each method
+// may take a context, which is done when the call is cancelled, as its first parameter and must
+// return an error as its last result, preceded by at most one other result. A method whose other
+// result is a receive-only channel streams the values sent on it until it closes the channel,
+// which it must do when its context is done.
+func (s *Server) Register(namespace string, handler interface{}) error {
+	v := reflect.ValueOf(handler)
+	for i := 0; i < v.NumMethod(); i++ {
+		name := v.Type().Method(i).Name
+		sig, err := newSignature(v.Method(i).Type())
+		if err != nil {
+			return errors.Wrapf(err, "method %s cannot be served", name)
+		}
+		s.methods[namespace+"."+name] = &method{signature: sig, fn: v.Method(i)}
+	}
+	return nil
+}
+
+// ServeHTTP serves a call in an HTTP POST request or upgrades the connection to a websocket.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if websocket.IsWebSocketUpgrade(r) {
+		s.serveWebsocket(w, r)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "JSON-RPC requests must be sent with POST", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var res *response
+	var req request
+	r.Body = http.MaxBytesReader(w, r.Body, MaxRequestSize)
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		res = errorResponse(nil, &Error{Code: CodeParseError, Message: err.Error()})
+	} else {
+		res = s.handleHTTP(r, &req)
+	}
+	if res == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		log.Warningf("failed to write response: %s", err)
+	}
+}
+
+func (s *Server) handleHTTP(r *http.Request, req *request) *response {
+	m, args, rpcErr := s.prepare(r, req)
+	if rpcErr != nil {
+		return errorResponse(req.ID, rpcErr)
+	}
+	if m.stream {
+		return errorResponse(req.ID, &Error{Code: CodeInvalidRequest, Message: fmt.Sprintf("%s streams its result and must be called over a websocket", req.Method)})
+	}
+	result, err := m.call(r.Context(), args)
+	if req.ID == nil {
+		return nil
+	}
+	return resultResponse(req.ID, result, err)
+}
+
+// prepare finds and authorizes the method that req calls and decodes its arguments.
+func (s *Server) prepare(r *http.Request, req *request) (*method, []reflect.Value, *Error) {
+	if req.Jsonrpc != version {
+		return nil, nil, &Error{Code: CodeInvalidRequest, Message: fmt.Sprintf("unsupported JSON-RPC version %q", req.Jsonrpc)}
+	}
+	m, ok := s.methods[req.Method]
+	if !ok {
+		return nil, nil, &Error{Code: CodeMethodNotFound, Message: fmt.Sprintf("method %s not found", req.Method)}
+	}
+	if err := s.authorize(r, req.Method); err != nil {
+		return nil, nil, &Error{Code: CodeUnauthorized, Message: err.Error()}
+	}
+	if len(req.Params) != len(m.params) {
+		return nil, nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("%s expects %d params, got %d", req.Method, len(m.params), len(req.Params))}
+	}
+	args := make([]reflect.Value, len(m.params))
+	for i, t := range m.params {
+		if t.Kind() == reflect.Ptr && bytes.Equal(bytes.TrimSpace(req.Params[i]), []byte("null")) {
+			return nil, nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("invalid param %d: must not be null", i)}
+		}
+		v := reflect.New(t)
+		if err := json.Unmarshal(req.Params[i], v.Interface()); err != nil {
+			return nil, nil, &Error{Code: CodeInvalidParams, Message: fmt.Sprintf("invalid param %d: %s", i, err)}
+		}
+		args[i] = v.Elem()
+	}
+	return m, args, nil
+}
+
+func (s *Server) serveWebsocket(w http.ResponseWriter, r *http.Request) {
+	conn, err := s.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// The upgrader has responded with an error.
+		return
+	}
+	defer conn.Close() // nolint: errcheck
+	conn.SetReadLimit(MaxRequestSize)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	sc := &serverConn{
+		server:  s,
+		conn:    conn,
+		upgrade: r,
+		streams: make(map[uint64]context.CancelFunc),
+	}
+	for {
+		_, data, err := conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		var req request
+		if err := json.Unmarshal(data, &req); err != nil {
+			sc.write(errorResponse(nil, &Error{Code: CodeParseError, Message: err.Error()}))
+			continue
+		}
+		go sc.handle(ctx, &req)
+	}
+}
+
+// serverConn is the server side of a websocket connection.
+type serverConn struct {
+	server  *Server
+	conn    *websocket.Conn
+	upgrade *http.Request
+
+	// writeLk serializes writes to conn.
+	writeLk sync.Mutex
+
+	// lk protects the fields below.
+	lk         sync.Mutex
+	nextStream uint64
+	streams    map[uint64]context.CancelFunc
+}
+
+func (sc *serverConn) handle(ctx context.Context, req *request) {
+	if req.Method == streamCancelMethod {
+		var id uint64
+		if len(req.Params) == 1 && json.Unmarshal(req.Params[0], &id) == nil {
+			sc.cancelStream(id)
+		}
+		return
+	}
+
+	m, args, rpcErr := sc.server.prepare(sc.upgrade, req)
+	if rpcErr != nil {
+		sc.respond(req.ID, errorResponse(req.ID, rpcErr))
+		return
+	}
+	if !m.stream {
+		result, err := m.call(ctx, args)
+		sc.respond(req.ID, resultResponse(req.ID, result, err))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	ch, err := m.call(ctx, args)
+	if err != nil {
+		sc.respond(req.ID, resultResponse(req.ID, reflect.Value{}, err))
+		return
+	}
+	id := sc.addStream(cancel)
+	defer sc.removeStream(id)
+	// The response carrying the stream's ID precedes its values.
+	sc.respond(req.ID, resultResponse(req.ID, reflect.ValueOf(id), nil))
+
+	cases := []reflect.SelectCase{
+		{Dir: reflect.SelectRecv, Chan: ch},
+		{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(ctx.Done())},
+	}
+	for {
+		chosen, v, ok := reflect.Select(cases)
+		if chosen == 1 || !ok {
+			break
+		}
+		sc.notify(streamValueMethod, id, v.Interface())
+	}
+	sc.notify(streamCloseMethod, id)
+}
+
+func (sc *serverConn) addStream(cancel context.CancelFunc) uint64 {
+	sc.lk.Lock()
+	defer sc.lk.Unlock()
+	sc.nextStream++
+	sc.streams[sc.nextStream] = cancel
+	return sc.nextStream
+}
+
+func (sc *serverConn) removeStream(id uint64) {
+	sc.lk.Lock()
+	defer sc.lk.Unlock()
+	delete(sc.streams, id)
+}
+
+func (sc *serverConn) cancelStream(id uint64) {
+	sc.lk.Lock()
+	defer sc.lk.Unlock()
+	if cancel, ok := sc.streams[id]; ok {
+		cancel()
+	}
+}
+
+// respond writes res, unless the request with ID id was a notification.
+func (sc *serverConn) respond(id json.RawMessage, res *response) {
+	if id != nil {
+		sc.write(res)
+	}
+}
+
+func (sc *serverConn) notify(method string, params ...interface{}) {
+	req := &request{Jsonrpc: version, Method: method}
+	for _, p := range params {
+		raw, err := json.Marshal(p)
+		if err != nil {
+			log.Errorf("failed to encode %s notification: %s", method, err)
+			return
+		}
+		req.Params = append(req.Params, raw)
+	}
+	sc.write(req)
+}
+
+func (sc *serverConn) write(v interface{}) {
+	sc.writeLk.Lock()
+	defer sc.writeLk.Unlock()
+	if err := sc.conn.WriteJSON(v); err != nil {
+		log.Debugf("failed to write to websocket: %s", err)
+	}
+}
+
+func errorResponse(id json.RawMessage, err *Error) *response {
+	return &response{Jsonrpc: version, ID: id, Error: err}
+}
+
+// resultResponse returns a response carrying result, which may be invalid if the method has no
+// result, or err, if it is not nil. An err that is an *Error keeps its code.
+func resultResponse(id json.RawMessage, result reflect.Value, err error) *response {
+	if rpcErr, ok := err.(*Error); ok {
+		return errorResponse(id, rpcErr)
+	}
+	if err != nil {
+		return errorResponse(id, &Error{Code: CodeServerError, Message: err.Error()})
+	}
+	raw := json.RawMessage("null")
+	if result.IsValid() {
+		var encErr error
+		raw, encErr = json.Marshal(result.Interface())
+		if encErr != nil {
+			return errorResponse(id, &Error{Code: CodeInternalError, Message: fmt.Sprintf("failed to encode result: %s", encErr)})
+		}
+	}
+	return &response{Jsonrpc: version, ID: id, Result: raw}
+}
@@ -73,6 +73,8 @@ func (ib *Inbox) HandleNewHead(ctx context.Context, oldChain, newChain []types.T
 	}
 
 	// Add all message from the old tipsets to the message pool, so they can be mined again.
+	// Their signatures were checked when the blocks were validated, and BLS messages are
+	// stored without them.
 	for _, tipset := range oldChain {
 		for i := 0; i < tipset.Len(); i++ {
 			block := tipset.At(i)
@@ -81,7 +83,7 @@ func (ib *Inbox) HandleNewHead(ctx context.Context, oldChain, newChain []types.T
 				return err
 			}
 			for _, msg := range msgs {
-				_, err = ib.pool.Add(ctx, msg, chainHeight)
+				_, err = ib.pool.AddReverted(ctx, msg, chainHeight)
 				if err != nil {
 					// Messages from the removed chain are frequently invalidated, e.g. because that
 					// same message is already mined on the new chain.
@@ -110,7 +112,7 @@ func (ib *Inbox) HandleNewHead(ctx context.Context, oldChain, newChain []types.T
 		}
 	}
 	for _, c := range removeCids {
-		ib.pool.Remove(c)
+		ib.pool.removeAs(c, MessageIncluded)
 	}
 
 	// prune all messages that have been in the pool too long
@@ -144,7 +146,7 @@ func timeoutMessages(ctx context.Context, pool *Pool, chains chain.TipSetProvide
 
 	// remove all messages added before minimumHeight
 	for _, cid := range pool.PendingBefore(minimumHeight) {
-		pool.Remove(cid)
+		pool.removeAs(cid, MessageExpired)
 	}
 
 	return nil
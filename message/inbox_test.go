@@ -385,6 +385,34 @@ func TestUpdateMessagePool(t *testing.T) {
 	})
 }
 
+func TestInboxPoolEvents(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	type msgs []*types.SignedMessage
+	type msgsSet [][]*types.SignedMessage
+
+	var mockSigner, _ = types.NewMockSignersAndKeyInfo(10)
+
+	chainProvider, parent := newProviderWithGenesis(t)
+	p := message.NewPool(config.NewDefaultConfig().Mpool, th.NewMockMessagePoolValidator())
+	ib := message.NewInbox(p, 1, chainProvider, chainProvider)
+
+	m := types.NewSignedMsgs(2, mockSigner)
+	requireAdd(t, ib, m[0], m[1])
+
+	ch := p.Subscribe()
+	defer p.Unsubscribe(ch)
+
+	// m1 is mined in the first new tipset, and m0 expires a tipset later.
+	newChain := requireChainWithMessages(t, chainProvider.Builder, parent, msgsSet{msgs{m[1]}})
+	require.NoError(t, ib.HandleNewHead(ctx, nil, newChain))
+	requireEvent(t, ch, message.MessageIncluded, m[1])
+
+	next := requireChainWithMessages(t, chainProvider.Builder, newChain[0], msgsSet{msgs{}})
+	require.NoError(t, ib.HandleNewHead(ctx, nil, next))
+	requireEvent(t, ch, message.MessageExpired, m[0])
+}
+
 func newProviderWithGenesis(t *testing.T) (*message.FakeProvider, types.TipSet) {
 	provider := message.NewFakeProvider(t)
 	head := provider.Builder.NewGenesis()
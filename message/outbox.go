@@ -130,6 +130,135 @@ func (ob *Outbox) Send(ctx context.Context, from, to address.Address, value type
 	return signed.Cid()
 }
 
+// Replace re-signs a message in the outbound message queue with a new gas price and sends it in
+// place of the original, which is useful when the original gas price is too low for the message
+// to be mined. The message pool only accepts the replacement if the new gas price is sufficiently
+// higher than the original.
+// If bcast is true, the publisher broadcasts the replacement to the network at the current block height.
+func (ob *Outbox) Replace(ctx context.Context, msgCid cid.Cid, gasPrice types.AttoFIL, bcast bool) (out cid.Cid, err error) {
+	defer func() {
+		if err != nil {
+			msgSendErrCt.Inc(ctx, 1)
+		}
+	}()
+
+	// Lock to avoid racing with a send for the same actor.
+	ob.nonceLock.Lock()
+	defer ob.nonceLock.Unlock()
+
+	queued, found := ob.findQueued(msgCid)
+	if !found {
+		return cid.Undef, errors.Errorf("message %s is not in the outbound queue", msgCid)
+	}
+	original := queued.Msg
+
+	head := ob.chains.GetHead()
+
+	fromActor, err := ob.actors.GetActorAt(ctx, head, original.From)
+	if err != nil {
+		return cid.Undef, errors.Wrapf(err, "no actor at address %s", original.From)
+	}
+
+	signed, err := types.NewSignedMessage(original.Message, ob.signer, gasPrice, original.GasLimit)
+	if err != nil {
+		return cid.Undef, errors.Wrap(err, "failed to sign message")
+	}
+
+	err = ob.validator.Validate(ctx, signed, fromActor)
+	if err != nil {
+		return cid.Undef, errors.Wrap(err, "invalid message")
+	}
+
+	height, err := tipsetHeight(ob.chains, head)
+	if err != nil {
+		return cid.Undef, errors.Wrap(err, "failed to get block height")
+	}
+
+	if _, found := ob.queue.Replace(ctx, signed, height); !found {
+		return cid.Undef, errors.Errorf("message %s is no longer in the outbound queue", msgCid)
+	}
+	err = ob.publisher.Publish(ctx, signed, height, bcast)
+	if err != nil {
+		// Restore the original, which the message pool still holds.
+		ob.queue.Replace(ctx, original, queued.Stamp)
+		return cid.Undef, err
+	}
+
+	return signed.Cid()
+}
+
+// Republish re-validates the messages in the outbound message queue against the current head and
+// publishes them again, which is necessary after a restart because the message pool does not
+// survive it. Messages with nonces that the head has already passed are dropped from the queue,
+// as are messages that are no longer valid, along with any later messages from the same sender.
+func (ob *Outbox) Republish(ctx context.Context) error {
+	ob.nonceLock.Lock()
+	defer ob.nonceLock.Unlock()
+
+	head := ob.chains.GetHead()
+	height, err := tipsetHeight(ob.chains, head)
+	if err != nil {
+		return errors.Wrap(err, "failed to get block height")
+	}
+
+	for _, sender := range ob.queue.Queues() {
+		fromActor, err := ob.actors.GetActorAt(ctx, head, sender)
+		if err != nil {
+			log.Warningf("dropping queued messages from %s: no actor at address: %s", sender, err)
+			ob.queue.Clear(ctx, sender)
+			continue
+		}
+		actorNonce, err := actor.NextNonce(fromActor)
+		if err != nil {
+			log.Warningf("dropping queued messages from %s: %s", sender, err)
+			ob.queue.Clear(ctx, sender)
+			continue
+		}
+
+		var valid []*Queued
+		queued := ob.queue.List(sender)
+		for _, qm := range queued {
+			if uint64(qm.Msg.Nonce) < actorNonce {
+				continue
+			}
+			if err := ob.validator.Validate(ctx, qm.Msg, fromActor); err != nil {
+				log.Warningf("dropping queued messages from %s from nonce %d: %s", sender, qm.Msg.Nonce, err)
+				break
+			}
+			valid = append(valid, qm)
+		}
+
+		if len(valid) < len(queued) {
+			ob.queue.Clear(ctx, sender)
+			for _, qm := range valid {
+				if err := ob.queue.Enqueue(ctx, qm.Msg, qm.Stamp); err != nil {
+					return errors.Wrap(err, "failed to restore outbound queue")
+				}
+			}
+		}
+
+		for _, qm := range valid {
+			if err := ob.publisher.Publish(ctx, qm.Msg, height, true); err != nil {
+				log.Warningf("failed to republish queued message from %s with nonce %d: %s", sender, qm.Msg.Nonce, err)
+			}
+		}
+	}
+	return nil
+}
+
+// findQueued returns the message with CID `msgCid` from the outbound message queue.
+func (ob *Outbox) findQueued(msgCid cid.Cid) (*Queued, bool) {
+	for _, sender := range ob.queue.Queues() {
+		for _, qm := range ob.queue.List(sender) {
+			c, err := qm.Msg.Cid()
+			if err == nil && c.Equals(msgCid) {
+				return qm, true
+			}
+		}
+	}
+	return nil, false
+}
+
 // HandleNewHead maintains the message queue in response to a new head tipset.
 func (ob *Outbox) HandleNewHead(ctx context.Context, oldTips, newTips []types.TipSet) error {
 	return ob.policy.HandleNewHead(ctx, ob.queue, oldTips, newTips)
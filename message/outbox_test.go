@@ -6,6 +6,8 @@ import (
 	"sync"
 	"testing"
 
+	"github.com/ipfs/go-datastore"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -129,6 +131,104 @@ func TestOutbox(t *testing.T) {
 		}
 	})
 
+	t.Run("replace re-signs a queued message with a new gas price and publishes it", func(t *testing.T) {
+		ctx := context.Background()
+		w, _ := types.NewMockSignersAndKeyInfo(1)
+		sender := w.Addresses[0]
+		toAddr := address.NewForTestGetter()()
+		queue := message.NewQueue()
+		publisher := &message.MockPublisher{}
+		provider := message.NewFakeProvider(t)
+
+		head := provider.BuildOneOn(types.UndefTipSet, func(b *chain.BlockBuilder) {
+			b.IncHeight(1000)
+		})
+		actr, _ := account.NewActor(types.ZeroAttoFIL)
+		provider.SetHeadAndActor(t, head.Key(), sender, actr)
+
+		ob := message.NewOutbox(w, message.FakeValidator{}, queue, publisher, message.NullPolicy{}, provider, provider)
+		original, err := ob.Send(ctx, sender, toAddr, types.NewAttoFILFromFIL(1), types.NewGasPrice(1), types.NewGasUnits(100), true, "")
+		require.NoError(t, err)
+		_, err = ob.Send(ctx, sender, toAddr, types.ZeroAttoFIL, types.NewGasPrice(1), types.NewGasUnits(100), true, "")
+		require.NoError(t, err)
+
+		replacement, err := ob.Replace(ctx, original, types.NewGasPrice(2), true)
+		require.NoError(t, err)
+		assert.False(t, original.Equals(replacement))
+
+		published := publisher.Message
+		assert.Equal(t, types.NewGasPrice(2), published.GasPrice)
+		assert.Equal(t, types.NewGasUnits(100), published.GasLimit)
+		assert.Equal(t, types.Uint64(0), published.Nonce)
+		assert.Equal(t, types.NewAttoFILFromFIL(1), published.Value)
+		assert.True(t, publisher.Bcast)
+
+		queued := queue.List(sender)
+		require.Len(t, queued, 2)
+		assert.Equal(t, published, queued[0].Msg)
+
+		t.Log("the original is no longer in the queue")
+		_, err = ob.Replace(ctx, original, types.NewGasPrice(3), true)
+		assert.Error(t, err)
+
+		t.Log("a publishing failure restores the queued message")
+		publisher.ReturnError = errors.New("publish failed")
+		_, err = ob.Replace(ctx, replacement, types.NewGasPrice(3), true)
+		assert.Error(t, err)
+		assert.Equal(t, published, queue.List(sender)[0].Msg)
+	})
+
+	t.Run("republish drops mined messages and publishes the rest", func(t *testing.T) {
+		ctx := context.Background()
+		w, _ := types.NewMockSignersAndKeyInfo(1)
+		sender := w.Addresses[0]
+		toAddr := address.NewForTestGetter()()
+		ds := datastore.NewMapDatastore()
+		queue, err := message.NewPersistentQueue(ds)
+		require.NoError(t, err)
+		provider := message.NewFakeProvider(t)
+
+		head := provider.BuildOneOn(types.UndefTipSet, func(b *chain.BlockBuilder) {
+			b.IncHeight(1000)
+		})
+		actr, _ := account.NewActor(types.ZeroAttoFIL)
+		provider.SetHeadAndActor(t, head.Key(), sender, actr)
+
+		ob := message.NewOutbox(w, message.FakeValidator{}, queue, &message.MockPublisher{}, message.NullPolicy{}, provider, provider)
+		for i := 0; i < 3; i++ {
+			_, err := ob.Send(ctx, sender, toAddr, types.ZeroAttoFIL, types.NewGasPrice(1), types.NewGasUnits(0), true, "")
+			require.NoError(t, err)
+		}
+
+		t.Log("the first message is mined while the node is stopped")
+		mined, _ := account.NewActor(types.ZeroAttoFIL)
+		mined.Nonce = 1
+		provider.SetHeadAndActor(t, head.Key(), sender, mined)
+
+		reloaded, err := message.NewPersistentQueue(ds)
+		require.NoError(t, err)
+		require.Len(t, reloaded.List(sender), 3)
+
+		publisher := &message.MockPublisher{}
+		ob = message.NewOutbox(w, message.FakeValidator{}, reloaded, publisher, message.NullPolicy{}, provider, provider)
+		require.NoError(t, ob.Republish(ctx))
+
+		queued := reloaded.List(sender)
+		require.Len(t, queued, 2)
+		assert.Equal(t, types.Uint64(1), queued[0].Msg.Nonce)
+		assert.Equal(t, types.Uint64(2), publisher.Message.Nonce)
+		assert.True(t, publisher.Bcast)
+
+		t.Log("invalid messages are dropped from the persisted queue")
+		ob = message.NewOutbox(w, message.FakeValidator{RejectMessages: true}, reloaded, publisher, message.NullPolicy{}, provider, provider)
+		require.NoError(t, ob.Republish(ctx))
+		assert.Empty(t, reloaded.List(sender))
+
+		reloaded, err = message.NewPersistentQueue(ds)
+		require.NoError(t, err)
+		assert.Empty(t, reloaded.List(sender))
+	})
+
 	t.Run("fails with non-account actor", func(t *testing.T) {
 		w, _ := types.NewMockSignersAndKeyInfo(1)
 		sender := w.Addresses[0]
@@ -1,9 +1,13 @@
 package message
 
 import (
+	"bytes"
+	"container/heap"
 	"context"
+	"math/big"
 	"sync"
 
+	"github.com/cskr/pubsub"
 	"github.com/ipfs/go-cid"
 	"github.com/pkg/errors"
 
@@ -14,10 +18,46 @@ import (
 )
 
 var mpSize = metrics.NewInt64Gauge("message_pool_size", "The size of the message pool")
+var mpEvictedCt = metrics.NewInt64Counter("message_pool_evicted", "Number of messages evicted from the message pool to make room for messages paying a higher gas price")
+
+// poolEventTopic is the pubsub topic on which the pool publishes PoolEvents.
+const poolEventTopic = "message_pool_event"
+
+// poolEventBuffer is the number of events buffered for each subscriber. Events are dropped for
+// subscribers that fall this far behind, so that slow subscribers never block the pool.
+const poolEventBuffer = 128
+
+// PoolEventType identifies a change to the message pool.
+type PoolEventType string
+
+const (
+	// MessageAdded is emitted when a message is added to the pool.
+	MessageAdded = PoolEventType("added")
+	// MessageRemoved is emitted when a message is removed from the pool explicitly, or is
+	// replaced or evicted by another message.
+	MessageRemoved = PoolEventType("removed")
+	// MessageIncluded is emitted when a message is removed from the pool because it was
+	// included in a block.
+	MessageIncluded = PoolEventType("included")
+	// MessageExpired is emitted when a message is removed from the pool because it has been
+	// pending for too long.
+	MessageExpired = PoolEventType("expired")
+)
+
+// PoolEvent describes a message being added to or removed from the pool.
+type PoolEvent struct {
+	Type    PoolEventType
+	Cid     cid.Cid
+	Message *types.SignedMessage
+}
 
 // PoolValidator defines a validator that ensures a message can go through the pool.
 type PoolValidator interface {
 	Validate(ctx context.Context, msg *types.SignedMessage) error
+	// ValidateReverted validates a message from a block that has been reverted, like Validate
+	// but without checking its signature. The signature was checked when the block was
+	// validated, and a BLS message is stored in a block without its signature.
+	ValidateReverted(ctx context.Context, msg *types.SignedMessage) error
 }
 
 // Pool keeps an unordered, de-duplicated set of Messages and supports removal by CID.
@@ -25,6 +65,18 @@ type PoolValidator interface {
 // exists is a nop. We use a Pool to store all messages received by this node
 // via network or directly created via user command that have yet to be included
 // in a block. Messages are removed as they are processed.
+// A message with the same sender and nonce as a pending message replaces it if its
+// gas price is sufficiently higher (replace-by-fee), so that a sender can unblock a
+// message that is stuck because its gas price is too low.
+// When the pool is full, a new message evicts the pending message paying the lowest gas
+// price if it pays more. Only the message with the highest nonce of a sender is evicted, so
+// that eviction never leaves a gap in the nonces of a sender. Messages originating from this
+// node are never evicted and are not subject to the limit on pending messages per sender, and
+// neither are messages re-added from reverted blocks subject to that limit.
+// A BLS message re-added from a reverted block lacks its signature until a signed copy of it
+// is added, which then takes its place.
+//
+// Subscribers are notified of messages being added to and removed from the pool.
 //
 // Pool is safe for concurrent access.
 type Pool struct {
@@ -33,12 +85,24 @@ type Pool struct {
 	cfg           *config.MessagePoolConfig
 	validator     PoolValidator
 	pending       map[cid.Cid]*timedmessage // all pending messages
-	addressNonces map[addressNonce]bool     // set of address nonce pairs used to efficiently validate duplicate nonces
+	addressNonces map[addressNonce]cid.Cid  // address nonce pairs of pending messages, used to efficiently find duplicate nonces
+	senders       map[address.Address]*pendingSender
+	evictable     senderHeap // senders whose highest nonce message may be evicted, by its gas price
+	events        *pubsub.PubSub
+}
+
+// pendingSender tracks the pending messages of a sender.
+type pendingSender struct {
+	from  address.Address
+	cids  map[uint64]cid.Cid // pending messages by nonce
+	tail  *timedmessage      // the pending message with the highest nonce
+	index int                // position in the eviction heap, or -1 if tail may not be evicted
 }
 
 type timedmessage struct {
 	message *types.SignedMessage
 	addedAt uint64
+	local   bool // whether the message originates from this node
 }
 
 type addressNonce struct {
@@ -50,19 +114,54 @@ func newAddressNonce(msg *types.SignedMessage) addressNonce {
 	return addressNonce{addr: msg.From, nonce: uint64(msg.Nonce)}
 }
 
+// messageOrigin is where a message added to the pool comes from.
+type messageOrigin int
+
+const (
+	// fromNetwork messages are received from other nodes.
+	fromNetwork = messageOrigin(iota)
+	// fromLocal messages originate from this node.
+	fromLocal
+	// fromRevertedBlock messages were mined in a block that has since been reverted.
+	fromRevertedBlock
+)
+
 // NewPool constructs a new Pool.
 func NewPool(cfg *config.MessagePoolConfig, validator PoolValidator) *Pool {
 	return &Pool{
 		cfg:           cfg,
 		validator:     validator,
 		pending:       make(map[cid.Cid]*timedmessage),
-		addressNonces: make(map[addressNonce]bool),
+		addressNonces: make(map[addressNonce]cid.Cid),
+		senders:       make(map[address.Address]*pendingSender),
+		events:        pubsub.New(poolEventBuffer),
 	}
 }
 
 // Add adds a message to the pool, tagged with the block height at which it was received.
-// Does nothing if the message is already in the pool.
+// Does nothing if the message is already in the pool. If the pool holds a message with the
+// same sender and nonce, the new message replaces it if it pays a sufficiently higher gas
+// price, and is rejected otherwise.
 func (pool *Pool) Add(ctx context.Context, msg *types.SignedMessage, height uint64) (cid.Cid, error) {
+	return pool.add(ctx, msg, height, fromNetwork)
+}
+
+// AddLocal adds a message originating from this node to the pool, like Add. Local messages
+// are never evicted and are not subject to the limit on pending messages per sender.
+func (pool *Pool) AddLocal(ctx context.Context, msg *types.SignedMessage, height uint64) (cid.Cid, error) {
+	return pool.add(ctx, msg, height, fromLocal)
+}
+
+// AddReverted adds a message from a block that has been reverted to the pool, like Add, so
+// that it can be mined again. Its signature is not checked again, and it may lack one if it
+// is a BLS message. Reverted messages are not subject to the limit on pending messages per
+// sender, since the sender had them all accepted before.
+func (pool *Pool) AddReverted(ctx context.Context, msg *types.SignedMessage, height uint64) (cid.Cid, error) {
+	return pool.add(ctx, msg, height, fromRevertedBlock)
+}
+
+func (pool *Pool) add(ctx context.Context, msg *types.SignedMessage, height uint64, origin messageOrigin) (cid.Cid, error) {
+	local := origin == fromLocal
 	pool.lk.Lock()
 	defer pool.lk.Unlock()
 
@@ -71,19 +170,51 @@ func (pool *Pool) Add(ctx context.Context, msg *types.SignedMessage, height uint
 		return cid.Undef, errors.Wrap(err, "failed to create CID")
 	}
 
-	// ignore message prior to validation if it is already in pool
-	_, found := pool.pending[c]
+	// ignore message prior to validation if it is already in pool, unless it supplies the
+	// signature of a BLS message re-added from a reverted block
+	existing, found := pool.pending[c]
 	if found {
+		if local && !existing.local {
+			existing.local = true
+			pool.fixEvictable(pool.senders[msg.From])
+		}
+		if len(existing.message.Signature) > 0 || len(msg.Signature) == 0 {
+			return c, nil
+		}
+		if !msg.VerifySignature() {
+			return cid.Undef, errors.New("validation error adding message to pool: invalid signature")
+		}
+		existing.message = msg
 		return c, nil
 	}
 
-	if err = pool.validateMessage(ctx, msg); err != nil {
+	replaced, evicted, err := pool.validateMessage(ctx, msg, origin)
+	if err != nil {
 		return cid.Undef, errors.Wrap(err, "validation error adding message to pool")
 	}
 
-	pool.pending[c] = &timedmessage{message: msg, addedAt: height}
-	pool.addressNonces[newAddressNonce(msg)] = true
+	if replaced.Defined() {
+		pool.remove(replaced, MessageRemoved)
+	}
+	if evicted.Defined() {
+		pool.remove(evicted, MessageRemoved)
+		mpEvictedCt.Inc(ctx, 1)
+	}
+	tm := &timedmessage{message: msg, addedAt: height, local: local}
+	pool.pending[c] = tm
+	pool.addressNonces[newAddressNonce(msg)] = c
+	sender, ok := pool.senders[msg.From]
+	if !ok {
+		sender = &pendingSender{from: msg.From, cids: make(map[uint64]cid.Cid), index: -1}
+		pool.senders[msg.From] = sender
+	}
+	sender.cids[uint64(msg.Nonce)] = c
+	if sender.tail == nil || msg.Nonce >= sender.tail.message.Nonce {
+		sender.tail = tm
+	}
+	pool.fixEvictable(sender)
 	mpSize.Set(ctx, int64(len(pool.pending)))
+	pool.events.TryPub(&PoolEvent{Type: MessageAdded, Cid: c, Message: msg}, poolEventTopic)
 	return c, nil
 }
 
@@ -114,15 +245,58 @@ func (pool *Pool) Get(c cid.Cid) (*types.SignedMessage, bool) {
 
 // Remove removes the message by CID from the pending pool.
 func (pool *Pool) Remove(c cid.Cid) {
+	pool.removeAs(c, MessageRemoved)
+}
+
+// removeAs removes the message by CID from the pending pool, notifying subscribers with an event
+// of type eventType.
+func (pool *Pool) removeAs(c cid.Cid, eventType PoolEventType) {
 	pool.lk.Lock()
 	defer pool.lk.Unlock()
 
+	pool.remove(c, eventType)
+	mpSize.Set(context.TODO(), int64(len(pool.pending)))
+}
+
+func (pool *Pool) remove(c cid.Cid, eventType PoolEventType) {
 	msg, ok := pool.pending[c]
-	if ok {
-		delete(pool.addressNonces, newAddressNonce(msg.message))
-		delete(pool.pending, c)
+	if !ok {
+		return
 	}
-	mpSize.Set(context.TODO(), int64(len(pool.pending)))
+	delete(pool.addressNonces, newAddressNonce(msg.message))
+	delete(pool.pending, c)
+
+	sender := pool.senders[msg.message.From]
+	delete(sender.cids, uint64(msg.message.Nonce))
+	if len(sender.cids) == 0 {
+		if sender.index >= 0 {
+			heap.Remove(&pool.evictable, sender.index)
+		}
+		delete(pool.senders, sender.from)
+	} else {
+		if sender.tail == msg {
+			sender.tail = nil
+			for _, sc := range sender.cids {
+				if pending := pool.pending[sc]; sender.tail == nil || pending.message.Nonce > sender.tail.message.Nonce {
+					sender.tail = pending
+				}
+			}
+		}
+		pool.fixEvictable(sender)
+	}
+	pool.events.TryPub(&PoolEvent{Type: eventType, Cid: c, Message: msg.message}, poolEventTopic)
+}
+
+// Subscribe returns a channel on which the pool emits a *PoolEvent each time a message is added
+// to or removed from the pool. Events are dropped if the subscriber falls too far behind.
+// The channel is closed by Unsubscribe.
+func (pool *Pool) Subscribe() chan interface{} {
+	return pool.events.Sub(poolEventTopic)
+}
+
+// Unsubscribe stops the pool emitting events on a channel returned by Subscribe, and closes it.
+func (pool *Pool) Unsubscribe(ch chan interface{}) {
+	pool.events.Unsub(ch, poolEventTopic)
 }
 
 // LargestNonce returns the largest nonce used by a message from address in the pool.
@@ -154,18 +328,133 @@ func (pool *Pool) PendingBefore(minimumHeight uint64) []cid.Cid {
 }
 
 // validateMessage validates that too many messages aren't added to the pool and the ones that are
-// have a high probability of making it through processing.
-func (pool *Pool) validateMessage(ctx context.Context, message *types.SignedMessage) error {
-	if uint(len(pool.pending)) >= pool.cfg.MaxPoolSize {
-		return errors.Errorf("message pool is full (%d messages)", pool.cfg.MaxPoolSize)
-	}
-
-	// check that message with this nonce does not already exist
-	_, found := pool.addressNonces[newAddressNonce(message)]
+// have a high probability of making it through processing. If the message replaces a pending
+// message with the same nonce, it returns the CID of the message to be replaced. If the pool is
+// full, it returns the CID of the message to be evicted to make room for it.
+func (pool *Pool) validateMessage(ctx context.Context, message *types.SignedMessage, origin messageOrigin) (replaced cid.Cid, evicted cid.Cid, err error) {
+	// check that message with this nonce does not already exist, unless the new message pays
+	// enough to replace it
+	replaced, found := pool.addressNonces[newAddressNonce(message)]
 	if found {
-		return errors.Errorf("message pool contains message with same actor and nonce but different cid")
+		existing := pool.pending[replaced].message
+		minPrice := MinReplacementGasPrice(existing.GasPrice, pool.cfg.ReplaceByFeePercent)
+		if message.GasPrice.LessThan(minPrice) {
+			return cid.Undef, cid.Undef, errors.Errorf("message pool contains message with same actor and nonce but different cid, and gas price %s is below the %s required to replace it", message.GasPrice, minPrice)
+		}
+	} else {
+		if origin == fromNetwork && pool.senderCount(message.From) >= pool.cfg.MaxSenderMessages {
+			return cid.Undef, cid.Undef, errors.Errorf("message pool contains too many messages from %s (%d messages)", message.From, pool.cfg.MaxSenderMessages)
+		}
+		if uint(len(pool.pending)) >= pool.cfg.MaxPoolSize {
+			evicted, found = pool.evictionCandidate(message)
+			if !found {
+				return cid.Undef, cid.Undef, errors.Errorf("message pool is full (%d messages)", pool.cfg.MaxPoolSize)
+			}
+		}
 	}
 
 	// check that the message is likely to succeed in processing
-	return pool.validator.Validate(ctx, message)
+	validate := pool.validator.Validate
+	if origin == fromRevertedBlock {
+		validate = pool.validator.ValidateReverted
+	}
+	if err := validate(ctx, message); err != nil {
+		return cid.Undef, cid.Undef, err
+	}
+	return replaced, evicted, nil
+}
+
+// senderCount returns the number of pending messages of sender.
+func (pool *Pool) senderCount(sender address.Address) uint {
+	if s, ok := pool.senders[sender]; ok {
+		return uint(len(s.cids))
+	}
+	return 0
+}
+
+// evictionCandidate returns the CID of the pending message with the lowest gas price that may
+// be evicted to make room for message, if any pays a lower gas price than message. Only the
+// message with the highest nonce of each sender other than the sender of message may be evicted,
+// so that eviction does not create nonce gaps, and local messages are never evicted.
+func (pool *Pool) evictionCandidate(message *types.SignedMessage) (cid.Cid, bool) {
+	// the cheapest sender other than that of message is either at the root of the heap, or
+	// is one of its children if the root is the sender of message
+	var candidate *pendingSender
+	for i := 0; i < len(pool.evictable) && i < 3; i++ {
+		sender := pool.evictable[i]
+		if sender.from == message.From {
+			continue
+		}
+		if candidate == nil || pool.evictable.Less(i, candidate.index) {
+			candidate = sender
+		}
+		if i == 0 {
+			break
+		}
+	}
+	if candidate == nil || !candidate.tail.message.GasPrice.LessThan(message.GasPrice) {
+		return cid.Undef, false
+	}
+	return candidate.cids[uint64(candidate.tail.message.Nonce)], true
+}
+
+// fixEvictable restores the position of sender in the eviction heap after its highest nonce
+// message has changed.
+func (pool *Pool) fixEvictable(sender *pendingSender) {
+	switch {
+	case sender.tail.local && sender.index >= 0:
+		heap.Remove(&pool.evictable, sender.index)
+	case sender.tail.local:
+	case sender.index >= 0:
+		heap.Fix(&pool.evictable, sender.index)
+	default:
+		heap.Push(&pool.evictable, sender)
+	}
+}
+
+// senderHeap implements heap.Interface to order senders by the gas price of their highest nonce
+// message, lowest first.
+type senderHeap []*pendingSender
+
+func (sh senderHeap) Len() int { return len(sh) }
+
+// Less implements Heap.Interface.Less to compare senders on gas price and address.
+func (sh senderHeap) Less(i, j int) bool {
+	pi, pj := sh[i].tail.message.GasPrice, sh[j].tail.message.GasPrice
+	if !pi.Equal(pj) {
+		return pi.LessThan(pj)
+	}
+	// Secondarily order by address to give a stable ordering.
+	return bytes.Compare(sh[i].from.Bytes(), sh[j].from.Bytes()) < 0
+}
+
+func (sh senderHeap) Swap(i, j int) {
+	sh[i], sh[j] = sh[j], sh[i]
+	sh[i].index = i
+	sh[j].index = j
+}
+
+func (sh *senderHeap) Push(x interface{}) {
+	sender := x.(*pendingSender)
+	sender.index = len(*sh)
+	*sh = append(*sh, sender)
+}
+
+func (sh *senderHeap) Pop() interface{} {
+	n := len(*sh)
+	sender := (*sh)[n-1]
+	sender.index = -1
+	*sh = (*sh)[0 : n-1]
+	return sender
+}
+
+// MinReplacementGasPrice returns the lowest gas price with which a message may replace a pending
+// message with gas price `price`, which is `percent` percent more than `price` and at least one
+// attoFIL more.
+func MinReplacementGasPrice(price types.AttoFIL, percent uint) types.AttoFIL {
+	premium := price.MulBigInt(big.NewInt(int64(percent))).DivCeil(types.NewAttoFIL(big.NewInt(100)))
+	if !premium.IsPositive() {
+		premium = types.NewAttoFIL(big.NewInt(1))
+	}
+	return price.Add(premium)
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -66,6 +67,7 @@ func TestMessagePoolValidate(t *testing.T) {
 		// pull the default size from the default config value
 		mpoolCfg := config.NewDefaultConfig().Mpool
 		maxMessagePoolSize := mpoolCfg.MaxPoolSize
+		mpoolCfg.MaxSenderMessages = maxMessagePoolSize + 1
 		ctx := context.Background()
 		pool := message.NewPool(mpoolCfg, th.NewMockMessagePoolValidator())
 
@@ -112,6 +114,208 @@ func TestMessagePoolValidate(t *testing.T) {
 	})
 }
 
+func TestMessagePoolReplaceByFee(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	pool := message.NewPool(config.NewDefaultConfig().Mpool, th.NewMockMessagePoolValidator())
+
+	withGasPrice := func(msg *types.SignedMessage, price int64) *types.SignedMessage {
+		smsg, err := types.NewSignedMessage(msg.Message, mockSigner, types.NewGasPrice(price), msg.GasLimit)
+		require.NoError(t, err)
+		return smsg
+	}
+
+	original := withGasPrice(newSignedMessage(), 100)
+	c1, err := pool.Add(ctx, original, 0)
+	require.NoError(t, err)
+
+	t.Log("a message paying less than the premium is rejected")
+	_, err = pool.Add(ctx, withGasPrice(newSignedMessage(), 109), 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "message with same actor and nonce")
+
+	t.Log("a message paying the premium replaces the pending message")
+	replacement := withGasPrice(newSignedMessage(), 110)
+	c2, err := pool.Add(ctx, replacement, 0)
+	require.NoError(t, err)
+
+	assert.Equal(t, []*types.SignedMessage{replacement}, pool.Pending())
+	_, ok := pool.Get(c1)
+	assert.False(t, ok)
+	_, ok = pool.Get(c2)
+	assert.True(t, ok)
+
+	t.Log("replacing does not count against the pool size")
+	cfg := config.NewDefaultConfig().Mpool
+	cfg.MaxPoolSize = 1
+	full := message.NewPool(cfg, th.NewMockMessagePoolValidator())
+	reqAdd(t, full, 0, original)
+	_, err = full.Add(ctx, replacement, 0)
+	require.NoError(t, err)
+	assert.Len(t, full.Pending(), 1)
+}
+
+func TestMessagePoolEviction(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	newPool := func() *message.Pool {
+		cfg := config.NewDefaultConfig().Mpool
+		cfg.MaxPoolSize = 3
+		return message.NewPool(cfg, th.NewMockMessagePoolValidator())
+	}
+
+	t.Run("evicts the message paying the lowest gas price", func(t *testing.T) {
+		pool := newPool()
+		cheap := newSenderMessage(t, 0, 0, 5)
+		reqAdd(t, pool, 0, newSenderMessage(t, 1, 0, 10), cheap, newSenderMessage(t, 2, 0, 20))
+
+		_, err := pool.Add(ctx, newSenderMessage(t, 3, 0, 5), 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "message pool is full")
+
+		expensive := newSenderMessage(t, 3, 0, 6)
+		_, err = pool.Add(ctx, expensive, 0)
+		require.NoError(t, err)
+
+		assert.Len(t, pool.Pending(), 3)
+		assert.Contains(t, pool.Pending(), expensive)
+		assert.NotContains(t, pool.Pending(), cheap)
+	})
+
+	t.Run("never creates nonce gaps", func(t *testing.T) {
+		pool := newPool()
+		first := newSenderMessage(t, 0, 0, 1)
+		second := newSenderMessage(t, 0, 1, 10)
+		reqAdd(t, pool, 0, first, second, newSenderMessage(t, 1, 0, 5))
+
+		_, err := pool.Add(ctx, newSenderMessage(t, 2, 0, 8), 0)
+		require.NoError(t, err)
+		assert.Contains(t, pool.Pending(), first)
+		assert.Contains(t, pool.Pending(), second)
+
+		t.Log("the messages of the sender itself are not evicted")
+		_, err = pool.Add(ctx, newSenderMessage(t, 2, 1, 9), 0)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "message pool is full")
+	})
+
+	t.Run("evicts the cheapest message of another sender", func(t *testing.T) {
+		pool := newPool()
+		evicted := newSenderMessage(t, 1, 0, 5)
+		reqAdd(t, pool, 0, newSenderMessage(t, 0, 0, 1), evicted, newSenderMessage(t, 2, 0, 3))
+
+		_, err := pool.Add(ctx, newSenderMessage(t, 0, 1, 10), 0)
+		require.NoError(t, err)
+		assert.Len(t, pool.Pending(), 3)
+		assert.NotContains(t, pool.Pending(), newSenderMessage(t, 2, 0, 3))
+		assert.Contains(t, pool.Pending(), evicted)
+	})
+
+	t.Run("evicts the next message of a sender once its last one is removed", func(t *testing.T) {
+		pool := newPool()
+		first := newSenderMessage(t, 0, 0, 1)
+		second := newSenderMessage(t, 0, 1, 10)
+		reqAdd(t, pool, 0, first, second, newSenderMessage(t, 1, 0, 5))
+
+		c, err := second.Cid()
+		require.NoError(t, err)
+		pool.Remove(c)
+		reqAdd(t, pool, 0, newSenderMessage(t, 2, 0, 3))
+
+		_, err = pool.Add(ctx, newSenderMessage(t, 3, 0, 2), 0)
+		require.NoError(t, err)
+		assert.Len(t, pool.Pending(), 3)
+		assert.NotContains(t, pool.Pending(), first)
+	})
+
+	t.Run("never evicts local messages", func(t *testing.T) {
+		pool := newPool()
+		local := newSenderMessage(t, 0, 0, 1)
+		_, err := pool.AddLocal(ctx, local, 0)
+		require.NoError(t, err)
+		reqAdd(t, pool, 0, newSenderMessage(t, 1, 0, 10), newSenderMessage(t, 2, 0, 20))
+
+		remote := newSenderMessage(t, 1, 0, 10)
+		_, err = pool.Add(ctx, newSenderMessage(t, 3, 0, 15), 0)
+		require.NoError(t, err)
+		assert.Contains(t, pool.Pending(), local)
+		assert.NotContains(t, pool.Pending(), remote)
+	})
+}
+
+func TestMessagePoolSenderLimit(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	cfg := config.NewDefaultConfig().Mpool
+	cfg.MaxSenderMessages = 2
+	pool := message.NewPool(cfg, th.NewMockMessagePoolValidator())
+
+	reqAdd(t, pool, 0, newSenderMessage(t, 0, 0, 1), newSenderMessage(t, 0, 1, 1), newSenderMessage(t, 1, 0, 1))
+
+	_, err := pool.Add(ctx, newSenderMessage(t, 0, 2, 1), 0)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "too many messages")
+
+	t.Log("replacing a message does not count against the limit")
+	_, err = pool.Add(ctx, newSenderMessage(t, 0, 1, 2), 0)
+	require.NoError(t, err)
+
+	t.Log("local messages are not limited")
+	_, err = pool.AddLocal(ctx, newSenderMessage(t, 0, 2, 1), 0)
+	require.NoError(t, err)
+	assert.Len(t, pool.Pending(), 4)
+
+	t.Log("messages re-added from reverted blocks are not limited")
+	_, err = pool.AddReverted(ctx, newSenderMessage(t, 0, 3, 1), 0)
+	require.NoError(t, err)
+	assert.Len(t, pool.Pending(), 5)
+}
+
+func TestMessagePoolSubscribe(t *testing.T) {
+	tf.UnitTest(t)
+
+	pool := message.NewPool(config.NewDefaultConfig().Mpool, th.NewMockMessagePoolValidator())
+	ch := pool.Subscribe()
+
+	original := newSenderMessage(t, 0, 0, 100)
+	replacement := newSenderMessage(t, 0, 0, 200)
+	other := newSenderMessage(t, 0, 1, 100)
+
+	reqAdd(t, pool, 0, original)
+	requireEvent(t, ch, message.MessageAdded, original)
+
+	t.Log("a replaced message is removed")
+	reqAdd(t, pool, 0, replacement)
+	requireEvent(t, ch, message.MessageRemoved, original)
+	requireEvent(t, ch, message.MessageAdded, replacement)
+
+	t.Log("adding a message already in the pool emits nothing")
+	reqAdd(t, pool, 0, replacement, other)
+	requireEvent(t, ch, message.MessageAdded, other)
+
+	c, err := other.Cid()
+	require.NoError(t, err)
+	pool.Remove(c)
+	requireEvent(t, ch, message.MessageRemoved, other)
+
+	t.Log("unsubscribing closes the channel")
+	pool.Unsubscribe(ch)
+	_, ok := <-ch
+	assert.False(t, ok)
+}
+
+func TestMinReplacementGasPrice(t *testing.T) {
+	tf.UnitTest(t)
+
+	assert.Equal(t, types.NewGasPrice(110), message.MinReplacementGasPrice(types.NewGasPrice(100), 10))
+	assert.Equal(t, types.NewGasPrice(13), message.MinReplacementGasPrice(types.NewGasPrice(11), 10))
+	assert.Equal(t, types.NewGasPrice(1), message.MinReplacementGasPrice(types.ZeroAttoFIL, 10))
+	assert.Equal(t, types.NewGasPrice(101), message.MinReplacementGasPrice(types.NewGasPrice(100), 0))
+}
+
 func TestMessagePoolDedup(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -130,6 +334,52 @@ func TestMessagePoolDedup(t *testing.T) {
 	assert.Len(t, pool.Pending(), 1)
 }
 
+func TestMessagePoolAddReverted(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	blsSigner := types.NewMockSigner(types.MustGenerateBLSKeyInfo(1))
+	msg := types.NewMessage(blsSigner.Addresses[0], address.TestAddress, 0, types.ZeroAttoFIL, "", nil)
+	signed, err := types.NewSignedMessage(*msg, blsSigner, types.NewGasPrice(1), types.NewGasUnits(0))
+	require.NoError(t, err)
+	// a block carries a BLS message without its signature
+	unsigned := &types.SignedMessage{MeteredMessage: signed.MeteredMessage}
+
+	t.Run("a signed copy supplies the signature of a reverted BLS message", func(t *testing.T) {
+		pool := message.NewPool(config.NewDefaultConfig().Mpool, th.NewMockMessagePoolValidator())
+
+		c, err := pool.AddReverted(ctx, unsigned, 0)
+		require.NoError(t, err)
+		pending, ok := pool.Get(c)
+		require.True(t, ok)
+		assert.Empty(t, pending.Signature)
+
+		c2, err := pool.Add(ctx, signed, 0)
+		require.NoError(t, err)
+		assert.Equal(t, c, c2)
+		pending, ok = pool.Get(c)
+		require.True(t, ok)
+		assert.Equal(t, signed.Signature, pending.Signature)
+		assert.Len(t, pool.Pending(), 1)
+	})
+
+	t.Run("a copy with an invalid signature does not", func(t *testing.T) {
+		pool := message.NewPool(config.NewDefaultConfig().Mpool, th.NewMockMessagePoolValidator())
+
+		c, err := pool.AddReverted(ctx, unsigned, 0)
+		require.NoError(t, err)
+
+		corrupted := &types.SignedMessage{MeteredMessage: signed.MeteredMessage, Signature: append(types.Signature{}, signed.Signature...)}
+		corrupted.Signature[0] ^= 0xFF
+		_, err = pool.Add(ctx, corrupted, 0)
+		assert.Error(t, err)
+
+		pending, ok := pool.Get(c)
+		require.True(t, ok)
+		assert.Empty(t, pending.Signature)
+	})
+}
+
 func TestMessagePoolAsync(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -138,6 +388,7 @@ func TestMessagePoolAsync(t *testing.T) {
 	count := uint(400)
 	mpoolCfg := config.NewDefaultConfig().Mpool
 	mpoolCfg.MaxPoolSize = count
+	mpoolCfg.MaxSenderMessages = count
 	msgs := types.NewSignedMsgs(count, mockSigner)
 
 	pool := message.NewPool(mpoolCfg, th.NewMockMessagePoolValidator())
@@ -227,6 +478,14 @@ func signMessage(signer types.Signer, message types.Message) (*types.SignedMessa
 	return types.NewSignedMessage(message, signer, types.NewGasPrice(0), types.NewGasUnits(0))
 }
 
+// newSenderMessage returns a message from the mock signer's address at index sender.
+func newSenderMessage(t *testing.T, sender int, nonce uint64, gasPrice int64) *types.SignedMessage {
+	msg := types.NewMessage(mockSigner.Addresses[sender], address.TestAddress, nonce, types.ZeroAttoFIL, "", nil)
+	smsg, err := types.NewSignedMessage(*msg, mockSigner, types.NewGasPrice(gasPrice), types.NewGasUnits(0))
+	require.NoError(t, err)
+	return smsg
+}
+
 func reqAdd(t *testing.T, p *message.Pool, height uint64, msgs ...*types.SignedMessage) {
 	ctx := context.Background()
 	for _, m := range msgs {
@@ -234,3 +493,20 @@ func reqAdd(t *testing.T, p *message.Pool, height uint64, msgs ...*types.SignedM
 		require.NoError(t, err)
 	}
 }
+
+// requireEvent requires that the next event on ch, a channel returned by Pool.Subscribe, is of
+// type eventType for msg.
+func requireEvent(t *testing.T, ch chan interface{}, eventType message.PoolEventType, msg *types.SignedMessage) {
+	c, err := msg.Cid()
+	require.NoError(t, err)
+
+	select {
+	case e := <-ch:
+		event := e.(*message.PoolEvent)
+		assert.Equal(t, eventType, event.Type)
+		assert.Equal(t, c, event.Cid)
+		assert.Equal(t, msg, event.Message)
+	case <-time.After(time.Second):
+		t.Fatalf("timed out waiting for %s event", eventType)
+	}
+}
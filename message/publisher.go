@@ -25,15 +25,15 @@ func NewDefaultPublisher(pubsub networkPublisher, topic string, pool *Pool) *Def
 	return &DefaultPublisher{pubsub, topic, pool}
 }
 
-// Publish marshals and publishes a message to the core message pool, and if bcast is true,
-// broadcasts it to the network with the publisher's topic.
+// Publish marshals and publishes a message to the core message pool as a local message, and
+// if bcast is true, broadcasts it to the network with the publisher's topic.
 func (p *DefaultPublisher) Publish(ctx context.Context, message *types.SignedMessage, height uint64, bcast bool) error {
 	encoded, err := message.Marshal()
 	if err != nil {
 		return errors.Wrap(err, "failed to marshal message")
 	}
 
-	if _, err := p.pool.Add(ctx, message, height); err != nil {
+	if _, err := p.pool.AddLocal(ctx, message, height); err != nil {
 		return errors.Wrap(err, "failed to add message to message pool")
 	}
 
@@ -4,6 +4,9 @@ import (
 	"context"
 	"sync"
 
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	cbor "github.com/ipfs/go-ipld-cbor"
 	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/go-filecoin/address"
@@ -17,17 +20,29 @@ var (
 	mqExpireCt = metrics.NewInt64Counter("message_queue_expire", "The number messages expired from the queue")
 )
 
+// QueueDatastorePrefix is the datastore prefix under which a persistent queue stores the
+// messages of each sender.
+const QueueDatastorePrefix = "outbox"
+
+func init() {
+	cbor.RegisterCborType(Queued{})
+}
+
 // Queue stores an ordered list of messages (per actor) and enforces that their nonces form a contiguous sequence.
 // Each message is associated with a "stamp" (an opaque integer), and the queue supports expiring any list
 // of messages where the first message has a stamp below some threshold. The relative order of stamps in a queue is
 // not enforced.
 // A message queue is intended to record outbound messages that have been transmitted but not yet appeared in a block,
 // where the stamp could be block height.
+// A persistent queue also writes the messages of each sender to a datastore whenever they change,
+// so that the queue survives restarts.
 // Queue is safe for concurrent access.
 type Queue struct {
 	lk sync.RWMutex
 	// Message queues keyed by sending actor address, in nonce order
 	queues map[address.Address][]*Queued
+	// Persists the queues, if non-nil
+	ds datastore.Datastore
 }
 
 // Queued is a message an the stamp it was enqueued with.
@@ -43,6 +58,33 @@ func NewQueue() *Queue {
 	}
 }
 
+// NewPersistentQueue constructs a queue that persists its messages to ds, loaded with the messages
+// previously persisted there.
+func NewPersistentQueue(ds datastore.Datastore) (*Queue, error) {
+	mq := NewQueue()
+	mq.ds = ds
+
+	results, err := ds.Query(query.Query{Prefix: "/" + QueueDatastorePrefix})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query queued messages")
+	}
+	defer results.Close() // nolint: errcheck
+
+	for entry := range results.Next() {
+		if entry.Error != nil {
+			return nil, errors.Wrap(entry.Error, "failed to read queued messages")
+		}
+		var q []*Queued
+		if err := cbor.DecodeInto(entry.Value, &q); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode queued messages at %s", entry.Key)
+		}
+		if len(q) > 0 {
+			mq.queues[q[0].Msg.From] = q
+		}
+	}
+	return mq, nil
+}
+
 // Enqueue appends a new message for an address. If the queue already contains any messages for
 // from same address, the new message's nonce must be exactly one greater than the largest nonce
 // present.
@@ -63,6 +105,7 @@ func (mq *Queue) Enqueue(ctx context.Context, msg *types.SignedMessage, stamp ui
 		}
 	}
 	mq.queues[msg.From] = append(q, &Queued{msg, stamp})
+	mq.persist(msg.From)
 	return nil
 }
 
@@ -85,9 +128,32 @@ func (mq *Queue) Requeue(ctx context.Context, msg *types.SignedMessage, stamp ui
 		}
 	}
 	mq.queues[msg.From] = append([]*Queued{{msg, stamp}}, q...)
+	mq.persist(msg.From)
 	return nil
 }
 
+// Replace swaps the queued message with the same sender and nonce as `msg` for `msg`, restamping
+// it with `stamp`. It returns the replaced message, or found = false if there is no such message.
+func (mq *Queue) Replace(ctx context.Context, msg *types.SignedMessage, stamp uint64) (replaced *types.SignedMessage, found bool) {
+	defer func() {
+		mqOldestGa.Set(ctx, int64(mq.Oldest()))
+	}()
+
+	mq.lk.Lock()
+	defer mq.lk.Unlock()
+
+	for _, qm := range mq.queues[msg.From] {
+		if qm.Msg.Nonce == msg.Nonce {
+			replaced = qm.Msg
+			qm.Msg = msg
+			qm.Stamp = stamp
+			mq.persist(msg.From)
+			return replaced, true
+		}
+	}
+	return nil, false
+}
+
 // RemoveNext removes and returns a single message from the queue, if it bears the expected nonce value, with found = true.
 // Returns found = false if the queue is empty or the expected nonce is less than any in the queue for that address
 // (indicating the message had already been removed).
@@ -107,6 +173,7 @@ func (mq *Queue) RemoveNext(ctx context.Context, sender address.Address, expecte
 		head := q[0]
 		if expectedNonce == uint64(head.Msg.Nonce) {
 			mq.queues[sender] = q[1:] // pop the head
+			mq.persist(sender)
 			msg = head.Msg
 			found = true
 		} else if expectedNonce > uint64(head.Msg.Nonce) {
@@ -130,6 +197,7 @@ func (mq *Queue) Clear(ctx context.Context, sender address.Address) bool {
 
 	q := mq.queues[sender]
 	delete(mq.queues, sender)
+	mq.persist(sender)
 	return len(q) > 0
 }
 
@@ -156,6 +224,7 @@ func (mq *Queue) ExpireBefore(ctx context.Context, stamp uint64) map[address.Add
 			}
 
 			mq.queues[sender] = []*Queued{}
+			mq.persist(sender)
 		}
 	}
 	return expired
@@ -235,3 +304,29 @@ func (mq *Queue) List(sender address.Address) []*Queued {
 	}
 	return out
 }
+
+// persist writes the messages queued for sender to the datastore, if the queue is persistent.
+// It must be called with the lock held.
+func (mq *Queue) persist(sender address.Address) {
+	if mq.ds == nil {
+		return
+	}
+
+	key := datastore.KeyWithNamespaces([]string{QueueDatastorePrefix, sender.String()})
+	q := mq.queues[sender]
+	if len(q) == 0 {
+		if err := mq.ds.Delete(key); err != nil && err != datastore.ErrNotFound {
+			log.Errorf("failed to delete queued messages for %s: %s", sender, err)
+		}
+		return
+	}
+
+	datum, err := cbor.DumpObject(q)
+	if err != nil {
+		log.Errorf("failed to encode queued messages for %s: %s", sender, err)
+		return
+	}
+	if err := mq.ds.Put(key, datum); err != nil {
+		log.Errorf("failed to persist queued messages for %s: %s", sender, err)
+	}
+}
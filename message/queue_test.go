@@ -5,6 +5,7 @@ import (
 	"math"
 	"testing"
 
+	"github.com/ipfs/go-datastore"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -233,6 +234,34 @@ func TestMessageQueue(t *testing.T) {
 		assertLargestNonce(q, alice, 1)
 	})
 
+	t.Run("replace", func(t *testing.T) {
+		msgs := []*types.SignedMessage{
+			mm.NewSignedMessage(alice, 0),
+			mm.NewSignedMessage(alice, 1),
+			mm.NewSignedMessage(alice, 1),
+			mm.NewSignedMessage(alice, 2),
+		}
+
+		q := message.NewQueue()
+		requireEnqueue(q, msgs[0], 0)
+		requireEnqueue(q, msgs[1], 0)
+
+		replaced, found := q.Replace(ctx, msgs[2], 5)
+		require.True(t, found)
+		assert.Equal(t, msgs[1], replaced)
+		assert.Equal(t, int64(2), q.Size())
+		assert.Equal(t, msgs[2], q.List(alice)[1].Msg)
+		assert.Equal(t, uint64(5), q.List(alice)[1].Stamp)
+
+		_, found = q.Replace(ctx, msgs[3], 5)
+		assert.False(t, found)
+		_, found = q.Replace(ctx, mm.NewSignedMessage(bob, 0), 5)
+		assert.False(t, found)
+
+		assert.Equal(t, msgs[0], requireRemoveNext(q, alice, 0))
+		assert.Equal(t, msgs[2], requireRemoveNext(q, alice, 1))
+	})
+
 	t.Run("independent addresses", func(t *testing.T) {
 		fromAlice := []*types.SignedMessage{
 			mm.NewSignedMessage(alice, 0),
@@ -325,6 +354,30 @@ func TestMessageQueue(t *testing.T) {
 		assertNoNonce(q, bob)
 	})
 
+	t.Run("persistent queue survives reload", func(t *testing.T) {
+		ds := datastore.NewMapDatastore()
+		q, err := message.NewPersistentQueue(ds)
+		require.NoError(t, err)
+
+		msgs := []*types.SignedMessage{
+			mm.NewSignedMessage(alice, 1),
+			mm.NewSignedMessage(alice, 2),
+			mm.NewSignedMessage(alice, 3),
+			mm.NewSignedMessage(bob, 1),
+		}
+		for i, msg := range msgs {
+			requireEnqueue(q, msg, uint64(i))
+		}
+		requireRemoveNext(q, alice, 1)
+		q.Clear(ctx, bob)
+
+		reloaded, err := message.NewPersistentQueue(ds)
+		require.NoError(t, err)
+		assert.Equal(t, q.List(alice), reloaded.List(alice))
+		assert.Empty(t, reloaded.List(bob))
+		assert.Equal(t, int64(2), reloaded.Size())
+	})
+
 	t.Run("oldest is correct", func(t *testing.T) {
 		fromAlice := []*types.SignedMessage{
 			mm.NewSignedMessage(alice, 0),
@@ -10,8 +10,10 @@ import (
 
 	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/types"
 	"github.com/filecoin-project/go-filecoin/vm"
+	wutil "github.com/filecoin-project/go-filecoin/wallet/util"
 )
 
 // Generate returns a new block created from the messages in the pool.
@@ -21,6 +23,9 @@ func (w *DefaultWorker) Generate(ctx context.Context,
 	electionProof types.VRFPi,
 	nullBlockCount uint64) (*types.Block, error) {
 
+	w.gcLock.Lock()
+	defer w.gcLock.Unlock()
+
 	generateTimer := time.Now()
 	defer func() {
 		log.Infof("[TIMER] DefaultWorker.Generate baseTipset: %s - elapsed time: %s", baseTipSet.String(), time.Since(generateTimer).Round(time.Millisecond))
@@ -57,9 +62,8 @@ func (w *DefaultWorker) Generate(ctx context.Context,
 		return nil, errors.Wrap(err, "get base tip set ancestors")
 	}
 
-	pending := w.messageSource.Pending()
-	mq := NewMessageQueue(pending)
-	messages := mq.Drain()
+	pending := signedMessages(w.messageSource.Pending())
+	messages := PackMessages(pending, types.BlockGasLimit)
 
 	vms := vm.NewStorageMap(w.blockstore)
 	res, err := w.processor.ApplyMessagesAndPayRewards(ctx, stateTree, vms, messages, w.minerOwnerAddr, types.NewBlockHeight(blockHeight), ancestors)
@@ -83,9 +87,9 @@ func (w *DefaultWorker) Generate(ctx context.Context,
 		receipts = append(receipts, r.Receipt)
 	}
 
-	minedMessages := []*types.SignedMessage{}
-	for _, msg := range res.SuccessfulMessages {
-		minedMessages = append(minedMessages, msg)
+	minedMessages, blsAggregateSig, err := aggregateBLSSignatures(res.SuccessfulMessages)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to aggregate BLS signatures")
 	}
 
 	// Persist messages to ipld storage
@@ -109,6 +113,7 @@ func (w *DefaultWorker) Generate(ctx context.Context,
 		StateRoot:       newStateTreeCid,
 		Tickets:         tickets,
 		Timestamp:       types.Uint64(w.clock.Now().Unix()),
+		BLSAggregateSig: blsAggregateSig,
 	}
 	workerAddr, err := w.api.MinerGetWorkerAddress(ctx, w.minerAddr, baseTipSet.Key())
 	if err != nil {
@@ -142,3 +147,45 @@ func (w *DefaultWorker) Generate(ctx context.Context,
 
 	return next, nil
 }
+
+// signedMessages returns the messages that carry a signature. A BLS message re-added to the
+// pool from a reverted block has no signature, since the block held only the aggregate, so
+// it cannot be mined until a signed copy of it reaches the pool.
+func signedMessages(messages []*types.SignedMessage) []*types.SignedMessage {
+	signed := make([]*types.SignedMessage, 0, len(messages))
+	for _, msg := range messages {
+		if len(msg.Signature) > 0 {
+			signed = append(signed, msg)
+		}
+	}
+	return signed
+}
+
+// aggregateBLSSignatures returns the messages to store in a block along with the
+// aggregate of the signatures of the BLS-signed messages. BLS-signed messages are stored
+// without their signatures, other messages keep theirs. The aggregate signature is nil
+// if there are no BLS-signed messages.
+func aggregateBLSSignatures(messages []*types.SignedMessage) ([]*types.SignedMessage, types.Signature, error) {
+	// By default no messages is serialized as the zero length slice, not the nil slice.
+	stored := []*types.SignedMessage{}
+	var blsSigs [][]byte
+	for _, msg := range messages {
+		if msg.From.Protocol() != address.BLS {
+			stored = append(stored, msg)
+			continue
+		}
+
+		blsSigs = append(blsSigs, msg.Signature)
+		stored = append(stored, &types.SignedMessage{MeteredMessage: msg.MeteredMessage})
+	}
+
+	if len(blsSigs) == 0 {
+		return stored, nil, nil
+	}
+
+	aggregate, err := wutil.AggregateBLS(blsSigs)
+	if err != nil {
+		return nil, nil, err
+	}
+	return stored, aggregate, nil
+}
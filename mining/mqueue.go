@@ -14,10 +14,8 @@ import (
 // always in increasing nonce order.
 // All messages for a queue are inserted at construction, after which messages may only
 // be popped.
-// Potential improvements include:
-// - deprioritising messages after a gap in nonce value, which can never be mined (see Ethereum)
-// - attempting to pack messages into a fixed gas limit (i.e. 0/1 knapsack subject to nonce ordering),
-//   see https://en.wikipedia.org/wiki/Knapsack_problem
+// Block generation orders messages with PackMessages instead, which packs them into the block
+// gas limit; the greedy MessageQueue serves as a baseline for it.
 type MessageQueue struct {
 	// A heap of nonce-ordered queues, one per sender.
 	senderQueues queueHeap
@@ -0,0 +1,191 @@
+package mining
+
+import (
+	"bytes"
+	"container/heap"
+	"math/big"
+	"sort"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+// PackMessages orders msgs for inclusion in a block with gas limit gasLimit, aiming to maximise
+// the fees the miner collects, subject to the constraint that messages from a single actor are
+// always in increasing nonce order. A message is valued at its gas price times its gas limit,
+// the most it can pay.
+//
+// Packing this into the gas limit is a 0/1 knapsack subject to nonce ordering, which PackMessages
+// approximates. It splits the messages of each actor into chunks, each the run of following
+// messages paying the highest fee per unit of gas, and selects chunks by decreasing fee per
+// unit of gas. When a chunk does not fit in the remaining gas, the messages of its actor are
+// trimmed to those that fit and split into chunks again. Messages after a gap in the nonces of
+// their actor, which cannot be mined until the gap is filled, are deprioritised.
+//
+// Messages that did not fit follow those selected, in nonce order for each sender in the order
+// the senders were trimmed, and messages after a nonce gap come last,
+// so that all of msgs are returned; the processor may still include some of them if messages
+// use less than their gas limit.
+func PackMessages(msgs []*types.SignedMessage, gasLimit types.GasUnits) []*types.SignedMessage {
+	// Group messages by sender, splitting off any messages after a nonce gap.
+	bySender := make(map[address.Address]nonceQueue)
+	for _, m := range msgs {
+		bySender[m.From] = append(bySender[m.From], m)
+	}
+	var chunks chunkHeap
+	var gapped []nonceQueue
+	for _, nq := range bySender {
+		sort.Slice(nq, func(i, j int) bool { return nq[i].Nonce < nq[j].Nonce })
+		runnable := 1
+		for runnable < len(nq) && nq[runnable].Nonce == nq[runnable-1].Nonce+1 {
+			runnable++
+		}
+		chunks = append(chunks, splitChunks(nq[:runnable]))
+		if runnable < len(nq) {
+			gapped = append(gapped, nq[runnable:])
+		}
+	}
+	heap.Init(&chunks)
+
+	// Select chunks with the best fee per unit of gas while they fit. The chunks of a sender
+	// never improve on the one before, so a sender's next chunk only competes once its
+	// previous chunk is selected.
+	var packed []*types.SignedMessage
+	// The messages of a sender may be trimmed several times, each time dropping messages
+	// before those dropped the time before, so collect them by sender.
+	unpacked := make(map[address.Address]nonceQueue)
+	var trimmed []address.Address
+	remaining := gasLimit
+	for len(chunks) > 0 {
+		senderChunks := heap.Pop(&chunks).([]*msgChunk)
+		if chunk := senderChunks[0]; chunk.gas <= remaining {
+			packed = append(packed, chunk.msgs...)
+			remaining -= chunk.gas
+			if len(senderChunks) > 1 {
+				heap.Push(&chunks, senderChunks[1:])
+			}
+			continue
+		}
+
+		// The chunk does not fit. Trim the messages of the sender to those that fit in the
+		// remaining gas, which none of its later messages may follow, and split them into
+		// chunks again.
+		var rest nonceQueue
+		for _, chunk := range senderChunks {
+			rest = append(rest, chunk.msgs...)
+		}
+		fits, gas := 0, types.NewGasUnits(0)
+		for fits < len(rest) && gas+rest[fits].GasLimit <= remaining {
+			gas += rest[fits].GasLimit
+			fits++
+		}
+		from := rest[0].From
+		if _, ok := unpacked[from]; !ok {
+			trimmed = append(trimmed, from)
+		}
+		unpacked[from] = append(rest[fits:len(rest):len(rest)], unpacked[from]...)
+		if fits > 0 {
+			heap.Push(&chunks, splitChunks(rest[:fits]))
+		}
+	}
+
+	// Order messages after nonce gaps by sender for a stable ordering.
+	sort.Slice(gapped, func(i, j int) bool {
+		return bytes.Compare(gapped[i][0].From.Bytes(), gapped[j][0].From.Bytes()) < 0
+	})
+	out := packed
+	for _, from := range trimmed {
+		out = append(out, unpacked[from]...)
+	}
+	for _, nq := range gapped {
+		out = append(out, nq...)
+	}
+	return out
+}
+
+// A msgChunk is a run of consecutive messages from a single sender, selected together.
+type msgChunk struct {
+	msgs []*types.SignedMessage
+	// gas is the sum of the gas limits of the messages.
+	gas types.GasUnits
+	// fee and weight are the sum of the value and the gas limit of the messages, counting
+	// messages with no gas limit as one unit of gas so that they are ordered by gas price.
+	fee    *big.Int
+	weight *big.Int
+}
+
+func newMsgChunk() *msgChunk {
+	return &msgChunk{fee: big.NewInt(0), weight: big.NewInt(0)}
+}
+
+func (c *msgChunk) add(msg *types.SignedMessage) {
+	weight := uint64(msg.GasLimit)
+	if weight == 0 {
+		weight = 1
+	}
+	c.msgs = append(c.msgs, msg)
+	c.gas += msg.GasLimit
+	c.fee.Add(c.fee, new(big.Int).Mul(msg.GasPrice.AsBigInt(), new(big.Int).SetUint64(weight)))
+	c.weight.Add(c.weight, new(big.Int).SetUint64(weight))
+}
+
+// denser tests whether c pays at least as much fee per unit of gas as o.
+func (c *msgChunk) denser(o *msgChunk) bool {
+	return new(big.Int).Mul(c.fee, o.weight).Cmp(new(big.Int).Mul(o.fee, c.weight)) >= 0
+}
+
+// splitChunks splits a nonce-ordered queue into chunks, each the longest run of the following
+// messages with the highest fee per unit of gas. The fee per unit of gas of the chunks is
+// non-increasing.
+func splitChunks(nq nonceQueue) []*msgChunk {
+	var chunks []*msgChunk
+	for len(nq) > 0 {
+		best := newMsgChunk()
+		best.add(nq[0])
+		prefix := newMsgChunk()
+		for _, msg := range nq {
+			prefix.add(msg)
+			if prefix.denser(best) {
+				best = &msgChunk{
+					msgs:   prefix.msgs[:len(prefix.msgs):len(prefix.msgs)],
+					gas:    prefix.gas,
+					fee:    new(big.Int).Set(prefix.fee),
+					weight: new(big.Int).Set(prefix.weight),
+				}
+			}
+		}
+		chunks = append(chunks, best)
+		nq = nq[len(best.msgs):]
+	}
+	return chunks
+}
+
+// Implements heap.Interface to hold a priority queue of the chunks of each sender, ordered by
+// the fee per unit of gas of the first chunk of each sender.
+type chunkHeap [][]*msgChunk
+
+func (ch chunkHeap) Len() int { return len(ch) }
+
+func (ch chunkHeap) Less(i, j int) bool {
+	ci, cj := ch[i][0], ch[j][0]
+	if ci.denser(cj) != cj.denser(ci) {
+		return ci.denser(cj)
+	}
+	// Secondarily order by address to give a stable ordering.
+	return bytes.Compare(ci.msgs[0].From.Bytes(), cj.msgs[0].From.Bytes()) < 0
+}
+
+func (ch chunkHeap) Swap(i, j int) {
+	ch[i], ch[j] = ch[j], ch[i]
+}
+
+func (ch *chunkHeap) Push(x interface{}) {
+	*ch = append(*ch, x.([]*msgChunk))
+}
+
+func (ch *chunkHeap) Pop() interface{} {
+	n := len(*ch)
+	item := (*ch)[n-1]
+	*ch = (*ch)[0 : n-1]
+	return item
+}
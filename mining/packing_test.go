@@ -0,0 +1,186 @@
+package mining
+
+import (
+	"math/big"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestPackMessages(t *testing.T) {
+	tf.UnitTest(t)
+
+	var ki = types.MustGenerateKeyInfo(10, 42)
+	var mockSigner = types.NewMockSigner(ki)
+
+	a0 := mockSigner.Addresses[0]
+	a1 := mockSigner.Addresses[2]
+	a2 := mockSigner.Addresses[3]
+	to := mockSigner.Addresses[9]
+
+	sign := func(from address.Address, nonce uint64, units uint64, price int64) *types.SignedMessage {
+		msg := types.Message{
+			From:  from,
+			To:    to,
+			Nonce: types.Uint64(nonce),
+		}
+		s, err := types.NewSignedMessage(msg, &mockSigner, types.NewGasPrice(price), types.NewGasUnits(units))
+		require.NoError(t, err)
+		return s
+	}
+
+	t.Run("empty", func(t *testing.T) {
+		assert.Empty(t, PackMessages([]*types.SignedMessage{}, types.BlockGasLimit))
+	})
+
+	t.Run("orders by nonce", func(t *testing.T) {
+		msgs := []*types.SignedMessage{
+			sign(a0, 1, 10, 9),
+			sign(a1, 3, 10, 1),
+			sign(a0, 0, 10, 1),
+			sign(a1, 2, 10, 9),
+			sign(a2, 0, 10, 5),
+		}
+
+		packed := PackMessages(msgs, types.BlockGasLimit)
+		assert.Len(t, packed, len(msgs))
+		lastFromAddr := make(map[address.Address]uint64)
+		for _, msg := range packed {
+			last, seen := lastFromAddr[msg.From]
+			if seen {
+				assert.True(t, last < uint64(msg.Nonce))
+			}
+			lastFromAddr[msg.From] = uint64(msg.Nonce)
+		}
+	})
+
+	t.Run("packs valuable messages behind cheap ones", func(t *testing.T) {
+		msgs := []*types.SignedMessage{
+			sign(a0, 0, 10, 1),
+			sign(a0, 1, 10, 100), // Only worth including along with the previous message
+			sign(a1, 0, 10, 40),
+			sign(a2, 0, 10, 30),
+		}
+
+		greedy := NewMessageQueue(msgs)
+		assert.Equal(t, []*types.SignedMessage{msgs[2], msgs[3]}, greedy.Drain()[:2])
+
+		packed := PackMessages(msgs, types.NewGasUnits(20))
+		assert.Equal(t, []*types.SignedMessage{msgs[0], msgs[1], msgs[2], msgs[3]}, packed)
+	})
+
+	t.Run("trims messages that do not fit", func(t *testing.T) {
+		msgs := []*types.SignedMessage{
+			sign(a0, 0, 60, 10),
+			sign(a1, 0, 30, 9),
+			sign(a1, 1, 30, 9),
+			sign(a2, 0, 30, 5),
+		}
+
+		packed := PackMessages(msgs, types.NewGasUnits(100))
+		assert.Equal(t, []*types.SignedMessage{msgs[0], msgs[1], msgs[2], msgs[3]}, packed)
+		assert.Equal(t, big.NewInt(870), packedFees(packed, types.NewGasUnits(100)))
+	})
+
+	t.Run("keeps the nonce order of a sender trimmed twice", func(t *testing.T) {
+		msgs := []*types.SignedMessage{
+			sign(a0, 0, 4, 10),
+			sign(a0, 1, 4, 1),
+			sign(a0, 2, 4, 100),
+			sign(a1, 0, 2, 50),
+			sign(a2, 0, 2, 20),
+		}
+
+		// The messages of a0 are first trimmed to the first two, whose second is trimmed
+		// once a2 is selected.
+		packed := PackMessages(msgs, types.NewGasUnits(10))
+		assert.Equal(t, []*types.SignedMessage{msgs[3], msgs[4], msgs[0], msgs[1], msgs[2]}, packed)
+	})
+
+	t.Run("deprioritises messages after a nonce gap", func(t *testing.T) {
+		msgs := []*types.SignedMessage{
+			sign(a0, 0, 10, 1),
+			sign(a0, 2, 10, 100),
+			sign(a1, 0, 10, 2),
+		}
+
+		packed := PackMessages(msgs, types.BlockGasLimit)
+		assert.Equal(t, []*types.SignedMessage{msgs[2], msgs[0], msgs[1]}, packed)
+	})
+}
+
+func TestPackMessagesEarnsAtLeastGreedy(t *testing.T) {
+	tf.UnitTest(t)
+
+	msgs := randomMessages(t, 20, 500, 1)
+	gasLimit := types.NewGasUnits(200000)
+
+	greedy := NewMessageQueue(msgs)
+	greedyFees := packedFees(greedy.Drain(), gasLimit)
+	packFees := packedFees(PackMessages(msgs, gasLimit), gasLimit)
+	assert.True(t, packFees.Cmp(greedyFees) >= 0, "packed fees %s below greedy fees %s", packFees, greedyFees)
+}
+
+func BenchmarkMessageQueueDrain(b *testing.B) {
+	msgs := randomMessages(b, 100, 2000, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		q := NewMessageQueue(msgs)
+		q.Drain()
+	}
+}
+
+func BenchmarkPackMessages(b *testing.B) {
+	msgs := randomMessages(b, 100, 2000, 1)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		PackMessages(msgs, types.BlockGasLimit)
+	}
+}
+
+// randomMessages returns count messages from senders actors, in nonce sequences, with random
+// gas prices and limits.
+func randomMessages(t testing.TB, senders int, count int, seed int64) []*types.SignedMessage {
+	mockSigner := types.NewMockSigner(types.MustGenerateKeyInfo(senders, 42))
+	rnd := rand.New(rand.NewSource(seed))
+
+	nonces := make([]uint64, senders)
+	msgs := make([]*types.SignedMessage, count)
+	for i := range msgs {
+		sender := rnd.Intn(senders)
+		msg := types.Message{
+			From:  mockSigner.Addresses[sender],
+			To:    address.TestAddress,
+			Nonce: types.Uint64(nonces[sender]),
+		}
+		nonces[sender]++
+
+		price := types.NewGasPrice(rnd.Int63n(1000))
+		limit := types.NewGasUnits(uint64(rnd.Int63n(20000)))
+		smsg, err := types.NewSignedMessage(msg, &mockSigner, price, limit)
+		require.NoError(t, err)
+		msgs[i] = smsg
+	}
+	return msgs
+}
+
+// packedFees returns the fees collected from applying msgs in order to a block with the given
+// gas limit, assuming each message uses all its gas, as the processor does.
+func packedFees(msgs []*types.SignedMessage, gasLimit types.GasUnits) *big.Int {
+	fees := big.NewInt(0)
+	var used types.GasUnits
+	for _, msg := range msgs {
+		if used+msg.GasLimit > gasLimit {
+			continue
+		}
+		used += msg.GasLimit
+		fees.Add(fees, msg.GasPrice.MulBigInt(new(big.Int).SetUint64(uint64(msg.GasLimit))).AsBigInt())
+	}
+	return fees
+}
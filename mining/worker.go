@@ -6,6 +6,7 @@ package mining
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/ipfs/go-cid"
@@ -106,6 +107,7 @@ type DefaultWorker struct {
 	processor     MessageApplier
 	messageStore  chain.MessageWriter // nolint: structcheck
 	blockstore    blockstore.Blockstore
+	gcLock        sync.Locker
 	clock         clock.Clock
 }
 
@@ -129,11 +131,19 @@ type WorkerParameters struct {
 	Processor     MessageApplier
 	MessageStore  chain.MessageWriter
 	Blockstore    blockstore.Blockstore
-	Clock         clock.Clock
+	// GCLock is held while a block is generated, so that garbage collection of Blockstore does
+	// not delete blocks of the state being written. If nil, generation does not wait for
+	// garbage collection.
+	GCLock sync.Locker
+	Clock  clock.Clock
 }
 
 // NewDefaultWorker instantiates a new Worker.
 func NewDefaultWorker(parameters WorkerParameters) *DefaultWorker {
+	gcLock := parameters.GCLock
+	if gcLock == nil {
+		gcLock = &sync.Mutex{}
+	}
 	return &DefaultWorker{
 		api:            parameters.API,
 		getStateTree:   parameters.GetStateTree,
@@ -143,6 +153,7 @@ func NewDefaultWorker(parameters WorkerParameters) *DefaultWorker {
 		messageStore:   parameters.MessageStore,
 		processor:      parameters.Processor,
 		blockstore:     parameters.Blockstore,
+		gcLock:         gcLock,
 		minerAddr:      parameters.MinerAddr,
 		minerOwnerAddr: parameters.MinerOwnerAddr,
 		workerSigner:   parameters.WorkerSigner,
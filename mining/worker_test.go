@@ -3,6 +3,7 @@ package mining_test
 import (
 	"context"
 	"errors"
+	"sync"
 	"testing"
 	"time"
 
@@ -246,7 +247,7 @@ func TestApplyMessagesForSuccessTempAndPermFailures(t *testing.T) {
 
 	messages := []*types.SignedMessage{smsg1, smsg2, smsg3, smsg4}
 
-	res, err := consensus.NewDefaultProcessor().ApplyMessagesAndPayRewards(ctx, st, vms, messages, addr1, types.NewBlockHeight(0), nil)
+	res, err := consensus.NewTestProcessor().ApplyMessagesAndPayRewards(ctx, st, vms, messages, addr1, types.NewBlockHeight(0), nil)
 	require.NotNil(t, res)
 
 	assert.Len(t, res.PermanentFailures, 2)
@@ -351,7 +352,7 @@ func TestGeneratePoolBlockResults(t *testing.T) {
 		TicketGen:    &consensus.FakeTicketMachine{},
 
 		MessageSource: pool,
-		Processor:     consensus.NewDefaultProcessor(),
+		Processor:     consensus.NewTestProcessor(),
 		Blockstore:    bs,
 		MessageStore:  messages,
 		Clock:         th.NewFakeClock(time.Unix(1234567890, 0)),
@@ -424,6 +425,102 @@ func TestGeneratePoolBlockResults(t *testing.T) {
 	assert.Len(t, rcpts, 1)
 }
 
+func TestGenerateAggregatesBLSSignatures(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	kis := append(types.MustGenerateKeyInfo(10, 42), types.MustGenerateBLSKeyInfo(2)...)
+	mockSigner := types.NewMockSigner(kis)
+	blockSignerAddr := mockSigner.Addresses[9]
+	blsAddrs := mockSigner.Addresses[10:]
+	newCid := types.NewCidForTestGetter()
+	st, pool, addrs, cst, bs := sharedSetup(t, mockSigner)
+
+	vms := th.VMStorage()
+	for _, addr := range blsAddrs {
+		require.NoError(t, st.SetActor(ctx, addr, th.RequireNewFakeActor(t, vms, addr, types.AccountActorCodeCid)))
+	}
+	stateRoot, err := st.Flush(ctx)
+	require.NoError(t, err)
+
+	getStateTree := func(c context.Context, ts types.TipSet) (state.Tree, error) {
+		return st, nil
+	}
+	getAncestors := func(ctx context.Context, ts types.TipSet, newBlockHeight *types.BlockHeight) ([]types.TipSet, error) {
+		return nil, nil
+	}
+
+	messages := chain.NewMessageStore(cst)
+
+	worker := mining.NewDefaultWorker(mining.WorkerParameters{
+		API: th.NewDefaultFakeWorkerPorcelainAPI(blockSignerAddr),
+
+		MinerAddr:      addrs[4],
+		MinerOwnerAddr: addrs[3],
+		WorkerSigner:   mockSigner,
+
+		GetStateTree: getStateTree,
+		GetWeight:    getWeightTest,
+		GetAncestors: getAncestors,
+		Election:     &consensus.FakeElectionMachine{},
+		TicketGen:    &consensus.FakeTicketMachine{},
+
+		MessageSource: pool,
+		Processor:     consensus.NewTestProcessor(),
+		Blockstore:    bs,
+		MessageStore:  messages,
+		Clock:         th.NewFakeClock(time.Unix(1234567890, 0)),
+	})
+
+	var pooled []*types.SignedMessage
+	for _, from := range []address.Address{addrs[0], blsAddrs[0], blsAddrs[1]} {
+		msg := types.NewMessage(from, addrs[1], 0, types.ZeroAttoFIL, "", nil)
+		smsg, err := types.NewSignedMessage(*msg, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
+		require.NoError(t, err)
+		_, err = pool.Add(ctx, smsg, 0)
+		require.NoError(t, err)
+		pooled = append(pooled, smsg)
+	}
+
+	baseBlock := types.Block{
+		Parents:       types.NewTipSetKey(newCid()),
+		Height:        types.Uint64(100),
+		StateRoot:     stateRoot,
+		ElectionProof: consensus.MakeFakeElectionProofForTest(),
+	}
+	blk, err := worker.Generate(ctx, th.RequireNewTipSet(t, &baseBlock), []types.Ticket{{VRFProof: []byte{0}}}, consensus.MakeFakeElectionProofForTest(), 0)
+	require.NoError(t, err)
+	assert.NotEmpty(t, blk.BLSAggregateSig)
+
+	msgs, err := messages.LoadMessages(ctx, blk.Messages)
+	require.NoError(t, err)
+	require.Len(t, msgs, 3)
+
+	for _, msg := range msgs {
+		if msg.From.Protocol() == address.BLS {
+			assert.Empty(t, msg.Signature)
+		} else {
+			assert.NotEmpty(t, msg.Signature)
+		}
+	}
+
+	// Messages keep the CIDs they had in the pool.
+	for _, smsg := range pooled {
+		expected, err := smsg.Cid()
+		require.NoError(t, err)
+		found := false
+		for _, msg := range msgs {
+			c, err := msg.Cid()
+			require.NoError(t, err)
+			found = found || c.Equals(expected)
+		}
+		assert.True(t, found)
+	}
+
+	validator := consensus.NewTestBlockValidator(th.BlockTimeTest, th.NewFakeClock(time.Unix(1234567890, 0)))
+	assert.NoError(t, validator.ValidateMessagesSemantic(ctx, blk, msgs))
+}
+
 func TestGenerateSetsBasicFields(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -458,7 +555,7 @@ func TestGenerateSetsBasicFields(t *testing.T) {
 		TicketGen:    &consensus.FakeTicketMachine{},
 
 		MessageSource: pool,
-		Processor:     consensus.NewDefaultProcessor(),
+		Processor:     consensus.NewTestProcessor(),
 		Blockstore:    bs,
 		MessageStore:  messages,
 		Clock:         th.NewFakeClock(time.Unix(1234567890, 0)),
@@ -520,7 +617,7 @@ func TestGenerateWithoutMessages(t *testing.T) {
 		TicketGen:    &consensus.FakeTicketMachine{},
 
 		MessageSource: pool,
-		Processor:     consensus.NewDefaultProcessor(),
+		Processor:     consensus.NewTestProcessor(),
 		Blockstore:    bs,
 		MessageStore:  messages,
 		Clock:         th.NewFakeClock(time.Unix(1234567890, 0)),
@@ -542,6 +639,73 @@ func TestGenerateWithoutMessages(t *testing.T) {
 	assert.Equal(t, types.ReceiptCollection{}.Cid(), blk.MessageReceipts)
 }
 
+func TestGenerateWaitsForGarbageCollection(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	mockSigner, blockSignerAddr := setupSigner()
+	newCid := types.NewCidForTestGetter()
+
+	st, pool, addrs, cst, bs := sharedSetup(t, mockSigner)
+	getStateTree := func(c context.Context, ts types.TipSet) (state.Tree, error) {
+		return st, nil
+	}
+	getAncestors := func(ctx context.Context, ts types.TipSet, newBlockHeight *types.BlockHeight) ([]types.TipSet, error) {
+		return nil, nil
+	}
+
+	var gcLock sync.RWMutex
+	worker := mining.NewDefaultWorker(mining.WorkerParameters{
+		API: th.NewDefaultFakeWorkerPorcelainAPI(blockSignerAddr),
+
+		MinerAddr:      addrs[4],
+		MinerOwnerAddr: addrs[3],
+		WorkerSigner:   mockSigner,
+
+		GetStateTree: getStateTree,
+		GetWeight:    getWeightTest,
+		GetAncestors: getAncestors,
+		Election:     &consensus.FakeElectionMachine{},
+		TicketGen:    &consensus.FakeTicketMachine{},
+
+		MessageSource: pool,
+		Processor:     consensus.NewTestProcessor(),
+		Blockstore:    bs,
+		MessageStore:  chain.NewMessageStore(cst),
+		GCLock:        gcLock.RLocker(),
+		Clock:         th.NewFakeClock(time.Unix(1234567890, 0)),
+	})
+
+	baseBlock := types.Block{
+		Parents:       types.NewTipSetKey(newCid()),
+		Height:        types.Uint64(100),
+		StateRoot:     newCid(),
+		ElectionProof: consensus.MakeFakeElectionProofForTest(),
+	}
+
+	// Hold the lock as garbage collection does.
+	gcLock.Lock()
+	done := make(chan error, 1)
+	go func() {
+		_, err := worker.Generate(ctx, th.RequireNewTipSet(t, &baseBlock), []types.Ticket{{VRFProof: []byte{0}}}, consensus.MakeFakeElectionProofForTest(), 0)
+		done <- err
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("generated a block during garbage collection")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	gcLock.Unlock()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("timed out waiting for block generation")
+	}
+}
+
 // If something goes wrong while generating a new block, even as late as when flushing it,
 // no block should be returned, and the message pool should not be pruned.
 func TestGenerateError(t *testing.T) {
@@ -572,7 +736,7 @@ func TestGenerateError(t *testing.T) {
 		TicketGen:    &consensus.FakeTicketMachine{},
 
 		MessageSource: pool,
-		Processor:     consensus.NewDefaultProcessor(),
+		Processor:     consensus.NewTestProcessor(),
 		Blockstore:    bs,
 		MessageStore:  messages,
 		Clock:         th.NewFakeClock(time.Unix(1234567890, 0)),
@@ -62,7 +62,7 @@ func TestBlockPubSubValidation(t *testing.T) {
 	blocktime := time.Second * 1
 
 	// setup a block validator and a topic validator
-	bv := consensus.NewDefaultBlockValidator(blocktime, mclock)
+	bv := consensus.NewTestBlockValidator(blocktime, mclock)
 	btv := net.NewBlockTopicValidator(bv)
 
 	// setup a floodsub instance on the host and register the topic validator
@@ -1,6 +1,8 @@
 package node
 
 import (
+	"sync"
+
 	bserv "github.com/ipfs/go-blockservice"
 	"github.com/ipfs/go-hamt-ipld"
 	bstore "github.com/ipfs/go-ipfs-blockstore"
@@ -20,6 +22,10 @@ type BlockstoreSubmodule struct {
 	// Blockstore is the un-networked blocks interface
 	Blockstore bstore.Blockstore
 
+	// GCLock is held for writing while garbage is collected from Blockstore and for reading while
+	// a block is generated, so that collection does not delete blocks of the state being written.
+	GCLock *sync.RWMutex
+
 	// cborStore is a temporary interface for interacting with IPLD objects.
 	cborStore *hamt.CborIpldStore
 }
@@ -2,6 +2,7 @@ package node
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/ipfs/go-bitswap"
@@ -32,6 +33,7 @@ import (
 
 	"github.com/filecoin-project/go-filecoin/chain"
 	"github.com/filecoin-project/go-filecoin/clock"
+	"github.com/filecoin-project/go-filecoin/config"
 	"github.com/filecoin-project/go-filecoin/consensus"
 	"github.com/filecoin-project/go-filecoin/message"
 	"github.com/filecoin-project/go-filecoin/net"
@@ -158,6 +160,7 @@ func (nc *Builder) build(ctx context.Context) (*Node, error) {
 	nc.Libp2pOpts = append(nc.Libp2pOpts, libp2p.BandwidthReporter(bandwidthTracker))
 
 	ipldCborStore := hamt.CborIpldStore{Blocks: bserv.New(bs, offline.Exchange(bs))}
+	gcLock := &sync.RWMutex{}
 	genCid, err := readGenesisCid(nc.Repo.Datastore())
 	if err != nil {
 		return nil, err
@@ -167,14 +170,20 @@ func (nc *Builder) build(ctx context.Context) (*Node, error) {
 	// set up chain and message stores
 	chainStore := chain.NewStore(nc.Repo.ChainDatastore(), &ipldCborStore, &state.TreeStateLoader{}, chainStatusReporter, genCid)
 	messageStore := chain.NewMessageStore(&ipldCborStore)
-	chainState := cst.NewChainStateReadWriter(chainStore, messageStore, &ipldCborStore)
-	actorState := consensus.NewActorStateStore(chainStore, &ipldCborStore, bs)
+	chainState := cst.NewChainStateReadWriter(chainStore, messageStore, &ipldCborStore, bs)
 
-	// create protocol upgrade table
-	network, err := networkNameFromGenesis(ctx, chainStore, actorState)
+	// create protocol upgrade table. The network name is read from the genesis state, which is
+	// always queried at the initial protocol version.
+	genesisState := consensus.NewActorStateStore(chainStore, &ipldCborStore, bs, version.NewSingleVersionTable(version.Protocol0))
+	network, err := networkNameFromGenesis(ctx, chainStore, genesisState)
 	if err != nil {
 		return nil, err
 	}
+	protocolVersions, err := version.ConfigureProtocolVersions(network)
+	if err != nil {
+		return nil, err
+	}
+	actorState := consensus.NewActorStateStore(chainStore, &ipldCborStore, bs, protocolVersions)
 
 	if !nc.OfflineMode {
 		makeDHT := func(h host.Host) (routing.Routing, error) {
@@ -207,7 +216,7 @@ func (nc *Builder) build(ctx context.Context) (*Node, error) {
 
 	// setup block validation
 	// TODO when #2961 is resolved do the needful here.
-	blkValid := consensus.NewDefaultBlockValidator(nc.BlockTime, nc.Clock)
+	blkValid := consensus.NewDefaultBlockValidator(nc.BlockTime, nc.Clock, protocolVersions)
 
 	// set up peer tracking
 	peerTracker := net.NewPeerTracker(peerHost.ID())
@@ -225,18 +234,12 @@ func (nc *Builder) build(ctx context.Context) (*Node, error) {
 	gsync := graphsync.New(ctx, graphsyncNetwork, bridge, loader, storer)
 	fetcher := net.NewGraphSyncFetcher(ctx, gsync, bs, blkValid, nc.Clock, peerTracker)
 
-	// TODO: inject protocol upgrade table into code that requires it (#3360)
-	_, err = version.ConfigureProtocolVersions(network)
-	if err != nil {
-		return nil, err
-	}
-
 	// set up processor
 	var processor consensus.Processor
 	if nc.Rewarder == nil {
-		processor = consensus.NewDefaultProcessor()
+		processor = consensus.NewDefaultProcessor(protocolVersions)
 	} else {
-		processor = consensus.NewConfiguredProcessor(consensus.NewDefaultMessageValidator(), nc.Rewarder)
+		processor = consensus.NewConfiguredProcessor(consensus.NewBlockMessageValidator(), nc.Rewarder, protocolVersions)
 	}
 
 	// set up consensus
@@ -255,18 +258,21 @@ func (nc *Builder) build(ctx context.Context) (*Node, error) {
 		return nil, errors.Wrap(err, "failed to register block validator")
 	}
 
-	backend, err := wallet.NewDSBackend(nc.Repo.WalletDatastore())
+	backends, err := newWalletBackends(nc.Repo.WalletDatastore(), nc.Repo.Config().Wallet)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to set up wallet backend")
 	}
-	fcWallet := wallet.New(backend)
+	fcWallet := wallet.New(backends...)
 
 	// only the syncer gets the storage which is online connected
 	chainSyncer := chain.NewSyncer(nodeConsensus, chainStore, messageStore, fetcher, chainStatusReporter, nc.Clock)
 	msgPool := message.NewPool(nc.Repo.Config().Mpool, consensus.NewIngestionValidator(chainState, nc.Repo.Config().Mpool))
 	inbox := message.NewInbox(msgPool, message.InboxMaxAgeTipsets, chainStore, messageStore)
 
-	msgQueue := message.NewQueue()
+	msgQueue, err := message.NewPersistentQueue(nc.Repo.Datastore())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load outbound message queue")
+	}
 	outboxPolicy := message.NewMessageQueuePolicy(messageStore, message.OutboxMaxAgeRounds)
 	msgPublisher := message.NewDefaultPublisher(pubsub.NewPublisher(fsub), net.MessageTopic(network), msgPool)
 	outbox := message.NewOutbox(fcWallet, consensus.NewOutboundMessageValidator(), msgQueue, msgPublisher, outboxPolicy, chainStore, chainState)
@@ -276,11 +282,12 @@ func (nc *Builder) build(ctx context.Context) (*Node, error) {
 		OfflineMode: nc.OfflineMode,
 		Repo:        nc.Repo,
 		Network: NetworkSubmodule{
-			host:        peerHost,
-			PeerHost:    peerHost,
-			NetworkName: network,
-			PeerTracker: peerTracker,
-			Router:      router,
+			host:          peerHost,
+			PeerHost:      peerHost,
+			NetworkName:   network,
+			PeerTracker:   peerTracker,
+			Router:        router,
+			GraphExchange: gsync,
 		},
 		Wallet: WalletSubmodule{
 			Wallet: fcWallet,
@@ -292,18 +299,20 @@ func (nc *Builder) build(ctx context.Context) (*Node, error) {
 		Blockstore: BlockstoreSubmodule{
 			blockservice: bservice,
 			Blockstore:   bs,
+			GCLock:       gcLock,
 			cborStore:    &ipldCborStore,
 		},
 		StorageNetworking: StorageNetworkingSubmodule{
 			Exchange: bswap,
 		},
 		Chain: ChainSubmodule{
-			Fetcher:      fetcher,
-			Consensus:    nodeConsensus,
-			ChainReader:  chainStore,
-			ChainSynced:  moresync.NewLatch(1),
-			MessageStore: messageStore,
-			Syncer:       chainSyncer,
+			Fetcher:          fetcher,
+			Consensus:        nodeConsensus,
+			ProtocolVersions: protocolVersions,
+			ChainReader:      chainStore,
+			ChainSynced:      moresync.NewLatch(1),
+			MessageStore:     messageStore,
+			Syncer:           chainSyncer,
 		},
 	}
 
@@ -315,10 +324,11 @@ func (nc *Builder) build(ctx context.Context) (*Node, error) {
 		DAG:           dag.NewDAG(merkledag.NewDAGService(bservice)),
 		Deals:         strgdls.New(nc.Repo.DealsDatastore()),
 		Expected:      nodeConsensus,
+		GCLock:        gcLock,
 		MsgPool:       msgPool,
-		MsgPreviewer:  msg.NewPreviewer(chainStore, &ipldCborStore, bs),
+		MsgPreviewer:  msg.NewPreviewer(chainStore, &ipldCborStore, bs, protocolVersions),
 		ActState:      actorState,
-		MsgWaiter:     msg.NewWaiter(chainStore, messageStore, bs, &ipldCborStore),
+		MsgWaiter:     msg.NewWaiter(chainStore, messageStore, bs, &ipldCborStore, protocolVersions),
 		Network:       net.New(peerHost, pubsub.NewPublisher(fsub), pubsub.NewSubscriber(fsub), net.NewRouter(router), bandwidthTracker, net.NewPinger(peerHost, pingService)),
 		Outbox:        outbox,
 		SectorBuilder: nd.SectorBuilder,
@@ -394,3 +404,42 @@ func (nc *Builder) buildHost(ctx context.Context, makeDHT func(host host.Host) (
 		libp2p.ChainOptions(nc.Libp2pOpts...),
 	)
 }
+
+// newWalletBackends opens the wallet datastore with the HD backend if it derives its keys
+// from a seed, with the encrypted backend if its keys have been encrypted, and with the
+// plaintext datastore backend otherwise. The HD backend handles encrypted datastores
+// itself. An encrypted wallet starts out locked. If a remote
+// signer is configured, its backend is added as well.
+func newWalletBackends(store repo.Datastore, walletCfg *config.WalletConfig) ([]wallet.Backend, error) {
+	encrypted, err := wallet.IsEncrypted(store)
+	if err != nil {
+		return nil, err
+	}
+	hd, err := wallet.IsHD(store)
+	if err != nil {
+		return nil, err
+	}
+
+	var local wallet.Backend
+	switch {
+	case hd:
+		local, err = wallet.NewHDBackend(store)
+	case encrypted:
+		local, err = wallet.NewEncryptedBackend(store)
+	default:
+		local, err = wallet.NewDSBackend(store)
+	}
+	if err != nil {
+		return nil, err
+	}
+	backends := []wallet.Backend{local}
+
+	if walletCfg.RemoteSignerAPI != "" {
+		remote, err := wallet.NewRemoteBackend(walletCfg.RemoteSignerAPI, walletCfg.RemoteAddresses)
+		if err != nil {
+			return nil, err
+		}
+		backends = append(backends, remote)
+	}
+	return backends, nil
+}
@@ -8,16 +8,19 @@ import (
 	"github.com/filecoin-project/go-filecoin/net"
 	"github.com/filecoin-project/go-filecoin/net/pubsub"
 	"github.com/filecoin-project/go-filecoin/util/moresync"
+	"github.com/filecoin-project/go-filecoin/version"
 )
 
 // ChainSubmodule enhances the `Node` with chain capabilities.
 type ChainSubmodule struct {
-	BlockSub     pubsub.Subscription
-	Consensus    consensus.Protocol
-	ChainReader  nodeChainReader
-	MessageStore *chain.MessageStore
-	Syncer       nodeChainSyncer
-	PowerTable   consensus.PowerTableView
+	BlockSub  pubsub.Subscription
+	Consensus consensus.Protocol
+	// ProtocolVersions determines the protocol version in effect at each height of the chain.
+	ProtocolVersions *version.ProtocolVersionTable
+	ChainReader      nodeChainReader
+	MessageStore     *chain.MessageStore
+	Syncer           nodeChainSyncer
+	PowerTable       consensus.PowerTableView
 	// HeavyTipSetCh is a subscription to the heaviest tipset topic on the chain.
 	// https://github.com/filecoin-project/go-filecoin/issues/2309
 	HeaviestTipSetCh chan interface{}
@@ -22,4 +22,7 @@ type NetworkSubmodule struct {
 
 	// Router is a router from IPFS
 	Router routing.Routing
+
+	// GraphExchange is the graphsync exchange shared by chain sync and DAG retrieval.
+	GraphExchange net.GraphExchange
 }
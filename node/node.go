@@ -103,6 +103,12 @@ func (node *Node) Start(ctx context.Context) error {
 		return err
 	}
 
+	// Messages sent before the node last stopped that have yet to be mined are no longer in the
+	// message pool.
+	if err := node.Messaging.Outbox.Republish(ctx); err != nil {
+		return errors.Wrap(err, "failed to republish queued messages")
+	}
+
 	// Only set these up if there is a miner configured.
 	if _, err := node.MiningAddress(); err == nil {
 		if err := node.setupMining(ctx); err != nil {
@@ -117,7 +123,7 @@ func (node *Node) Start(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to set up protocols:")
 	}
-	node.RetrievalProtocol.RetrievalMiner = retrieval.NewMiner(node)
+	node.RetrievalProtocol.RetrievalMiner = retrieval.NewMiner(node, node.PorcelainAPI, node.RetrievalProtocol.Payments)
 
 	var syncCtx context.Context
 	syncCtx, node.Chain.cancelChainSync = context.WithCancel(context.Background())
@@ -129,6 +135,10 @@ func (node *Node) Start(ctx context.Context) error {
 	}
 	go node.handleNewChainHeads(syncCtx, head)
 
+	if err := node.setupGarbageCollection(syncCtx); err != nil {
+		return errors.Wrap(err, "failed to set up garbage collection")
+	}
+
 	if !node.OfflineMode {
 		// Start bootstrapper.
 		node.Network.Bootstrapper.Start(context.Background())
@@ -209,6 +219,39 @@ func (node *Node) Start(ctx context.Context) error {
 	return nil
 }
 
+// setupGarbageCollection periodically deletes old chain data from the repo, if a garbage
+// collection period is configured.
+func (node *Node) setupGarbageCollection(ctx context.Context) error {
+	cfg := node.Repo.Config().Datastore
+	if cfg.GCPeriod == "" {
+		log.Debug("periodic garbage collection is disabled")
+		return nil
+	}
+	period, err := time.ParseDuration(cfg.GCPeriod)
+	if err != nil {
+		return errors.Wrapf(err, "invalid garbage collection period %q", cfg.GCPeriod)
+	}
+
+	go func() {
+		ticker := time.NewTicker(period)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				deleted, err := node.PorcelainAPI.ChainCollectGarbage(ctx, cfg.GCKeepDepth)
+				if err != nil {
+					log.Errorf("garbage collection failed: %s", err)
+					continue
+				}
+				log.Infof("garbage collection removed %d blocks", deleted)
+			}
+		}
+	}()
+	return nil
+}
+
 // Subscribes a handler function to a pubsub topic.
 func (node *Node) pubsubscribe(ctx context.Context, topic string, handler pubSubHandler) (pubsub.Subscription, error) {
 	sub, err := node.PorcelainAPI.PubSubSubscribe(topic)
@@ -503,11 +546,8 @@ func (node *Node) StartMining(ctx context.Context) error {
 					log.Errorf("failed to seal sector with id %d: %s", result.SectorID, result.SealingErr.Error())
 				} else if result.SealingResult != nil {
 
-					// TODO: determine these algorithmically by simulating call and querying historical prices
-					gasPrice := types.NewGasPrice(1)
-					gasUnits := types.NewGasUnits(300)
-
 					val := result.SealingResult
+					params := []interface{}{val.SectorID, val.CommD[:], val.CommR[:], val.CommRStar[:], val.Proof[:]}
 
 					// look up miner worker address. If this fails, something is really wrong
 					// so we bail and don't commit sectors.
@@ -517,6 +557,18 @@ func (node *Node) StartMining(ctx context.Context) error {
 						continue
 					}
 
+					// The gas used grows with the size of the miner's state, which commitSector rewrites.
+					gasEstimate, err := node.PorcelainAPI.MessageEstimateGas(miningCtx, workerAddr, minerAddr, "commitSector", params...)
+					if err != nil {
+						log.Errorf("failed to estimate gas for commitSector message for sector with id %d: %s", val.SectorID, err)
+						continue
+					}
+					// Messages must pay a positive gas price, which the estimate is not when recent blocks are empty.
+					gasPrice := gasEstimate.GasPrice
+					if !gasPrice.GreaterThan(types.ZeroAttoFIL) {
+						gasPrice = types.NewGasPrice(1)
+					}
+
 					// This call can fail due to, e.g. nonce collisions. Our miners existence depends on this.
 					// We should deal with this, but MessageSendWithRetry is problematic.
 					msgCid, err := node.PorcelainAPI.MessageSend(
@@ -525,13 +577,9 @@ func (node *Node) StartMining(ctx context.Context) error {
 						minerAddr,
 						types.ZeroAttoFIL,
 						gasPrice,
-						gasUnits,
+						gasEstimate.GasLimit,
 						"commitSector",
-						val.SectorID,
-						val.CommD[:],
-						val.CommR[:],
-						val.CommRStar[:],
-						val.Proof[:],
+						params...,
 					)
 
 					if err != nil {
@@ -740,7 +788,11 @@ func (node *Node) setupProtocols() error {
 	node.BlockMining.BlockMiningAPI = &blockMiningAPI
 
 	// set up retrieval client and api
-	retapi := retrieval.NewAPI(retrieval.NewClient(node.Network.host, node.PorcelainAPI))
+	node.RetrievalProtocol.Payments = retrieval.NewPaymentStore(node.Repo.DealsDatastore())
+	retapi := retrieval.NewAPI(
+		retrieval.NewClient(node.Network.host, node.PorcelainAPI, node.RetrievalProtocol.Payments),
+		retrieval.NewDAGClient(node.Network.GraphExchange, node.Blockstore.Blockstore),
+	)
 	node.RetrievalProtocol.RetrievalAPI = &retapi
 
 	// set up storage client and api
@@ -761,7 +813,7 @@ func (node *Node) GetMiningWorker(ctx context.Context) (mining.Worker, error) {
 // CreateMiningWorker creates a mining.Worker for the node using the configured
 // getStateTree, getWeight, and getAncestors functions for the node
 func (node *Node) CreateMiningWorker(ctx context.Context) (mining.Worker, error) {
-	processor := consensus.NewDefaultProcessor()
+	processor := consensus.NewDefaultProcessor(node.Chain.ProtocolVersions)
 
 	minerAddr, err := node.MiningAddress()
 	if err != nil {
@@ -790,6 +842,7 @@ func (node *Node) CreateMiningWorker(ctx context.Context) (mining.Worker, error)
 		MessageStore:  node.Chain.MessageStore,
 		Processor:     processor,
 		Blockstore:    node.Blockstore.Blockstore,
+		GCLock:        node.Blockstore.GCLock.RLocker(),
 		Clock:         node.Clock,
 	}), nil
 }
@@ -6,6 +6,9 @@ import "github.com/filecoin-project/go-filecoin/protocol/retrieval"
 type RetrievalProtocolSubmodule struct {
 	RetrievalAPI *retrieval.API
 
+	// Payments records the vouchers of paid retrievals, as client or miner.
+	Payments *retrieval.PaymentStore
+
 	// Retrieval Interfaces
 	RetrievalMiner *retrieval.Miner
 }
@@ -52,13 +52,16 @@ func (a *NodeAPI) Run(ctx context.Context) (client *Client, stop func()) {
 	addr, err := a.node.Repo.APIAddr()
 	require.NoError(a.tb, err)
 	require.NotEmpty(a.tb, addr, "empty API address")
+	token, err := a.node.Repo.APIToken()
+	require.NoError(a.tb, err)
 
-	return &Client{addr, a.tb}, func() { close(terminate) }
+	return &Client{addr, token, a.tb}, func() { close(terminate) }
 }
 
 // Client is an in-process client to a command API.
 type Client struct {
 	address string
+	token   string
 	tb      testing.TB
 }
 
@@ -73,6 +76,7 @@ func (c *Client) Run(ctx context.Context, command ...string) *th.CmdOutput {
 	args := []string{
 		"go-filecoin", // A dummy first arg is required, simulating shell invocation.
 		fmt.Sprintf("--cmdapiaddr=%s", c.address),
+		fmt.Sprintf("--token=%s", c.token),
 	}
 	args = append(args, command...)
 
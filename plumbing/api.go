@@ -3,6 +3,7 @@ package plumbing
 import (
 	"context"
 	"io"
+	"sync"
 	"time"
 
 	"github.com/ipfs/go-bitswap"
@@ -33,6 +34,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/protocol/storage/storagedeal"
 	"github.com/filecoin-project/go-filecoin/state"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/vm"
 	"github.com/filecoin-project/go-filecoin/wallet"
 )
 
@@ -50,6 +52,7 @@ type API struct {
 	config        *cfg.Config
 	dag           *dag.DAG
 	expected      consensus.Protocol
+	gcLock        *sync.RWMutex
 	msgPool       *message.Pool
 	msgPreviewer  *msg.Previewer
 	actorState    *consensus.ActorStateStore
@@ -71,6 +74,7 @@ type APIDeps struct {
 	DAG           *dag.DAG
 	Deals         *strgdls.Store
 	Expected      consensus.Protocol
+	GCLock        *sync.RWMutex
 	MsgPool       *message.Pool
 	MsgPreviewer  *msg.Previewer
 	MsgWaiter     *msg.Waiter
@@ -92,6 +96,7 @@ func New(deps *APIDeps) *API {
 		config:        deps.Config,
 		dag:           deps.DAG,
 		expected:      deps.Expected,
+		gcLock:        deps.GCLock,
 		msgPool:       deps.MsgPool,
 		msgPreviewer:  deps.MsgPreviewer,
 		msgWaiter:     deps.MsgWaiter,
@@ -176,6 +181,47 @@ func (api *API) ChainLs(ctx context.Context) (*chain.TipsetIterator, error) {
 	return api.chain.Ls(ctx)
 }
 
+// ChainExport writes a snapshot of the chain ending at the tipset with key `key` to out as a CAR
+// file, including the state trees of the recentStates most recent tipsets.
+func (api *API) ChainExport(ctx context.Context, key types.TipSetKey, recentStates uint, out io.Writer) error {
+	return api.chain.ExportSnapshot(ctx, key, recentStates, out)
+}
+
+// ChainImport loads a chain snapshot written by ChainExport and sets the chain head to its head,
+// trusting the snapshot without validating it. The snapshot head must be heavier than the chain
+// head. It returns the new head.
+func (api *API) ChainImport(ctx context.Context, in io.Reader) (types.TipSet, error) {
+	return api.chain.ImportSnapshot(ctx, api.expected, in)
+}
+
+// ChainCollectGarbage deletes the states of the tipsets more than keepDepth tipsets below the
+// chain head from the blockstore, except for blocks still reachable from the more recent
+// tipsets. Chain sync and block generation are paused meanwhile, so that no state is written
+// while old states are deleted. It returns the number of blocks deleted.
+func (api *API) ChainCollectGarbage(ctx context.Context, keepDepth uint) (int, error) {
+	var deleted int
+	err := api.syncer.WhilePaused(func() error {
+		api.gcLock.Lock()
+		defer api.gcLock.Unlock()
+
+		var err error
+		deleted, err = api.chain.CollectGarbage(ctx, keepDepth)
+		return err
+	})
+	return deleted, err
+}
+
+// ChainSubscribeHeads returns a channel on which each new head tipset is published.
+// The subscriber must consume the channel until it is closed by ChainUnsubscribeHeads.
+func (api *API) ChainSubscribeHeads() chan interface{} {
+	return api.chain.SubscribeHeads()
+}
+
+// ChainUnsubscribeHeads stops publishing new heads on a channel returned by ChainSubscribeHeads.
+func (api *API) ChainUnsubscribeHeads(ch chan interface{}) {
+	api.chain.UnsubscribeHeads(ch)
+}
+
 // ChainSampleRandomness produces a slice of random bytes sampled from a TipSet
 // in the blockchain at a given height, useful for things like PoSt challenge seed
 // generation.
@@ -234,6 +280,18 @@ func (api *API) MessagePoolRemove(cid cid.Cid) {
 	api.msgPool.Remove(cid)
 }
 
+// MessagePoolSubscribe returns a channel on which the message pool emits a *message.PoolEvent
+// each time a message is added to or removed from the pool.
+func (api *API) MessagePoolSubscribe() chan interface{} {
+	return api.msgPool.Subscribe()
+}
+
+// MessagePoolUnsubscribe stops the message pool emitting events on a channel returned by
+// MessagePoolSubscribe.
+func (api *API) MessagePoolUnsubscribe(ch chan interface{}) {
+	api.msgPool.Unsubscribe(ch)
+}
+
 // MessagePreview previews the Gas cost of a message by running it locally on the client and
 // recording the amount of Gas used.
 func (api *API) MessagePreview(ctx context.Context, from, to address.Address, method string, params ...interface{}) (types.GasUnits, error) {
@@ -264,6 +322,12 @@ func (api *API) MessageSend(ctx context.Context, from, to address.Address, value
 	return api.outbox.Send(ctx, from, to, value, gasPrice, gasLimit, true, method, params...)
 }
 
+// MessageReplace replaces a message this node sent, which has yet to be mined, with a copy paying
+// a higher gas price, and broadcasts the copy to the network. It returns the CID of the copy.
+func (api *API) MessageReplace(ctx context.Context, msgCid cid.Cid, gasPrice types.AttoFIL) (cid.Cid, error) {
+	return api.outbox.Replace(ctx, msgCid, gasPrice, true)
+}
+
 // MessageFind returns a message and receipt from the blockchain, if it exists.
 func (api *API) MessageFind(ctx context.Context, msgCid cid.Cid) (*msg.ChainMessage, bool, error) {
 	return api.msgWaiter.Find(ctx, msgCid)
@@ -278,6 +342,12 @@ func (api *API) MessageWait(ctx context.Context, msgCid cid.Cid, cb func(*types.
 	return api.msgWaiter.Wait(ctx, msgCid, cb)
 }
 
+// MessageTrace re-executes the tipset in which the message with msgCid appears and returns a
+// trace of the message's execution, including the messages that it sent.
+func (api *API) MessageTrace(ctx context.Context, msgCid cid.Cid) (*vm.ExecutionTrace, error) {
+	return api.msgWaiter.Trace(ctx, msgCid)
+}
+
 // PubSubSubscribe subscribes to a topic for notifications from the filecoin network
 func (api *API) PubSubSubscribe(topic string) (pubsub.Subscription, error) {
 	return api.network.Subscribe(topic)
@@ -353,9 +423,9 @@ func (api *API) WalletGetPubKeyForAddress(addr address.Address) ([]byte, error)
 	return api.wallet.GetPubKeyForAddress(addr)
 }
 
-// WalletNewAddress generates a new wallet address
-func (api *API) WalletNewAddress() (address.Address, error) {
-	return wallet.NewAddress(api.wallet)
+// WalletNewAddress generates a new wallet address using the given protocol
+func (api *API) WalletNewAddress(protocol address.Protocol) (address.Address, error) {
+	return wallet.NewAddress(api.wallet, protocol)
 }
 
 // WalletImport adds a given set of KeyInfos to the wallet
@@ -363,6 +433,33 @@ func (api *API) WalletImport(kinfos ...*types.KeyInfo) ([]address.Address, error
 	return api.wallet.Import(kinfos...)
 }
 
+// WalletLock locks the encrypted wallet, after which its keys cannot be used to sign
+func (api *API) WalletLock() error {
+	return api.wallet.Lock()
+}
+
+// WalletUnlock unlocks the encrypted wallet with its passphrase. A positive timeout
+// locks the wallet again after that long.
+func (api *API) WalletUnlock(passphrase []byte, timeout time.Duration) error {
+	return api.wallet.Unlock(passphrase, timeout)
+}
+
+// WalletEncrypt encrypts the keys of a plaintext wallet under a passphrase
+func (api *API) WalletEncrypt(passphrase []byte) error {
+	return api.wallet.Encrypt(passphrase)
+}
+
+// WalletInitHD turns the wallet into an HD wallet with the seed of a mnemonic, generating
+// the mnemonic if it is empty, and derives its first count addresses
+func (api *API) WalletInitHD(mnemonic string, count int) (string, []address.Address, error) {
+	return api.wallet.InitHD(mnemonic, count)
+}
+
+// WalletDerivationPath returns the path an address of an HD wallet was derived along
+func (api *API) WalletDerivationPath(addr address.Address) (string, bool) {
+	return api.wallet.DerivationPath(addr)
+}
+
 // WalletExport returns the KeyInfos for the given wallet addresses
 func (api *API) WalletExport(addrs []address.Address) ([]*types.KeyInfo, error) {
 	return api.wallet.Export(addrs)
@@ -3,9 +3,12 @@ package cst
 import (
 	"context"
 	"fmt"
+	"io"
 
+	"github.com/cskr/pubsub"
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-hamt-ipld"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
 	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/go-filecoin/actor"
@@ -21,7 +24,17 @@ type chainReadWriter interface {
 	GetHead() types.TipSetKey
 	GetTipSet(types.TipSetKey) (types.TipSet, error)
 	GetTipSetState(context.Context, types.TipSetKey) (state.Tree, error)
+	GetTipSetStateRoot(types.TipSetKey) (cid.Cid, error)
+	PutTipSetAndState(context.Context, *chain.TipSetAndState) error
+	PutTipSetWithoutState(context.Context, types.TipSet) error
 	SetHead(context.Context, types.TipSet) error
+	HeadEvents() *pubsub.PubSub
+	GenesisCid() cid.Cid
+}
+
+// chainWeigher compares the weights of tipsets applied to the given parent states.
+type chainWeigher interface {
+	IsHeavier(ctx context.Context, a, b types.TipSet, aStateID, bStateID cid.Cid) (bool, error)
 }
 
 // ChainStateReadWriter composes a:
@@ -30,6 +43,7 @@ type chainReadWriter interface {
 type ChainStateReadWriter struct {
 	readWriter      chainReadWriter
 	cst             *hamt.CborIpldStore // Provides chain blocks and state trees.
+	bstore          bstore.Blockstore   // Holds the blocks of cst, for snapshots.
 	messageProvider chain.MessageProvider
 }
 
@@ -44,10 +58,11 @@ var (
 )
 
 // NewChainStateReadWriter returns a new ChainStateReadWriter.
-func NewChainStateReadWriter(crw chainReadWriter, messages chain.MessageProvider, cst *hamt.CborIpldStore) *ChainStateReadWriter {
+func NewChainStateReadWriter(crw chainReadWriter, messages chain.MessageProvider, cst *hamt.CborIpldStore, bs bstore.Blockstore) *ChainStateReadWriter {
 	return &ChainStateReadWriter{
 		readWriter:      crw,
 		cst:             cst,
+		bstore:          bs,
 		messageProvider: messages,
 	}
 }
@@ -71,6 +86,18 @@ func (chn *ChainStateReadWriter) Ls(ctx context.Context) (*chain.TipsetIterator,
 	return chain.IterAncestors(ctx, chn.readWriter, ts), nil
 }
 
+// SubscribeHeads returns a channel on which each new head tipset is published.
+// The subscriber must consume the channel until it is closed by UnsubscribeHeads.
+func (chn *ChainStateReadWriter) SubscribeHeads() chan interface{} {
+	return chn.readWriter.HeadEvents().Sub(chain.NewHeadTopic)
+}
+
+// UnsubscribeHeads stops publishing new heads on a channel returned by SubscribeHeads, and
+// closes it.
+func (chn *ChainStateReadWriter) UnsubscribeHeads(ch chan interface{}) {
+	chn.readWriter.HeadEvents().Unsub(ch, chain.NewHeadTopic)
+}
+
 // GetBlock gets a block by CID
 func (chn *ChainStateReadWriter) GetBlock(ctx context.Context, id cid.Cid) (*types.Block, error) {
 	var out types.Block
@@ -172,3 +199,31 @@ func (chn *ChainStateReadWriter) SetHead(ctx context.Context, key types.TipSetKe
 	}
 	return chn.readWriter.SetHead(ctx, headTs)
 }
+
+// ExportSnapshot writes a snapshot of the chain ending at the tipset with key `key` to out as a
+// CAR file, including the state trees of the recentStates most recent tipsets.
+func (chn *ChainStateReadWriter) ExportSnapshot(ctx context.Context, key types.TipSetKey, recentStates uint, out io.Writer) error {
+	head, err := chn.readWriter.GetTipSet(key)
+	if err != nil {
+		return err
+	}
+	return chain.ExportSnapshot(ctx, chn.readWriter, chn.bstore, head, recentStates, out)
+}
+
+// ImportSnapshot loads a snapshot written by ExportSnapshot and sets the chain head to its head,
+// trusting the snapshot without validating it, if its head is heavier than the chain head as
+// weighed by weigher. It returns the new head.
+func (chn *ChainStateReadWriter) ImportSnapshot(ctx context.Context, weigher chainWeigher, in io.Reader) (types.TipSet, error) {
+	return chain.ImportSnapshot(ctx, chn.readWriter, weigher, chn.bstore, in)
+}
+
+// CollectGarbage deletes the states of the tipsets more than keepDepth tipsets below the chain
+// head, except for blocks still reachable from the more recent tipsets. No state may be written
+// while it runs. It returns the number of blocks deleted.
+func (chn *ChainStateReadWriter) CollectGarbage(ctx context.Context, keepDepth uint) (int, error) {
+	head, err := chn.readWriter.GetTipSet(chn.readWriter.GetHead())
+	if err != nil {
+		return 0, err
+	}
+	return chain.CollectGarbage(ctx, chn.readWriter, chn.bstore, head, keepDepth)
+}
@@ -10,6 +10,7 @@ import (
 type chainSync interface {
 	HandleNewTipSet(context.Context, *types.ChainInfo, bool) error
 	Status() chain.Status
+	WhilePaused(f func() error) error
 }
 
 // ChainSyncProvider provides access to chain sync operations and their status.
@@ -37,3 +38,9 @@ func (chs *ChainSyncProvider) Status() chain.Status {
 func (chs *ChainSyncProvider) HandleNewTipSet(ctx context.Context, ci *types.ChainInfo, trusted bool) error {
 	return chs.sync.HandleNewTipSet(ctx, ci, trusted)
 }
+
+// WhilePaused runs f while no chain is being synced, so that no tipset is processed and no
+// state is written while it runs.
+func (chs *ChainSyncProvider) WhilePaused(f func() error) error {
+	return chs.sync.WhilePaused(f)
+}
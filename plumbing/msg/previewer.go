@@ -12,6 +12,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/consensus"
 	"github.com/filecoin-project/go-filecoin/state"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
 	"github.com/filecoin-project/go-filecoin/vm"
 )
 
@@ -30,11 +31,13 @@ type Previewer struct {
 	cst *hamt.CborIpldStore
 	// For vm storage.
 	bs bstore.Blockstore
+	// To get the protocol version at the head.
+	protocolVersions *version.ProtocolVersionTable
 }
 
 // NewPreviewer constructs a Previewer.
-func NewPreviewer(chainReader previewerChainReader, cst *hamt.CborIpldStore, bs bstore.Blockstore) *Previewer {
-	return &Previewer{chainReader, cst, bs}
+func NewPreviewer(chainReader previewerChainReader, cst *hamt.CborIpldStore, bs bstore.Blockstore, pvt *version.ProtocolVersionTable) *Previewer {
+	return &Previewer{chainReader, cst, bs, pvt}
 }
 
 // Preview sends a read-only message to an actor.
@@ -57,8 +60,14 @@ func (p *Previewer) Preview(ctx context.Context, optFrom, to address.Address, me
 		return types.NewGasUnits(0), errors.Wrap(err, "failed to get head tipset height")
 	}
 
+	bh := types.NewBlockHeight(h)
+	protocolVersion, err := p.protocolVersions.VersionAt(bh)
+	if err != nil {
+		return types.NewGasUnits(0), errors.Wrapf(err, "failed to get protocol version at height %s", bh)
+	}
+
 	vms := vm.NewStorageMap(p.bs)
-	usedGas, err := consensus.PreviewQueryMethod(ctx, st, vms, to, method, encodedParams, optFrom, types.NewBlockHeight(h))
+	usedGas, err := consensus.PreviewQueryMethod(ctx, st, vms, to, method, encodedParams, optFrom, bh, protocolVersion)
 	if err != nil {
 		return types.NewGasUnits(0), errors.Wrap(err, "query method returned an error")
 	}
@@ -14,6 +14,7 @@ import (
 	th "github.com/filecoin-project/go-filecoin/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
 	"github.com/filecoin-project/go-filecoin/vm"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -47,7 +48,7 @@ func TestPreview(t *testing.T) {
 		)
 		deps := requireCommonDepsWithGifAndBlockstore(t, testGen, r, bs)
 
-		previewer := NewPreviewer(deps.chainStore, deps.cst, deps.blockstore)
+		previewer := NewPreviewer(deps.chainStore, deps.cst, deps.blockstore, version.NewSingleVersionTable(version.Protocol0))
 		returnValue, err := previewer.Preview(ctx, fromAddr, fakeActorAddr, "hasReturnValue")
 		require.NoError(t, err)
 		require.NotNil(t, returnValue)
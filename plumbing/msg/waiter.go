@@ -15,11 +15,16 @@ import (
 	"github.com/filecoin-project/go-filecoin/consensus"
 	"github.com/filecoin-project/go-filecoin/state"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
 	"github.com/filecoin-project/go-filecoin/vm"
 )
 
 var log = logging.Logger("messageimpl")
 
+// errMsgNotInTipSet is the cause of the error msgIndexOfTipSet returns for a message that is
+// not in the tipset.
+var errMsgNotInTipSet = errors.New("message not in tipset")
+
 // Abstracts over a store of blockchain state.
 type waiterChainReader interface {
 	GetHead() types.TipSetKey
@@ -34,6 +39,9 @@ type Waiter struct {
 	messageProvider chain.MessageProvider
 	cst             *hamt.CborIpldStore
 	bs              bstore.Blockstore
+	// protocolVersions determines the protocol versions at which tipsets are processed to
+	// compute receipts.
+	protocolVersions *version.ProtocolVersionTable
 }
 
 // ChainMessage is an on-chain message with its block and receipt.
@@ -44,12 +52,13 @@ type ChainMessage struct {
 }
 
 // NewWaiter returns a new Waiter.
-func NewWaiter(chainStore waiterChainReader, messages chain.MessageProvider, bs bstore.Blockstore, cst *hamt.CborIpldStore) *Waiter {
+func NewWaiter(chainStore waiterChainReader, messages chain.MessageProvider, bs bstore.Blockstore, cst *hamt.CborIpldStore, pvt *version.ProtocolVersionTable) *Waiter {
 	return &Waiter{
-		chainReader:     chainStore,
-		cst:             cst,
-		bs:              bs,
-		messageProvider: messages,
+		chainReader:      chainStore,
+		cst:              cst,
+		bs:               bs,
+		messageProvider:  messages,
+		protocolVersions: pvt,
 	}
 }
 
@@ -206,6 +215,84 @@ func (w *Waiter) receiptFromTipSet(ctx context.Context, msgCid cid.Cid, ts types
 	}
 
 	// Apply all the tipset's messages to determine the correct receipts.
+	res, err := w.processTipSet(ctx, consensus.NewDefaultProcessor(w.protocolVersions), ts)
+	if err != nil {
+		return nil, err
+	}
+
+	// If this is a failing conflict message there is no application receipt.
+	_, failed := res.Failures[msgCid]
+	if failed {
+		return nil, nil
+	}
+
+	j, err := w.msgIndexOfTipSet(ctx, msgCid, ts, res.Failures)
+	if err != nil {
+		return nil, err
+	}
+	// TODO #3194: out of bounds receipt index should return an error.
+	if j < len(res.Results) {
+		rcpt = res.Results[j].Receipt
+	}
+	return rcpt, nil
+}
+
+// Trace re-executes the tipset in which the message with msgCid appears against the state of
+// its parent, and returns the execution trace of the message.
+func (w *Waiter) Trace(ctx context.Context, msgCid cid.Cid) (*vm.ExecutionTrace, error) {
+	ts, found, err := w.findTipSet(ctx, msgCid)
+	if err != nil {
+		return nil, err
+	}
+	if !found {
+		return nil, fmt.Errorf("message %s not found on chain", msgCid.String())
+	}
+
+	res, err := w.processTipSet(ctx, consensus.NewTracingProcessor(w.protocolVersions), ts)
+	if err != nil {
+		return nil, err
+	}
+
+	// A failing conflict message was not applied, so there is no execution to trace.
+	if _, failed := res.Failures[msgCid]; failed {
+		return nil, fmt.Errorf("message %s was not applied because it conflicts with another message of its tipset", msgCid.String())
+	}
+
+	j, err := w.msgIndexOfTipSet(ctx, msgCid, ts, res.Failures)
+	if err != nil {
+		return nil, err
+	}
+	if j >= len(res.Results) {
+		return nil, fmt.Errorf("no result for message %s in its tipset", msgCid.String())
+	}
+	return res.Results[j].Trace, nil
+}
+
+// findTipSet searches the blockchain history for the tipset in which the message with msgCid
+// appears.
+func (w *Waiter) findTipSet(ctx context.Context, msgCid cid.Cid) (types.TipSet, bool, error) {
+	headTipSet, err := w.chainReader.GetTipSet(w.chainReader.GetHead())
+	if err != nil {
+		return types.UndefTipSet, false, err
+	}
+
+	for iterator := chain.IterAncestors(ctx, w.chainReader, headTipSet); !iterator.Complete(); err = iterator.Next() {
+		if err != nil {
+			return types.UndefTipSet, false, err
+		}
+		_, err := w.msgIndexOfTipSet(ctx, msgCid, iterator.Value(), make(map[cid.Cid]struct{}))
+		if err == nil {
+			return iterator.Value(), true, nil
+		}
+		if errors.Cause(err) != errMsgNotInTipSet {
+			return types.UndefTipSet, false, err
+		}
+	}
+	return types.UndefTipSet, false, nil
+}
+
+// processTipSet applies the messages of ts to the state of its parent with processor.
+func (w *Waiter) processTipSet(ctx context.Context, processor *consensus.DefaultProcessor, ts types.TipSet) (*consensus.ProcessTipSetResponse, error) {
 	ids, err := ts.Parents()
 	if err != nil {
 		return nil, err
@@ -239,26 +326,7 @@ func (w *Waiter) receiptFromTipSet(ctx context.Context, msgCid cid.Cid, ts types
 		tsMessages = append(tsMessages, msgs)
 	}
 
-	res, err := consensus.NewDefaultProcessor().ProcessTipSet(ctx, st, vm.NewStorageMap(w.bs), ts, tsMessages, ancestors)
-	if err != nil {
-		return nil, err
-	}
-
-	// If this is a failing conflict message there is no application receipt.
-	_, failed := res.Failures[msgCid]
-	if failed {
-		return nil, nil
-	}
-
-	j, err := w.msgIndexOfTipSet(ctx, msgCid, ts, res.Failures)
-	if err != nil {
-		return nil, err
-	}
-	// TODO #3194: out of bounds receipt index should return an error.
-	if j < len(res.Results) {
-		rcpt = res.Results[j].Receipt
-	}
-	return rcpt, nil
+	return processor.ProcessTipSet(ctx, st, vm.NewStorageMap(w.bs), ts, tsMessages, ancestors)
 }
 
 // msgIndexOfTipSet returns the order in which msgCid appears in the canonical
@@ -294,5 +362,5 @@ func (w *Waiter) msgIndexOfTipSet(ctx context.Context, msgCid cid.Cid, ts types.
 		}
 	}
 
-	return -1, fmt.Errorf("message cid %s not in tipset", msgCid.String())
+	return -1, errors.Wrapf(errMsgNotInTipSet, "message cid %s", msgCid.String())
 }
@@ -16,6 +16,7 @@ import (
 	th "github.com/filecoin-project/go-filecoin/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
 )
 
 var mockSigner, _ = types.NewMockSignersAndKeyInfo(10)
@@ -46,12 +47,12 @@ type smsgsSet [][]*types.SignedMessage
 
 func setupTest(t *testing.T) (*hamt.CborIpldStore, *chain.Store, *chain.MessageStore, *Waiter) {
 	d := requiredCommonDeps(t, th.DefaultGenesis)
-	return d.cst, d.chainStore, d.messages, NewWaiter(d.chainStore, d.messages, d.blockstore, d.cst)
+	return d.cst, d.chainStore, d.messages, NewWaiter(d.chainStore, d.messages, d.blockstore, d.cst, version.NewSingleVersionTable(version.Protocol0))
 }
 
 func setupTestWithGif(t *testing.T, gif consensus.GenesisInitFunc) (*hamt.CborIpldStore, *chain.Store, *chain.MessageStore, *Waiter) {
 	d := requiredCommonDeps(t, gif)
-	return d.cst, d.chainStore, d.messages, NewWaiter(d.chainStore, d.messages, d.blockstore, d.cst)
+	return d.cst, d.chainStore, d.messages, NewWaiter(d.chainStore, d.messages, d.blockstore, d.cst, version.NewSingleVersionTable(version.Protocol0))
 }
 
 func TestWait(t *testing.T) {
@@ -222,6 +223,93 @@ func TestWaitConflicting(t *testing.T) {
 	testWaitHelp(nil, t, waiter, sm2, false, msgApplyFail)
 }
 
+func TestTrace(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+
+	addr1, addr2 := mockSigner.Addresses[0], mockSigner.Addresses[1]
+	minerAddr := mockSigner.Addresses[3]
+
+	testGen := consensus.MakeGenesisFunc(
+		consensus.ActorAccount(addr1, types.NewAttoFILFromFIL(10000)),
+		consensus.ActorAccount(addr2, types.NewAttoFILFromFIL(0)),
+		consensus.MinerActor(minerAddr, addr2, th.RequireRandomPeerID(t), types.ZeroAttoFIL, types.OneKiBSectorSize),
+	)
+	cst, chainStore, msgStore, waiter := setupTestWithGif(t, testGen)
+
+	m1 := types.NewMessage(addr1, addr2, 0, types.NewAttoFILFromFIL(100), "", nil)
+	sm1, err := types.NewSignedMessage(*m1, &mockSigner, types.NewGasPrice(1), types.NewGasUnits(0))
+	require.NoError(t, err)
+	sm1Cid, err := sm1.Cid()
+	require.NoError(t, err)
+
+	headTipSet, err := chainStore.GetTipSet(chainStore.GetHead())
+	require.NoError(t, err)
+	baseBlock := headTipSet.ToSlice()[0]
+
+	b1 := th.RequireMkFakeChild(t,
+		th.FakeChildParams{
+			MinerAddr:   minerAddr,
+			Parent:      headTipSet,
+			GenesisCid:  chainStore.GenesisCid(),
+			StateRoot:   baseBlock.StateRoot,
+			Signer:      mockSigner,
+			MinerWorker: addr1,
+		})
+	b1.Messages, err = msgStore.StoreMessages(ctx, []*types.SignedMessage{sm1})
+	require.NoError(t, err)
+	b1.MessageReceipts, err = msgStore.StoreReceipts(ctx, []*types.MessageReceipt{})
+	require.NoError(t, err)
+	mustPut(cst, b1)
+
+	ts := th.RequireNewTipSet(t, b1)
+	require.NoError(t, chainStore.PutTipSetAndState(ctx, &chain.TipSetAndState{
+		TipSet:          ts,
+		TipSetStateRoot: baseBlock.StateRoot,
+	}))
+	require.NoError(t, chainStore.SetHead(ctx, ts))
+
+	t.Run("traces a message on chain", func(t *testing.T) {
+		execTrace, err := waiter.Trace(ctx, sm1Cid)
+		require.NoError(t, err)
+		require.NotNil(t, execTrace)
+		assert.Equal(t, addr1, execTrace.From)
+		assert.Equal(t, addr2, execTrace.To)
+		assert.Equal(t, types.NewAttoFILFromFIL(100), execTrace.Value)
+		assert.Equal(t, uint8(0), execTrace.ExitCode)
+		assert.Empty(t, execTrace.Error)
+		assert.Empty(t, execTrace.Subcalls)
+	})
+
+	t.Run("errors for a message not on chain", func(t *testing.T) {
+		_, err := waiter.Trace(ctx, types.CidFromString(t, "somecid"))
+		assert.Error(t, err)
+	})
+
+	t.Run("errors if the messages of a tipset cannot be loaded", func(t *testing.T) {
+		b2 := &types.Block{
+			Parents:         ts.Key(),
+			Height:          b1.Height + 1,
+			Miner:           minerAddr,
+			StateRoot:       baseBlock.StateRoot,
+			Messages:        types.CidFromString(t, "missingmessages"),
+			MessageReceipts: b1.MessageReceipts,
+		}
+		mustPut(cst, b2)
+
+		badTs := th.RequireNewTipSet(t, b2)
+		require.NoError(t, chainStore.PutTipSetAndState(ctx, &chain.TipSetAndState{
+			TipSet:          badTs,
+			TipSetStateRoot: baseBlock.StateRoot,
+		}))
+		require.NoError(t, chainStore.SetHead(ctx, badTs))
+
+		_, err := waiter.Trace(ctx, sm1Cid)
+		assert.Error(t, err)
+	})
+}
+
 func TestWaitRespectsContextCancel(t *testing.T) {
 	tf.UnitTest(t)
 
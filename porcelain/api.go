@@ -56,6 +56,12 @@ func (a *API) ChainGetFullBlock(ctx context.Context, id cid.Cid) (*types.FullBlo
 	return GetFullBlock(ctx, a, id)
 }
 
+// ChainNotify returns a channel on which a change is emitted for each tipset joining or leaving
+// the chain as its head changes, until ctx is done.
+func (a *API) ChainNotify(ctx context.Context) (<-chan *ChainHeadChange, error) {
+	return ChainNotify(ctx, a)
+}
+
 // CreatePayments establishes a payment channel and create multiple payments against it
 func (a *API) CreatePayments(ctx context.Context, config CreatePaymentsParams) (*CreatePaymentsReturn, error) {
 	return CreatePayments(ctx, a, config)
@@ -94,6 +100,16 @@ func (a *API) MessagePoolWait(ctx context.Context, messageCount uint) ([]*types.
 	return MessagePoolWait(ctx, a, messageCount)
 }
 
+// MessageEstimateGas suggests a gas price and gas limit for sending a message
+func (a *API) MessageEstimateGas(ctx context.Context, from, to address.Address, method string, params ...interface{}) (*GasEstimate, error) {
+	return MessageEstimateGas(ctx, a, from, to, method, params...)
+}
+
+// MessageEstimateGasPrice suggests a gas price with which a message is likely to be mined soon
+func (a *API) MessageEstimateGasPrice(ctx context.Context) (types.AttoFIL, error) {
+	return MessageEstimateGasPrice(ctx, a)
+}
+
 // MinerCreate creates a miner
 func (a *API) MinerCreate(
 	ctx context.Context,
@@ -122,6 +138,11 @@ func (a *API) MinerGetAsk(ctx context.Context, minerAddr address.Address, askID
 	return MinerGetAsk(ctx, a, minerAddr, askID)
 }
 
+// MinerGetRetrievalAsk queries for the retrieval ask of the given miner
+func (a *API) MinerGetRetrievalAsk(ctx context.Context, minerAddr address.Address) (*minerActor.RetrievalAsk, error) {
+	return MinerGetRetrievalAsk(ctx, a, minerAddr)
+}
+
 // MinerGetOwnerAddress queries for the owner address of the given miner
 func (a *API) MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address) (address.Address, error) {
 	return MinerGetOwnerAddress(ctx, a, minerAddr)
@@ -157,6 +178,11 @@ func (a *API) MinerSetPrice(ctx context.Context, from address.Address, miner add
 	return MinerSetPrice(ctx, a, from, miner, gasPrice, gasLimit, price, expiry)
 }
 
+// MinerSetRetrievalPrice configures the price of retrieval. See implementation for details.
+func (a *API) MinerSetRetrievalPrice(ctx context.Context, from address.Address, miner address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, ask minerActor.RetrievalAsk) (MinerSetRetrievalPriceResponse, error) {
+	return MinerSetRetrievalPrice(ctx, a, from, miner, gasPrice, gasLimit, ask)
+}
+
 // MinerGetPower queries for the power of the given miner
 func (a *API) MinerGetPower(ctx context.Context, minerAddr address.Address) (MinerPower, error) {
 	return MinerGetPower(ctx, a, minerAddr)
@@ -184,6 +210,39 @@ func (a *API) MinerPreviewSetPrice(
 	return MinerPreviewSetPrice(ctx, a, from, miner, price, expiry)
 }
 
+// MultisigCreate creates a multisig actor and waits for it to appear on chain
+func (a *API) MultisigCreate(
+	ctx context.Context,
+	fromAddr address.Address,
+	gasPrice types.AttoFIL,
+	gasLimit types.GasUnits,
+	signers []address.Address,
+	threshold uint64,
+	value types.AttoFIL,
+) (address.Address, error) {
+	return MultisigCreate(ctx, a, fromAddr, gasPrice, gasLimit, signers, threshold, value)
+}
+
+// MultisigPropose proposes a transaction of a multisig actor and returns its id
+func (a *API) MultisigPropose(
+	ctx context.Context,
+	fromAddr address.Address,
+	msigAddr address.Address,
+	gasPrice types.AttoFIL,
+	gasLimit types.GasUnits,
+	to address.Address,
+	value types.AttoFIL,
+	method string,
+	params ...interface{},
+) (uint64, error) {
+	return MultisigPropose(ctx, a, fromAddr, msigAddr, gasPrice, gasLimit, to, value, method, params...)
+}
+
+// MultisigLs returns the signers, threshold and pending transactions of a multisig actor
+func (a *API) MultisigLs(ctx context.Context, msigAddr address.Address) (*MultisigInfo, error) {
+	return MultisigLs(ctx, a, msigAddr)
+}
+
 // ProtocolParameters fetches the current protocol configuration parameters.
 func (a *API) ProtocolParameters(ctx context.Context) (*ProtocolParams, error) {
 	return ProtocolParameters(ctx, a)
@@ -226,6 +285,16 @@ func (a *API) ClientListAsks(ctx context.Context) <-chan Ask {
 	return ClientListAsks(ctx, a)
 }
 
+// ClientListRetrievalAsks returns a channel with retrieval asks from the latest chain state
+func (a *API) ClientListRetrievalAsks(ctx context.Context) <-chan RetrievalAsk {
+	return ClientListRetrievalAsks(ctx, a)
+}
+
+// ClientFindRetrievalMiners finds and ranks the miners that can serve the given piece
+func (a *API) ClientFindRetrievalMiners(ctx context.Context, pieceCID cid.Cid) ([]RetrievalMiner, error) {
+	return ClientFindRetrievalMiners(ctx, a, pieceCID)
+}
+
 // ClientValidateDeal checks to see that a storage deal is in the `Complete` state, and that its PIP is valid
 func (a *API) ClientValidateDeal(ctx context.Context, proposalCid cid.Cid, proofInfo *storagedeal.ProofInfo) error {
 	return ClientVerifyStorageDeal(ctx, a, proposalCid, proofInfo)
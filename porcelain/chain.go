@@ -4,7 +4,9 @@ import (
 	"context"
 
 	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/go-filecoin/chain"
 	"github.com/filecoin-project/go-filecoin/types"
 )
 
@@ -18,6 +20,148 @@ func ChainHead(plumbing chainHeadPlumbing) (types.TipSet, error) {
 	return plumbing.ChainTipSet(plumbing.ChainHeadKey())
 }
 
+// ChainHeadChangeType identifies whether a tipset joins or leaves the chain.
+type ChainHeadChangeType string
+
+const (
+	// ChainApply is the type of a change adding a tipset to the chain.
+	ChainApply = ChainHeadChangeType("apply")
+	// ChainRevert is the type of a change removing a tipset from the chain.
+	ChainRevert = ChainHeadChangeType("revert")
+)
+
+// ChainHeadChange is a tipset joining or leaving the chain as its head changes.
+type ChainHeadChange struct {
+	Type   ChainHeadChangeType
+	Key    types.TipSetKey
+	Height uint64
+	Blocks []*types.Block
+}
+
+type chainNotifyPlumbing interface {
+	chainHeadPlumbing
+	ChainSubscribeHeads() chan interface{}
+	ChainUnsubscribeHeads(ch chan interface{})
+}
+
+// ChainNotify returns a channel on which a change is emitted for each tipset joining or leaving
+// the chain as its head changes, until ctx is done. When the head moves to another fork, the
+// tipsets back to the common ancestor of the old and new heads are reverted in decreasing height
+// order, then the tipsets of the new fork are applied in increasing height order.
+// New heads are consumed as soon as they are published, so that a slow reader never blocks
+// the chain from setting its head. If several heads are published while the reader is behind,
+// only the latest is kept, and the changes from the last head emitted to it are emitted next.
+func ChainNotify(ctx context.Context, plumbing chainNotifyPlumbing) (<-chan *ChainHeadChange, error) {
+	// Subscribe before reading the head so that no new head is missed.
+	heads := plumbing.ChainSubscribeHeads()
+	prevHead, err := ChainHead(plumbing)
+	if err != nil {
+		go drainHeads(heads)
+		plumbing.ChainUnsubscribeHeads(heads)
+		return nil, errors.Wrap(err, "failed to get chain head")
+	}
+
+	// latest holds the most recent head not yet handled. It is closed once heads is.
+	latest := make(chan types.TipSet, 1)
+	go func() {
+		defer close(latest)
+		for h := range heads {
+			select {
+			case <-latest:
+			default:
+			}
+			latest <- h.(types.TipSet)
+		}
+	}()
+
+	out := make(chan *ChainHeadChange)
+	go func() {
+		defer close(out)
+		defer plumbing.ChainUnsubscribeHeads(heads)
+
+		for {
+			var newHead types.TipSet
+			select {
+			case <-ctx.Done():
+				return
+			case h, ok := <-latest:
+				if !ok {
+					return
+				}
+				newHead = h
+			}
+			if newHead.Equals(prevHead) {
+				continue
+			}
+
+			changes, err := chainHeadChanges(ctx, plumbing, prevHead, newHead)
+			if err != nil {
+				log.Errorf("failed to compute chain changes from %s to %s: %s", prevHead.Key(), newHead.Key(), err)
+				return
+			}
+			for _, change := range changes {
+				select {
+				case out <- change:
+				case <-ctx.Done():
+					return
+				}
+			}
+			prevHead = newHead
+		}
+	}()
+	return out, nil
+}
+
+// chainHeadChanges returns the changes moving the head of the chain from oldHead to newHead.
+func chainHeadChanges(ctx context.Context, plumbing chainHeadPlumbing, oldHead, newHead types.TipSet) ([]*ChainHeadChange, error) {
+	oldTips, newTips, err := chain.CollectTipsToCommonAncestor(ctx, tipSetPlumbing{plumbing}, oldHead, newHead)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []*ChainHeadChange
+	add := func(changeType ChainHeadChangeType, ts types.TipSet) error {
+		height, err := ts.Height()
+		if err != nil {
+			return err
+		}
+		changes = append(changes, &ChainHeadChange{
+			Type:   changeType,
+			Key:    ts.Key(),
+			Height: height,
+			Blocks: ts.ToSlice(),
+		})
+		return nil
+	}
+	for _, ts := range oldTips {
+		if err := add(ChainRevert, ts); err != nil {
+			return nil, err
+		}
+	}
+	for i := len(newTips) - 1; i >= 0; i-- {
+		if err := add(ChainApply, newTips[i]); err != nil {
+			return nil, err
+		}
+	}
+	return changes, nil
+}
+
+// drainHeads consumes a head subscription until it is closed, so that the publisher never blocks
+// on it while it is being unsubscribed.
+func drainHeads(heads chan interface{}) {
+	for range heads {
+	}
+}
+
+// tipSetPlumbing adapts plumbing to a chain.TipSetProvider.
+type tipSetPlumbing struct {
+	plumbing chainHeadPlumbing
+}
+
+func (p tipSetPlumbing) GetTipSet(key types.TipSetKey) (types.TipSet, error) {
+	return p.plumbing.ChainTipSet(key)
+}
+
 type fullBlockPlumbing interface {
 	ChainGetBlock(context.Context, cid.Cid) (*types.Block, error)
 	ChainGetMessages(context.Context, cid.Cid) ([]*types.SignedMessage, error)
@@ -0,0 +1,141 @@
+package porcelain_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/cskr/pubsub"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/chain"
+	"github.com/filecoin-project/go-filecoin/porcelain"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+type fakeChainNotifyPlumbing struct {
+	builder *chain.Builder
+	head    types.TipSet
+	events  *pubsub.PubSub
+}
+
+func (p *fakeChainNotifyPlumbing) setHead(ts types.TipSet) {
+	p.head = ts
+	p.events.Pub(ts, chain.NewHeadTopic)
+}
+
+func (p *fakeChainNotifyPlumbing) ChainHeadKey() types.TipSetKey {
+	return p.head.Key()
+}
+
+func (p *fakeChainNotifyPlumbing) ChainTipSet(key types.TipSetKey) (types.TipSet, error) {
+	return p.builder.GetTipSet(key)
+}
+
+func (p *fakeChainNotifyPlumbing) ChainSubscribeHeads() chan interface{} {
+	return p.events.Sub(chain.NewHeadTopic)
+}
+
+func (p *fakeChainNotifyPlumbing) ChainUnsubscribeHeads(ch chan interface{}) {
+	p.events.Unsub(ch, chain.NewHeadTopic)
+}
+
+func TestChainNotify(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	builder := chain.NewBuilder(t, address.Undef)
+	genesis := builder.NewGenesis()
+	plumbing := &fakeChainNotifyPlumbing{builder: builder, head: genesis, events: pubsub.New(128)}
+
+	changes, err := porcelain.ChainNotify(ctx, plumbing)
+	require.NoError(t, err)
+
+	requireChange := func(changeType porcelain.ChainHeadChangeType, ts types.TipSet) {
+		select {
+		case change := <-changes:
+			height, err := ts.Height()
+			require.NoError(t, err)
+			assert.Equal(t, changeType, change.Type)
+			assert.Equal(t, ts.Key(), change.Key)
+			assert.Equal(t, height, change.Height)
+			assert.Equal(t, ts.ToSlice(), change.Blocks)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for %s of %s", changeType, ts.Key())
+		}
+	}
+
+	t.Log("extending the head applies each new tipset, lowest first")
+	a1 := builder.AppendOn(genesis, 1)
+	a2 := builder.AppendOn(a1, 1)
+	plumbing.setHead(a2)
+	requireChange(porcelain.ChainApply, a1)
+	requireChange(porcelain.ChainApply, a2)
+
+	t.Log("a reorg reverts tipsets back to the common ancestor before applying the new fork")
+	b2 := builder.AppendOn(a1, 2)
+	b3 := builder.AppendOn(b2, 1)
+	plumbing.setHead(b3)
+	requireChange(porcelain.ChainRevert, a2)
+	requireChange(porcelain.ChainApply, b2)
+	requireChange(porcelain.ChainApply, b3)
+
+	t.Log("the channel closes when the context is done")
+	cancel()
+	select {
+	case _, ok := <-changes:
+		assert.False(t, ok)
+	case <-time.After(time.Second):
+		t.Fatal("timed out waiting for channel to close")
+	}
+}
+
+func TestChainNotifySlowReader(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	builder := chain.NewBuilder(t, address.Undef)
+	genesis := builder.NewGenesis()
+	plumbing := &fakeChainNotifyPlumbing{builder: builder, head: genesis, events: pubsub.New(1)}
+
+	changes, err := porcelain.ChainNotify(ctx, plumbing)
+	require.NoError(t, err)
+
+	t.Log("publishing heads does not block while the reader is behind")
+	var tips []types.TipSet
+	head := genesis
+	for i := 0; i < 10; i++ {
+		head = builder.AppendOn(head, 1)
+		tips = append(tips, head)
+	}
+	published := make(chan struct{})
+	go func() {
+		defer close(published)
+		for _, ts := range tips {
+			plumbing.setHead(ts)
+		}
+	}()
+	select {
+	case <-published:
+	case <-time.After(time.Second):
+		t.Fatal("timed out publishing heads")
+	}
+
+	t.Log("the reader catches up with every tipset applied in order")
+	for _, ts := range tips {
+		select {
+		case change := <-changes:
+			assert.Equal(t, porcelain.ChainApply, change.Type)
+			assert.Equal(t, ts.Key(), change.Key)
+		case <-time.After(time.Second):
+			t.Fatalf("timed out waiting for apply of %s", ts.Key())
+		}
+	}
+}
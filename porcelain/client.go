@@ -26,6 +26,16 @@ type Ask struct {
 	Error error
 }
 
+// RetrievalAsk is a result of querying for a miner's retrieval ask, it may contain an error
+type RetrievalAsk struct {
+	Miner           address.Address
+	Price           types.AttoFIL
+	PaymentInterval *types.BytesAmount
+	UnsealPrice     types.AttoFIL
+
+	Error error
+}
+
 type claPlubming interface {
 	ActorLs(ctx context.Context) (<-chan state.GetAllActorsResult, error)
 	ChainHeadKey() types.TipSetKey
@@ -96,6 +106,65 @@ func listAsksFromActorResult(ctx context.Context, plumbing claPlubming, actorRes
 	return nil
 }
 
+// ClientListRetrievalAsks returns a channel with the retrieval asks of all miners from the
+// latest chain state. Miners that have not set a retrieval ask are skipped.
+func ClientListRetrievalAsks(ctx context.Context, plumbing claPlubming) <-chan RetrievalAsk {
+	out := make(chan RetrievalAsk)
+
+	go func() {
+		defer close(out)
+		actorCh, err := plumbing.ActorLs(ctx)
+		if err != nil {
+			out <- RetrievalAsk{
+				Error: err,
+			}
+			return
+		}
+
+		for actorResult := range actorCh {
+			err := listRetrievalAskFromActorResult(ctx, plumbing, actorResult, out)
+			if err != nil {
+				out <- RetrievalAsk{
+					Error: err,
+				}
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+func listRetrievalAskFromActorResult(ctx context.Context, plumbing claPlubming, actorResult state.GetAllActorsResult, out chan RetrievalAsk) error {
+	if actorResult.Error != nil {
+		return actorResult.Error
+	}
+
+	addr, _ := address.NewFromString(actorResult.Address)
+	actor := actorResult.Actor
+
+	if !types.MinerActorCodeCid.Equals(actor.Code) && !types.BootstrapMinerActorCodeCid.Equals(actor.Code) {
+		return nil
+	}
+
+	ask, err := MinerGetRetrievalAsk(ctx, plumbing, addr)
+	if err != nil {
+		return err
+	}
+	if ask == nil {
+		return nil
+	}
+
+	out <- RetrievalAsk{
+		Miner:           addr,
+		Price:           ask.Price,
+		PaymentInterval: ask.PaymentInterval,
+		UnsealPrice:     ask.UnsealPrice,
+	}
+
+	return nil
+}
+
 // The subset of plumbing used by ClientVerifyStorageDeal
 type cvsdPlumbing interface {
 	ChainHeadKey() types.TipSetKey
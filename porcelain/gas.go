@@ -0,0 +1,153 @@
+package porcelain
+
+import (
+	"context"
+	"math/big"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/chain"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+const (
+	// gasEstimateTipSets is the number of recent tipsets whose messages inform gas price estimates
+	gasEstimateTipSets = 10
+	// gasPricePercentile is the percentile of gas prices, both within and across blocks, that
+	// gas price estimates suggest
+	gasPricePercentile = 50
+	// gasLimitMarginPercent is the margin added to the gas used by a previewed message, to allow
+	// for the state changing before the message is mined
+	gasLimitMarginPercent = 10
+)
+
+// GasEstimate is the gas price and gas limit suggested for sending a message.
+type GasEstimate struct {
+	GasPrice types.AttoFIL
+	GasLimit types.GasUnits
+}
+
+// gasPricePlumbing is the subset of the plumbing.API that MessageEstimateGasPrice uses.
+type gasPricePlumbing interface {
+	ChainLs(ctx context.Context) (*chain.TipsetIterator, error)
+	ChainGetMessages(ctx context.Context, id cid.Cid) ([]*types.SignedMessage, error)
+	MessagePoolPending() []*types.SignedMessage
+}
+
+// gasEstimatePlumbing is the subset of the plumbing.API that MessageEstimateGas uses.
+type gasEstimatePlumbing interface {
+	gasPricePlumbing
+	MessagePreview(ctx context.Context, from, to address.Address, method string, params ...interface{}) (types.GasUnits, error)
+}
+
+// MessageEstimateGas suggests a gas price and gas limit for a message calling method on `to`
+// with params. The gas limit is the gas used by previewing the message plus a margin, and the
+// gas price is estimated by MessageEstimateGasPrice.
+func MessageEstimateGas(ctx context.Context, plumbing gasEstimatePlumbing, from, to address.Address, method string, params ...interface{}) (*GasEstimate, error) {
+	usedGas, err := plumbing.MessagePreview(ctx, from, to, method, params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to preview message")
+	}
+
+	gasPrice, err := MessageEstimateGasPrice(ctx, plumbing)
+	if err != nil {
+		return nil, err
+	}
+
+	gasLimit := usedGas + usedGas*gasLimitMarginPercent/100
+	if gasLimit > types.BlockGasLimit {
+		gasLimit = types.BlockGasLimit
+	}
+
+	return &GasEstimate{
+		GasPrice: gasPrice,
+		GasLimit: gasLimit,
+	}, nil
+}
+
+// MessageEstimateGasPrice suggests a gas price with which a message is likely to be mined soon.
+// It takes the percentile of the gas prices of the messages in each of the most recent blocks,
+// and suggests the same percentile of those across blocks. If the message pool holds more
+// messages than fit in a block, it suggests at least outbidding the messages that miss the
+// next block.
+func MessageEstimateGasPrice(ctx context.Context, plumbing gasPricePlumbing) (types.AttoFIL, error) {
+	chainPrice, err := chainGasPrice(ctx, plumbing)
+	if err != nil {
+		return types.ZeroAttoFIL, err
+	}
+
+	poolPrice := poolGasPrice(plumbing.MessagePoolPending())
+	if poolPrice.GreaterThan(chainPrice) {
+		return poolPrice, nil
+	}
+	return chainPrice, nil
+}
+
+// chainGasPrice returns the percentile across recent blocks of the percentile of gas prices
+// in each block, or zero if recent blocks hold no messages.
+func chainGasPrice(ctx context.Context, plumbing gasPricePlumbing) (types.AttoFIL, error) {
+	iter, err := plumbing.ChainLs(ctx)
+	if err != nil {
+		return types.ZeroAttoFIL, err
+	}
+
+	var blockPrices []types.AttoFIL
+	for i := 0; i < gasEstimateTipSets && !iter.Complete(); i++ {
+		ts := iter.Value()
+		for j := 0; j < ts.Len(); j++ {
+			msgs, err := plumbing.ChainGetMessages(ctx, ts.At(j).Messages)
+			if err != nil {
+				return types.ZeroAttoFIL, errors.Wrapf(err, "failed to get messages of block %s", ts.At(j).Cid())
+			}
+			if len(msgs) == 0 {
+				continue
+			}
+
+			prices := make([]types.AttoFIL, len(msgs))
+			for k, msg := range msgs {
+				prices[k] = msg.GasPrice
+			}
+			blockPrices = append(blockPrices, gasPriceAtPercentile(prices, gasPricePercentile))
+		}
+
+		if err := iter.Next(); err != nil {
+			return types.ZeroAttoFIL, err
+		}
+	}
+
+	if len(blockPrices) == 0 {
+		return types.ZeroAttoFIL, nil
+	}
+	return gasPriceAtPercentile(blockPrices, gasPricePercentile), nil
+}
+
+// poolGasPrice returns one attoFIL more than the highest gas price of the pending messages that
+// do not fit in the next block when it is filled with the highest paying ones, or zero if all
+// pending messages fit.
+func poolGasPrice(pending []*types.SignedMessage) types.AttoFIL {
+	sorted := make([]*types.SignedMessage, len(pending))
+	copy(sorted, pending)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].GasPrice.GreaterThan(sorted[j].GasPrice)
+	})
+
+	var gas types.GasUnits
+	for _, msg := range sorted {
+		gas += msg.GasLimit
+		if gas > types.BlockGasLimit {
+			return msg.GasPrice.Add(types.NewAttoFIL(big.NewInt(1)))
+		}
+	}
+	return types.ZeroAttoFIL
+}
+
+// gasPriceAtPercentile sorts prices and returns the one at percentile p.
+func gasPriceAtPercentile(prices []types.AttoFIL, p int) types.AttoFIL {
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].LessThan(prices[j])
+	})
+	return prices[(len(prices)-1)*p/100]
+}
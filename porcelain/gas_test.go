@@ -0,0 +1,146 @@
+package porcelain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/chain"
+	"github.com/filecoin-project/go-filecoin/porcelain"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+type fakeGasEstimatePlumbing struct {
+	t        *testing.T
+	tipsets  map[string]types.TipSet
+	head     types.TipSet
+	messages map[cid.Cid][]*types.SignedMessage
+	pending  []*types.SignedMessage
+	usedGas  types.GasUnits
+	signer   types.MockSigner
+	newCid   func() cid.Cid
+}
+
+func newFakeGasEstimatePlumbing(t *testing.T) *fakeGasEstimatePlumbing {
+	signer, _ := types.NewMockSignersAndKeyInfo(1)
+	return &fakeGasEstimatePlumbing{
+		t:        t,
+		tipsets:  make(map[string]types.TipSet),
+		messages: make(map[cid.Cid][]*types.SignedMessage),
+		signer:   signer,
+		newCid:   types.NewCidForTestGetter(),
+	}
+}
+
+// addBlock adds a block on top of the head holding messages with the given gas prices.
+func (p *fakeGasEstimatePlumbing) addBlock(prices ...int64) {
+	var msgs []*types.SignedMessage
+	for _, price := range prices {
+		msgs = append(msgs, p.newMessage(price, 0))
+	}
+
+	blk := &types.Block{Messages: p.newCid()}
+	if p.head.Defined() {
+		blk.Parents = p.head.Key()
+		height, err := p.head.Height()
+		require.NoError(p.t, err)
+		blk.Height = types.Uint64(height + 1)
+	}
+	p.messages[blk.Messages] = msgs
+
+	ts, err := types.NewTipSet(blk)
+	require.NoError(p.t, err)
+	p.tipsets[ts.Key().String()] = ts
+	p.head = ts
+}
+
+func (p *fakeGasEstimatePlumbing) newMessage(price int64, gasLimit types.GasUnits) *types.SignedMessage {
+	msg := types.NewMessage(p.signer.Addresses[0], address.TestAddress, 0, types.ZeroAttoFIL, "", nil)
+	smsg, err := types.NewSignedMessage(*msg, p.signer, types.NewGasPrice(price), gasLimit)
+	require.NoError(p.t, err)
+	return smsg
+}
+
+func (p *fakeGasEstimatePlumbing) GetTipSet(key types.TipSetKey) (types.TipSet, error) {
+	return p.tipsets[key.String()], nil
+}
+
+func (p *fakeGasEstimatePlumbing) ChainLs(ctx context.Context) (*chain.TipsetIterator, error) {
+	return chain.IterAncestors(ctx, p, p.head), nil
+}
+
+func (p *fakeGasEstimatePlumbing) ChainGetMessages(ctx context.Context, id cid.Cid) ([]*types.SignedMessage, error) {
+	return p.messages[id], nil
+}
+
+func (p *fakeGasEstimatePlumbing) MessagePoolPending() []*types.SignedMessage {
+	return p.pending
+}
+
+func (p *fakeGasEstimatePlumbing) MessagePreview(ctx context.Context, from, to address.Address, method string, params ...interface{}) (types.GasUnits, error) {
+	return p.usedGas, nil
+}
+
+func TestMessageEstimateGasPrice(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+
+	t.Run("zero without messages", func(t *testing.T) {
+		plumbing := newFakeGasEstimatePlumbing(t)
+		plumbing.addBlock()
+
+		price, err := porcelain.MessageEstimateGasPrice(ctx, plumbing)
+		require.NoError(t, err)
+		assert.Equal(t, types.ZeroAttoFIL, price)
+	})
+
+	t.Run("takes the median across blocks of the median of each block", func(t *testing.T) {
+		plumbing := newFakeGasEstimatePlumbing(t)
+		plumbing.addBlock(3, 1, 2)
+		plumbing.addBlock()
+		plumbing.addBlock(10, 20)
+		plumbing.addBlock(4)
+
+		price, err := porcelain.MessageEstimateGasPrice(ctx, plumbing)
+		require.NoError(t, err)
+		assert.Equal(t, types.NewGasPrice(4), price)
+	})
+
+	t.Run("outbids pending messages that do not fit in the next block", func(t *testing.T) {
+		plumbing := newFakeGasEstimatePlumbing(t)
+		plumbing.addBlock(4)
+
+		halfBlock := types.BlockGasLimit/2 + 1
+		plumbing.pending = []*types.SignedMessage{
+			plumbing.newMessage(1, 1000),
+			plumbing.newMessage(100, halfBlock),
+		}
+		price, err := porcelain.MessageEstimateGasPrice(ctx, plumbing)
+		require.NoError(t, err)
+		assert.Equal(t, types.NewGasPrice(4), price)
+
+		plumbing.pending = append(plumbing.pending, plumbing.newMessage(50, halfBlock))
+		price, err = porcelain.MessageEstimateGasPrice(ctx, plumbing)
+		require.NoError(t, err)
+		assert.Equal(t, types.NewGasPrice(51), price)
+	})
+}
+
+func TestMessageEstimateGas(t *testing.T) {
+	tf.UnitTest(t)
+
+	plumbing := newFakeGasEstimatePlumbing(t)
+	plumbing.addBlock(7)
+	plumbing.usedGas = 1000
+
+	estimate, err := porcelain.MessageEstimateGas(context.Background(), plumbing, address.TestAddress, address.TestAddress2, "")
+	require.NoError(t, err)
+	assert.Equal(t, types.NewGasPrice(7), estimate.GasPrice)
+	assert.Equal(t, types.NewGasUnits(1100), estimate.GasLimit)
+}
@@ -381,6 +381,83 @@ func MinerGetAsk(ctx context.Context, plumbing mgaAPI, minerAddr address.Address
 	return ask, nil
 }
 
+// MinerGetRetrievalAsk queries for the retrieval ask of the given miner. It returns nil if
+// the miner has not set one.
+func MinerGetRetrievalAsk(ctx context.Context, plumbing mgaAPI, minerAddr address.Address) (*minerActor.RetrievalAsk, error) {
+	ret, err := plumbing.MessageQuery(ctx, address.Undef, minerAddr, "getRetrievalAsk", plumbing.ChainHeadKey())
+	if err != nil {
+		return nil, err
+	}
+
+	if len(ret[0]) == 0 {
+		return nil, nil
+	}
+
+	var ask minerActor.RetrievalAsk
+	if err := cbor.DecodeInto(ret[0], &ask); err != nil {
+		return nil, err
+	}
+
+	return &ask, nil
+}
+
+// MinerSetRetrievalPriceResponse collects relevant stats from the set retrieval price process
+type MinerSetRetrievalPriceResponse struct {
+	SetAskCid cid.Cid
+	BlockCid  cid.Cid
+	MinerAddr address.Address
+	Ask       minerActor.RetrievalAsk
+}
+
+// MinerSetRetrievalPrice configures the price of retrieval, then sends a retrieval ask advertising
+// that price and waits for it to be mined. If minerAddr is empty, the default miner will be used.
+// Like MinerSetPrice, this method sets the configured price whether or not it creates the ask successfully.
+func MinerSetRetrievalPrice(ctx context.Context, plumbing mspAPI, from address.Address, miner address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, ask minerActor.RetrievalAsk) (MinerSetRetrievalPriceResponse, error) {
+	res := MinerSetRetrievalPriceResponse{
+		Ask: ask,
+	}
+
+	// get miner address if not provided
+	if miner.Empty() {
+		minerValue, err := plumbing.ConfigGet("mining.minerAddress")
+		if err != nil {
+			return res, errors.Wrap(err, "Could not get miner address in config")
+		}
+		minerAddr, ok := minerValue.(address.Address)
+		if !ok {
+			return res, errors.Wrap(err, "Configured miner is not an address")
+		}
+		miner = minerAddr
+	}
+	res.MinerAddr = miner
+
+	// set price
+	jsonPrice, err := json.Marshal(ask.Price)
+	if err != nil {
+		return res, errors.New("Could not marshal price")
+	}
+	if err := plumbing.ConfigSet("mining.retrievalPrice", string(jsonPrice)); err != nil {
+		return res, err
+	}
+
+	// set ask
+	res.SetAskCid, err = plumbing.MessageSend(ctx, from, res.MinerAddr, types.ZeroAttoFIL, gasPrice, gasLimit, "setRetrievalAsk", ask.Price, ask.PaymentInterval, ask.UnsealPrice)
+	if err != nil {
+		return res, errors.Wrap(err, "couldn't send message")
+	}
+
+	// wait for ask to be mined
+	err = plumbing.MessageWait(ctx, res.SetAskCid, func(blk *types.Block, smsg *types.SignedMessage, receipt *types.MessageReceipt) error {
+		res.BlockCid = blk.Cid()
+
+		if receipt.ExitCode != uint8(0) {
+			return vmErrors.VMExitCodeToError(receipt.ExitCode, minerActor.Errors)
+		}
+		return nil
+	})
+	return res, err
+}
+
 // mgpidAPI is the subset of the plumbing.API that MinerGetPeerID uses.
 type mgpidAPI interface {
 	ChainHeadKey() types.TipSetKey
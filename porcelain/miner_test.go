@@ -76,7 +76,7 @@ func (mpc *minerCreate) MessageWait(ctx context.Context, msgCid cid.Cid, cb func
 }
 
 func (mpc *minerCreate) WalletDefaultAddress() (address.Address, error) {
-	return wallet.NewAddress(mpc.wallet)
+	return wallet.NewAddress(mpc.wallet, address.SECP256K1)
 }
 
 func TestMinerCreate(t *testing.T) {
@@ -148,7 +148,7 @@ func (mpc *minerPreviewCreate) NetworkGetPeerID() peer.ID {
 }
 
 func (mpc *minerPreviewCreate) WalletDefaultAddress() (address.Address, error) {
-	return wallet.NewAddress(mpc.wallet)
+	return wallet.NewAddress(mpc.wallet, address.SECP256K1)
 }
 
 func TestMinerPreviewCreate(t *testing.T) {
@@ -565,6 +565,53 @@ func TestMinerGetAsk(t *testing.T) {
 	assert.Equal(t, big.NewInt(4), ask.ID)
 }
 
+type minerGetRetrievalAskPlumbing struct {
+	ask *miner.RetrievalAsk
+}
+
+func (mgrap *minerGetRetrievalAskPlumbing) ChainHeadKey() types.TipSetKey {
+	return types.NewTipSetKey()
+}
+
+func (mgrap *minerGetRetrievalAskPlumbing) MessageQuery(ctx context.Context, optFrom, to address.Address, method string, _ types.TipSetKey, params ...interface{}) ([][]byte, error) {
+	if mgrap.ask == nil {
+		return [][]byte{{}}, nil
+	}
+	out, err := cbor.DumpObject(mgrap.ask)
+	if err != nil {
+		panic("Could not encode retrieval ask")
+	}
+	return [][]byte{out}, nil
+}
+
+func TestMinerGetRetrievalAsk(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("decodes the miner's ask", func(t *testing.T) {
+		plumbing := &minerGetRetrievalAskPlumbing{
+			ask: &miner.RetrievalAsk{
+				Price:           types.NewAttoFILFromFIL(3),
+				PaymentInterval: types.NewBytesAmount(2048),
+				UnsealPrice:     types.NewAttoFILFromFIL(9),
+			},
+		}
+
+		ask, err := MinerGetRetrievalAsk(context.Background(), plumbing, address.TestAddress2)
+		require.NoError(t, err)
+		require.NotNil(t, ask)
+
+		assert.Equal(t, types.NewAttoFILFromFIL(3), ask.Price)
+		assert.Equal(t, types.NewBytesAmount(2048), ask.PaymentInterval)
+		assert.Equal(t, types.NewAttoFILFromFIL(9), ask.UnsealPrice)
+	})
+
+	t.Run("returns nil when the miner has no ask", func(t *testing.T) {
+		ask, err := MinerGetRetrievalAsk(context.Background(), &minerGetRetrievalAskPlumbing{}, address.TestAddress2)
+		require.NoError(t, err)
+		assert.Nil(t, ask)
+	})
+}
+
 func requirePeerID() peer.ID {
 	id, err := peer.IDB58Decode("QmWbMozPyW6Ecagtxq7SXBXXLY5BNdP1GwHB2WoZCKMvcb")
 	if err != nil {
@@ -0,0 +1,188 @@
+package porcelain
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/actor/builtin/multisig"
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
+	vmErrors "github.com/filecoin-project/go-filecoin/vm/errors"
+)
+
+// MultisigInfo describes a multisig actor and its pending transactions.
+type MultisigInfo struct {
+	Signers   []address.Address
+	Threshold uint64
+	Pending   []*MultisigPendingTx
+}
+
+// MultisigPendingTx is a transaction of a multisig actor awaiting approvals, with its
+// params decoded.
+type MultisigPendingTx struct {
+	ID       uint64
+	Proposer address.Address
+	To       address.Address
+	Value    types.AttoFIL
+	Method   string
+	Params   []interface{}
+	Approved []address.Address
+}
+
+// msigSendPlumbing is the subset of the plumbing.API that sending to multisig actors uses.
+type msigSendPlumbing interface {
+	MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method string, params ...interface{}) (cid.Cid, error)
+	MessageWait(ctx context.Context, msgCid cid.Cid, cb func(*types.Block, *types.SignedMessage, *types.MessageReceipt) error) error
+}
+
+// MultisigCreate creates a multisig actor controlled by signers, threshold of which must
+// approve each transaction, and deposits value into it. It waits for the actor to be
+// created on chain and returns its address.
+func MultisigCreate(
+	ctx context.Context,
+	plumbing msigSendPlumbing,
+	from address.Address,
+	gasPrice types.AttoFIL,
+	gasLimit types.GasUnits,
+	signers []address.Address,
+	threshold uint64,
+	value types.AttoFIL,
+) (address.Address, error) {
+	msgCid, err := plumbing.MessageSend(
+		ctx,
+		from,
+		address.InitAddress,
+		value,
+		gasPrice,
+		gasLimit,
+		"createMultisig",
+		signers,
+		new(big.Int).SetUint64(threshold),
+	)
+	if err != nil {
+		return address.Undef, err
+	}
+
+	var msigAddr address.Address
+	err = plumbing.MessageWait(ctx, msgCid, func(blk *types.Block, smsg *types.SignedMessage, receipt *types.MessageReceipt) (err error) {
+		if receipt.ExitCode != uint8(0) {
+			return vmErrors.VMExitCodeToError(receipt.ExitCode, multisig.Errors)
+		}
+		msigAddr, err = address.NewFromBytes(receipt.Return[0])
+		return err
+	})
+	if err != nil {
+		return address.Undef, err
+	}
+
+	return msigAddr, nil
+}
+
+// MultisigPropose proposes that the multisig actor at msigAddr sends value to `to`,
+// calling method with params. It waits for the proposal to be mined and returns the id
+// of the transaction, which has already been sent if the threshold of the multisig is one.
+func MultisigPropose(
+	ctx context.Context,
+	plumbing msigSendPlumbing,
+	from address.Address,
+	msigAddr address.Address,
+	gasPrice types.AttoFIL,
+	gasLimit types.GasUnits,
+	to address.Address,
+	value types.AttoFIL,
+	method string,
+	params ...interface{},
+) (uint64, error) {
+	paramTypes, encoded, err := multisig.EncodeParams(params...)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to encode transaction params")
+	}
+
+	msgCid, err := plumbing.MessageSend(
+		ctx,
+		from,
+		msigAddr,
+		types.ZeroAttoFIL,
+		gasPrice,
+		gasLimit,
+		"propose",
+		to,
+		value,
+		method,
+		paramTypes,
+		encoded,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	var txID uint64
+	err = plumbing.MessageWait(ctx, msgCid, func(blk *types.Block, smsg *types.SignedMessage, receipt *types.MessageReceipt) error {
+		if receipt.ExitCode != uint8(0) {
+			return vmErrors.VMExitCodeToError(receipt.ExitCode, multisig.Errors)
+		}
+		txID = new(big.Int).SetBytes(receipt.Return[0]).Uint64()
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return txID, nil
+}
+
+type msigLsPlumbing interface {
+	ChainHeadKey() types.TipSetKey
+	MessageQuery(ctx context.Context, optFrom, to address.Address, method string, baseKey types.TipSetKey, params ...interface{}) ([][]byte, error)
+}
+
+// MultisigLs returns the signers, threshold and pending transactions of the multisig
+// actor at msigAddr, with the params of the transactions decoded.
+func MultisigLs(ctx context.Context, plumbing msigLsPlumbing, msigAddr address.Address) (*MultisigInfo, error) {
+	values, err := plumbing.MessageQuery(ctx, address.Undef, msigAddr, "getState", plumbing.ChainHeadKey())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to query multisig %s", msigAddr)
+	}
+
+	var state multisig.State
+	if err := cbor.DecodeInto(values[0], &state); err != nil {
+		return nil, errors.Wrap(err, "failed to decode multisig state")
+	}
+
+	info := &MultisigInfo{
+		Signers:   state.Signers,
+		Threshold: state.Threshold,
+	}
+	for key, tx := range state.Transactions {
+		id, err := strconv.ParseUint(key, 10, 64)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid transaction id %q", key)
+		}
+
+		params, err := multisig.DecodeParams(tx.ParamTypes, tx.Params)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode params of transaction %d", id)
+		}
+
+		info.Pending = append(info.Pending, &MultisigPendingTx{
+			ID:       id,
+			Proposer: tx.Proposer,
+			To:       tx.To,
+			Value:    tx.Value,
+			Method:   tx.Method,
+			Params:   params,
+			Approved: tx.Approved,
+		})
+	}
+	sort.Slice(info.Pending, func(i, j int) bool {
+		return info.Pending[i].ID < info.Pending[j].ID
+	})
+
+	return info, nil
+}
@@ -0,0 +1,205 @@
+package porcelain
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/protocol/storage/storagedeal"
+	"github.com/filecoin-project/go-filecoin/state"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+const (
+	// retrievalProviderCount is the maximum number of DHT provider records consulted
+	// when looking for miners that hold a piece.
+	retrievalProviderCount = 20
+
+	// retrievalProviderTimeout bounds the time spent waiting for DHT provider records.
+	retrievalProviderTimeout = 10 * time.Second
+
+	// retrievalPingTimeout bounds the time spent pinging each candidate miner.
+	retrievalPingTimeout = 5 * time.Second
+)
+
+// RetrievalMiner is a miner that can serve a piece, as found by ClientFindRetrievalMiners.
+type RetrievalMiner struct {
+	Address address.Address
+	PeerID  peer.ID
+
+	// Price is the price per byte of the miner's retrieval ask, or zero if it has not set one.
+	Price types.AttoFIL
+
+	// Latency is the round trip time of a ping to the miner.
+	Latency time.Duration
+}
+
+// cfrmPlumbing is the subset of the plumbing.API that ClientFindRetrievalMiners uses.
+type cfrmPlumbing interface {
+	ActorLs(ctx context.Context) (<-chan state.GetAllActorsResult, error)
+	ChainHeadKey() types.TipSetKey
+	DealsLs(context.Context) (<-chan *StorageDealLsResult, error)
+	MessageQuery(ctx context.Context, optFrom, to address.Address, method string, baseKey types.TipSetKey, params ...interface{}) ([][]byte, error)
+	NetworkFindProvidersAsync(ctx context.Context, key cid.Cid, count int) <-chan peer.AddrInfo
+	NetworkPing(ctx context.Context, pid peer.ID) (<-chan ping.Result, error)
+}
+
+// ClientFindRetrievalMiners finds the miners that can serve the piece with the given CID and
+// ranks them by the price of their retrieval ask, then by ping latency. Candidates are the
+// miners of completed deals for the piece in the local deal store, and the miners whose
+// on-chain peer IDs provide the piece in the DHT. Miners whose peer ID or retrieval ask
+// cannot be read, or that do not answer a ping, are left out.
+func ClientFindRetrievalMiners(ctx context.Context, plumbing cfrmPlumbing, pieceCID cid.Cid) ([]RetrievalMiner, error) {
+	candidates := make(map[address.Address]struct{})
+
+	if err := addDealMiners(ctx, plumbing, pieceCID, candidates); err != nil {
+		return nil, errors.Wrap(err, "failed to search deals for piece")
+	}
+
+	if err := addProviderMiners(ctx, plumbing, pieceCID, candidates); err != nil {
+		return nil, errors.Wrap(err, "failed to search providers of piece")
+	}
+
+	var miners []RetrievalMiner
+	for minerAddr := range candidates {
+		pid, err := MinerGetPeerID(ctx, plumbing, minerAddr)
+		if err != nil {
+			log.Debugf("skipping retrieval miner %s without peer ID: %s", minerAddr, err)
+			continue
+		}
+
+		latency, err := pingLatency(ctx, plumbing, pid)
+		if err != nil {
+			log.Debugf("skipping unreachable retrieval miner %s: %s", minerAddr, err)
+			continue
+		}
+
+		price := types.ZeroAttoFIL
+		ask, err := MinerGetRetrievalAsk(ctx, plumbing, minerAddr)
+		if err != nil {
+			log.Debugf("skipping retrieval miner %s without readable retrieval ask: %s", minerAddr, err)
+			continue
+		}
+		if ask != nil {
+			price = ask.Price
+		}
+
+		miners = append(miners, RetrievalMiner{
+			Address: minerAddr,
+			PeerID:  pid,
+			Price:   price,
+			Latency: latency,
+		})
+	}
+
+	sort.Slice(miners, func(i, j int) bool {
+		if !miners[i].Price.Equal(miners[j].Price) {
+			return miners[i].Price.LessThan(miners[j].Price)
+		}
+		return miners[i].Latency < miners[j].Latency
+	})
+
+	return miners, nil
+}
+
+// addDealMiners adds the miners of completed deals for the piece to candidates.
+func addDealMiners(ctx context.Context, plumbing cfrmPlumbing, pieceCID cid.Cid, candidates map[address.Address]struct{}) error {
+	dealCh, err := plumbing.DealsLs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for result := range dealCh {
+		if result.Err != nil {
+			return result.Err
+		}
+
+		deal := result.Deal
+		if deal.Proposal == nil || deal.Response == nil {
+			continue
+		}
+		if deal.Response.State == storagedeal.Complete && deal.Proposal.PieceRef.Equals(pieceCID) {
+			candidates[deal.Miner] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+// addProviderMiners adds the miners whose peers provide the piece in the DHT to candidates.
+// Provider records only name peers, so they are matched to miners through the peer IDs
+// registered in the miner actors.
+func addProviderMiners(ctx context.Context, plumbing cfrmPlumbing, pieceCID cid.Cid, candidates map[address.Address]struct{}) error {
+	findCtx, cancel := context.WithTimeout(ctx, retrievalProviderTimeout)
+	defer cancel()
+
+	providers := make(map[peer.ID]struct{})
+	for info := range plumbing.NetworkFindProvidersAsync(findCtx, pieceCID, retrievalProviderCount) {
+		providers[info.ID] = struct{}{}
+	}
+	if len(providers) == 0 {
+		return nil
+	}
+
+	actorCh, err := plumbing.ActorLs(ctx)
+	if err != nil {
+		return err
+	}
+
+	for actorResult := range actorCh {
+		if actorResult.Error != nil {
+			return actorResult.Error
+		}
+
+		if !types.MinerActorCodeCid.Equals(actorResult.Actor.Code) && !types.BootstrapMinerActorCodeCid.Equals(actorResult.Actor.Code) {
+			continue
+		}
+
+		minerAddr, err := address.NewFromString(actorResult.Address)
+		if err != nil {
+			return err
+		}
+
+		pid, err := MinerGetPeerID(ctx, plumbing, minerAddr)
+		if err != nil {
+			log.Debugf("skipping miner %s without peer ID: %s", minerAddr, err)
+			continue
+		}
+
+		if _, ok := providers[pid]; ok {
+			candidates[minerAddr] = struct{}{}
+		}
+	}
+
+	return nil
+}
+
+// pingLatency returns the round trip time of a single ping to the peer.
+func pingLatency(ctx context.Context, plumbing cfrmPlumbing, pid peer.ID) (time.Duration, error) {
+	ctx, cancel := context.WithTimeout(ctx, retrievalPingTimeout)
+	defer cancel()
+
+	res, err := plumbing.NetworkPing(ctx, pid)
+	if err != nil {
+		return 0, err
+	}
+
+	select {
+	case result, ok := <-res:
+		if !ok {
+			return 0, errors.New("ping channel closed")
+		}
+		if result.Error != nil {
+			return 0, result.Error
+		}
+		return result.RTT, nil
+	case <-ctx.Done():
+		return 0, ctx.Err()
+	}
+}
@@ -0,0 +1,167 @@
+package porcelain_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/actor"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/miner"
+	"github.com/filecoin-project/go-filecoin/address"
+	. "github.com/filecoin-project/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/protocol/storage/storagedeal"
+	"github.com/filecoin-project/go-filecoin/state"
+	th "github.com/filecoin-project/go-filecoin/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+type findRetrievalMinersPlumbing struct {
+	deals      []storagedeal.Deal
+	providers  []peer.ID
+	minerPeers map[address.Address]peer.ID
+	asks       map[address.Address]*miner.RetrievalAsk
+	latencies  map[peer.ID]time.Duration
+	// broken are the miners whose actor state cannot be queried.
+	broken map[address.Address]bool
+}
+
+func (p *findRetrievalMinersPlumbing) ActorLs(ctx context.Context) (<-chan state.GetAllActorsResult, error) {
+	out := make(chan state.GetAllActorsResult, len(p.minerPeers))
+	for addr := range p.minerPeers {
+		out <- state.GetAllActorsResult{
+			Address: addr.String(),
+			Actor:   &actor.Actor{Code: types.MinerActorCodeCid},
+		}
+	}
+	close(out)
+	return out, nil
+}
+
+func (p *findRetrievalMinersPlumbing) ChainHeadKey() types.TipSetKey {
+	return types.NewTipSetKey()
+}
+
+func (p *findRetrievalMinersPlumbing) DealsLs(ctx context.Context) (<-chan *StorageDealLsResult, error) {
+	out := make(chan *StorageDealLsResult, len(p.deals))
+	for _, deal := range p.deals {
+		out <- &StorageDealLsResult{Deal: deal}
+	}
+	close(out)
+	return out, nil
+}
+
+func (p *findRetrievalMinersPlumbing) MessageQuery(ctx context.Context, optFrom, to address.Address, method string, _ types.TipSetKey, params ...interface{}) ([][]byte, error) {
+	if p.broken[to] {
+		return nil, errors.New("failed to query miner actor")
+	}
+	switch method {
+	case "getPeerID":
+		return [][]byte{[]byte(p.minerPeers[to])}, nil
+	case "getRetrievalAsk":
+		ask, ok := p.asks[to]
+		if !ok {
+			return [][]byte{{}}, nil
+		}
+		out, err := cbor.DumpObject(ask)
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{out}, nil
+	}
+	return nil, errors.New("unexpected method " + method)
+}
+
+func (p *findRetrievalMinersPlumbing) NetworkFindProvidersAsync(ctx context.Context, key cid.Cid, count int) <-chan peer.AddrInfo {
+	out := make(chan peer.AddrInfo, len(p.providers))
+	for _, pid := range p.providers {
+		out <- peer.AddrInfo{ID: pid}
+	}
+	close(out)
+	return out
+}
+
+func (p *findRetrievalMinersPlumbing) NetworkPing(ctx context.Context, pid peer.ID) (<-chan ping.Result, error) {
+	latency, ok := p.latencies[pid]
+	if !ok {
+		return nil, errors.New("unreachable")
+	}
+	out := make(chan ping.Result, 1)
+	out <- ping.Result{RTT: latency}
+	return out, nil
+}
+
+func completedDeal(minerAddr address.Address, pieceCID cid.Cid) storagedeal.Deal {
+	return storagedeal.Deal{
+		Miner: minerAddr,
+		Proposal: &storagedeal.SignedProposal{
+			Proposal: storagedeal.Proposal{PieceRef: pieceCID},
+		},
+		Response: &storagedeal.SignedResponse{
+			Response: storagedeal.Response{State: storagedeal.Complete},
+		},
+	}
+}
+
+func TestClientFindRetrievalMiners(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	addrGetter := address.NewForTestGetter()
+	cidGetter := types.NewCidForTestGetter()
+	pieceCID := cidGetter()
+
+	dealMiner, providerMiner, cheapMiner, offlineMiner, otherMiner, brokenMiner := addrGetter(), addrGetter(), addrGetter(), addrGetter(), addrGetter(), addrGetter()
+	peers := map[address.Address]peer.ID{
+		brokenMiner:   th.RequireRandomPeerID(t),
+		dealMiner:     th.RequireRandomPeerID(t),
+		providerMiner: th.RequireRandomPeerID(t),
+		cheapMiner:    th.RequireRandomPeerID(t),
+		offlineMiner:  th.RequireRandomPeerID(t),
+		otherMiner:    th.RequireRandomPeerID(t),
+	}
+
+	plumbing := &findRetrievalMinersPlumbing{
+		deals: []storagedeal.Deal{
+			completedDeal(dealMiner, pieceCID),
+			completedDeal(cheapMiner, pieceCID),
+			completedDeal(offlineMiner, pieceCID),
+			completedDeal(otherMiner, cidGetter()),
+			completedDeal(brokenMiner, pieceCID),
+		},
+		providers:  []peer.ID{peers[providerMiner]},
+		minerPeers: peers,
+		asks: map[address.Address]*miner.RetrievalAsk{
+			dealMiner:     {Price: types.NewAttoFILFromFIL(2), PaymentInterval: types.NewBytesAmount(1)},
+			providerMiner: {Price: types.NewAttoFILFromFIL(2), PaymentInterval: types.NewBytesAmount(1)},
+		},
+		latencies: map[peer.ID]time.Duration{
+			peers[dealMiner]:     30 * time.Millisecond,
+			peers[providerMiner]: 10 * time.Millisecond,
+			peers[cheapMiner]:    50 * time.Millisecond,
+			peers[otherMiner]:    10 * time.Millisecond,
+			peers[brokenMiner]:   10 * time.Millisecond,
+		},
+		broken: map[address.Address]bool{brokenMiner: true},
+	}
+
+	miners, err := ClientFindRetrievalMiners(ctx, plumbing, pieceCID)
+	require.NoError(t, err)
+	require.Len(t, miners, 3)
+
+	// cheapest first, then lowest latency
+	assert.Equal(t, cheapMiner, miners[0].Address)
+	assert.Equal(t, types.ZeroAttoFIL, miners[0].Price)
+	assert.Equal(t, providerMiner, miners[1].Address)
+	assert.Equal(t, peers[providerMiner], miners[1].PeerID)
+	assert.Equal(t, 10*time.Millisecond, miners[1].Latency)
+	assert.Equal(t, dealMiner, miners[2].Address)
+}
@@ -13,14 +13,33 @@ import (
 // API here is the API for a retrieval client.
 type API struct {
 	rc *Client
+	dc *DAGClient
 }
 
 // NewAPI creates a new API for a retrieval client.
-func NewAPI(rc *Client) API {
-	return API{rc: rc}
+func NewAPI(rc *Client, dc *DAGClient) API {
+	return API{rc: rc, dc: dc}
 }
 
-// RetrievePiece retrieves bytes referenced by CID pieceCID
-func (a *API) RetrievePiece(ctx context.Context, pieceCID cid.Cid, mpid peer.ID, minerAddr address.Address) (io.ReadCloser, error) {
-	return a.rc.RetrievePiece(ctx, mpid, pieceCID)
+// RetrievePiece retrieves bytes referenced by CID pieceCID, starting at offset. A length of
+// zero retrieves up to the end of the piece.
+func (a *API) RetrievePiece(ctx context.Context, pieceCID cid.Cid, mpid peer.ID, minerAddr address.Address, offset, length uint64) (io.ReadCloser, error) {
+	return a.rc.RetrievePiece(ctx, mpid, pieceCID, offset, length)
+}
+
+// RetrievePieceForPayment retrieves bytes referenced by CID pieceCID, paying the miner
+// through a new payment channel configured by params.
+func (a *API) RetrievePieceForPayment(ctx context.Context, pieceCID cid.Cid, mpid peer.ID, minerAddr address.Address, offset, length uint64, params PaymentParams) (PaidReader, error) {
+	return a.rc.RetrievePieceForPayment(ctx, minerAddr, mpid, pieceCID, offset, length, params)
+}
+
+// RetrieveDAG retrieves the DAG below the node reached by following path from root into
+// the local blockstore, and returns the CID of that node.
+func (a *API) RetrieveDAG(ctx context.Context, root cid.Cid, path []string, mpid peer.ID) (cid.Cid, error) {
+	return a.dc.RetrieveDAG(ctx, mpid, root, path)
+}
+
+// Payments returns the payment records of paid retrievals made by or with this node.
+func (a *API) Payments() ([]*PaymentRecord, error) {
+	return a.rc.Payments()
 }
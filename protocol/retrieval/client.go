@@ -1,10 +1,10 @@
 package retrieval
 
 import (
-	"bytes"
 	"context"
+	"fmt"
 	"io"
-	"io/ioutil"
+	"sync"
 	"time"
 
 	"github.com/ipfs/go-cid"
@@ -14,8 +14,11 @@ import (
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/go-filecoin/actor/builtin/paymentbroker"
+	"github.com/filecoin-project/go-filecoin/address"
 	cbu "github.com/filecoin-project/go-filecoin/cborutil"
 	"github.com/filecoin-project/go-filecoin/net"
+	"github.com/filecoin-project/go-filecoin/types"
 )
 
 // RetrievePieceChunkSize defines the size of piece-chunks to be sent from miner to client. The maximum size of readable
@@ -23,78 +26,605 @@ import (
 // succeed.
 const RetrievePieceChunkSize = 256 << 8
 
+// DefaultChannelLifetime is the number of blocks a retrieval payment channel stays open
+// when the client does not choose an expiry.
+const DefaultChannelLifetime = 2 * MinChannelLifetime
+
 type clientPorcelainAPI interface {
+	ChainHeadKey() types.TipSetKey
+	ChainTipSet(types.TipSetKey) (types.TipSet, error)
+	MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method string, params ...interface{}) (cid.Cid, error)
+	MessageWait(ctx context.Context, msgCid cid.Cid, cb func(*types.Block, *types.SignedMessage, *types.MessageReceipt) error) error
+	MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address) (address.Address, error)
+	PaymentChannelLs(ctx context.Context, fromAddr address.Address, payerAddr address.Address) (map[string]*paymentbroker.PaymentChannel, error)
 	PingMinerWithTimeout(ctx context.Context, p peer.ID, to time.Duration) error
+	types.Signer
+}
+
+// PaymentParams configures how a client pays for a retrieval.
+type PaymentParams struct {
+	// Payer is the address of the account that funds the payment channel.
+	Payer address.Address
+
+	// ChannelAmount bounds the total the client will pay for the retrieval. A new payment
+	// channel is funded with this amount, and a reused one is topped up so that at least
+	// this amount remains unspent.
+	ChannelAmount types.AttoFIL
+
+	// ChannelExpiry is the block height at which the payment channel closes. If nil,
+	// the channel closes DefaultChannelLifetime blocks after the current height. A
+	// reused channel is extended to this height if it would close earlier.
+	ChannelExpiry *types.BlockHeight
+
+	// MaxPricePerByte is the highest price per byte the client will accept.
+	MaxPricePerByte types.AttoFIL
+
+	// GasPrice is the gas price of the message that creates or extends the payment channel.
+	GasPrice types.AttoFIL
+
+	// GasLimit is the gas limit of the message that creates or extends the payment channel.
+	GasLimit types.GasUnits
 }
 
 // Client is a client interface to the retrieval market protocols.
 type Client struct {
-	api  clientPorcelainAPI
-	host host.Host
-	log  logging.EventLogger
+	api      clientPorcelainAPI
+	host     host.Host
+	log      logging.EventLogger
+	payments *PaymentStore
+
+	// channelsLk guards channels, the payment channels of paid retrievals in progress,
+	// which are not reused until the retrieval ends.
+	channelsLk sync.Mutex
+	channels   map[string]struct{}
 }
 
 // NewClient produces a new Client.
-func NewClient(host host.Host, api clientPorcelainAPI) *Client {
+func NewClient(host host.Host, api clientPorcelainAPI, payments *PaymentStore) *Client {
 	return &Client{
-		api:  api,
-		host: host,
-		log:  logging.Logger("retrieval/client"),
+		api:      api,
+		host:     host,
+		log:      logging.Logger("retrieval/client"),
+		payments: payments,
+		channels: make(map[string]struct{}),
 	}
 }
 
-// RetrievePiece connects to a miner and transfers a piece of content.
-func (sc *Client) RetrievePiece(ctx context.Context, minerPeerID peer.ID, pieceCID cid.Cid) (io.ReadCloser, error) {
-	err := sc.api.PingMinerWithTimeout(ctx, minerPeerID, 15*time.Second)
-	if err == net.ErrPingSelf {
-		return nil, errors.New("attempting to retrieve piece from self. This is currently unsupported.  Please use a separate go-filecoin node as client")
-	}
-	if err != nil {
+// RetrievePiece connects to a miner and transfers a piece of content. Offset and length
+// select a byte range of the piece; a length of zero retrieves up to the end of the piece.
+// The piece is streamed from the miner as the returned reader is read.
+func (sc *Client) RetrievePiece(ctx context.Context, minerPeerID peer.ID, pieceCID cid.Cid, offset, length uint64) (io.ReadCloser, error) {
+	if err := sc.pingMiner(ctx, minerPeerID); err != nil {
 		return nil, err
 	}
 	s, err := sc.host.NewStream(ctx, minerPeerID, retrievalFreeProtocol)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to create stream to retrieval miner")
 	}
-	defer sc.safeCloseStream(s)
 
 	streamReader := cbu.NewMsgReader(s)
 
 	req := RetrievePieceRequest{
 		PieceRef: pieceCID,
+		Offset:   offset,
+		Length:   length,
+	}
+
+	if err := cbu.NewMsgWriter(s).WriteMsg(&req); err != nil {
+		sc.safeCloseStream(s)
+		return nil, errors.Wrap(err, "failed to write request message to stream")
+	}
+
+	var res RetrievePieceResponse
+	if err := streamReader.ReadMsg(&res); err != nil {
+		sc.safeCloseStream(s)
+		return nil, errors.Wrap(err, "failed to read response message from stream")
+	}
+
+	if res.Status != Success {
+		sc.safeCloseStream(s)
+		return nil, errors.Errorf("could not retrieve piece - error from miner: %s", res.ErrorMessage)
+	}
+
+	return &pieceReader{
+		streamReader: streamReader,
+		onClose: func() {
+			sc.safeCloseStream(s)
+		},
+	}, nil
+}
+
+// QueryRetrievalTerms asks a miner for the price per byte, payment interval and unseal
+// price it would charge to retrieve a piece.
+func (sc *Client) QueryRetrievalTerms(ctx context.Context, minerPeerID peer.ID, pieceCID cid.Cid) (*RetrievePieceResponse, error) {
+	s, err := sc.host.NewStream(ctx, minerPeerID, retrievalTermsProtocol)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create stream to retrieval miner")
+	}
+	defer sc.safeCloseStream(s)
+
+	if err := cbu.NewMsgWriter(s).WriteMsg(&RetrievePieceRequest{PieceRef: pieceCID}); err != nil {
+		return nil, errors.Wrap(err, "failed to write terms request to stream")
+	}
+
+	var res RetrievePieceResponse
+	if err := cbu.NewMsgReader(s).ReadMsg(&res); err != nil {
+		return nil, errors.Wrap(err, "failed to read terms response from stream")
+	}
+	if res.Status != Success {
+		return nil, errors.Errorf("could not get retrieval terms - error from miner: %s", res.ErrorMessage)
+	}
+	return &res, nil
+}
+
+// RetrievePieceForPayment pays the owner of the miner through a payment channel to
+// transfer a range of a piece of content. The miner's terms are checked before the
+// channel is funded. The client keeps one channel per miner: a channel left open by an
+// earlier retrieval from the miner is reused, and topped up only if it holds less than
+// params.ChannelAmount unspent, so a retrieval that fails does not strand another
+// channel's funds. The client pays the unseal price before any bytes are sent, and then
+// pays with a voucher covering all bytes received whenever the unpaid bytes could
+// otherwise exceed the miner's payment interval, and at the end. Every voucher is
+// recorded as it is sent, so that the payment can be audited later and the channel reused.
+func (sc *Client) RetrievePieceForPayment(ctx context.Context, minerAddr address.Address, minerPeerID peer.ID, pieceCID cid.Cid, offset, length uint64, params PaymentParams) (PaidReader, error) {
+	if err := sc.pingMiner(ctx, minerPeerID); err != nil {
+		return nil, err
+	}
+
+	terms, err := sc.QueryRetrievalTerms(ctx, minerPeerID, pieceCID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkRetrievalTerms(terms, params); err != nil {
+		return nil, err
+	}
+
+	ownerAddr, err := sc.api.MinerGetOwnerAddress(ctx, minerAddr)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get miner owner address")
+	}
+
+	head, err := sc.api.ChainTipSet(sc.api.ChainHeadKey())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not access head tipset")
+	}
+	h, err := head.Height()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get current block height")
+	}
+	validAt := types.NewBlockHeight(h)
+
+	eol := params.ChannelExpiry
+	if eol == nil {
+		eol = validAt.Add(types.NewBlockHeight(DefaultChannelLifetime))
+	}
+
+	payment, base, err := sc.openChannel(ctx, minerAddr, ownerAddr, pieceCID, params, validAt, eol)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := sc.host.NewStream(ctx, minerPeerID, retrievalPaidProtocol)
+	if err != nil {
+		sc.releaseChannel(payment)
+		return nil, errors.Wrap(err, "failed to create stream to retrieval miner")
+	}
+
+	// closeStream ends the retrieval and frees the channel for later retrievals.
+	closeStream := func() {
+		sc.safeCloseStream(s)
+		sc.releaseChannel(payment)
+	}
+
+	streamReader := cbu.NewMsgReader(s)
+
+	req := RetrievePieceForPaymentRequest{
+		RetrievePieceRequest: RetrievePieceRequest{
+			PieceRef: pieceCID,
+			Offset:   offset,
+			Length:   length,
+		},
+		Payment: *payment,
 	}
 
 	if err := cbu.NewMsgWriter(s).WriteMsg(&req); err != nil {
+		closeStream()
 		return nil, errors.Wrap(err, "failed to write request message to stream")
 	}
 
 	var res RetrievePieceResponse
 	if err := streamReader.ReadMsg(&res); err != nil {
+		closeStream()
 		return nil, errors.Wrap(err, "failed to read response message from stream")
 	}
 
 	if res.Status != Success {
+		closeStream()
 		return nil, errors.Errorf("could not retrieve piece - error from miner: %s", res.ErrorMessage)
 	}
 
-	var buf []byte
-	for {
-		var chunk RetrievePieceChunk
-		if err := streamReader.ReadMsg(&chunk); err != nil {
-			if err == io.EOF {
-				break
+	// The miner may have changed its terms since they were queried.
+	if err := checkRetrievalTerms(&res, params); err != nil {
+		closeStream()
+		return nil, err
+	}
+
+	record := &PaymentRecord{
+		PieceRef: pieceCID,
+		Miner:    minerAddr,
+		Payment:  *payment,
+	}
+
+	reader := &paidPieceReader{paid: types.ZeroAttoFIL}
+
+	// pay sends a voucher for the unseal price and the first n bytes, on top of what was
+	// paid through the channel before this retrieval.
+	var paidBytes uint64
+	pay := func(n uint64) error {
+		owed := res.UnsealPrice.Add(res.PricePerByte.CalculatePrice(types.NewBytesAmount(n)))
+		if owed.GreaterThan(params.ChannelAmount) {
+			return fmt.Errorf("payment channel funds (%s) exhausted after %d bytes", params.ChannelAmount, n)
+		}
+
+		voucher, err := sc.makeVoucher(payment, ownerAddr, base.Add(owed), validAt)
+		if err != nil {
+			return errors.Wrap(err, "failed to create payment voucher")
+		}
+
+		// Record the voucher before sending it, so that a later retrieval on the channel
+		// never pays less than the miner has already been given.
+		record.Voucher = voucher
+		record.BytesTransferred = n
+		if err := sc.payments.Put(record); err != nil {
+			return errors.Wrap(err, "failed to record retrieval voucher")
+		}
+
+		if err := cbu.NewMsgWriter(s).WriteMsg(voucher); err != nil {
+			return errors.Wrap(err, "failed to write payment voucher to stream")
+		}
+
+		paidBytes = n
+		reader.paid = owed
+		return nil
+	}
+
+	// The miner sends nothing until the piece's unseal price is paid.
+	if res.UnsealPrice.IsPositive() {
+		if err := pay(0); err != nil {
+			closeStream()
+			return nil, err
+		}
+	}
+
+	var received uint64
+	reader.pieceReader = pieceReader{
+		streamReader: streamReader,
+		onChunk: func(total uint64) error {
+			received = total
+			// Pay before the next chunk could take the unpaid bytes past the payment interval,
+			// at which point the miner would wait for payment.
+			if received+RetrievePieceChunkSize-paidBytes <= res.PaymentInterval {
+				return nil
 			}
+			return pay(received)
+		},
+		onClose: func() {
+			// The miner holds the stream open until the bytes it sent are paid for.
+			if received > paidBytes {
+				if err := pay(received); err != nil {
+					sc.log.Errorf("failed to pay for retrieved bytes: %s", err)
+				}
+			}
+			closeStream()
+		},
+	}
+	return reader, nil
+}
 
-			return nil, errors.Errorf("could not read chunk from stream: %s", err.Error())
+// checkRetrievalTerms returns an error if a miner's retrieval terms are not acceptable to
+// a client paying with params.
+func checkRetrievalTerms(terms *RetrievePieceResponse, params PaymentParams) error {
+	if terms.PricePerByte.GreaterThan(params.MaxPricePerByte) {
+		return fmt.Errorf("miner price per byte (%s) exceeds maximum (%s)", terms.PricePerByte, params.MaxPricePerByte)
+	}
+	if terms.UnsealPrice.GreaterThan(params.ChannelAmount) {
+		return fmt.Errorf("miner unseal price (%s) exceeds payment channel funds (%s)", terms.UnsealPrice, params.ChannelAmount)
+	}
+	// A miner would wait for payment before sending the first chunk, which the client pays
+	// for only once received.
+	if terms.PaymentInterval < RetrievePieceChunkSize {
+		return fmt.Errorf("miner payment interval (%d bytes) is smaller than a chunk (%d bytes)", terms.PaymentInterval, RetrievePieceChunkSize)
+	}
+	return nil
+}
+
+// Payments returns the payment records of all paid retrievals made by or with this node.
+func (sc *Client) Payments() ([]*PaymentRecord, error) {
+	return sc.payments.List()
+}
+
+func (sc *Client) pingMiner(ctx context.Context, minerPeerID peer.ID) error {
+	err := sc.api.PingMinerWithTimeout(ctx, minerPeerID, 15*time.Second)
+	if err == net.ErrPingSelf {
+		return errors.New("attempting to retrieve piece from self. This is currently unsupported.  Please use a separate go-filecoin node as client")
+	}
+	return err
+}
+
+// openChannel returns a payment channel from the payer to the owner of the miner holding at
+// least params.ChannelAmount unspent, and the amount already paid through it. A channel
+// recorded by an earlier retrieval from the miner is reused if it is still open and not in
+// use, and extended if it holds too little or closes too early. Otherwise a new channel is
+// created and recorded straight away, so that it is reused even if this retrieval fails
+// before paying anything. The channel is claimed until releaseChannel is called.
+func (sc *Client) openChannel(ctx context.Context, minerAddr, target address.Address, pieceCID cid.Cid, params PaymentParams, validAt, eol *types.BlockHeight) (*PaymentInfo, types.AttoFIL, error) {
+	payment, channel, err := sc.findChannel(ctx, minerAddr, target, params.Payer)
+	if err != nil {
+		return nil, types.ZeroAttoFIL, err
+	}
+	if payment == nil {
+		payment, err := sc.createChannel(ctx, target, params, eol)
+		if err != nil {
+			return nil, types.ZeroAttoFIL, err
+		}
+		sc.claimChannel(payment)
+		if err := sc.payments.Put(&PaymentRecord{PieceRef: pieceCID, Miner: minerAddr, Payment: *payment}); err != nil {
+			sc.releaseChannel(payment)
+			return nil, types.ZeroAttoFIL, err
 		}
+		return payment, types.ZeroAttoFIL, nil
+	}
+
+	base, err := sc.payments.Paid(payment, channel)
+	if err != nil {
+		sc.releaseChannel(payment)
+		return nil, types.ZeroAttoFIL, err
+	}
 
-		buf = append(buf, chunk.Data...)
+	topUp := base.Add(params.ChannelAmount).Sub(channel.Amount)
+	if !topUp.IsPositive() {
+		topUp = types.ZeroAttoFIL
 	}
+	requiredEol := validAt.Add(types.NewBlockHeight(MinChannelLifetime))
+	if params.ChannelExpiry != nil && params.ChannelExpiry.GreaterThan(requiredEol) {
+		requiredEol = params.ChannelExpiry
+	}
+	if topUp.IsPositive() || channel.Eol.LessThan(requiredEol) {
+		newEol := channel.Eol
+		if eol.GreaterThan(newEol) {
+			newEol = eol
+		}
+		if err := sc.extendChannel(ctx, payment, topUp, newEol, params); err != nil {
+			sc.releaseChannel(payment)
+			return nil, types.ZeroAttoFIL, err
+		}
+	}
+
+	return payment, base, nil
+}
 
-	// TODO: Figure out how to stream piece-bytes w/out having to buffer.
-	buffered := ioutil.NopCloser(bytes.NewReader(buf))
+// findChannel returns a payment channel recorded by an earlier retrieval from the miner
+// that is still open on chain, targets its owner and is not in use, and claims it. It
+// returns nil if there is none.
+func (sc *Client) findChannel(ctx context.Context, minerAddr, target, payer address.Address) (*PaymentInfo, *paymentbroker.PaymentChannel, error) {
+	records, err := sc.payments.List()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var channels map[string]*paymentbroker.PaymentChannel
+	for _, record := range records {
+		if record.Miner != minerAddr || record.Payment.Payer != payer {
+			continue
+		}
+
+		if channels == nil {
+			channels, err = sc.api.PaymentChannelLs(ctx, payer, payer)
+			if err != nil {
+				return nil, nil, errors.Wrap(err, "could not get payment channels for payer")
+			}
+		}
+		channel, ok := channels[record.Payment.Channel.KeyString()]
+		if !ok || channel.Target != target {
+			continue
+		}
+
+		if sc.claimChannel(&record.Payment) {
+			return &record.Payment, channel, nil
+		}
+	}
+	return nil, nil, nil
+}
+
+// claimChannel marks a payment channel as in use by a retrieval, and returns false if it
+// already is.
+func (sc *Client) claimChannel(payment *PaymentInfo) bool {
+	sc.channelsLk.Lock()
+	defer sc.channelsLk.Unlock()
+
+	key := paymentRecordKey(payment.Payer, payment.Channel).String()
+	if _, ok := sc.channels[key]; ok {
+		return false
+	}
+	sc.channels[key] = struct{}{}
+	return true
+}
+
+func (sc *Client) releaseChannel(payment *PaymentInfo) {
+	sc.channelsLk.Lock()
+	defer sc.channelsLk.Unlock()
+	delete(sc.channels, paymentRecordKey(payment.Payer, payment.Channel).String())
+}
+
+// extendChannel adds funds to a payment channel, moves its eol to the given height and
+// waits for the change to appear on chain.
+func (sc *Client) extendChannel(ctx context.Context, payment *PaymentInfo, amount types.AttoFIL, eol *types.BlockHeight, params PaymentParams) error {
+	msgCid, err := sc.api.MessageSend(ctx,
+		payment.Payer,
+		address.PaymentBrokerAddress,
+		amount,
+		params.GasPrice,
+		params.GasLimit,
+		"extend",
+		payment.Channel,
+		eol)
+	if err != nil {
+		return errors.Wrap(err, "failed to extend payment channel")
+	}
+
+	return sc.api.MessageWait(ctx, msgCid, func(block *types.Block, message *types.SignedMessage, receipt *types.MessageReceipt) error {
+		if receipt.ExitCode != 0 {
+			return fmt.Errorf("extend failed %d", receipt.ExitCode)
+		}
+		return nil
+	})
+}
 
-	return buffered, nil
+// createChannel creates a payment channel from the payer to the target and waits for
+// it to appear on chain.
+func (sc *Client) createChannel(ctx context.Context, target address.Address, params PaymentParams, eol *types.BlockHeight) (*PaymentInfo, error) {
+	msgCid, err := sc.api.MessageSend(ctx,
+		params.Payer,
+		address.PaymentBrokerAddress,
+		params.ChannelAmount,
+		params.GasPrice,
+		params.GasLimit,
+		"createChannel",
+		target,
+		eol)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create payment channel")
+	}
+
+	var channel *types.ChannelID
+	err = sc.api.MessageWait(ctx, msgCid, func(block *types.Block, message *types.SignedMessage, receipt *types.MessageReceipt) error {
+		if receipt.ExitCode != 0 {
+			return fmt.Errorf("createChannel failed %d", receipt.ExitCode)
+		}
+
+		channel = types.NewChannelIDFromBytes(receipt.Return[0])
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PaymentInfo{
+		Payer:         params.Payer,
+		Channel:       channel,
+		ChannelMsgCid: &msgCid,
+	}, nil
+}
+
+func (sc *Client) makeVoucher(payment *PaymentInfo, target address.Address, amount types.AttoFIL, validAt *types.BlockHeight) (*types.PaymentVoucher, error) {
+	sig, err := paymentbroker.SignVoucher(payment.Channel, amount, validAt, payment.Payer, nil, sc.api)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.PaymentVoucher{
+		Channel:   *payment.Channel,
+		Payer:     payment.Payer,
+		Target:    target,
+		Amount:    amount,
+		ValidAt:   *validAt,
+		Signature: sig,
+	}, nil
+}
+
+// pieceReader reads piece chunks from a retrieval stream as they are requested.
+type pieceReader struct {
+	streamReader *cbu.MsgReader
+
+	// onChunk, if set, is called with the total number of bytes received after each chunk.
+	onChunk func(received uint64) error
+	// onClose is called once, when the piece has been read or the reader is closed.
+	onClose func()
+
+	buf      []byte
+	received uint64
+	err      error
+	closed   bool
+}
+
+var _ io.ReadCloser = (*pieceReader)(nil)
+
+// PaidReader reads a piece retrieved for payment.
+type PaidReader interface {
+	io.ReadCloser
+
+	// Paid returns the amount paid to the miner for the retrieval so far, which is final
+	// once the reader is closed.
+	Paid() types.AttoFIL
+}
+
+// paidPieceReader is a pieceReader that tracks what has been paid for the piece.
+type paidPieceReader struct {
+	pieceReader
+	paid types.AttoFIL
+}
+
+var _ PaidReader = (*paidPieceReader)(nil)
+
+// Paid implements PaidReader.
+func (pr *paidPieceReader) Paid() types.AttoFIL {
+	return pr.paid
+}
+
+// Read implements io.Reader.
+func (pr *pieceReader) Read(p []byte) (int, error) {
+	for len(pr.buf) == 0 {
+		if pr.err != nil {
+			return 0, pr.err
+		}
+		pr.err = pr.nextChunk()
+		if pr.err != nil {
+			pr.finish()
+		}
+	}
+
+	n := copy(p, pr.buf)
+	pr.buf = pr.buf[n:]
+	return n, nil
+}
+
+// Close implements io.Closer. Closing the reader before the end of the piece abandons
+// the rest of the transfer.
+func (pr *pieceReader) Close() error {
+	if pr.err == nil {
+		pr.err = errors.New("piece reader is closed")
+	}
+	pr.finish()
+	return nil
+}
+
+func (pr *pieceReader) nextChunk() error {
+	var chunk RetrievePieceChunk
+	if err := pr.streamReader.ReadMsg(&chunk); err != nil {
+		if err == io.EOF {
+			return io.EOF
+		}
+		return errors.Errorf("could not read chunk from stream: %s", err.Error())
+	}
+
+	pr.buf = chunk.Data
+	pr.received += uint64(len(chunk.Data))
+
+	if pr.onChunk != nil {
+		if err := pr.onChunk(pr.received); err != nil {
+			pr.buf = nil
+			return err
+		}
+	}
+	return nil
+}
+
+func (pr *pieceReader) finish() {
+	if pr.closed {
+		return
+	}
+	pr.closed = true
+	pr.onClose()
 }
 
 func (sc *Client) safeCloseStream(stream inet.Stream) {
@@ -0,0 +1,110 @@
+package retrieval
+
+import (
+	"context"
+
+	bserv "github.com/ipfs/go-blockservice"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-graphsync"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	offline "github.com/ipfs/go-ipfs-exchange-offline"
+	format "github.com/ipfs/go-ipld-format"
+	"github.com/ipfs/go-merkledag"
+	"github.com/ipld/go-ipld-prime"
+	ipldfree "github.com/ipld/go-ipld-prime/impl/free"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	selectorbuilder "github.com/ipld/go-ipld-prime/traversal/selector/builder"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+)
+
+// maxDAGDepth bounds the depth of the recursive selector used to retrieve a DAG.
+const maxDAGDepth = 1024
+
+// graphExchange is the subset of graphsync that DAGClient uses.
+type graphExchange interface {
+	Request(ctx context.Context, p peer.ID, root ipld.Link, selector ipld.Node) (<-chan graphsync.ResponseProgress, <-chan error)
+}
+
+// DAGClient retrieves IPLD DAGs from miners over graphsync. The graphsync exchange writes
+// retrieved blocks into the local blockstore, so a retrieved DAG can be read afterwards
+// like any data imported locally.
+type DAGClient struct {
+	exchange graphExchange
+	dserv    format.DAGService
+	ssb      selectorbuilder.SelectorSpecBuilder
+}
+
+// NewDAGClient creates a DAGClient that retrieves into the given blockstore, which must be
+// the one the exchange stores blocks in.
+func NewDAGClient(exchange graphExchange, bs bstore.Blockstore) *DAGClient {
+	return &DAGClient{
+		exchange: exchange,
+		dserv:    merkledag.NewDAGService(bserv.New(bs, offline.Exchange(bs))),
+		ssb:      selectorbuilder.NewSelectorSpecBuilder(ipldfree.NodeBuilder()),
+	}
+}
+
+// RetrieveDAG retrieves the DAG below the node reached by following path, a sequence of
+// link names, from root. Only the nodes along the path and the DAG below its end are
+// transferred. It returns the CID of the node at the end of the path once all of its
+// blocks are stored locally.
+func (dc *DAGClient) RetrieveDAG(ctx context.Context, minerPeerID peer.ID, root cid.Cid, path []string) (cid.Cid, error) {
+	target := root
+	for _, name := range path {
+		if err := dc.fetch(ctx, minerPeerID, target, dc.ssb.Matcher()); err != nil {
+			return cid.Undef, err
+		}
+
+		nd, err := dc.dserv.Get(ctx, target)
+		if err != nil {
+			return cid.Undef, errors.Wrapf(err, "miner did not send block %s", target)
+		}
+
+		link, _, err := nd.ResolveLink([]string{name})
+		if err != nil {
+			return cid.Undef, errors.Wrapf(err, "could not resolve %q in %s", name, target)
+		}
+		target = link.Cid
+	}
+
+	all := dc.ssb.ExploreRecursive(maxDAGDepth, dc.ssb.ExploreAll(dc.ssb.ExploreRecursiveEdge()))
+	if err := dc.fetch(ctx, minerPeerID, target, all); err != nil {
+		return cid.Undef, err
+	}
+
+	// The miner may hold only part of the DAG, for instance when it was stored in
+	// several pieces with different miners.
+	if err := merkledag.EnumerateChildren(ctx, merkledag.GetLinksDirect(dc.dserv), target, cid.NewSet().Visit); err != nil {
+		return cid.Undef, errors.Wrapf(err, "retrieved DAG below %s is incomplete", target)
+	}
+
+	return target, nil
+}
+
+// fetch runs a graphsync request to completion and returns the first error it reports.
+func (dc *DAGClient) fetch(ctx context.Context, minerPeerID peer.ID, root cid.Cid, selector selectorbuilder.SelectorSpec) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	responses, errs := dc.exchange.Request(ctx, minerPeerID, cidlink.Link{Cid: root}, selector.Node())
+
+	var anyError error
+	for responses != nil || errs != nil {
+		select {
+		case _, ok := <-responses:
+			if !ok {
+				responses = nil
+			}
+		case err, ok := <-errs:
+			if !ok {
+				errs = nil
+				continue
+			}
+			if anyError == nil {
+				anyError = errors.Wrapf(err, "graphsync request for %s failed", root)
+			}
+		}
+	}
+	return anyError
+}
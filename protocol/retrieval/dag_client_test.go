@@ -0,0 +1,123 @@
+package retrieval_test
+
+import (
+	"context"
+	"testing"
+
+	bserv "github.com/ipfs/go-blockservice"
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-datastore"
+	dssync "github.com/ipfs/go-datastore/sync"
+	"github.com/ipfs/go-graphsync"
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	offline "github.com/ipfs/go-ipfs-exchange-offline"
+	format "github.com/ipfs/go-ipld-format"
+	"github.com/ipfs/go-merkledag"
+	"github.com/ipld/go-ipld-prime"
+	cidlink "github.com/ipld/go-ipld-prime/linking/cid"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/protocol/retrieval"
+	th "github.com/filecoin-project/go-filecoin/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+)
+
+// fakeGraphExchange serves every request with all blocks reachable from the root that
+// the source DAG holds, ignoring the selector.
+type fakeGraphExchange struct {
+	source    format.DAGService
+	dest      bstore.Blockstore
+	requested []cid.Cid
+}
+
+func (fge *fakeGraphExchange) Request(ctx context.Context, p peer.ID, root ipld.Link, selector ipld.Node) (<-chan graphsync.ResponseProgress, <-chan error) {
+	c := root.(cidlink.Link).Cid
+	fge.requested = append(fge.requested, c)
+	fge.copy(ctx, c)
+
+	responses := make(chan graphsync.ResponseProgress)
+	errs := make(chan error)
+	close(responses)
+	close(errs)
+	return responses, errs
+}
+
+func (fge *fakeGraphExchange) copy(ctx context.Context, c cid.Cid) {
+	nd, err := fge.source.Get(ctx, c)
+	if err != nil {
+		return
+	}
+	if err := fge.dest.Put(nd); err != nil {
+		panic(err)
+	}
+	for _, l := range nd.Links() {
+		fge.copy(ctx, l.Cid)
+	}
+}
+
+func newTestBlockstore() bstore.Blockstore {
+	return bstore.NewBlockstore(dssync.MutexWrap(datastore.NewMapDatastore()))
+}
+
+func TestDAGClientRetrieveDAG(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	minerPID := th.RequireRandomPeerID(t)
+
+	file := merkledag.NodeWithData([]byte("hello"))
+	other := merkledag.NodeWithData([]byte("other"))
+	dir := merkledag.NodeWithData(nil)
+	require.NoError(t, dir.AddNodeLink("a", file))
+	require.NoError(t, dir.AddNodeLink("b", other))
+
+	newSource := func(nodes ...format.Node) format.DAGService {
+		bs := newTestBlockstore()
+		dserv := merkledag.NewDAGService(bserv.New(bs, offline.Exchange(bs)))
+		require.NoError(t, dserv.AddMany(ctx, nodes))
+		return dserv
+	}
+
+	t.Run("retrieves the whole DAG", func(t *testing.T) {
+		dest := newTestBlockstore()
+		exchange := &fakeGraphExchange{source: newSource(dir, file, other), dest: dest}
+
+		target, err := retrieval.NewDAGClient(exchange, dest).RetrieveDAG(ctx, minerPID, dir.Cid(), nil)
+		require.NoError(t, err)
+		assert.Equal(t, dir.Cid(), target)
+
+		for _, c := range []cid.Cid{dir.Cid(), file.Cid(), other.Cid()} {
+			has, err := dest.Has(c)
+			require.NoError(t, err)
+			assert.True(t, has)
+		}
+	})
+
+	t.Run("follows a path to the requested node", func(t *testing.T) {
+		dest := newTestBlockstore()
+		exchange := &fakeGraphExchange{source: newSource(dir, file, other), dest: dest}
+
+		target, err := retrieval.NewDAGClient(exchange, dest).RetrieveDAG(ctx, minerPID, dir.Cid(), []string{"a"})
+		require.NoError(t, err)
+		assert.Equal(t, file.Cid(), target)
+		assert.Equal(t, []cid.Cid{dir.Cid(), file.Cid()}, exchange.requested)
+	})
+
+	t.Run("fails on an unknown link name", func(t *testing.T) {
+		dest := newTestBlockstore()
+		exchange := &fakeGraphExchange{source: newSource(dir, file, other), dest: dest}
+
+		_, err := retrieval.NewDAGClient(exchange, dest).RetrieveDAG(ctx, minerPID, dir.Cid(), []string{"missing"})
+		assert.Error(t, err)
+	})
+
+	t.Run("fails when the miner holds only part of the DAG", func(t *testing.T) {
+		dest := newTestBlockstore()
+		exchange := &fakeGraphExchange{source: newSource(dir, file), dest: dest}
+
+		_, err := retrieval.NewDAGClient(exchange, dest).RetrieveDAG(ctx, minerPID, dir.Cid(), nil)
+		assert.Error(t, err)
+	})
+}
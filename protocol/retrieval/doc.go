@@ -3,6 +3,23 @@
 // 1. CLIENT opens /fil/retrieval/free/0.0.0 stream to MINER
 // 2. CLIENT sends MINER a RetrievePieceRequest
 // 3. MINER sends CLIENT a RetrievePieceResponse with Status set to Success if it has PieceRef in a sealed sector
-// 4. MINER sends CLIENT RetrievePieceChunks until all data associated with PieceRef has been sent
+// 4. MINER sends CLIENT RetrievePieceChunks until all data associated with PieceRef (or the range selected by Offset and Length) has been sent
 // 5. CLIENT reads RetrievePieceChunk from stream until EOF and then closes stream
+//
+// Paid retrieval works the same way over /fil/retrieval/paid/0.0.0, with these differences:
+//
+// 1. CLIENT asks MINER for its terms over /fil/retrieval/terms/0.0.0 and checks them
+// 2. CLIENT reuses its payment channel to the MINER's owner, topping it up, or creates one before opening the stream
+// 3. CLIENT sends MINER a RetrievePieceForPaymentRequest identifying the channel
+// 4. MINER validates the channel and replies with its PricePerByte, PaymentInterval and UnsealPrice
+// 5. CLIENT sends MINER a signed PaymentVoucher for the UnsealPrice, before which MINER sends nothing
+// 6. CLIENT sends MINER a PaymentVoucher covering all bytes received before the unpaid bytes could pass PaymentInterval
+// 7. MINER stops streaming when more than PaymentInterval bytes are unpaid
+// 8. MINER closes the stream for writing after the last chunk, and CLIENT pays for the rest
+//
+// Both sides record the latest voucher on each channel in a PaymentStore, so that the MINER can redeem it later
+// and a later retrieval on the channel pays only above it.
+//
+// A DAGClient retrieves a stored DAG, or the part of it below a path, over graphsync instead.
+// The blocks are written into the CLIENT's blockstore rather than returned as piece bytes.
 package retrieval
@@ -1,20 +1,47 @@
 package retrieval
 
 import (
+	"context"
+	"fmt"
+	"io"
 	"io/ioutil"
+	"math/big"
+	"sync"
+	"time"
 
+	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log"
 	host "github.com/libp2p/go-libp2p-core/host"
 	inet "github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/pkg/errors"
 
+	minerActor "github.com/filecoin-project/go-filecoin/actor/builtin/miner"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/paymentbroker"
+	"github.com/filecoin-project/go-filecoin/address"
 	cbu "github.com/filecoin-project/go-filecoin/cborutil"
 	"github.com/filecoin-project/go-filecoin/proofs/sectorbuilder"
+	"github.com/filecoin-project/go-filecoin/types"
 )
 
 var log = logging.Logger("/fil/retrieval")
 
-const retrievalFreeProtocol = protocol.ID("/fil/retrieval/free/0.0.0")
+const (
+	retrievalFreeProtocol  = protocol.ID("/fil/retrieval/free/0.0.0")
+	retrievalPaidProtocol  = protocol.ID("/fil/retrieval/paid/0.0.0")
+	retrievalTermsProtocol = protocol.ID("/fil/retrieval/terms/0.0.0")
+
+	// DefaultPaymentInterval is the number of bytes a miner streams ahead of payment
+	// when it has not published a retrieval ask.
+	DefaultPaymentInterval = 4 * RetrievePieceChunkSize
+
+	// MinChannelLifetime is the minimum number of blocks a payment channel must remain
+	// open after a paid retrieval starts, giving the miner time to redeem its vouchers.
+	MinChannelLifetime = 100
+
+	waitForPaymentChannelDuration = 2 * time.Minute
+	waitForPaymentDuration        = 30 * time.Second
+)
 
 // TODO: better name
 type minerNode interface {
@@ -22,18 +49,42 @@ type minerNode interface {
 	SectorBuilder() sectorbuilder.SectorBuilder
 }
 
+// minerPorcelain is the subset of the porcelain API that retrieval.Miner needs.
+type minerPorcelain interface {
+	ChainHeadKey() types.TipSetKey
+	ChainTipSet(types.TipSetKey) (types.TipSet, error)
+	ConfigGet(dottedPath string) (interface{}, error)
+	MessageWait(ctx context.Context, msgCid cid.Cid, cb func(*types.Block, *types.SignedMessage, *types.MessageReceipt) error) error
+	MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address) (address.Address, error)
+	MinerGetRetrievalAsk(ctx context.Context, minerAddr address.Address) (*minerActor.RetrievalAsk, error)
+	PaymentChannelLs(ctx context.Context, fromAddr address.Address, payerAddr address.Address) (map[string]*paymentbroker.PaymentChannel, error)
+}
+
 // Miner serves requests for pieces from RetrievalClients.
 type Miner struct {
-	node minerNode
+	node         minerNode
+	porcelainAPI minerPorcelain
+	payments     *PaymentStore
+
+	// channelsLk guards channels, the payment channels of paid retrievals in progress.
+	// A client may reuse a channel for later retrievals, but not concurrently, since each
+	// retrieval counts only the payment above the channel's latest voucher when it starts.
+	channelsLk sync.Mutex
+	channels   map[string]struct{}
 }
 
-// NewMiner is used to create a Miner and bind a handling function to the piece retrieval protocol.
-func NewMiner(nd minerNode) *Miner {
+// NewMiner is used to create a Miner and bind handling functions to the piece retrieval protocols.
+func NewMiner(nd minerNode, porcelainAPI minerPorcelain, payments *PaymentStore) *Miner {
 	rm := &Miner{
-		node: nd,
+		node:         nd,
+		porcelainAPI: porcelainAPI,
+		payments:     payments,
+		channels:     make(map[string]struct{}),
 	}
 
 	nd.Host().SetStreamHandler(retrievalFreeProtocol, rm.handleRetrievePieceForFree)
+	nd.Host().SetStreamHandler(retrievalPaidProtocol, rm.handleRetrievePieceForPayment)
+	nd.Host().SetStreamHandler(retrievalTermsProtocol, rm.handleQueryRetrievalTerms)
 
 	return rm
 }
@@ -47,50 +98,498 @@ func (rm *Miner) handleRetrievePieceForFree(s inet.Stream) {
 		return
 	}
 
-	reader, err := rm.node.SectorBuilder().ReadPieceFromSealedSector(req.PieceRef)
+	reader, err := rm.openPiece(&req)
 	if err != nil {
 		log.Warningf("failed to obtain a reader for piece with CID %s: %s", req.PieceRef.String(), err)
+		rm.writeFailure(s, req.PieceRef, err)
+		return
+	}
 
-		resp := RetrievePieceResponse{
-			Status:       Failure,
-			ErrorMessage: err.Error(),
-		}
+	resp := RetrievePieceResponse{
+		Status: Success,
+	}
 
-		if err := cbu.NewMsgWriter(s).WriteMsg(&resp); err != nil {
-			log.Warningf("failed to write response for piece with CID %s: %s", req.PieceRef.String(), err)
-		}
+	if err := cbu.NewMsgWriter(s).WriteMsg(&resp); err != nil {
+		log.Warningf("failed to write response for piece with CID %s: %s", req.PieceRef.String(), err)
+		return
+	}
+
+	if _, err := sendPieceChunks(s, reader, nil); err != nil {
+		log.Warningf("failed to send piece with CID %s: %s", req.PieceRef.String(), err)
+	}
+}
+
+// handleQueryRetrievalTerms tells a client the price per byte, payment interval and unseal
+// price the miner would charge for a paid retrieval, so that the client can decline before
+// it funds a payment channel.
+func (rm *Miner) handleQueryRetrievalTerms(s inet.Stream) {
+	defer s.Close() // nolint: errcheck
 
+	ctx := context.Background()
+
+	var req RetrievePieceRequest
+	if err := cbu.NewMsgReader(s).ReadMsg(&req); err != nil {
+		log.Errorf("failed to read retrieval terms request: %s", err)
 		return
 	}
 
-	bs, err := ioutil.ReadAll(reader)
+	minerAddr, err := rm.getMinerAddress()
 	if err != nil {
-		log.Errorf("failed to read all bytes: %s", err)
+		rm.writeFailure(s, req.PieceRef, err)
+		return
 	}
 
-	resp := RetrievePieceResponse{
-		Status: Success,
+	terms, err := rm.getRetrievalTerms(ctx, minerAddr)
+	if err != nil {
+		rm.writeFailure(s, req.PieceRef, err)
+		return
 	}
 
-	if err := cbu.NewMsgWriter(s).WriteMsg(&resp); err != nil {
+	if err := cbu.NewMsgWriter(s).WriteMsg(terms); err != nil {
+		log.Warningf("failed to write retrieval terms for piece with CID %s: %s", req.PieceRef.String(), err)
+	}
+}
+
+// handleRetrievePieceForPayment streams a piece to a client that pays for it with vouchers
+// drawn on a payment channel targeting the miner owner. The client sends a voucher
+// covering all bytes received after every chunk. The miner streams at most one payment
+// interval, as set in its retrieval ask, ahead of payment and stops if the client falls behind.
+func (rm *Miner) handleRetrievePieceForPayment(s inet.Stream) {
+	defer s.Close() // nolint: errcheck
+
+	ctx := context.Background()
+	streamReader := cbu.NewMsgReader(s)
+
+	var req RetrievePieceForPaymentRequest
+	if err := streamReader.ReadMsg(&req); err != nil {
+		log.Errorf("failed to read paid piece retrieval request: %s", err)
+		return
+	}
+
+	minerAddr, err := rm.getMinerAddress()
+	if err != nil {
+		log.Warningf("rejecting paid retrieval of piece with CID %s: %s", req.PieceRef.String(), err)
+		rm.writeFailure(s, req.PieceRef, err)
+		return
+	}
+
+	terms, err := rm.getRetrievalTerms(ctx, minerAddr)
+	if err != nil {
+		log.Warningf("rejecting paid retrieval of piece with CID %s: %s", req.PieceRef.String(), err)
+		rm.writeFailure(s, req.PieceRef, err)
+		return
+	}
+
+	channel, err := rm.validateRetrievalPayment(ctx, minerAddr, &req.Payment)
+	if err != nil {
+		log.Warningf("rejecting paid retrieval of piece with CID %s: %s", req.PieceRef.String(), err)
+		rm.writeFailure(s, req.PieceRef, err)
+		return
+	}
+
+	if !rm.claimChannel(&req.Payment) {
+		err := fmt.Errorf("payment channel %s is in use by another retrieval", req.Payment.Channel)
+		log.Warningf("rejecting paid retrieval of piece with CID %s: %s", req.PieceRef.String(), err)
+		rm.writeFailure(s, req.PieceRef, err)
+		return
+	}
+	defer rm.releaseChannel(&req.Payment)
+
+	paid, err := rm.payments.Paid(&req.Payment, channel)
+	if err != nil {
+		log.Warningf("rejecting paid retrieval of piece with CID %s: %s", req.PieceRef.String(), err)
+		rm.writeFailure(s, req.PieceRef, err)
+		return
+	}
+
+	reader, err := rm.openPiece(&req.RetrievePieceRequest)
+	if err != nil {
+		log.Warningf("failed to obtain a reader for piece with CID %s: %s", req.PieceRef.String(), err)
+		rm.writeFailure(s, req.PieceRef, err)
+		return
+	}
+
+	if err := cbu.NewMsgWriter(s).WriteMsg(terms); err != nil {
 		log.Warningf("failed to write response for piece with CID %s: %s", req.PieceRef.String(), err)
 		return
 	}
 
-	for i := 0; i < len(bs); i += RetrievePieceChunkSize {
-		end := i + RetrievePieceChunkSize
+	record := &PaymentRecord{
+		PieceRef: req.PieceRef,
+		Miner:    minerAddr,
+		Payment:  req.Payment,
+	}
+	tracker := newPaymentTracker(record, channel, paid, terms.PricePerByte, terms.UnsealPrice, rm.payments)
+	go tracker.receiveVouchers(streamReader)
+
+	// owed is the amount due once n bytes have been sent, including the unseal price.
+	owed := func(n uint64) types.AttoFIL {
+		return terms.UnsealPrice.Add(terms.PricePerByte.CalculatePrice(types.NewBytesAmount(n)))
+	}
+
+	// The client pays to unseal the piece before any of it is sent.
+	if err := tracker.waitForPayment(owed(0)); err != nil {
+		log.Warningf("client did not pay unseal price for piece with CID %s: %s", req.PieceRef.String(), err)
+		return
+	}
+
+	// Allow at most one payment interval of unpaid bytes in flight.
+	sent, err := sendPieceChunks(s, reader, func(total uint64) error {
+		if total <= terms.PaymentInterval {
+			return nil
+		}
+		return tracker.waitForPayment(owed(total - terms.PaymentInterval))
+	})
+	if err != nil {
+		log.Warningf("stopping retrieval of piece with CID %s: %s", req.PieceRef.String(), err)
+		return
+	}
+
+	// Close the stream for writing, so the client sees the end of the piece, but keep
+	// reading vouchers until the client has paid for everything it received.
+	if err := s.Close(); err != nil {
+		log.Warningf("failed to close stream for piece with CID %s: %s", req.PieceRef.String(), err)
+	}
+	if err := tracker.waitForPayment(owed(sent)); err != nil {
+		log.Warningf("client did not pay in full for piece with CID %s: %s", req.PieceRef.String(), err)
+	}
+}
+
+// validateRetrievalPayment checks that the payment channel described by the client exists,
+// targets the miner owner and stays open long enough to redeem vouchers.
+func (rm *Miner) validateRetrievalPayment(ctx context.Context, minerAddr address.Address, payment *PaymentInfo) (*paymentbroker.PaymentChannel, error) {
+	ownerAddr, err := rm.porcelainAPI.MinerGetOwnerAddress(ctx, minerAddr)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get miner owner address")
+	}
+
+	if payment.Channel == nil {
+		return nil, errors.New("request contains no payment channel")
+	}
+	if payment.ChannelMsgCid == nil {
+		return nil, errors.New("request contains no payment channel message")
+	}
+
+	waitCtx, waitCancel := context.WithTimeout(ctx, waitForPaymentChannelDuration)
+	err = rm.porcelainAPI.MessageWait(waitCtx, *payment.ChannelMsgCid, func(blk *types.Block, smsg *types.SignedMessage, receipt *types.MessageReceipt) error {
+		return nil
+	})
+	waitCancel()
+	if err != nil {
+		if err == context.DeadlineExceeded {
+			return nil, errors.Wrap(err, "timeout waiting for payment channel")
+		}
+		return nil, err
+	}
+
+	channels, err := rm.porcelainAPI.PaymentChannelLs(ctx, ownerAddr, payment.Payer)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get payment channels for payer")
+	}
+	channel, ok := channels[payment.Channel.KeyString()]
+	if !ok {
+		return nil, fmt.Errorf("could not find payment channel for payer %s and id %s", payment.Payer, payment.Channel)
+	}
+
+	if channel.Target != ownerAddr {
+		return nil, fmt.Errorf("miner account (%s) is not target of payment channel (%s)", ownerAddr, channel.Target)
+	}
+
+	head, err := rm.porcelainAPI.ChainTipSet(rm.porcelainAPI.ChainHeadKey())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not access head tipset")
+	}
+	h, err := head.Height()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get current block height")
+	}
+	requiredEol := types.NewBlockHeight(h + MinChannelLifetime)
+	if channel.Eol.LessThan(requiredEol) {
+		return nil, fmt.Errorf("payment channel eol (%s) less than required eol (%s)", channel.Eol, requiredEol)
+	}
+
+	return channel, nil
+}
+
+// claimChannel marks a payment channel as in use by a retrieval, and returns false if it
+// already is.
+func (rm *Miner) claimChannel(payment *PaymentInfo) bool {
+	rm.channelsLk.Lock()
+	defer rm.channelsLk.Unlock()
+
+	key := paymentRecordKey(payment.Payer, payment.Channel).String()
+	if _, ok := rm.channels[key]; ok {
+		return false
+	}
+	rm.channels[key] = struct{}{}
+	return true
+}
+
+func (rm *Miner) releaseChannel(payment *PaymentInfo) {
+	rm.channelsLk.Lock()
+	defer rm.channelsLk.Unlock()
+	delete(rm.channels, paymentRecordKey(payment.Payer, payment.Channel).String())
+}
+
+// openPiece returns a reader over the range of a sealed piece selected by the request.
+// The piece is read from the sector as it is streamed, rather than buffered in memory.
+func (rm *Miner) openPiece(req *RetrievePieceRequest) (io.Reader, error) {
+	reader, err := rm.node.SectorBuilder().ReadPieceFromSealedSector(req.PieceRef)
+	if err != nil {
+		return nil, err
+	}
 
-		if end > len(bs) {
-			end = len(bs)
+	if req.Offset > 0 {
+		if _, err := io.CopyN(ioutil.Discard, reader, int64(req.Offset)); err != nil {
+			if err == io.EOF {
+				return nil, fmt.Errorf("offset %d is beyond the end of the piece", req.Offset)
+			}
+			return nil, errors.Wrap(err, "failed to skip to offset")
 		}
+	}
+
+	if req.Length > 0 {
+		reader = io.LimitReader(reader, int64(req.Length))
+	}
+
+	return reader, nil
+}
+
+// sendPieceChunks writes the contents of reader to the stream as RetrievePieceChunks and
+// returns the number of bytes sent. If beforeChunk is not nil, it is called with the
+// total number of bytes that will have been sent once the next chunk is written, and
+// may stop the transfer by returning an error.
+func sendPieceChunks(w io.Writer, reader io.Reader, beforeChunk func(total uint64) error) (uint64, error) {
+	buf := make([]byte, RetrievePieceChunkSize)
+	var sent uint64
+	for {
+		n, readErr := io.ReadFull(reader, buf)
+		if n > 0 {
+			if beforeChunk != nil {
+				if err := beforeChunk(sent + uint64(n)); err != nil {
+					return sent, err
+				}
+			}
+
+			chunk := RetrievePieceChunk{
+				Data: buf[:n],
+			}
+			if err := cbu.NewMsgWriter(w).WriteMsg(&chunk); err != nil {
+				return sent, errors.Wrap(err, "failed to write chunk")
+			}
+			sent += uint64(n)
+		}
+
+		if readErr == io.EOF || readErr == io.ErrUnexpectedEOF {
+			return sent, nil
+		}
+		if readErr != nil {
+			return sent, errors.Wrap(readErr, "failed to read piece")
+		}
+	}
+}
+
+func (rm *Miner) getMinerAddress() (address.Address, error) {
+	val, err := rm.porcelainAPI.ConfigGet("mining.minerAddress")
+	if err != nil {
+		return address.Undef, err
+	}
+	minerAddr, ok := val.(address.Address)
+	if !ok || minerAddr.Empty() {
+		return address.Undef, errors.New("node is not configured with a miner address")
+	}
+	return minerAddr, nil
+}
+
+// getRetrievalTerms returns a successful response carrying the price per byte, payment
+// interval and unseal price of the miner's retrieval ask. Miners that have not published an
+// ask charge the configured retrieval price with the default payment interval and no
+// unseal price.
+func (rm *Miner) getRetrievalTerms(ctx context.Context, minerAddr address.Address) (*RetrievePieceResponse, error) {
+	ask, err := rm.porcelainAPI.MinerGetRetrievalAsk(ctx, minerAddr)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get retrieval ask")
+	}
+	if ask != nil {
+		return &RetrievePieceResponse{
+			Status:          Success,
+			PricePerByte:    ask.Price,
+			PaymentInterval: ask.PaymentInterval.Uint64(),
+			UnsealPrice:     ask.UnsealPrice,
+		}, nil
+	}
+
+	price, err := rm.getRetrievalPrice()
+	if err != nil {
+		return nil, err
+	}
+	return &RetrievePieceResponse{
+		Status:          Success,
+		PricePerByte:    price,
+		PaymentInterval: DefaultPaymentInterval,
+		UnsealPrice:     types.ZeroAttoFIL,
+	}, nil
+}
 
-		chunk := RetrievePieceChunk{
-			Data: bs[i:end],
+func (rm *Miner) getRetrievalPrice() (types.AttoFIL, error) {
+	val, err := rm.porcelainAPI.ConfigGet("mining.retrievalPrice")
+	if err != nil {
+		return types.ZeroAttoFIL, err
+	}
+	price, ok := val.(types.AttoFIL)
+	if !ok {
+		return types.ZeroAttoFIL, errors.New("could not retrieve retrievalPrice from config")
+	}
+	return price, nil
+}
+
+func (rm *Miner) writeFailure(s inet.Stream, pieceRef cid.Cid, err error) {
+	resp := RetrievePieceResponse{
+		Status:       Failure,
+		ErrorMessage: err.Error(),
+	}
+
+	if err := cbu.NewMsgWriter(s).WriteMsg(&resp); err != nil {
+		log.Warningf("failed to write response for piece with CID %s: %s", pieceRef.String(), err)
+	}
+}
+
+// paymentTracker validates the vouchers a client sends during a paid retrieval and
+// records the latest one. Vouchers are read concurrently with chunks being written so
+// that neither side of the stream blocks the other.
+//
+// Vouchers on a channel are cumulative: redeeming one transfers its amount less the
+// amount the channel has already redeemed. A client may reuse a channel for several
+// retrievals, so only the part of a voucher above the amount already paid through the
+// channel, as returned by PaymentStore.Paid, pays for this retrieval.
+type paymentTracker struct {
+	channel *paymentbroker.PaymentChannel
+	// base is the amount paid through the channel before this retrieval.
+	base        types.AttoFIL
+	price       types.AttoFIL
+	unsealPrice types.AttoFIL
+	store       *PaymentStore
+
+	lk     sync.Mutex
+	record *PaymentRecord
+	// paid is the amount the latest voucher pays for this retrieval.
+	paid types.AttoFIL
+	err  error
+
+	// notify is signalled (without blocking) whenever paid or err changes.
+	notify chan struct{}
+}
+
+func newPaymentTracker(record *PaymentRecord, channel *paymentbroker.PaymentChannel, base, price, unsealPrice types.AttoFIL, store *PaymentStore) *paymentTracker {
+	return &paymentTracker{
+		channel:     channel,
+		base:        base,
+		price:       price,
+		unsealPrice: unsealPrice,
+		store:       store,
+		record:      record,
+		paid:        types.ZeroAttoFIL,
+		notify:      make(chan struct{}, 1),
+	}
+}
+
+// receiveVouchers reads vouchers from the stream until it is closed or a voucher is invalid.
+func (pt *paymentTracker) receiveVouchers(r *cbu.MsgReader) {
+	for {
+		var voucher types.PaymentVoucher
+		if err := r.ReadMsg(&voucher); err != nil {
+			if err == io.EOF {
+				err = errors.New("client stopped sending payment")
+			}
+			pt.fail(err)
+			return
 		}
 
-		if err := cbu.NewMsgWriter(s).WriteMsg(&chunk); err != nil {
-			log.Warningf("failed to write chunk for CID %s: %s", req.PieceRef.String(), err)
+		if err := pt.accept(&voucher); err != nil {
+			pt.fail(err)
 			return
 		}
 	}
 }
+
+func (pt *paymentTracker) accept(voucher *types.PaymentVoucher) error {
+	pt.lk.Lock()
+	defer pt.lk.Unlock()
+
+	payment := pt.record.Payment
+	if voucher.Payer != payment.Payer || !voucher.Channel.Equal(payment.Channel) {
+		return errors.New("voucher is not drawn on the agreed payment channel")
+	}
+	if voucher.Condition != nil {
+		return errors.New("retrieval vouchers must not carry a condition")
+	}
+	if !paymentbroker.VerifyVoucherSignature(payment.Payer, payment.Channel, voucher.Amount, &voucher.ValidAt, voucher.Condition, voucher.Signature) {
+		return errors.New("invalid signature in voucher")
+	}
+	// The miner must be able to redeem the voucher for at least MinChannelLifetime blocks
+	// before the channel closes.
+	if redeemBy := pt.channel.Eol.Sub(types.NewBlockHeight(MinChannelLifetime)); voucher.ValidAt.GreaterThan(redeemBy) {
+		return fmt.Errorf("voucher valid at (%s) leaves less than %d blocks to redeem it before channel eol (%s)", voucher.ValidAt.String(), MinChannelLifetime, pt.channel.Eol)
+	}
+	if voucher.Amount.GreaterThan(pt.channel.Amount) {
+		return fmt.Errorf("voucher amount (%s) exceeds channel funds (%s)", voucher.Amount, pt.channel.Amount)
+	}
+	if voucher.Amount.LessThan(pt.base) {
+		return fmt.Errorf("voucher amount (%s) is less than the amount already paid through the channel (%s)", voucher.Amount, pt.base)
+	}
+	paid := voucher.Amount.Sub(pt.base)
+	if paid.LessThan(pt.paid) {
+		return fmt.Errorf("voucher amount (%s) is less than previous voucher (%s)", voucher.Amount, pt.base.Add(pt.paid))
+	}
+
+	record := *pt.record
+	record.Voucher = voucher
+	if pt.price.IsPositive() && paid.GreaterThan(pt.unsealPrice) {
+		record.BytesTransferred = big.NewInt(0).Div(paid.Sub(pt.unsealPrice).AsBigInt(), pt.price.AsBigInt()).Uint64()
+	}
+	if err := pt.store.Put(&record); err != nil {
+		return errors.Wrap(err, "failed to record retrieval voucher")
+	}
+
+	pt.record = &record
+	pt.paid = paid
+	pt.signal()
+	return nil
+}
+
+func (pt *paymentTracker) fail(err error) {
+	pt.lk.Lock()
+	defer pt.lk.Unlock()
+	pt.err = err
+	pt.signal()
+}
+
+func (pt *paymentTracker) signal() {
+	select {
+	case pt.notify <- struct{}{}:
+	default:
+	}
+}
+
+// waitForPayment blocks until the client has paid at least amount, the client stops
+// paying, or no voucher arrives for waitForPaymentDuration.
+func (pt *paymentTracker) waitForPayment(amount types.AttoFIL) error {
+	for {
+		pt.lk.Lock()
+		paid, err := pt.paid, pt.err
+		pt.lk.Unlock()
+
+		if paid.GreaterEqual(amount) {
+			return nil
+		}
+		if err != nil {
+			return errors.Wrapf(err, "payment (%s) is behind amount owed (%s)", paid, amount)
+		}
+
+		select {
+		case <-pt.notify:
+		case <-time.After(waitForPaymentDuration):
+			return fmt.Errorf("timed out waiting for payment (%s) to reach amount owed (%s)", paid, amount)
+		}
+	}
+}
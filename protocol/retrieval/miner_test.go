@@ -0,0 +1,335 @@
+package retrieval
+
+import (
+	"bytes"
+	"io/ioutil"
+	"math/big"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/actor/builtin/paymentbroker"
+	"github.com/filecoin-project/go-filecoin/address"
+	cbu "github.com/filecoin-project/go-filecoin/cborutil"
+	"github.com/filecoin-project/go-filecoin/repo"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestPaymentTracker(t *testing.T) {
+	tf.UnitTest(t)
+
+	signer, _ := types.NewMockSignersAndKeyInfo(1)
+	payer := signer.Addresses[0]
+	target := address.NewForTestGetter()()
+	channelID := types.NewChannelID(7)
+	price := types.NewAttoFIL(big.NewInt(1000))
+	unsealPrice := types.NewAttoFIL(big.NewInt(500))
+
+	channelMsgCid := types.CidFromString(t, "createchannel")
+
+	channel := &paymentbroker.PaymentChannel{
+		Target:         target,
+		Amount:         types.NewAttoFILFromFIL(10),
+		AmountRedeemed: types.ZeroAttoFIL,
+		Eol:            types.NewBlockHeight(500),
+	}
+
+	newTrackerWithStore := func(channel *paymentbroker.PaymentChannel, store *PaymentStore) *paymentTracker {
+		record := &PaymentRecord{
+			PieceRef: types.NewCidForTestGetter()(),
+			Miner:    address.NewForTestGetter()(),
+			Payment: PaymentInfo{
+				Payer:         payer,
+				Channel:       channelID,
+				ChannelMsgCid: &channelMsgCid,
+			},
+		}
+		base, err := store.Paid(&record.Payment, channel)
+		require.NoError(t, err)
+		return newPaymentTracker(record, channel, base, price, unsealPrice, store)
+	}
+	newTrackerOnChannel := func(channel *paymentbroker.PaymentChannel) (*paymentTracker, *PaymentStore) {
+		store := NewPaymentStore(repo.NewInMemoryRepo().DealsDatastore())
+		return newTrackerWithStore(channel, store), store
+	}
+	newTracker := func() (*paymentTracker, *PaymentStore) {
+		return newTrackerOnChannel(channel)
+	}
+
+	makeVoucher := func(amount types.AttoFIL, validAt *types.BlockHeight) *types.PaymentVoucher {
+		sig, err := paymentbroker.SignVoucher(channelID, amount, validAt, payer, nil, signer)
+		require.NoError(t, err)
+		return &types.PaymentVoucher{
+			Channel:   *channelID,
+			Payer:     payer,
+			Target:    target,
+			Amount:    amount,
+			ValidAt:   *validAt,
+			Signature: sig,
+		}
+	}
+
+	t.Run("accepts increasing vouchers and records the latest", func(t *testing.T) {
+		tracker, store := newTracker()
+
+		require.NoError(t, tracker.accept(makeVoucher(unsealPrice, types.NewBlockHeight(10))))
+		assert.NoError(t, tracker.waitForPayment(unsealPrice))
+
+		first := unsealPrice.Add(price.CalculatePrice(types.NewBytesAmount(100)))
+		second := unsealPrice.Add(price.CalculatePrice(types.NewBytesAmount(300)))
+		require.NoError(t, tracker.accept(makeVoucher(first, types.NewBlockHeight(10))))
+		require.NoError(t, tracker.accept(makeVoucher(second, types.NewBlockHeight(10))))
+
+		assert.NoError(t, tracker.waitForPayment(second))
+
+		record, err := store.Get(payer, channelID)
+		require.NoError(t, err)
+		assert.Equal(t, second, record.Voucher.Amount)
+		assert.Equal(t, uint64(300), record.BytesTransferred)
+	})
+
+	t.Run("rejects a decreasing voucher", func(t *testing.T) {
+		tracker, _ := newTracker()
+
+		require.NoError(t, tracker.accept(makeVoucher(types.NewAttoFILFromFIL(2), types.NewBlockHeight(10))))
+		assert.Error(t, tracker.accept(makeVoucher(types.NewAttoFILFromFIL(1), types.NewBlockHeight(10))))
+	})
+
+	t.Run("rejects a voucher exceeding channel funds", func(t *testing.T) {
+		tracker, _ := newTracker()
+
+		assert.Error(t, tracker.accept(makeVoucher(types.NewAttoFILFromFIL(11), types.NewBlockHeight(10))))
+	})
+
+	t.Run("rejects a voucher valid after channel eol", func(t *testing.T) {
+		tracker, _ := newTracker()
+
+		assert.Error(t, tracker.accept(makeVoucher(types.NewAttoFILFromFIL(1), types.NewBlockHeight(500))))
+	})
+
+	t.Run("rejects a voucher that cannot be redeemed long enough before channel eol", func(t *testing.T) {
+		tracker, _ := newTracker()
+
+		assert.Error(t, tracker.accept(makeVoucher(types.NewAttoFILFromFIL(1), types.NewBlockHeight(500-MinChannelLifetime+1))))
+		assert.NoError(t, tracker.accept(makeVoucher(types.NewAttoFILFromFIL(1), types.NewBlockHeight(500-MinChannelLifetime))))
+	})
+
+	t.Run("counts only the amount above what the channel has already redeemed", func(t *testing.T) {
+		redeemed := price.CalculatePrice(types.NewBytesAmount(1000))
+		tracker, store := newTrackerOnChannel(&paymentbroker.PaymentChannel{
+			Target:         target,
+			Amount:         types.NewAttoFILFromFIL(10),
+			AmountRedeemed: redeemed,
+			Eol:            types.NewBlockHeight(500),
+		})
+
+		assert.Error(t, tracker.accept(makeVoucher(price.CalculatePrice(types.NewBytesAmount(999)), types.NewBlockHeight(10))))
+
+		payment := unsealPrice.Add(price.CalculatePrice(types.NewBytesAmount(200)))
+		require.NoError(t, tracker.accept(makeVoucher(redeemed.Add(payment), types.NewBlockHeight(10))))
+		assert.NoError(t, tracker.waitForPayment(payment))
+
+		record, err := store.Get(payer, channelID)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(200), record.BytesTransferred)
+	})
+
+	t.Run("counts only the amount above the latest voucher of an earlier retrieval on the channel", func(t *testing.T) {
+		store := NewPaymentStore(repo.NewInMemoryRepo().DealsDatastore())
+		earlier := newTrackerWithStore(channel, store)
+		earlierPayment := unsealPrice.Add(price.CalculatePrice(types.NewBytesAmount(400)))
+		require.NoError(t, earlier.accept(makeVoucher(earlierPayment, types.NewBlockHeight(10))))
+
+		tracker := newTrackerWithStore(channel, store)
+		assert.Error(t, tracker.accept(makeVoucher(unsealPrice, types.NewBlockHeight(10))))
+
+		payment := unsealPrice.Add(price.CalculatePrice(types.NewBytesAmount(200)))
+		require.NoError(t, tracker.accept(makeVoucher(earlierPayment.Add(payment), types.NewBlockHeight(10))))
+		assert.NoError(t, tracker.waitForPayment(payment))
+
+		record, err := store.Get(payer, channelID)
+		require.NoError(t, err)
+		assert.Equal(t, earlierPayment.Add(payment), record.Voucher.Amount)
+		assert.Equal(t, uint64(200), record.BytesTransferred)
+	})
+
+	t.Run("rejects a voucher with a bad signature", func(t *testing.T) {
+		tracker, _ := newTracker()
+
+		voucher := makeVoucher(types.NewAttoFILFromFIL(1), types.NewBlockHeight(10))
+		voucher.Amount = types.NewAttoFILFromFIL(2)
+		assert.Error(t, tracker.accept(voucher))
+	})
+
+	t.Run("waiting fails once the client stops paying", func(t *testing.T) {
+		tracker, _ := newTracker()
+
+		tracker.fail(errors.New("client stopped paying"))
+		assert.Error(t, tracker.waitForPayment(types.NewAttoFILFromFIL(1)))
+	})
+}
+
+func TestPaymentStoreList(t *testing.T) {
+	tf.UnitTest(t)
+
+	store := NewPaymentStore(repo.NewInMemoryRepo().DealsDatastore())
+	addrGetter := address.NewForTestGetter()
+	payer := addrGetter()
+	channelMsgCid := types.CidFromString(t, "createchannel")
+
+	for i := uint64(0); i < 3; i++ {
+		require.NoError(t, store.Put(&PaymentRecord{
+			PieceRef: types.NewCidForTestGetter()(),
+			Miner:    addrGetter(),
+			Payment: PaymentInfo{
+				Payer:         payer,
+				Channel:       types.NewChannelID(i),
+				ChannelMsgCid: &channelMsgCid,
+			},
+			BytesTransferred: i,
+		}))
+	}
+
+	records, err := store.List()
+	require.NoError(t, err)
+	assert.Len(t, records, 3)
+}
+
+func TestPaymentStorePaid(t *testing.T) {
+	tf.UnitTest(t)
+
+	store := NewPaymentStore(repo.NewInMemoryRepo().DealsDatastore())
+	channelMsgCid := types.CidFromString(t, "createchannel")
+	payment := PaymentInfo{
+		Payer:         address.NewForTestGetter()(),
+		Channel:       types.NewChannelID(3),
+		ChannelMsgCid: &channelMsgCid,
+	}
+	channel := &paymentbroker.PaymentChannel{
+		Amount:         types.NewAttoFILFromFIL(10),
+		AmountRedeemed: types.NewAttoFILFromFIL(2),
+		Eol:            types.NewBlockHeight(500),
+	}
+	record := &PaymentRecord{
+		PieceRef: types.NewCidForTestGetter()(),
+		Miner:    address.NewForTestGetter()(),
+		Payment:  payment,
+	}
+
+	paid, err := store.Paid(&payment, channel)
+	require.NoError(t, err)
+	assert.Equal(t, channel.AmountRedeemed, paid, "a channel without a record has paid what it redeemed")
+
+	require.NoError(t, store.Put(record))
+	paid, err = store.Paid(&payment, channel)
+	require.NoError(t, err)
+	assert.Equal(t, channel.AmountRedeemed, paid, "a channel recorded without a voucher has paid what it redeemed")
+
+	record.Voucher = &types.PaymentVoucher{Channel: *payment.Channel, Payer: payment.Payer, Amount: types.NewAttoFILFromFIL(3), ValidAt: *types.NewBlockHeight(10)}
+	require.NoError(t, store.Put(record))
+	paid, err = store.Paid(&payment, channel)
+	require.NoError(t, err)
+	assert.Equal(t, types.NewAttoFILFromFIL(3), paid)
+
+	record.Voucher.Amount = types.NewAttoFILFromFIL(1)
+	require.NoError(t, store.Put(record))
+	paid, err = store.Paid(&payment, channel)
+	require.NoError(t, err)
+	assert.Equal(t, channel.AmountRedeemed, paid, "a redeemed amount above the recorded voucher counts")
+}
+
+func TestSendPieceChunks(t *testing.T) {
+	tf.UnitTest(t)
+
+	piece := make([]byte, 2*RetrievePieceChunkSize+10)
+	for i := range piece {
+		piece[i] = byte(i)
+	}
+
+	t.Run("streams the piece in chunks that a piece reader reassembles", func(t *testing.T) {
+		var stream bytes.Buffer
+		sent, err := sendPieceChunks(&stream, bytes.NewReader(piece), nil)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(len(piece)), sent)
+
+		var totals []uint64
+		closed := false
+		pr := &pieceReader{
+			streamReader: cbu.NewMsgReader(&stream),
+			onChunk: func(received uint64) error {
+				totals = append(totals, received)
+				return nil
+			},
+			onClose: func() { closed = true },
+		}
+
+		got, err := ioutil.ReadAll(pr)
+		require.NoError(t, err)
+		assert.Equal(t, piece, got)
+		assert.Equal(t, []uint64{RetrievePieceChunkSize, 2 * RetrievePieceChunkSize, uint64(len(piece))}, totals)
+		assert.True(t, closed)
+	})
+
+	t.Run("stops when beforeChunk fails", func(t *testing.T) {
+		var stream bytes.Buffer
+		sent, err := sendPieceChunks(&stream, bytes.NewReader(piece), func(total uint64) error {
+			if total > RetrievePieceChunkSize {
+				return errors.New("client stopped paying")
+			}
+			return nil
+		})
+		assert.Error(t, err)
+		assert.Equal(t, uint64(RetrievePieceChunkSize), sent)
+	})
+
+	t.Run("piece reader stops when onChunk fails", func(t *testing.T) {
+		var stream bytes.Buffer
+		_, err := sendPieceChunks(&stream, bytes.NewReader(piece), nil)
+		require.NoError(t, err)
+
+		pr := &pieceReader{
+			streamReader: cbu.NewMsgReader(&stream),
+			onChunk: func(received uint64) error {
+				return errors.New("payment channel funds exhausted")
+			},
+			onClose: func() {},
+		}
+
+		_, err = ioutil.ReadAll(pr)
+		assert.Error(t, err)
+	})
+}
+
+func TestCheckRetrievalTerms(t *testing.T) {
+	tf.UnitTest(t)
+
+	params := PaymentParams{
+		ChannelAmount:   types.NewAttoFILFromFIL(10),
+		MaxPricePerByte: types.NewAttoFIL(big.NewInt(1000)),
+	}
+	terms := func() *RetrievePieceResponse {
+		return &RetrievePieceResponse{
+			Status:          Success,
+			PricePerByte:    types.NewAttoFIL(big.NewInt(1000)),
+			PaymentInterval: DefaultPaymentInterval,
+			UnsealPrice:     types.NewAttoFILFromFIL(1),
+		}
+	}
+
+	assert.NoError(t, checkRetrievalTerms(terms(), params))
+
+	expensive := terms()
+	expensive.PricePerByte = types.NewAttoFIL(big.NewInt(1001))
+	assert.Error(t, checkRetrievalTerms(expensive, params))
+
+	unaffordable := terms()
+	unaffordable.UnsealPrice = types.NewAttoFILFromFIL(11)
+	assert.Error(t, checkRetrievalTerms(unaffordable, params))
+
+	stalling := terms()
+	stalling.PaymentInterval = RetrievePieceChunkSize - 1
+	assert.Error(t, checkRetrievalTerms(stalling, params))
+}
@@ -0,0 +1,99 @@
+package retrieval
+
+import (
+	"github.com/ipfs/go-datastore"
+	"github.com/ipfs/go-datastore/query"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/actor/builtin/paymentbroker"
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/repo"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+// PaymentRecordPrefix is the datastore prefix for retrieval payment records.
+const PaymentRecordPrefix = "retrievalpayments"
+
+// PaymentStore persists the payment records of paid retrievals.
+type PaymentStore struct {
+	ds repo.Datastore
+}
+
+// NewPaymentStore returns a new PaymentStore.
+func NewPaymentStore(ds repo.Datastore) *PaymentStore {
+	return &PaymentStore{ds: ds}
+}
+
+// Put stores the record, replacing any existing record for the same payment channel.
+func (ps *PaymentStore) Put(record *PaymentRecord) error {
+	datum, err := cbor.DumpObject(record)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal payment record")
+	}
+
+	if err := ps.ds.Put(paymentRecordKey(record.Payment.Payer, record.Payment.Channel), datum); err != nil {
+		return errors.Wrap(err, "could not save payment record to disk")
+	}
+	return nil
+}
+
+// Get returns the record for the given payment channel.
+func (ps *PaymentStore) Get(payer address.Address, channel *types.ChannelID) (*PaymentRecord, error) {
+	datum, err := ps.ds.Get(paymentRecordKey(payer, channel))
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find payment record for channel %s of %s", channel, payer)
+	}
+
+	var record PaymentRecord
+	if err := cbor.DecodeInto(datum, &record); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal payment record")
+	}
+	return &record, nil
+}
+
+// Paid returns the amount already paid through a payment channel: the larger of the
+// amount the channel has redeemed and the latest voucher recorded for it. Vouchers are
+// cumulative, so a retrieval reusing the channel pays with vouchers above this amount.
+func (ps *PaymentStore) Paid(payment *PaymentInfo, channel *paymentbroker.PaymentChannel) (types.AttoFIL, error) {
+	paid := channel.AmountRedeemed
+
+	record, err := ps.Get(payment.Payer, payment.Channel)
+	if err != nil {
+		if errors.Cause(err) == datastore.ErrNotFound {
+			return paid, nil
+		}
+		return types.ZeroAttoFIL, err
+	}
+	if record.Voucher != nil && record.Voucher.Amount.GreaterThan(paid) {
+		paid = record.Voucher.Amount
+	}
+	return paid, nil
+}
+
+// List returns all stored records.
+func (ps *PaymentStore) List() ([]*PaymentRecord, error) {
+	results, err := ps.ds.Query(query.Query{Prefix: "/" + PaymentRecordPrefix})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query payment records from datastore")
+	}
+	defer results.Close() // nolint: errcheck
+
+	var records []*PaymentRecord
+	for entry := range results.Next() {
+		if entry.Error != nil {
+			return nil, entry.Error
+		}
+
+		var record PaymentRecord
+		if err := cbor.DecodeInto(entry.Value, &record); err != nil {
+			return nil, errors.Wrap(err, "could not unmarshal payment record")
+		}
+		records = append(records, &record)
+	}
+	return records, nil
+}
+
+func paymentRecordKey(payer address.Address, channel *types.ChannelID) datastore.Key {
+	return datastore.KeyWithNamespaces([]string{PaymentRecordPrefix, payer.String(), channel.KeyString()})
+}
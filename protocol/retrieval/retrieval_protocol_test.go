@@ -40,7 +40,7 @@ func TestRetrievalProtocolPieceNotFound(t *testing.T) {
 }
 
 func retrievePieceBytes(ctx context.Context, retrievalAPI *retrieval.API, data cid.Cid, minerPID peer.ID, addr address.Address) ([]byte, error) {
-	r, err := retrievalAPI.RetrievePiece(ctx, data, minerPID, addr)
+	r, err := retrievalAPI.RetrievePiece(ctx, data, minerPID, addr, 0, 0)
 	if err != nil {
 		return nil, err
 	}
@@ -3,12 +3,18 @@ package retrieval
 import (
 	"github.com/ipfs/go-cid"
 	cbor "github.com/ipfs/go-ipld-cbor"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
 )
 
 func init() {
 	cbor.RegisterCborType(RetrievePieceRequest{})
 	cbor.RegisterCborType(RetrievePieceResponse{})
 	cbor.RegisterCborType(RetrievePieceChunk{})
+	cbor.RegisterCborType(PaymentInfo{})
+	cbor.RegisterCborType(RetrievePieceForPaymentRequest{})
+	cbor.RegisterCborType(PaymentRecord{})
 }
 
 // RetrievePieceStatus communicates a successful (or failed) piece retrieval
@@ -28,15 +34,74 @@ const (
 // RetrievePieceRequest represents a retrieval miner's request for content.
 type RetrievePieceRequest struct {
 	PieceRef cid.Cid
+
+	// Offset is the number of bytes to skip at the start of the piece.
+	Offset uint64
+
+	// Length is the maximum number of bytes to send. Zero means up to the end of the piece.
+	Length uint64
+}
+
+// PaymentInfo identifies the payment channel a client will use to pay for a retrieval.
+type PaymentInfo struct {
+	// Payer is the address of the account that created the payment channel.
+	Payer address.Address
+
+	// Channel is the id of the payment channel. Its target must be the miner's owner.
+	Channel *types.ChannelID
+
+	// ChannelMsgCid is the cid of the message that created the channel, so the miner can wait for it.
+	ChannelMsgCid *cid.Cid
+}
+
+// RetrievePieceForPaymentRequest is a request for content the client intends to pay for
+// with vouchers drawn on the given payment channel.
+type RetrievePieceForPaymentRequest struct {
+	RetrievePieceRequest
+
+	Payment PaymentInfo
 }
 
 // RetrievePieceResponse contains the requested content.
 type RetrievePieceResponse struct {
 	Status       RetrievePieceStatus
 	ErrorMessage string
+
+	// PricePerByte is the price the miner charges for each byte sent. It is only set
+	// by the paid retrieval protocol.
+	PricePerByte types.AttoFIL
+
+	// PaymentInterval is the number of bytes the miner will send ahead of payment
+	// before it stops streaming. It is only set by the paid retrieval protocol.
+	PaymentInterval uint64
+
+	// UnsealPrice is the flat price the miner charges to unseal the piece. The client
+	// pays it before the miner sends any bytes. It is only set by the paid retrieval protocol.
+	UnsealPrice types.AttoFIL
 }
 
 // RetrievePieceChunk is a subset of bytes for a piece being retrieved.
 type RetrievePieceChunk struct {
 	Data []byte
 }
+
+// PaymentRecord tracks the payment state of a paid retrieval. Clients and miners both
+// keep one for every retrieval, updated with each voucher sent or accepted.
+type PaymentRecord struct {
+	// PieceRef is the cid of the retrieved piece.
+	PieceRef cid.Cid
+
+	// Miner is the address of the retrieval miner.
+	Miner address.Address
+
+	// Payment identifies the channel the retrieval is paid from.
+	Payment PaymentInfo
+
+	// BytesTransferred is the number of piece bytes covered by Voucher.
+	BytesTransferred uint64
+
+	// Voucher is the most valuable voucher issued on the channel. Since each voucher
+	// covers all bytes sent so far, it is the only one that needs to be redeemed. It is
+	// nil if the channel was created but nothing has been paid through it yet.
+	Voucher *types.PaymentVoucher
+}
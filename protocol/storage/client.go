@@ -49,7 +49,7 @@ const (
 	CreateChannelGasPrice = 1
 
 	// CreateChannelGasLimit is the gas limit of the message used to create the payment channel
-	CreateChannelGasLimit = 300
+	CreateChannelGasLimit = 1000
 )
 
 type clientPorcelainAPI interface {
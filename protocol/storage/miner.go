@@ -845,17 +845,13 @@ func (sm *Miner) submitPoSt(ctx context.Context, start, end *types.BlockHeight,
 		log.Errorf("failed to calculate PoSt: %s", err)
 		return
 	}
-	// TODO #2998. The done set should be updated by CLI users.
-	// Using the 0 value is just a placeholder until that work lands.
-	done := types.EmptyIntSet()
-
 	gasPrice := types.NewGasPrice(submitPostGasPrice)
 	workerAddr, err := sm.porcelainAPI.MinerGetWorkerAddress(ctx, sm.minerAddr, sm.porcelainAPI.ChainHeadKey())
 	if err != nil {
 		log.Errorf("failed to get worker address: %s", err)
 		return
 	}
-	_, err = sm.porcelainAPI.MessageSend(ctx, workerAddr, sm.minerAddr, submission.Fee, gasPrice, submission.GasLimit, "submitPoSt", submission.Proof, submission.Faults, done)
+	_, err = sm.porcelainAPI.MessageSend(ctx, workerAddr, sm.minerAddr, submission.Fee, gasPrice, submission.GasLimit, "submitPoSt", submission.Proof, submission.Faults, submission.Done)
 	if err != nil {
 		log.Errorf("failed to submit PoSt: %s", err)
 		return
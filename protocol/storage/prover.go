@@ -9,6 +9,7 @@ import (
 
 	"github.com/filecoin-project/go-filecoin/actor/builtin/miner"
 	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/types"
 )
 
@@ -19,9 +20,6 @@ const (
 	// fee necessary due to late submission. The miner expects the PoSt message to be mined
 	// into a block at most `buffer` rounds in the future.
 	postSubmissionDelayBufferRounds = 10
-
-	// This will likely depend on the sector size and proving period.
-	submitPostGasLimit = 300
 )
 
 // ProofReader provides information about the blockchain to the proving process.
@@ -38,6 +36,8 @@ type ProofReader interface {
 	WalletBalance(ctx context.Context, addr address.Address) (types.AttoFIL, error)
 	// MinerGetWorkerAddress returns the current worker address for a miner
 	MinerGetWorkerAddress(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (address.Address, error)
+	// MessageEstimateGas suggests a gas price and gas limit for a message by previewing it.
+	MessageEstimateGas(ctx context.Context, from, to address.Address, method string, params ...interface{}) (*porcelain.GasEstimate, error)
 }
 
 // ProofCalculator creates the proof-of-spacetime bytes.
@@ -69,6 +69,7 @@ type PoStSubmission struct {
 	Fee      types.AttoFIL
 	GasLimit types.GasUnits
 	Faults   types.FaultSet
+	Done     types.IntSet
 }
 
 // NewProver constructs a new Prover.
@@ -140,11 +141,23 @@ func (p *Prover) CalculatePoSt(ctx context.Context, start, end *types.BlockHeigh
 		// Submit anyway, in case the balance is topped up before the PoSt message is mined.
 	}
 
+	faults := types.EmptyFaultSet()
+	// TODO #2998. The done set should be updated by CLI users.
+	// Using the 0 value is just a placeholder until that work lands.
+	done := types.EmptyIntSet()
+
+	// The gas used grows with the size of the miner's state, which submitPoSt rewrites.
+	gasEstimate, err := p.chain.MessageEstimateGas(ctx, workerAddr, p.actorAddress, "submitPoSt", proof, faults, done)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to estimate gas for PoSt submission")
+	}
+
 	return &PoStSubmission{
 		Proof:    proof,
 		Fee:      feeDue,
-		GasLimit: types.NewGasUnits(submitPostGasLimit),
-		Faults:   types.EmptyFaultSet(),
+		GasLimit: gasEstimate.GasLimit,
+		Faults:   faults,
+		Done:     done,
 	}, nil
 }
 
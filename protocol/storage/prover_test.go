@@ -11,6 +11,7 @@ import (
 
 	"github.com/filecoin-project/go-filecoin/actor/builtin/miner"
 	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/protocol/storage"
 	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/types"
@@ -41,6 +42,7 @@ func TestProver(t *testing.T) {
 			balance:       collateralRequirement,
 			proof:         []byte{1, 2, 3, 4},
 			faults:        []uint64{},
+			gasLimit:      types.NewGasUnits(1234),
 		}
 	}
 
@@ -51,6 +53,7 @@ func TestProver(t *testing.T) {
 		require.NoError(t, e)
 		assert.Equal(t, pc.proof, submission.Proof)
 		assert.Equal(t, types.ZeroAttoFIL, submission.Fee)
+		assert.Equal(t, pc.gasLimit, submission.GasLimit)
 	})
 
 	t.Run("attaches a fee", func(t *testing.T) {
@@ -120,6 +123,7 @@ type fakeProverContext struct {
 	balance       types.AttoFIL
 	proof         types.PoStProof
 	faults        []uint64
+	gasLimit      types.GasUnits
 }
 
 func (f *fakeProverContext) ChainHeadKey() types.TipSetKey {
@@ -155,6 +159,13 @@ func (f *fakeProverContext) CalculatePoSt(ctx context.Context, sortedCommRs go_s
 	return f.proof, nil
 }
 
+func (f *fakeProverContext) MessageEstimateGas(_ context.Context, from, to address.Address, method string, _ ...interface{}) (*porcelain.GasEstimate, error) {
+	if from != f.workerAddress || to != f.actorAddress || method != "submitPoSt" {
+		return nil, errors.New("unexpected message")
+	}
+	return &porcelain.GasEstimate{GasPrice: types.NewGasPrice(1), GasLimit: f.gasLimit}, nil
+}
+
 func (f *fakeProverContext) MinerGetWorkerAddress(_ context.Context, _ address.Address, _ types.TipSetKey) (address.Address, error) {
 	return f.workerAddress, nil
 }
@@ -13,5 +13,6 @@ type FakeProver struct{}
 func (p *FakeProver) CalculatePoSt(ctx context.Context, start, end *types.BlockHeight, inputs []PoStInputs) (*PoStSubmission, error) {
 	return &PoStSubmission{
 		Proof: []byte("test proof"),
+		Done:  types.EmptyIntSet(),
 	}, nil
 }
@@ -23,7 +23,10 @@ import (
 
 const (
 	// apiFile is the filename containing the filecoin node's api address.
-	apiFile                = "api"
+	apiFile = "api"
+	// apiTokenFile is the filename containing the admin token of the filecoin node's api.
+	apiTokenFile = "token"
+
 	configFilename         = "config.json"
 	tempConfigFilename     = ".config.json.temp"
 	lockFile               = "repo.lock"
@@ -325,6 +328,10 @@ func (r *FSRepo) Close() error {
 		return errors.Wrap(err, "error removing API file")
 	}
 
+	if err := r.removeFile(filepath.Join(r.path, apiTokenFile)); err != nil {
+		return errors.Wrap(err, "error removing API token file")
+	}
+
 	return r.lockfile.Close()
 }
 
@@ -585,6 +592,37 @@ func (r *FSRepo) APIAddr() (string, error) {
 	return apiAddrFromFile(filepath.Join(filepath.Clean(r.path), apiFile))
 }
 
+// SetAPIToken writes the admin token of the running API to the API token file, which only the
+// owner of the repo may read.
+func (r *FSRepo) SetAPIToken(token string) error {
+	if err := ioutil.WriteFile(filepath.Join(r.path, apiTokenFile), []byte(token), 0600); err != nil {
+		return errors.Wrap(err, "could not write API token file")
+	}
+	return nil
+}
+
+// APITokenFromRepoPath returns the admin token of the running API from the filecoin repo.
+func APITokenFromRepoPath(repoPath string) (string, error) {
+	repoPath, err := homedir.Expand(repoPath)
+	if err != nil {
+		return "", errors.Wrap(err, fmt.Sprintf("can't resolve local repo path %s", repoPath))
+	}
+	return apiTokenFromFile(filepath.Join(repoPath, apiTokenFile))
+}
+
+func apiTokenFromFile(tokenFilePath string) (string, error) {
+	contents, err := ioutil.ReadFile(tokenFilePath)
+	if err != nil {
+		return "", errors.Wrap(err, "failed to read API token file")
+	}
+	return strings.TrimSpace(string(contents)), nil
+}
+
+// APIToken reads the FSRepo's API token file and returns the admin token.
+func (r *FSRepo) APIToken() (string, error) {
+	return apiTokenFromFile(filepath.Join(filepath.Clean(r.path), apiTokenFile))
+}
+
 func badgerOptions() *badgerds.Options {
 	result := &badgerds.DefaultOptions
 	result.Truncate = true
@@ -42,7 +42,9 @@ const (
 	},
 	"datastore": {
 		"type": "badgerds",
-		"path": "badger"
+		"path": "badger",
+		"gcKeepDepth": 2000,
+		"gcPeriod": ""
 	},
 	"heartbeat": {
 		"beatTarget": "",
@@ -53,11 +55,14 @@ const (
 	"mining": {
 		"minerAddress": "empty",
 		"autoSealIntervalSeconds": 120,
-		"storagePrice": "0"
+		"storagePrice": "0",
+		"retrievalPrice": "0"
 	},
 	"mpool": {
 		"maxPoolSize": 10000,
-		"maxNonceGap": "100"
+		"maxNonceGap": "100",
+		"replaceByFeePercent": 10,
+		"maxSenderMessages": 32
 	},
 	"observability": {
 		"metrics": {
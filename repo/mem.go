@@ -23,6 +23,7 @@ type MemRepo struct {
 	DealsDs    Datastore
 	version    uint
 	apiAddress string
+	apiToken   string
 }
 
 var _ Repo = (*MemRepo)(nil)
@@ -105,6 +106,17 @@ func (mr *MemRepo) APIAddr() (string, error) {
 	return mr.apiAddress, nil
 }
 
+// SetAPIToken writes the admin token of the running API to memory.
+func (mr *MemRepo) SetAPIToken(token string) error {
+	mr.apiToken = token
+	return nil
+}
+
+// APIToken reads the admin token of the running API from memory.
+func (mr *MemRepo) APIToken() (string, error) {
+	return mr.apiToken, nil
+}
+
 // Path returns the default path.
 func (mr *MemRepo) Path() (string, error) {
 	return paths.GetRepoPath("")
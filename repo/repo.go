@@ -43,6 +43,12 @@ type Repo interface {
 	// APIAddr returns the address of the running API.
 	APIAddr() (string, error)
 
+	// SetAPIToken sets the admin token of the running API.
+	SetAPIToken(string) error
+
+	// APIToken returns the admin token of the running API.
+	APIToken() (string, error)
+
 	// Version returns the current repo version.
 	Version() uint
 
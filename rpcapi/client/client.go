@@ -0,0 +1,25 @@
+// Package client implements the JSON-RPC API of a filecoin node for Go applications.
+package client
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/filecoin-project/go-filecoin/jsonrpc"
+	"github.com/filecoin-project/go-filecoin/rpcapi"
+)
+
+// NewNodeClient connects to the API of the node at addr, a websocket URL such as
+// "ws://127.0.0.1:3453/rpc/v1", authenticating its calls with the API token token.
+func NewNodeClient(ctx context.Context, addr string, token string) (rpcapi.Node, jsonrpc.ClientCloser, error) {
+	header := http.Header{}
+	if token != "" {
+		header.Set("Authorization", "Bearer "+token)
+	}
+	var res NodeStruct
+	closer, err := jsonrpc.NewClient(ctx, addr, rpcapi.Namespace, &res.Internal, header)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &res, closer, nil
+}
@@ -0,0 +1,137 @@
+package client_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	ma "github.com/multiformats/go-multiaddr"
+	manet "github.com/multiformats/go-multiaddr-net"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/abi"
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/auth"
+	"github.com/filecoin-project/go-filecoin/node/test"
+	"github.com/filecoin-project/go-filecoin/rpcapi"
+	"github.com/filecoin-project/go-filecoin/rpcapi/client"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func TestNodeClient(t *testing.T) {
+	tf.IntegrationTest(t)
+	ctx := context.Background()
+
+	nd := test.NewNodeBuilder(t).Build(ctx)
+	require.NoError(t, nd.Chain.ChainReader.Load(ctx))
+	_, stop := test.RunNodeAPI(ctx, nd, t)
+	defer stop()
+
+	apiAddr, err := nd.Repo.APIAddr()
+	require.NoError(t, err)
+	maddr, err := ma.NewMultiaddr(apiAddr)
+	require.NoError(t, err)
+	netAddr, err := manet.ToNetAddr(maddr)
+	require.NoError(t, err)
+	addr := fmt.Sprintf("ws://%s%s", netAddr, rpcapi.Path)
+
+	token, err := nd.Repo.APIToken()
+	require.NoError(t, err)
+	api, closer, err := client.NewNodeClient(ctx, addr, token)
+	require.NoError(t, err)
+	defer closer()
+
+	t.Run("calls return results", func(t *testing.T) {
+		version, err := api.Version(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, rpcapi.Version, version)
+
+		id, err := api.ID(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, nd.PorcelainAPI.NetworkGetPeerID().Pretty(), id)
+
+		head, err := api.ChainHead(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, nd.PorcelainAPI.ChainHeadKey(), head.Key)
+
+		addrs, err := api.WalletAddresses(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, nd.PorcelainAPI.WalletAddresses(), addrs)
+
+		status, err := api.ChainStatus(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, nd.PorcelainAPI.ChainStatus().ValidatedHead, status.ValidatedHead)
+
+		listen, err := api.NetworkGetPeerAddresses(ctx)
+		require.NoError(t, err)
+		assert.NotEmpty(t, listen)
+
+		msg, err := api.MessagePoolGet(ctx, types.CidFromString(t, "somecid"))
+		require.NoError(t, err)
+		assert.Nil(t, msg)
+	})
+
+	t.Run("params are decoded with the signature of the method called", func(t *testing.T) {
+		sig, err := api.ActorGetSignature(ctx, address.PaymentBrokerAddress, "ls")
+		require.NoError(t, err)
+		assert.Equal(t, []abi.Type{abi.Address}, sig.Params)
+
+		params, err := abi.ToEncodedValues(address.TestAddress)
+		require.NoError(t, err)
+		head, err := api.ChainHead(ctx)
+		require.NoError(t, err)
+		ret, err := api.MessageQuery(ctx, address.Undef, address.PaymentBrokerAddress, "ls", head.Key, params)
+		require.NoError(t, err)
+		expected, err := nd.PorcelainAPI.MessageQuery(ctx, address.Undef, address.PaymentBrokerAddress, "ls", head.Key, address.TestAddress)
+		require.NoError(t, err)
+		assert.Equal(t, expected, ret)
+
+		_, err = api.MessageQuery(ctx, address.Undef, address.PaymentBrokerAddress, "ls", head.Key, []byte{0x1})
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "invalid params")
+	})
+
+	t.Run("streams return tipsets", func(t *testing.T) {
+		ch, err := api.ChainLs(ctx)
+		require.NoError(t, err)
+		var tipsets []*rpcapi.TipSet
+		for ts := range ch {
+			tipsets = append(tipsets, ts)
+		}
+		require.Len(t, tipsets, 1)
+		assert.Equal(t, uint64(0), tipsets[0].Height)
+
+		actors, err := api.ActorLs(ctx)
+		require.NoError(t, err)
+		var found bool
+		for a := range actors {
+			found = found || a.Address == address.PaymentBrokerAddress.String()
+		}
+		assert.True(t, found)
+	})
+
+	t.Run("calls require permission", func(t *testing.T) {
+		authority, err := auth.LoadAuthority(nd.Repo.Datastore())
+		require.NoError(t, err)
+		readToken, err := authority.NewToken(auth.Read)
+		require.NoError(t, err)
+		reader, closeReader, err := client.NewNodeClient(ctx, addr, readToken)
+		require.NoError(t, err)
+		defer closeReader()
+
+		_, err = reader.WalletAddresses(ctx)
+		require.NoError(t, err)
+		_, err = reader.MessageSend(ctx, address.TestAddress, address.TestAddress2, types.ZeroAttoFIL, types.ZeroAttoFIL, types.NewGasUnits(0), "", nil)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "requires sign permission")
+
+		anonymous, closeAnonymous, err := client.NewNodeClient(ctx, addr, "")
+		require.NoError(t, err)
+		defer closeAnonymous()
+		_, err = anonymous.Version(ctx)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "API token required")
+	})
+}
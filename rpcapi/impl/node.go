@@ -0,0 +1,893 @@
+// Package impl implements the JSON-RPC API of a filecoin node with the node's porcelain API.
+package impl
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ipfs/go-bitswap"
+	"github.com/ipfs/go-cid"
+	logging "github.com/ipfs/go-log"
+	"github.com/libp2p/go-libp2p-core/metrics"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/abi"
+	"github.com/filecoin-project/go-filecoin/actor"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/miner"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/paymentbroker"
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/chain"
+	"github.com/filecoin-project/go-filecoin/exec"
+	"github.com/filecoin-project/go-filecoin/message"
+	"github.com/filecoin-project/go-filecoin/net"
+	"github.com/filecoin-project/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/protocol/storage/storagedeal"
+	"github.com/filecoin-project/go-filecoin/rpcapi"
+	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/vm"
+)
+
+var log = logging.Logger("rpcapi")
+
+// Node implements rpcapi.Node.
+type Node struct {
+	api *porcelain.API
+}
+
+var _ rpcapi.Node = (*Node)(nil)
+
+// New returns a Node serving api.
+func New(api *porcelain.API) *Node {
+	return &Node{api: api}
+}
+
+// Version returns the version of the API that the node serves.
+func (n *Node) Version(ctx context.Context) (string, error) {
+	return rpcapi.Version, nil
+}
+
+// ID returns the peer ID of the node.
+func (n *Node) ID(ctx context.Context) (string, error) {
+	return n.api.NetworkGetPeerID().Pretty(), nil
+}
+
+// ProtocolParameters returns the parameters of the protocol that the node runs.
+func (n *Node) ProtocolParameters(ctx context.Context) (*porcelain.ProtocolParams, error) {
+	return n.api.ProtocolParameters(ctx)
+}
+
+// BlockTime returns the time between blocks.
+func (n *Node) BlockTime(ctx context.Context) (time.Duration, error) {
+	return n.api.BlockTime(), nil
+}
+
+// ConfigGet returns the value of the node's configuration at dottedPath.
+func (n *Node) ConfigGet(ctx context.Context, dottedPath string) (interface{}, error) {
+	return n.api.ConfigGet(dottedPath)
+}
+
+// ConfigSet sets the node's configuration at dottedPath to the JSON value paramJSON.
+func (n *Node) ConfigSet(ctx context.Context, dottedPath string, paramJSON string) error {
+	return n.api.ConfigSet(dottedPath, paramJSON)
+}
+
+// ChainHead returns the head tipset of the chain.
+func (n *Node) ChainHead(ctx context.Context) (*rpcapi.TipSet, error) {
+	head, err := n.api.ChainHead()
+	if err != nil {
+		return nil, err
+	}
+	return toTipSet(head)
+}
+
+// ChainGetTipSet returns the tipset with key key.
+func (n *Node) ChainGetTipSet(ctx context.Context, key types.TipSetKey) (*rpcapi.TipSet, error) {
+	ts, err := n.api.ChainTipSet(key)
+	if err != nil {
+		return nil, err
+	}
+	return toTipSet(ts)
+}
+
+// ChainGetBlock returns the block with CID c.
+func (n *Node) ChainGetBlock(ctx context.Context, c cid.Cid) (*types.Block, error) {
+	return n.api.ChainGetBlock(ctx, c)
+}
+
+// ChainGetFullBlock returns the block with CID c with its messages and receipts.
+func (n *Node) ChainGetFullBlock(ctx context.Context, c cid.Cid) (*types.FullBlock, error) {
+	return n.api.ChainGetFullBlock(ctx, c)
+}
+
+// ChainGetMessages returns the messages of the collection with CID c.
+func (n *Node) ChainGetMessages(ctx context.Context, c cid.Cid) ([]*types.SignedMessage, error) {
+	return n.api.ChainGetMessages(ctx, c)
+}
+
+// ChainGetReceipts returns the receipts of the collection with CID c.
+func (n *Node) ChainGetReceipts(ctx context.Context, c cid.Cid) ([]*types.MessageReceipt, error) {
+	return n.api.ChainGetReceipts(ctx, c)
+}
+
+// ChainLs streams the tipsets of the chain from the head back to genesis.
+func (n *Node) ChainLs(ctx context.Context) (<-chan *rpcapi.TipSet, error) {
+	iter, err := n.api.ChainLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *rpcapi.TipSet)
+	go func() {
+		defer close(out)
+		for ; !iter.Complete(); err = iter.Next() {
+			if err != nil {
+				log.Errorf("failed to iterate over the chain: %s", err)
+				return
+			}
+			ts, err := toTipSet(iter.Value())
+			if err != nil {
+				log.Errorf("failed to read tipset %s: %s", iter.Value().Key(), err)
+				return
+			}
+			select {
+			case out <- ts:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// ChainNotify streams the tipsets applied to and reverted from the chain as the head changes.
+func (n *Node) ChainNotify(ctx context.Context) (<-chan *rpcapi.HeadChange, error) {
+	changes, err := n.api.ChainNotify(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *rpcapi.HeadChange)
+	go func() {
+		defer close(out)
+		for change := range changes {
+			hc := &rpcapi.HeadChange{
+				Type: rpcapi.HeadApply,
+				TipSet: rpcapi.TipSet{
+					Key:    change.Key,
+					Height: change.Height,
+					Blocks: change.Blocks,
+				},
+			}
+			if change.Type == porcelain.ChainRevert {
+				hc.Type = rpcapi.HeadRevert
+			}
+			select {
+			case out <- hc:
+			case <-ctx.Done():
+				// ChainNotify closes changes when ctx is done.
+			}
+		}
+	}()
+	return out, nil
+}
+
+// ChainStatus returns the status of chain sync.
+func (n *Node) ChainStatus(ctx context.Context) (*chain.Status, error) {
+	status := n.api.ChainStatus()
+	return &status, nil
+}
+
+// ChainSampleRandomness returns randomness sampled from the chain at height.
+func (n *Node) ChainSampleRandomness(ctx context.Context, height *types.BlockHeight) ([]byte, error) {
+	return n.api.ChainSampleRandomness(ctx, height)
+}
+
+// ChainSetHead sets the head of the chain to the tipset with key key.
+func (n *Node) ChainSetHead(ctx context.Context, key types.TipSetKey) error {
+	return n.api.ChainSetHead(ctx, key)
+}
+
+// ChainCollectGarbage deletes the states of the tipsets more than keepDepth tipsets below the
+// head, returning the number of blocks deleted.
+func (n *Node) ChainCollectGarbage(ctx context.Context, keepDepth uint) (int, error) {
+	return n.api.ChainCollectGarbage(ctx, keepDepth)
+}
+
+// ActorGet returns the actor at addr in the state of the head of the chain.
+func (n *Node) ActorGet(ctx context.Context, addr address.Address) (*actor.Actor, error) {
+	return n.api.ActorGet(ctx, addr)
+}
+
+// ActorGetSignature returns the signature of method of the actor at addr.
+func (n *Node) ActorGetSignature(ctx context.Context, addr address.Address, method string) (*exec.FunctionSignature, error) {
+	return n.api.ActorGetSignature(ctx, addr, method)
+}
+
+// ActorLs streams the actors in the state of the head of the chain.
+func (n *Node) ActorLs(ctx context.Context) (<-chan *rpcapi.ActorInfo, error) {
+	results, err := n.api.ActorLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *rpcapi.ActorInfo)
+	go func() {
+		defer close(out)
+		// The actors are drained until the channel closes, since ActorLs blocks sending them.
+		failed := false
+		for res := range results {
+			if failed {
+				continue
+			}
+			if res.Error != nil {
+				log.Errorf("failed to list actors: %s", res.Error)
+				failed = true
+				continue
+			}
+			select {
+			case out <- &rpcapi.ActorInfo{Address: res.Address, Actor: res.Actor}:
+			case <-ctx.Done():
+				failed = true
+			}
+		}
+	}()
+	return out, nil
+}
+
+// MessageSend signs and sends a message from an address in the node's wallet, returning its CID.
+func (n *Node) MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method string, params []byte) (cid.Cid, error) {
+	values, err := n.decodeParams(ctx, to, method, params)
+	if err != nil {
+		return cid.Undef, err
+	}
+	return n.api.MessageSend(ctx, from, to, value, gasPrice, gasLimit, method, values...)
+}
+
+// MessagePreview returns the gas that a message calling method of the actor at to would use.
+func (n *Node) MessagePreview(ctx context.Context, from, to address.Address, method string, params []byte) (types.GasUnits, error) {
+	values, err := n.decodeParams(ctx, to, method, params)
+	if err != nil {
+		return types.NewGasUnits(0), err
+	}
+	return n.api.MessagePreview(ctx, from, to, method, values...)
+}
+
+// MessageQuery calls method of the actor at to in the state of the tipset with key baseKey,
+// without sending a message, and returns its encoded return values.
+func (n *Node) MessageQuery(ctx context.Context, from, to address.Address, method string, baseKey types.TipSetKey, params []byte) ([][]byte, error) {
+	values, err := n.decodeParams(ctx, to, method, params)
+	if err != nil {
+		return nil, err
+	}
+	return n.api.MessageQuery(ctx, from, to, method, baseKey, values...)
+}
+
+// MessageEstimateGas estimates the gas price and limit of a message calling method of the actor
+// at to.
+func (n *Node) MessageEstimateGas(ctx context.Context, from, to address.Address, method string, params []byte) (*porcelain.GasEstimate, error) {
+	values, err := n.decodeParams(ctx, to, method, params)
+	if err != nil {
+		return nil, err
+	}
+	return n.api.MessageEstimateGas(ctx, from, to, method, values...)
+}
+
+// MessageEstimateGasPrice estimates a gas price for messages to be included soon.
+func (n *Node) MessageEstimateGasPrice(ctx context.Context) (types.AttoFIL, error) {
+	return n.api.MessageEstimateGasPrice(ctx)
+}
+
+// MessageReplace replaces the pending message with CID c with a copy paying gasPrice, returning
+// the CID of the copy.
+func (n *Node) MessageReplace(ctx context.Context, c cid.Cid, gasPrice types.AttoFIL) (cid.Cid, error) {
+	return n.api.MessageReplace(ctx, c, gasPrice)
+}
+
+// MessageFind returns the message with CID c if the chain includes it, or nil if it does not.
+func (n *Node) MessageFind(ctx context.Context, c cid.Cid) (*rpcapi.MessageResult, error) {
+	found, ok, err := n.api.MessageFind(ctx, c)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return &rpcapi.MessageResult{Block: found.Block.Cid(), Message: found.Message, Receipt: found.Receipt}, nil
+}
+
+// MessageWait waits for the message with CID c to be included in the chain.
+func (n *Node) MessageWait(ctx context.Context, c cid.Cid) (*rpcapi.MessageResult, error) {
+	var res *rpcapi.MessageResult
+	err := n.api.MessageWait(ctx, c, func(blk *types.Block, msg *types.SignedMessage, receipt *types.MessageReceipt) error {
+		res = &rpcapi.MessageResult{Block: blk.Cid(), Message: msg, Receipt: receipt}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+// MessageTrace returns the execution trace of the message with CID c.
+func (n *Node) MessageTrace(ctx context.Context, c cid.Cid) (*vm.ExecutionTrace, error) {
+	return n.api.MessageTrace(ctx, c)
+}
+
+// MessagePoolPending returns the messages in the node's message pool.
+func (n *Node) MessagePoolPending(ctx context.Context) ([]*types.SignedMessage, error) {
+	return n.api.MessagePoolPending(), nil
+}
+
+// MessagePoolGet returns the message with CID c in the message pool, or nil if it is not in the
+// pool.
+func (n *Node) MessagePoolGet(ctx context.Context, c cid.Cid) (*types.SignedMessage, error) {
+	msg, _ := n.api.MessagePoolGet(c)
+	return msg, nil
+}
+
+// MessagePoolRemove removes the message with CID c from the message pool.
+func (n *Node) MessagePoolRemove(ctx context.Context, c cid.Cid) error {
+	n.api.MessagePoolRemove(c)
+	return nil
+}
+
+// MessagePoolWait waits for the message pool to hold at least count messages and returns them.
+func (n *Node) MessagePoolWait(ctx context.Context, count uint) ([]*types.SignedMessage, error) {
+	return n.api.MessagePoolWait(ctx, count)
+}
+
+// MessagePoolWatch streams the messages added to and removed from the message pool.
+func (n *Node) MessagePoolWatch(ctx context.Context) (<-chan *rpcapi.PoolEvent, error) {
+	events := n.api.MessagePoolSubscribe()
+	out := make(chan *rpcapi.PoolEvent)
+	go func() {
+		defer close(out)
+		defer n.api.MessagePoolUnsubscribe(events)
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case e, ok := <-events:
+				if !ok {
+					return
+				}
+				event := e.(*message.PoolEvent)
+				select {
+				case out <- &rpcapi.PoolEvent{Type: poolEventTypes[event.Type], Cid: event.Cid, Message: event.Message}:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+	return out, nil
+}
+
+var poolEventTypes = map[message.PoolEventType]rpcapi.PoolEventType{
+	message.MessageAdded:    rpcapi.PoolMessageAdded,
+	message.MessageRemoved:  rpcapi.PoolMessageRemoved,
+	message.MessageIncluded: rpcapi.PoolMessageIncluded,
+	message.MessageExpired:  rpcapi.PoolMessageExpired,
+}
+
+// OutboxQueues returns the addresses with messages in the node's outbox.
+func (n *Node) OutboxQueues(ctx context.Context) ([]address.Address, error) {
+	return n.api.OutboxQueues(), nil
+}
+
+// OutboxQueueLs returns the messages from sender in the outbox.
+func (n *Node) OutboxQueueLs(ctx context.Context, sender address.Address) ([]*message.Queued, error) {
+	return n.api.OutboxQueueLs(sender), nil
+}
+
+// OutboxQueueClear removes the messages from sender from the outbox.
+func (n *Node) OutboxQueueClear(ctx context.Context, sender address.Address) error {
+	n.api.OutboxQueueClear(ctx, sender)
+	return nil
+}
+
+// NetworkGetBandwidthStats returns the bandwidth the node has used.
+func (n *Node) NetworkGetBandwidthStats(ctx context.Context) (*metrics.Stats, error) {
+	stats := n.api.NetworkGetBandwidthStats()
+	return &stats, nil
+}
+
+// NetworkGetPeerAddresses returns the multiaddresses that the node listens on.
+func (n *Node) NetworkGetPeerAddresses(ctx context.Context) ([]string, error) {
+	var addrs []string
+	for _, addr := range n.api.NetworkGetPeerAddresses() {
+		addrs = append(addrs, addr.String())
+	}
+	return addrs, nil
+}
+
+// NetworkFindProvidersAsync streams up to count peers providing the block with CID c.
+func (n *Node) NetworkFindProvidersAsync(ctx context.Context, c cid.Cid, count int) (<-chan *peer.AddrInfo, error) {
+	providers := n.api.NetworkFindProvidersAsync(ctx, c, count)
+	out := make(chan *peer.AddrInfo)
+	go func() {
+		defer close(out)
+		for provider := range providers {
+			provider := provider
+			select {
+			case out <- &provider:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// NetworkGetClosestPeers streams the peers closest to key.
+func (n *Node) NetworkGetClosestPeers(ctx context.Context, key string) (<-chan peer.ID, error) {
+	return n.api.NetworkGetClosestPeers(ctx, key)
+}
+
+// NetworkPing streams the results of pinging the peer pid.
+func (n *Node) NetworkPing(ctx context.Context, pid peer.ID) (<-chan *rpcapi.PingResult, error) {
+	results, err := n.api.NetworkPing(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *rpcapi.PingResult)
+	go func() {
+		defer close(out)
+		for res := range results {
+			result := &rpcapi.PingResult{RTT: res.RTT}
+			if res.Error != nil {
+				result.Error = res.Error.Error()
+			}
+			select {
+			case out <- result:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// NetworkFindPeer returns the addresses of the peer pid.
+func (n *Node) NetworkFindPeer(ctx context.Context, pid peer.ID) (*peer.AddrInfo, error) {
+	info, err := n.api.NetworkFindPeer(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	return &info, nil
+}
+
+// NetworkConnect connects to the peers at addrs, streaming the result of each connection.
+func (n *Node) NetworkConnect(ctx context.Context, addrs []string) (<-chan *rpcapi.ConnectionResult, error) {
+	results, err := n.api.NetworkConnect(ctx, addrs)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *rpcapi.ConnectionResult)
+	go func() {
+		defer close(out)
+		for res := range results {
+			result := &rpcapi.ConnectionResult{PeerID: res.PeerID}
+			if res.Err != nil {
+				result.Error = res.Err.Error()
+			}
+			select {
+			case out <- result:
+			case <-ctx.Done():
+				// Connect sends a result for each address, so keep draining them.
+			}
+		}
+	}()
+	return out, nil
+}
+
+// NetworkPeers returns the peers the node is connected to.
+func (n *Node) NetworkPeers(ctx context.Context, verbose, latency, streams bool) (*net.SwarmConnInfos, error) {
+	return n.api.NetworkPeers(ctx, verbose, latency, streams)
+}
+
+// PubSubSubscribe streams the messages published on topic.
+func (n *Node) PubSubSubscribe(ctx context.Context, topic string) (<-chan *rpcapi.PubSubMessage, error) {
+	sub, err := n.api.PubSubSubscribe(topic)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *rpcapi.PubSubMessage)
+	go func() {
+		defer close(out)
+		defer sub.Cancel()
+		for {
+			msg, err := sub.Next(ctx)
+			if err != nil {
+				if ctx.Err() == nil {
+					log.Errorf("failed to read pubsub topic %s: %s", topic, err)
+				}
+				return
+			}
+			select {
+			case out <- &rpcapi.PubSubMessage{From: msg.GetFrom(), Data: msg.GetData()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return out, nil
+}
+
+// PubSubPublish publishes data on topic.
+func (n *Node) PubSubPublish(ctx context.Context, topic string, data []byte) error {
+	return n.api.PubSubPublish(topic, data)
+}
+
+// WalletAddresses returns the addresses in the node's wallet.
+func (n *Node) WalletAddresses(ctx context.Context) ([]address.Address, error) {
+	return n.api.WalletAddresses(), nil
+}
+
+// WalletDefaultAddress returns the node's default wallet address.
+func (n *Node) WalletDefaultAddress(ctx context.Context) (address.Address, error) {
+	return n.api.WalletDefaultAddress()
+}
+
+// WalletBalance returns the balance of the actor at addr.
+func (n *Node) WalletBalance(ctx context.Context, addr address.Address) (types.AttoFIL, error) {
+	return n.api.WalletBalance(ctx, addr)
+}
+
+// WalletNewAddress adds a new key of protocol to the wallet, returning its address.
+func (n *Node) WalletNewAddress(ctx context.Context, protocol address.Protocol) (address.Address, error) {
+	return n.api.WalletNewAddress(protocol)
+}
+
+// WalletImport adds keys to the wallet, returning their addresses.
+func (n *Node) WalletImport(ctx context.Context, keys []*types.KeyInfo) ([]address.Address, error) {
+	return n.api.WalletImport(keys...)
+}
+
+// WalletExport returns the keys of addrs.
+func (n *Node) WalletExport(ctx context.Context, addrs []address.Address) ([]*types.KeyInfo, error) {
+	return n.api.WalletExport(addrs)
+}
+
+// WalletGetPubKeyForAddress returns the public key of addr.
+func (n *Node) WalletGetPubKeyForAddress(ctx context.Context, addr address.Address) ([]byte, error) {
+	return n.api.WalletGetPubKeyForAddress(addr)
+}
+
+// WalletDerivationPath returns the HD derivation path of addr, or an empty string if its key was
+// not derived.
+func (n *Node) WalletDerivationPath(ctx context.Context, addr address.Address) (string, error) {
+	path, _ := n.api.WalletDerivationPath(addr)
+	return path, nil
+}
+
+// WalletInitHD turns the wallet into an HD wallet restored from mnemonic, or from a new mnemonic
+// if it is empty, and derives count addresses.
+func (n *Node) WalletInitHD(ctx context.Context, mnemonic string, count int) (*rpcapi.HDWallet, error) {
+	mnemonic, addrs, err := n.api.WalletInitHD(mnemonic, count)
+	if err != nil {
+		return nil, err
+	}
+	return &rpcapi.HDWallet{Mnemonic: mnemonic, Addresses: addrs}, nil
+}
+
+// WalletEncrypt encrypts the keys of the wallet with passphrase.
+func (n *Node) WalletEncrypt(ctx context.Context, passphrase []byte) error {
+	return n.api.WalletEncrypt(passphrase)
+}
+
+// WalletLock locks an encrypted wallet.
+func (n *Node) WalletLock(ctx context.Context) error {
+	return n.api.WalletLock()
+}
+
+// WalletUnlock unlocks an encrypted wallet for timeout, or until it is locked if timeout is zero.
+func (n *Node) WalletUnlock(ctx context.Context, passphrase []byte, timeout time.Duration) error {
+	return n.api.WalletUnlock(passphrase, timeout)
+}
+
+// SignBytes signs data with the key of addr.
+func (n *Node) SignBytes(ctx context.Context, data []byte, addr address.Address) (types.Signature, error) {
+	return n.api.SignBytes(data, addr)
+}
+
+// DAGGetNode returns the IPLD node at ref, a CID optionally followed by a path.
+func (n *Node) DAGGetNode(ctx context.Context, ref string) (interface{}, error) {
+	return n.api.DAGGetNode(ctx, ref)
+}
+
+// DAGGetFileSize returns the size of the UnixFS file with CID c.
+func (n *Node) DAGGetFileSize(ctx context.Context, c cid.Cid) (uint64, error) {
+	return n.api.DAGGetFileSize(ctx, c)
+}
+
+// BitswapGetStats returns the node's bitswap statistics.
+func (n *Node) BitswapGetStats(ctx context.Context) (*bitswap.Stat, error) {
+	return n.api.BitswapGetStats(ctx)
+}
+
+// MinerCreate creates a miner owned by from, returning its address.
+func (n *Node) MinerCreate(ctx context.Context, from address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, sectorSize *types.BytesAmount, pid peer.ID, collateral types.AttoFIL) (address.Address, error) {
+	addr, err := n.api.MinerCreate(ctx, from, gasPrice, gasLimit, sectorSize, pid, collateral)
+	if err != nil {
+		return address.Undef, err
+	}
+	return *addr, nil
+}
+
+// MinerPreviewCreate returns the gas that creating a miner would use.
+func (n *Node) MinerPreviewCreate(ctx context.Context, from address.Address, sectorSize *types.BytesAmount, pid peer.ID) (types.GasUnits, error) {
+	return n.api.MinerPreviewCreate(ctx, from, sectorSize, pid)
+}
+
+// MinerGetAsk returns the ask with ID askID of the miner at minerAddr.
+func (n *Node) MinerGetAsk(ctx context.Context, minerAddr address.Address, askID uint64) (*miner.Ask, error) {
+	ask, err := n.api.MinerGetAsk(ctx, minerAddr, askID)
+	if err != nil {
+		return nil, err
+	}
+	return &ask, nil
+}
+
+// MinerGetRetrievalAsk returns the retrieval ask of the miner at minerAddr.
+func (n *Node) MinerGetRetrievalAsk(ctx context.Context, minerAddr address.Address) (*miner.RetrievalAsk, error) {
+	return n.api.MinerGetRetrievalAsk(ctx, minerAddr)
+}
+
+// MinerGetOwnerAddress returns the owner of the miner at minerAddr.
+func (n *Node) MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address) (address.Address, error) {
+	return n.api.MinerGetOwnerAddress(ctx, minerAddr)
+}
+
+// MinerGetWorkerAddress returns the worker of the miner at minerAddr in the state of the tipset
+// with key baseKey.
+func (n *Node) MinerGetWorkerAddress(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (address.Address, error) {
+	return n.api.MinerGetWorkerAddress(ctx, minerAddr, baseKey)
+}
+
+// MinerGetSectorSize returns the sector size of the miner at minerAddr.
+func (n *Node) MinerGetSectorSize(ctx context.Context, minerAddr address.Address) (*types.BytesAmount, error) {
+	return n.api.MinerGetSectorSize(ctx, minerAddr)
+}
+
+// MinerCalculateLateFee returns the fee that the miner at minerAddr owes for a late proof at
+// height.
+func (n *Node) MinerCalculateLateFee(ctx context.Context, minerAddr address.Address, height *types.BlockHeight) (types.AttoFIL, error) {
+	return n.api.MinerCalculateLateFee(ctx, minerAddr, height)
+}
+
+// MinerGetLastCommittedSectorID returns the ID of the last sector the miner at minerAddr
+// committed.
+func (n *Node) MinerGetLastCommittedSectorID(ctx context.Context, minerAddr address.Address) (uint64, error) {
+	return n.api.MinerGetLastCommittedSectorID(ctx, minerAddr)
+}
+
+// MinerGetPeerID returns the peer ID of the miner at minerAddr.
+func (n *Node) MinerGetPeerID(ctx context.Context, minerAddr address.Address) (peer.ID, error) {
+	return n.api.MinerGetPeerID(ctx, minerAddr)
+}
+
+// MinerGetPower returns the power of the miner at minerAddr and the total power.
+func (n *Node) MinerGetPower(ctx context.Context, minerAddr address.Address) (*porcelain.MinerPower, error) {
+	power, err := n.api.MinerGetPower(ctx, minerAddr)
+	if err != nil {
+		return nil, err
+	}
+	return &power, nil
+}
+
+// MinerGetProvingWindow returns the current proving window of the miner at minerAddr.
+func (n *Node) MinerGetProvingWindow(ctx context.Context, minerAddr address.Address) (*porcelain.MinerProvingWindow, error) {
+	window, err := n.api.MinerGetProvingWindow(ctx, minerAddr)
+	if err != nil {
+		return nil, err
+	}
+	return &window, nil
+}
+
+// MinerGetCollateral returns the collateral of the miner at minerAddr.
+func (n *Node) MinerGetCollateral(ctx context.Context, minerAddr address.Address) (types.AttoFIL, error) {
+	return n.api.MinerGetCollateral(ctx, minerAddr)
+}
+
+// MinerSetPrice adds an ask at price expiring after expiry blocks to the miner at minerAddr.
+func (n *Node) MinerSetPrice(ctx context.Context, from address.Address, minerAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, price types.AttoFIL, expiry *big.Int) (*porcelain.MinerSetPriceResponse, error) {
+	res, err := n.api.MinerSetPrice(ctx, from, minerAddr, gasPrice, gasLimit, price, expiry)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// MinerPreviewSetPrice returns the gas that adding an ask would use.
+func (n *Node) MinerPreviewSetPrice(ctx context.Context, from address.Address, minerAddr address.Address, price types.AttoFIL, expiry *big.Int) (types.GasUnits, error) {
+	return n.api.MinerPreviewSetPrice(ctx, from, minerAddr, price, expiry)
+}
+
+// MinerSetRetrievalPrice sets the retrieval ask of the miner at minerAddr.
+func (n *Node) MinerSetRetrievalPrice(ctx context.Context, from address.Address, minerAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, ask miner.RetrievalAsk) (*porcelain.MinerSetRetrievalPriceResponse, error) {
+	res, err := n.api.MinerSetRetrievalPrice(ctx, from, minerAddr, gasPrice, gasLimit, ask)
+	if err != nil {
+		return nil, err
+	}
+	return &res, nil
+}
+
+// MinerSetWorkerAddress sets the worker of the node's miner to worker.
+func (n *Node) MinerSetWorkerAddress(ctx context.Context, worker address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits) (cid.Cid, error) {
+	return n.api.MinerSetWorkerAddress(ctx, worker, gasPrice, gasLimit)
+}
+
+// SealNow seals the sectors that the node's miner is filling.
+func (n *Node) SealNow(ctx context.Context) error {
+	return n.api.SealNow(ctx)
+}
+
+// DealGet returns the storage deal with proposal CID c.
+func (n *Node) DealGet(ctx context.Context, c cid.Cid) (*storagedeal.Deal, error) {
+	return n.api.DealGet(ctx, c)
+}
+
+// DealsLs streams the node's storage deals.
+func (n *Node) DealsLs(ctx context.Context) (<-chan *storagedeal.Deal, error) {
+	results, err := n.api.DealsLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	out := make(chan *storagedeal.Deal)
+	go func() {
+		defer close(out)
+		// The deals are drained until the channel closes, since DealsLs blocks sending them.
+		failed := false
+		for res := range results {
+			if failed {
+				continue
+			}
+			if res.Err != nil {
+				log.Errorf("failed to list deals: %s", res.Err)
+				failed = true
+				continue
+			}
+			deal := res.Deal
+			select {
+			case out <- &deal:
+			case <-ctx.Done():
+				failed = true
+			}
+		}
+	}()
+	return out, nil
+}
+
+// DealRedeem redeems the vouchers of the storage deal with proposal CID c, returning the CID of
+// the message that redeems them.
+func (n *Node) DealRedeem(ctx context.Context, from address.Address, c cid.Cid, gasPrice types.AttoFIL, gasLimit types.GasUnits) (cid.Cid, error) {
+	return n.api.DealRedeem(ctx, from, c, gasPrice, gasLimit)
+}
+
+// DealRedeemPreview returns the gas that redeeming the vouchers of a deal would use.
+func (n *Node) DealRedeemPreview(ctx context.Context, from address.Address, c cid.Cid) (types.GasUnits, error) {
+	return n.api.DealRedeemPreview(ctx, from, c)
+}
+
+// ClientListAsks streams the asks of all miners.
+func (n *Node) ClientListAsks(ctx context.Context) (<-chan *rpcapi.Ask, error) {
+	asks := n.api.ClientListAsks(ctx)
+	out := make(chan *rpcapi.Ask)
+	go func() {
+		defer close(out)
+		// The asks are drained until the channel closes, since ClientListAsks blocks sending
+		// them.
+		failed := false
+		for ask := range asks {
+			if failed {
+				continue
+			}
+			if ask.Error != nil {
+				log.Errorf("failed to list asks: %s", ask.Error)
+				failed = true
+				continue
+			}
+			select {
+			case out <- &rpcapi.Ask{Miner: ask.Miner, Price: ask.Price, Expiry: ask.Expiry, ID: ask.ID}:
+			case <-ctx.Done():
+				failed = true
+			}
+		}
+	}()
+	return out, nil
+}
+
+// ClientListRetrievalAsks streams the retrieval asks of all miners.
+func (n *Node) ClientListRetrievalAsks(ctx context.Context) (<-chan *rpcapi.RetrievalAsk, error) {
+	asks := n.api.ClientListRetrievalAsks(ctx)
+	out := make(chan *rpcapi.RetrievalAsk)
+	go func() {
+		defer close(out)
+		// The asks are drained until the channel closes, since ClientListRetrievalAsks blocks
+		// sending them.
+		failed := false
+		for ask := range asks {
+			if failed {
+				continue
+			}
+			if ask.Error != nil {
+				log.Errorf("failed to list retrieval asks: %s", ask.Error)
+				failed = true
+				continue
+			}
+			select {
+			case out <- &rpcapi.RetrievalAsk{Miner: ask.Miner, Price: ask.Price, PaymentInterval: ask.PaymentInterval, UnsealPrice: ask.UnsealPrice}:
+			case <-ctx.Done():
+				failed = true
+			}
+		}
+	}()
+	return out, nil
+}
+
+// ClientFindRetrievalMiners returns the miners that can retrieve the piece with CID c.
+func (n *Node) ClientFindRetrievalMiners(ctx context.Context, c cid.Cid) ([]porcelain.RetrievalMiner, error) {
+	return n.api.ClientFindRetrievalMiners(ctx, c)
+}
+
+// PingMinerWithTimeout pings the miner with peer ID pid, failing after timeout.
+func (n *Node) PingMinerWithTimeout(ctx context.Context, pid peer.ID, timeout time.Duration) error {
+	return n.api.PingMinerWithTimeout(ctx, pid, timeout)
+}
+
+// CreatePayments creates a payment channel and vouchers paying into it.
+func (n *Node) CreatePayments(ctx context.Context, params porcelain.CreatePaymentsParams) (*porcelain.CreatePaymentsReturn, error) {
+	return n.api.CreatePayments(ctx, params)
+}
+
+// PaymentChannelLs returns the payment channels of payer.
+func (n *Node) PaymentChannelLs(ctx context.Context, from address.Address, payer address.Address) (map[string]*paymentbroker.PaymentChannel, error) {
+	return n.api.PaymentChannelLs(ctx, from, payer)
+}
+
+// PaymentChannelVoucher creates a voucher paying amount from channel.
+func (n *Node) PaymentChannelVoucher(ctx context.Context, from address.Address, channel *types.ChannelID, amount types.AttoFIL, validAt *types.BlockHeight, condition *types.Predicate) (*types.PaymentVoucher, error) {
+	return n.api.PaymentChannelVoucher(ctx, from, channel, amount, validAt, condition)
+}
+
+// MultisigCreate creates a multisig actor, returning its address.
+func (n *Node) MultisigCreate(ctx context.Context, from address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, signers []address.Address, threshold uint64, value types.AttoFIL) (address.Address, error) {
+	return n.api.MultisigCreate(ctx, from, gasPrice, gasLimit, signers, threshold, value)
+}
+
+// MultisigPropose proposes that the multisig actor at msigAddr call method of the actor at to,
+// returning the ID of the proposal.
+func (n *Node) MultisigPropose(ctx context.Context, from address.Address, msigAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, to address.Address, value types.AttoFIL, method string, params []byte) (uint64, error) {
+	values, err := n.decodeParams(ctx, to, method, params)
+	if err != nil {
+		return 0, err
+	}
+	return n.api.MultisigPropose(ctx, from, msigAddr, gasPrice, gasLimit, to, value, method, values...)
+}
+
+// MultisigLs returns the signers and pending proposals of the multisig actor at msigAddr.
+func (n *Node) MultisigLs(ctx context.Context, msigAddr address.Address) (*porcelain.MultisigInfo, error) {
+	return n.api.MultisigLs(ctx, msigAddr)
+}
+
+// decodeParams decodes the ABI encoded params of method of the actor at to into the values that
+// the porcelain API encodes again.
+func (n *Node) decodeParams(ctx context.Context, to address.Address, method string, params []byte) ([]interface{}, error) {
+	if len(params) == 0 {
+		return nil, nil
+	}
+	sig, err := n.api.ActorGetSignature(ctx, to, method)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get the signature of %s", method)
+	}
+	values, err := abi.DecodeValues(params, sig.Params)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid params")
+	}
+	return abi.FromValues(values), nil
+}
+
+func toTipSet(ts types.TipSet) (*rpcapi.TipSet, error) {
+	height, err := ts.Height()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get tipset height")
+	}
+	return &rpcapi.TipSet{Key: ts.Key(), Height: height, Blocks: ts.ToSlice()}, nil
+}
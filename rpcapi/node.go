@@ -0,0 +1,487 @@
+// Package rpcapi defines the JSON-RPC API of a filecoin node. Applications should depend on this
+// interface, served at Path and implemented for Go by the client package, rather than on the
+// output of CLI commands. Changes to the interface that are not backwards compatible must be made
+// in a new version.
+//
+// The API serves the methods of the node's porcelain and plumbing APIs, except for those that
+// read or write streams of raw data, such as chain export and import and DAG import, and those
+// that only the node's own subsystems use, such as chain sync and sector building.
+package rpcapi
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/ipfs/go-bitswap"
+	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/metrics"
+	"github.com/libp2p/go-libp2p-core/peer"
+
+	"github.com/filecoin-project/go-filecoin/actor"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/miner"
+	"github.com/filecoin-project/go-filecoin/actor/builtin/paymentbroker"
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/auth"
+	"github.com/filecoin-project/go-filecoin/chain"
+	"github.com/filecoin-project/go-filecoin/exec"
+	"github.com/filecoin-project/go-filecoin/message"
+	"github.com/filecoin-project/go-filecoin/net"
+	"github.com/filecoin-project/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/protocol/storage/storagedeal"
+	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/vm"
+)
+
+//go:generate go run ../tools/rpcgen -in node.go -out client/node_gen.go
+
+// Version is the version of the API.
+const Version = "1.0.0"
+
+// Path is the HTTP path at which nodes serve the API, over HTTP POST and websockets.
+const Path = "/rpc/v1"
+
+// Namespace prefixes the JSON-RPC method names of the methods of Node.
+const Namespace = "Filecoin"
+
+// Node is the JSON-RPC API of a filecoin node. Methods returning channels stream their results,
+// and may only be called over a websocket. The params of methods that call actor methods are the
+// ABI encoding of the actor method's parameters, as abi.ToEncodedValues returns it, or nil for a
+// method without parameters.
+type Node interface {
+	// Version returns the version of the API that the node serves.
+	Version(ctx context.Context) (string, error)
+	// ID returns the peer ID of the node.
+	ID(ctx context.Context) (string, error)
+	// ProtocolParameters returns the parameters of the protocol that the node runs.
+	ProtocolParameters(ctx context.Context) (*porcelain.ProtocolParams, error)
+	// BlockTime returns the time between blocks.
+	BlockTime(ctx context.Context) (time.Duration, error)
+	// ConfigGet returns the value of the node's configuration at dottedPath.
+	ConfigGet(ctx context.Context, dottedPath string) (interface{}, error)
+	// ConfigSet sets the node's configuration at dottedPath to the JSON value paramJSON.
+	ConfigSet(ctx context.Context, dottedPath string, paramJSON string) error
+
+	// ChainHead returns the head tipset of the chain.
+	ChainHead(ctx context.Context) (*TipSet, error)
+	// ChainGetTipSet returns the tipset with key key.
+	ChainGetTipSet(ctx context.Context, key types.TipSetKey) (*TipSet, error)
+	// ChainGetBlock returns the block with CID c.
+	ChainGetBlock(ctx context.Context, c cid.Cid) (*types.Block, error)
+	// ChainGetFullBlock returns the block with CID c with its messages and receipts.
+	ChainGetFullBlock(ctx context.Context, c cid.Cid) (*types.FullBlock, error)
+	// ChainGetMessages returns the messages of the collection with CID c.
+	ChainGetMessages(ctx context.Context, c cid.Cid) ([]*types.SignedMessage, error)
+	// ChainGetReceipts returns the receipts of the collection with CID c.
+	ChainGetReceipts(ctx context.Context, c cid.Cid) ([]*types.MessageReceipt, error)
+	// ChainLs streams the tipsets of the chain from the head back to genesis.
+	ChainLs(ctx context.Context) (<-chan *TipSet, error)
+	// ChainNotify streams the tipsets applied to and reverted from the chain as the head changes.
+	ChainNotify(ctx context.Context) (<-chan *HeadChange, error)
+	// ChainStatus returns the status of chain sync.
+	ChainStatus(ctx context.Context) (*chain.Status, error)
+	// ChainSampleRandomness returns randomness sampled from the chain at height.
+	ChainSampleRandomness(ctx context.Context, height *types.BlockHeight) ([]byte, error)
+	// ChainSetHead sets the head of the chain to the tipset with key key.
+	ChainSetHead(ctx context.Context, key types.TipSetKey) error
+	// ChainCollectGarbage deletes the states of the tipsets more than keepDepth tipsets below the
+	// head, returning the number of blocks deleted.
+	ChainCollectGarbage(ctx context.Context, keepDepth uint) (int, error)
+
+	// ActorGet returns the actor at addr in the state of the head of the chain.
+	ActorGet(ctx context.Context, addr address.Address) (*actor.Actor, error)
+	// ActorGetSignature returns the signature of method of the actor at addr.
+	ActorGetSignature(ctx context.Context, addr address.Address, method string) (*exec.FunctionSignature, error)
+	// ActorLs streams the actors in the state of the head of the chain.
+	ActorLs(ctx context.Context) (<-chan *ActorInfo, error)
+
+	// MessageSend signs and sends a message from an address in the node's wallet, returning its
+	// CID.
+	MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method string, params []byte) (cid.Cid, error)
+	// MessagePreview returns the gas that a message calling method of the actor at to would use.
+	MessagePreview(ctx context.Context, from, to address.Address, method string, params []byte) (types.GasUnits, error)
+	// MessageQuery calls method of the actor at to in the state of the tipset with key baseKey,
+	// without sending a message, and returns its encoded return values.
+	MessageQuery(ctx context.Context, from, to address.Address, method string, baseKey types.TipSetKey, params []byte) ([][]byte, error)
+	// MessageEstimateGas estimates the gas price and limit of a message calling method of the
+	// actor at to.
+	MessageEstimateGas(ctx context.Context, from, to address.Address, method string, params []byte) (*porcelain.GasEstimate, error)
+	// MessageEstimateGasPrice estimates a gas price for messages to be included soon.
+	MessageEstimateGasPrice(ctx context.Context) (types.AttoFIL, error)
+	// MessageReplace replaces the pending message with CID c with a copy paying gasPrice,
+	// returning the CID of the copy.
+	MessageReplace(ctx context.Context, c cid.Cid, gasPrice types.AttoFIL) (cid.Cid, error)
+	// MessageFind returns the message with CID c if the chain includes it, or nil if it does not.
+	MessageFind(ctx context.Context, c cid.Cid) (*MessageResult, error)
+	// MessageWait waits for the message with CID c to be included in the chain.
+	MessageWait(ctx context.Context, c cid.Cid) (*MessageResult, error)
+	// MessageTrace returns the execution trace of the message with CID c.
+	MessageTrace(ctx context.Context, c cid.Cid) (*vm.ExecutionTrace, error)
+
+	// MessagePoolPending returns the messages in the node's message pool.
+	MessagePoolPending(ctx context.Context) ([]*types.SignedMessage, error)
+	// MessagePoolGet returns the message with CID c in the message pool, or nil if it is not in
+	// the pool.
+	MessagePoolGet(ctx context.Context, c cid.Cid) (*types.SignedMessage, error)
+	// MessagePoolRemove removes the message with CID c from the message pool.
+	MessagePoolRemove(ctx context.Context, c cid.Cid) error
+	// MessagePoolWait waits for the message pool to hold at least count messages and returns them.
+	MessagePoolWait(ctx context.Context, count uint) ([]*types.SignedMessage, error)
+	// MessagePoolWatch streams the messages added to and removed from the message pool.
+	MessagePoolWatch(ctx context.Context) (<-chan *PoolEvent, error)
+
+	// OutboxQueues returns the addresses with messages in the node's outbox.
+	OutboxQueues(ctx context.Context) ([]address.Address, error)
+	// OutboxQueueLs returns the messages from sender in the outbox.
+	OutboxQueueLs(ctx context.Context, sender address.Address) ([]*message.Queued, error)
+	// OutboxQueueClear removes the messages from sender from the outbox.
+	OutboxQueueClear(ctx context.Context, sender address.Address) error
+
+	// NetworkGetBandwidthStats returns the bandwidth the node has used.
+	NetworkGetBandwidthStats(ctx context.Context) (*metrics.Stats, error)
+	// NetworkGetPeerAddresses returns the multiaddresses that the node listens on.
+	NetworkGetPeerAddresses(ctx context.Context) ([]string, error)
+	// NetworkFindProvidersAsync streams up to count peers providing the block with CID c.
+	NetworkFindProvidersAsync(ctx context.Context, c cid.Cid, count int) (<-chan *peer.AddrInfo, error)
+	// NetworkGetClosestPeers streams the peers closest to key.
+	NetworkGetClosestPeers(ctx context.Context, key string) (<-chan peer.ID, error)
+	// NetworkPing streams the results of pinging the peer pid.
+	NetworkPing(ctx context.Context, pid peer.ID) (<-chan *PingResult, error)
+	// NetworkFindPeer returns the addresses of the peer pid.
+	NetworkFindPeer(ctx context.Context, pid peer.ID) (*peer.AddrInfo, error)
+	// NetworkConnect connects to the peers at addrs, streaming the result of each connection.
+	NetworkConnect(ctx context.Context, addrs []string) (<-chan *ConnectionResult, error)
+	// NetworkPeers returns the peers the node is connected to.
+	NetworkPeers(ctx context.Context, verbose, latency, streams bool) (*net.SwarmConnInfos, error)
+	// PubSubSubscribe streams the messages published on topic.
+	PubSubSubscribe(ctx context.Context, topic string) (<-chan *PubSubMessage, error)
+	// PubSubPublish publishes data on topic.
+	PubSubPublish(ctx context.Context, topic string, data []byte) error
+
+	// WalletAddresses returns the addresses in the node's wallet.
+	WalletAddresses(ctx context.Context) ([]address.Address, error)
+	// WalletDefaultAddress returns the node's default wallet address.
+	WalletDefaultAddress(ctx context.Context) (address.Address, error)
+	// WalletBalance returns the balance of the actor at addr.
+	WalletBalance(ctx context.Context, addr address.Address) (types.AttoFIL, error)
+	// WalletNewAddress adds a new key of protocol to the wallet, returning its address.
+	WalletNewAddress(ctx context.Context, protocol address.Protocol) (address.Address, error)
+	// WalletImport adds keys to the wallet, returning their addresses.
+	WalletImport(ctx context.Context, keys []*types.KeyInfo) ([]address.Address, error)
+	// WalletExport returns the keys of addrs.
+	WalletExport(ctx context.Context, addrs []address.Address) ([]*types.KeyInfo, error)
+	// WalletGetPubKeyForAddress returns the public key of addr.
+	WalletGetPubKeyForAddress(ctx context.Context, addr address.Address) ([]byte, error)
+	// WalletDerivationPath returns the HD derivation path of addr, or an empty string if its key
+	// was not derived.
+	WalletDerivationPath(ctx context.Context, addr address.Address) (string, error)
+	// WalletInitHD turns the wallet into an HD wallet restored from mnemonic, or from a new
+	// mnemonic if it is empty, and derives count addresses.
+	WalletInitHD(ctx context.Context, mnemonic string, count int) (*HDWallet, error)
+	// WalletEncrypt encrypts the keys of the wallet with passphrase.
+	WalletEncrypt(ctx context.Context, passphrase []byte) error
+	// WalletLock locks an encrypted wallet.
+	WalletLock(ctx context.Context) error
+	// WalletUnlock unlocks an encrypted wallet for timeout, or until it is locked if timeout is
+	// zero.
+	WalletUnlock(ctx context.Context, passphrase []byte, timeout time.Duration) error
+	// SignBytes signs data with the key of addr.
+	SignBytes(ctx context.Context, data []byte, addr address.Address) (types.Signature, error)
+
+	// DAGGetNode returns the IPLD node at ref, a CID optionally followed by a path.
+	DAGGetNode(ctx context.Context, ref string) (interface{}, error)
+	// DAGGetFileSize returns the size of the UnixFS file with CID c.
+	DAGGetFileSize(ctx context.Context, c cid.Cid) (uint64, error)
+	// BitswapGetStats returns the node's bitswap statistics.
+	BitswapGetStats(ctx context.Context) (*bitswap.Stat, error)
+
+	// MinerCreate creates a miner owned by from, returning its address.
+	MinerCreate(ctx context.Context, from address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, sectorSize *types.BytesAmount, pid peer.ID, collateral types.AttoFIL) (address.Address, error)
+	// MinerPreviewCreate returns the gas that creating a miner would use.
+	MinerPreviewCreate(ctx context.Context, from address.Address, sectorSize *types.BytesAmount, pid peer.ID) (types.GasUnits, error)
+	// MinerGetAsk returns the ask with ID askID of the miner at minerAddr.
+	MinerGetAsk(ctx context.Context, minerAddr address.Address, askID uint64) (*miner.Ask, error)
+	// MinerGetRetrievalAsk returns the retrieval ask of the miner at minerAddr.
+	MinerGetRetrievalAsk(ctx context.Context, minerAddr address.Address) (*miner.RetrievalAsk, error)
+	// MinerGetOwnerAddress returns the owner of the miner at minerAddr.
+	MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address) (address.Address, error)
+	// MinerGetWorkerAddress returns the worker of the miner at minerAddr in the state of the
+	// tipset with key baseKey.
+	MinerGetWorkerAddress(ctx context.Context, minerAddr address.Address, baseKey types.TipSetKey) (address.Address, error)
+	// MinerGetSectorSize returns the sector size of the miner at minerAddr.
+	MinerGetSectorSize(ctx context.Context, minerAddr address.Address) (*types.BytesAmount, error)
+	// MinerCalculateLateFee returns the fee that the miner at minerAddr owes for a late proof at
+	// height.
+	MinerCalculateLateFee(ctx context.Context, minerAddr address.Address, height *types.BlockHeight) (types.AttoFIL, error)
+	// MinerGetLastCommittedSectorID returns the ID of the last sector the miner at minerAddr
+	// committed.
+	MinerGetLastCommittedSectorID(ctx context.Context, minerAddr address.Address) (uint64, error)
+	// MinerGetPeerID returns the peer ID of the miner at minerAddr.
+	MinerGetPeerID(ctx context.Context, minerAddr address.Address) (peer.ID, error)
+	// MinerGetPower returns the power of the miner at minerAddr and the total power.
+	MinerGetPower(ctx context.Context, minerAddr address.Address) (*porcelain.MinerPower, error)
+	// MinerGetProvingWindow returns the current proving window of the miner at minerAddr.
+	MinerGetProvingWindow(ctx context.Context, minerAddr address.Address) (*porcelain.MinerProvingWindow, error)
+	// MinerGetCollateral returns the collateral of the miner at minerAddr.
+	MinerGetCollateral(ctx context.Context, minerAddr address.Address) (types.AttoFIL, error)
+	// MinerSetPrice adds an ask at price expiring after expiry blocks to the miner at minerAddr.
+	MinerSetPrice(ctx context.Context, from address.Address, minerAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, price types.AttoFIL, expiry *big.Int) (*porcelain.MinerSetPriceResponse, error)
+	// MinerPreviewSetPrice returns the gas that adding an ask would use.
+	MinerPreviewSetPrice(ctx context.Context, from address.Address, minerAddr address.Address, price types.AttoFIL, expiry *big.Int) (types.GasUnits, error)
+	// MinerSetRetrievalPrice sets the retrieval ask of the miner at minerAddr.
+	MinerSetRetrievalPrice(ctx context.Context, from address.Address, minerAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, ask miner.RetrievalAsk) (*porcelain.MinerSetRetrievalPriceResponse, error)
+	// MinerSetWorkerAddress sets the worker of the node's miner to worker.
+	MinerSetWorkerAddress(ctx context.Context, worker address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits) (cid.Cid, error)
+	// SealNow seals the sectors that the node's miner is filling.
+	SealNow(ctx context.Context) error
+
+	// DealGet returns the storage deal with proposal CID c.
+	DealGet(ctx context.Context, c cid.Cid) (*storagedeal.Deal, error)
+	// DealsLs streams the node's storage deals.
+	DealsLs(ctx context.Context) (<-chan *storagedeal.Deal, error)
+	// DealRedeem redeems the vouchers of the storage deal with proposal CID c, returning the CID
+	// of the message that redeems them.
+	DealRedeem(ctx context.Context, from address.Address, c cid.Cid, gasPrice types.AttoFIL, gasLimit types.GasUnits) (cid.Cid, error)
+	// DealRedeemPreview returns the gas that redeeming the vouchers of a deal would use.
+	DealRedeemPreview(ctx context.Context, from address.Address, c cid.Cid) (types.GasUnits, error)
+
+	// ClientListAsks streams the asks of all miners.
+	ClientListAsks(ctx context.Context) (<-chan *Ask, error)
+	// ClientListRetrievalAsks streams the retrieval asks of all miners.
+	ClientListRetrievalAsks(ctx context.Context) (<-chan *RetrievalAsk, error)
+	// ClientFindRetrievalMiners returns the miners that can retrieve the piece with CID c.
+	ClientFindRetrievalMiners(ctx context.Context, c cid.Cid) ([]porcelain.RetrievalMiner, error)
+	// PingMinerWithTimeout pings the miner with peer ID pid, failing after timeout.
+	PingMinerWithTimeout(ctx context.Context, pid peer.ID, timeout time.Duration) error
+
+	// CreatePayments creates a payment channel and vouchers paying into it.
+	CreatePayments(ctx context.Context, params porcelain.CreatePaymentsParams) (*porcelain.CreatePaymentsReturn, error)
+	// PaymentChannelLs returns the payment channels of payer.
+	PaymentChannelLs(ctx context.Context, from address.Address, payer address.Address) (map[string]*paymentbroker.PaymentChannel, error)
+	// PaymentChannelVoucher creates a voucher paying amount from channel.
+	PaymentChannelVoucher(ctx context.Context, from address.Address, channel *types.ChannelID, amount types.AttoFIL, validAt *types.BlockHeight, condition *types.Predicate) (*types.PaymentVoucher, error)
+
+	// MultisigCreate creates a multisig actor, returning its address.
+	MultisigCreate(ctx context.Context, from address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, signers []address.Address, threshold uint64, value types.AttoFIL) (address.Address, error)
+	// MultisigPropose proposes that the multisig actor at msigAddr call method of the actor at
+	// to, returning the ID of the proposal.
+	MultisigPropose(ctx context.Context, from address.Address, msigAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits, to address.Address, value types.AttoFIL, method string, params []byte) (uint64, error)
+	// MultisigLs returns the signers and pending proposals of the multisig actor at msigAddr.
+	MultisigLs(ctx context.Context, msigAddr address.Address) (*porcelain.MultisigInfo, error)
+}
+
+// Permissions maps each method of Node to the permission that an API token must grant to call it.
+var Permissions = map[string]auth.Permission{
+	"Version":            auth.Read,
+	"ID":                 auth.Read,
+	"ProtocolParameters": auth.Read,
+	"BlockTime":          auth.Read,
+	"ConfigGet":          auth.Admin,
+	"ConfigSet":          auth.Admin,
+
+	"ChainHead":             auth.Read,
+	"ChainGetTipSet":        auth.Read,
+	"ChainGetBlock":         auth.Read,
+	"ChainGetFullBlock":     auth.Read,
+	"ChainGetMessages":      auth.Read,
+	"ChainGetReceipts":      auth.Read,
+	"ChainLs":               auth.Read,
+	"ChainNotify":           auth.Read,
+	"ChainStatus":           auth.Read,
+	"ChainSampleRandomness": auth.Read,
+	"ChainSetHead":          auth.Admin,
+	"ChainCollectGarbage":   auth.Admin,
+
+	"ActorGet":          auth.Read,
+	"ActorGetSignature": auth.Read,
+	"ActorLs":           auth.Read,
+
+	"MessageSend":             auth.Sign,
+	"MessagePreview":          auth.Read,
+	"MessageQuery":            auth.Read,
+	"MessageEstimateGas":      auth.Read,
+	"MessageEstimateGasPrice": auth.Read,
+	"MessageReplace":          auth.Sign,
+	"MessageFind":             auth.Read,
+	"MessageWait":             auth.Read,
+	"MessageTrace":            auth.Read,
+
+	"MessagePoolPending": auth.Read,
+	"MessagePoolGet":     auth.Read,
+	"MessagePoolRemove":  auth.Write,
+	"MessagePoolWait":    auth.Read,
+	"MessagePoolWatch":   auth.Read,
+
+	"OutboxQueues":     auth.Read,
+	"OutboxQueueLs":    auth.Read,
+	"OutboxQueueClear": auth.Write,
+
+	"NetworkGetBandwidthStats":  auth.Read,
+	"NetworkGetPeerAddresses":   auth.Read,
+	"NetworkFindProvidersAsync": auth.Read,
+	"NetworkGetClosestPeers":    auth.Read,
+	"NetworkPing":               auth.Read,
+	"NetworkFindPeer":           auth.Read,
+	"NetworkConnect":            auth.Write,
+	"NetworkPeers":              auth.Read,
+	"PubSubSubscribe":           auth.Read,
+	"PubSubPublish":             auth.Write,
+
+	"WalletAddresses":           auth.Read,
+	"WalletDefaultAddress":      auth.Read,
+	"WalletBalance":             auth.Read,
+	"WalletNewAddress":          auth.Write,
+	"WalletImport":              auth.Admin,
+	"WalletExport":              auth.Admin,
+	"WalletGetPubKeyForAddress": auth.Read,
+	"WalletDerivationPath":      auth.Read,
+	"WalletInitHD":              auth.Admin,
+	"WalletEncrypt":             auth.Admin,
+	"WalletLock":                auth.Admin,
+	"WalletUnlock":              auth.Admin,
+	"SignBytes":                 auth.Sign,
+
+	"DAGGetNode":      auth.Read,
+	"DAGGetFileSize":  auth.Read,
+	"BitswapGetStats": auth.Read,
+
+	"MinerCreate":                   auth.Sign,
+	"MinerPreviewCreate":            auth.Read,
+	"MinerGetAsk":                   auth.Read,
+	"MinerGetRetrievalAsk":          auth.Read,
+	"MinerGetOwnerAddress":          auth.Read,
+	"MinerGetWorkerAddress":         auth.Read,
+	"MinerGetSectorSize":            auth.Read,
+	"MinerCalculateLateFee":         auth.Read,
+	"MinerGetLastCommittedSectorID": auth.Read,
+	"MinerGetPeerID":                auth.Read,
+	"MinerGetPower":                 auth.Read,
+	"MinerGetProvingWindow":         auth.Read,
+	"MinerGetCollateral":            auth.Read,
+	"MinerSetPrice":                 auth.Sign,
+	"MinerPreviewSetPrice":          auth.Read,
+	"MinerSetRetrievalPrice":        auth.Sign,
+	"MinerSetWorkerAddress":         auth.Sign,
+	"SealNow":                       auth.Admin,
+
+	"DealGet":           auth.Read,
+	"DealsLs":           auth.Read,
+	"DealRedeem":        auth.Sign,
+	"DealRedeemPreview": auth.Read,
+
+	"ClientListAsks":            auth.Read,
+	"ClientListRetrievalAsks":   auth.Read,
+	"ClientFindRetrievalMiners": auth.Read,
+	"PingMinerWithTimeout":      auth.Read,
+
+	"CreatePayments":        auth.Sign,
+	"PaymentChannelLs":      auth.Read,
+	"PaymentChannelVoucher": auth.Sign,
+
+	"MultisigCreate":  auth.Sign,
+	"MultisigPropose": auth.Sign,
+	"MultisigLs":      auth.Read,
+}
+
+// TipSet is a tipset of the chain.
+type TipSet struct {
+	Key    types.TipSetKey
+	Height uint64
+	Blocks []*types.Block
+}
+
+// HeadChangeType is the type of a HeadChange.
+type HeadChangeType string
+
+const (
+	// HeadApply is the type of changes that apply a tipset to the chain.
+	HeadApply = HeadChangeType("apply")
+	// HeadRevert is the type of changes that revert a tipset from the chain.
+	HeadRevert = HeadChangeType("revert")
+)
+
+// HeadChange is a tipset applied to or reverted from the chain.
+type HeadChange struct {
+	Type HeadChangeType
+	TipSet
+}
+
+// MessageResult is a message included in the chain.
+type MessageResult struct {
+	// Block is the CID of the block that includes the message.
+	Block   cid.Cid
+	Message *types.SignedMessage
+	Receipt *types.MessageReceipt
+}
+
+// PoolEventType is the type of a PoolEvent.
+type PoolEventType string
+
+const (
+	// PoolMessageAdded is the type of events for messages added to the pool.
+	PoolMessageAdded = PoolEventType("added")
+	// PoolMessageRemoved is the type of events for messages removed from the pool explicitly,
+	// or replaced or evicted by other messages.
+	PoolMessageRemoved = PoolEventType("removed")
+	// PoolMessageIncluded is the type of events for messages removed from the pool because
+	// they were included in a block.
+	PoolMessageIncluded = PoolEventType("included")
+	// PoolMessageExpired is the type of events for messages removed from the pool because they
+	// were not included in a block in time.
+	PoolMessageExpired = PoolEventType("expired")
+)
+
+// PoolEvent is a message added to or removed from the message pool.
+type PoolEvent struct {
+	Type    PoolEventType
+	Cid     cid.Cid
+	Message *types.SignedMessage
+}
+
+// ActorInfo is an actor in the state of the chain.
+type ActorInfo struct {
+	Address string
+	Actor   *actor.Actor
+}
+
+// PingResult is the result of pinging a peer.
+type PingResult struct {
+	RTT time.Duration
+	// Error is the error with which the ping failed, or empty if it succeeded.
+	Error string
+}
+
+// ConnectionResult is the result of connecting to a peer.
+type ConnectionResult struct {
+	PeerID peer.ID
+	// Error is the error with which the connection failed, or empty if it succeeded.
+	Error string
+}
+
+// PubSubMessage is a message published on a pubsub topic.
+type PubSubMessage struct {
+	From peer.ID
+	Data []byte
+}
+
+// HDWallet is the mnemonic and the first addresses of an HD wallet.
+type HDWallet struct {
+	Mnemonic  string
+	Addresses []address.Address
+}
+
+// Ask is a storage ask of a miner.
+type Ask struct {
+	Miner  address.Address
+	Price  types.AttoFIL
+	Expiry *types.BlockHeight
+	ID     uint64
+}
+
+// RetrievalAsk is a retrieval ask of a miner.
+type RetrievalAsk struct {
+	Miner           address.Address
+	Price           types.AttoFIL
+	PaymentInterval *types.BytesAmount
+	UnsealPrice     types.AttoFIL
+}
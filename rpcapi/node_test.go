@@ -0,0 +1,23 @@
+package rpcapi_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/filecoin-project/go-filecoin/rpcapi"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+)
+
+func TestPermissionsCoverEachMethod(t *testing.T) {
+	tf.UnitTest(t)
+
+	node := reflect.TypeOf((*rpcapi.Node)(nil)).Elem()
+	for i := 0; i < node.NumMethod(); i++ {
+		name := node.Method(i).Name
+		_, ok := rpcapi.Permissions[name]
+		assert.True(t, ok, "no permission for %s", name)
+	}
+	assert.Len(t, rpcapi.Permissions, node.NumMethod())
+}
@@ -13,6 +13,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/consensus"
 	"github.com/filecoin-project/go-filecoin/repo"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
 )
 
 // FakeChildParams is a wrapper for all the params needed to create fake child blocks.
@@ -47,7 +48,7 @@ func MkFakeChild(params FakeChildParams) (*types.Block, error) {
 	// Create consensus for reading the valid weight
 	bs := bstore.NewBlockstore(repo.NewInMemoryRepo().Datastore())
 	cst := hamt.NewCborStore()
-	actorState := consensus.NewActorStateStore(nil, cst, bs)
+	actorState := consensus.NewActorStateStore(nil, cst, bs, version.NewSingleVersionTable(version.Protocol0))
 	con := consensus.NewExpected(cst,
 		bs,
 		NewFakeProcessor(),
@@ -12,6 +12,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/consensus"
 	"github.com/filecoin-project/go-filecoin/state"
 	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
 	"github.com/filecoin-project/go-filecoin/vm"
 )
 
@@ -99,6 +100,11 @@ func (fbv *FakeBlockValidator) ValidateSemantic(ctx context.Context, child *type
 	return nil
 }
 
+// ValidateMessagesSemantic does nothing.
+func (fbv *FakeBlockValidator) ValidateMessagesSemantic(ctx context.Context, blk *types.Block, messages []*types.SignedMessage) error {
+	return nil
+}
+
 // ValidateSyntax does nothing.
 func (fbv *FakeBlockValidator) ValidateSyntax(ctx context.Context, blk *types.Block) error {
 	return nil
@@ -134,6 +140,11 @@ func (mbv *StubBlockValidator) ValidateSemantic(ctx context.Context, child *type
 	return mbv.semanticStubs[child.Cid()]
 }
 
+// ValidateMessagesSemantic does nothing.
+func (mbv *StubBlockValidator) ValidateMessagesSemantic(ctx context.Context, blk *types.Block, messages []*types.SignedMessage) error {
+	return nil
+}
+
 // ValidateSyntax return nil or error for stubbed block `blk`.
 func (mbv *StubBlockValidator) ValidateSyntax(ctx context.Context, blk *types.Block) error {
 	return mbv.syntaxStubs[blk.Cid()]
@@ -153,7 +164,7 @@ func (mbv *StubBlockValidator) StubSemanticValidationForBlock(child *types.Block
 
 // NewFakeProcessor creates a processor with a test validator and test rewarder
 func NewFakeProcessor() *consensus.DefaultProcessor {
-	return consensus.NewConfiguredProcessor(&FakeSignedMessageValidator{}, &FakeBlockRewarder{})
+	return consensus.NewConfiguredProcessor(&FakeSignedMessageValidator{}, &FakeBlockRewarder{}, version.NewSingleVersionTable(version.Protocol0))
 }
 
 type testSigner struct{}
@@ -176,7 +187,7 @@ func ApplyTestMessageWithGas(st state.Tree, store vm.StorageMap, msg *types.Mess
 	if err != nil {
 		panic(err)
 	}
-	applier := consensus.NewConfiguredProcessor(consensus.NewDefaultMessageValidator(), consensus.NewDefaultBlockRewarder())
+	applier := consensus.NewConfiguredProcessor(consensus.NewDefaultMessageValidator(), consensus.NewDefaultBlockRewarder(), version.NewSingleVersionTable(version.Protocol0))
 	return newMessageApplier(smsg, applier, st, store, bh, minerOwner, nil)
 }
 
@@ -207,7 +218,7 @@ func CreateAndApplyTestMessage(t *testing.T, st state.Tree, vms vm.StorageMap, t
 }
 
 func applyTestMessageWithAncestors(st state.Tree, store vm.StorageMap, msg *types.Message, bh *types.BlockHeight, ancestors []types.TipSet) (*consensus.ApplicationResult, error) {
-	smsg, err := types.NewSignedMessage(*msg, testSigner{}, types.NewGasPrice(1), types.NewGasUnits(300))
+	smsg, err := types.NewSignedMessage(*msg, testSigner{}, types.NewGasPrice(1), types.NewGasUnits(100000))
 	if err != nil {
 		panic(err)
 	}
@@ -217,5 +228,5 @@ func applyTestMessageWithAncestors(st state.Tree, store vm.StorageMap, msg *type
 }
 
 func newTestApplier() *consensus.DefaultProcessor {
-	return consensus.NewConfiguredProcessor(&FakeSignedMessageValidator{}, &FakeBlockRewarder{})
+	return consensus.NewConfiguredProcessor(&FakeSignedMessageValidator{}, &FakeBlockRewarder{}, version.NewSingleVersionTable(version.Protocol0))
 }
@@ -112,6 +112,11 @@ func (v *MockMessagePoolValidator) Validate(ctx context.Context, msg *types.Sign
 	return errors.New("mock validation error")
 }
 
+// ValidateReverted returns true if the mock validator is set to validate the message
+func (v *MockMessagePoolValidator) ValidateReverted(ctx context.Context, msg *types.SignedMessage) error {
+	return v.Validate(ctx, msg)
+}
+
 // VMStorage creates a new storage object backed by an in memory datastore
 func VMStorage() vm.StorageMap {
 	return vm.NewStorageMap(blockstore.NewBlockstore(datastore.NewMapDatastore()))
@@ -229,6 +234,7 @@ type FakeVMContext struct {
 	StorageValue            exec.Storage
 	BalanceValue            types.AttoFIL
 	BlockHeightValue        *types.BlockHeight
+	ProtocolVersionValue    uint64
 	VerifierValue           verification.Verifier
 	RandomnessValue         []byte
 	IsFromAccountActorValue bool
@@ -304,6 +310,11 @@ func (tc *FakeVMContext) BlockHeight() *types.BlockHeight {
 	return tc.BlockHeightValue
 }
 
+// ProtocolVersion is the protocol version in effect at the current chain height
+func (tc *FakeVMContext) ProtocolVersion() uint64 {
+	return tc.ProtocolVersionValue
+}
+
 // MyBalance is the balance of the current actor
 func (tc *FakeVMContext) MyBalance() types.AttoFIL {
 	return tc.BalanceValue
@@ -97,6 +97,16 @@ func (td *TestDaemon) CmdAddr() (ma.Multiaddr, error) {
 	return ma.NewMultiaddr(strings.TrimSpace(string(str)))
 }
 
+// APIToken returns the admin token of the test daemon's API (if it is running).
+func (td *TestDaemon) APIToken() (string, error) {
+	str, err := ioutil.ReadFile(filepath.Join(td.RepoDir(), "token"))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(string(str)), nil
+}
+
 // Config is a helper to read out the config of the daemon.
 func (td *TestDaemon) Config() *config.Config {
 	cfg, err := config.ReadFile(filepath.Join(td.RepoDir(), "config.json"))
@@ -409,7 +419,7 @@ func (td *TestDaemon) CreateStorageMinerAddr(peer *TestDaemon, fromAddr string)
 	var minerAddr address.Address
 	wg.Add(1)
 	go func() {
-		miner := td.RunSuccess("miner", "create", "--from", fromAddr, "--gas-price", "1", "--gas-limit", "100", "20")
+		miner := td.RunSuccess("miner", "create", "--from", fromAddr, "--gas-price", "1", "--gas-limit", "1000", "20")
 		addr, err := address.NewFromString(strings.Trim(miner.ReadStdout(), "\n"))
 		require.NoError(td.test, err)
 		require.NotEqual(td.test, addr, address.Undef)
@@ -428,7 +438,7 @@ func (td *TestDaemon) MinerSetPrice(minerAddr string, fromAddr string, price str
 		"--from", fromAddr,
 		"--miner", minerAddr,
 		"--gas-price", "1",
-		"--gas-limit", "300",
+		"--gas-limit", "1000",
 		"--enc", "json",
 		price, expiry).ReadStdout()
 
@@ -450,7 +460,7 @@ func (td *TestDaemon) UpdatePeerID() {
 	peerIDJSON := td.RunSuccess("id").ReadStdout()
 	err := json.Unmarshal([]byte(peerIDJSON), &idOutput)
 	require.NoError(td.test, err)
-	updateCidStr := td.RunSuccess("miner", "update-peerid", "--gas-price=1", "--gas-limit=300", td.GetMinerAddress().String(), idOutput["ID"].(string)).ReadStdoutTrimNewlines()
+	updateCidStr := td.RunSuccess("miner", "update-peerid", "--gas-price=1", "--gas-limit=1000", td.GetMinerAddress().String(), idOutput["ID"].(string)).ReadStdoutTrimNewlines()
 	updateCid, err := cid.Parse(updateCidStr)
 	require.NoError(td.test, err)
 	assert.NotNil(td.test, updateCid)
@@ -586,8 +596,18 @@ func tryAPICheck(td *TestDaemon) error {
 		return err
 	}
 
+	token, err := td.APIToken()
+	if err != nil {
+		return err
+	}
+
 	url := fmt.Sprintf("http://%s/api/id", host)
-	resp, err := http.Get(url)
+	req, err := http.NewRequest("GET", url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -84,6 +84,12 @@ func (f *Filecoin) ClientListAsks(ctx context.Context) (*json.Decoder, error) {
 	return f.RunCmdLDJSONWithStdin(ctx, nil, "go-filecoin", "client", "list-asks")
 }
 
+// ClientListRetrievalAsks runs the client list-retrieval-asks command against the filecoin process.
+// A json decoder is returned that retrieval asks may be decoded from.
+func (f *Filecoin) ClientListRetrievalAsks(ctx context.Context) (*json.Decoder, error) {
+	return f.RunCmdLDJSONWithStdin(ctx, nil, "go-filecoin", "client", "list-retrieval-asks")
+}
+
 // ClientPayments runs the client payments command against the filecoin process.
 func (f *Filecoin) ClientPayments(ctx context.Context, deal cid.Cid) ([]types.PaymentVoucher, error) {
 	var out []types.PaymentVoucher
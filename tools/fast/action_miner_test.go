@@ -44,7 +44,7 @@ func requireMiner(ctx context.Context, t *testing.T, minerDaemon, clientDaemon *
 	series.CtxMiningNext(ctx, 1)
 
 	// Create miner
-	_, err = minerDaemon.MinerCreate(ctx, collateral, fast.AOSectorSize(sinfo.Size), fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(300))
+	_, err = minerDaemon.MinerCreate(ctx, collateral, fast.AOSectorSize(sinfo.Size), fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(1000))
 	require.NoError(t, err)
 }
 
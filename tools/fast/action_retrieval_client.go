@@ -12,7 +12,7 @@ import (
 
 // RetrievalClientRetrievePiece runs the retrieval-client retrieve-piece commands against the filecoin process.
 func (f *Filecoin) RetrievalClientRetrievePiece(ctx context.Context, pieceCID cid.Cid, minerAddr address.Address) (io.ReadCloser, error) {
-	out, err := f.RunCmdWithStdin(ctx, nil, "go-filecoin", "retrieval-client", "retrieve-piece", minerAddr.String(), pieceCID.String())
+	out, err := f.RunCmdWithStdin(ctx, nil, "go-filecoin", "retrieval-client", "retrieve-piece", pieceCID.String(), "--miner", minerAddr.String())
 	if err != nil {
 		return nil, err
 	}
@@ -38,6 +38,7 @@ func (mt *timeImpl) Until(t time.Time) time.Duration {
 func main() {
 	filapi := flag.String("fil-api", "localhost:3453", "set the api address of the filecoin node to use")
 	filwal := flag.String("fil-wallet", "", "(required) set the wallet address for the controlled filecoin node to send funds from")
+	filtoken := flag.String("fil-token", "", "(required) set the api token, with sign permission, of the filecoin node to use")
 	expiry := flag.Duration("limiter-expiry", defaultLimiterExpiry, "minimum time duration between faucet request to the same wallet addr")
 	faucetval := flag.Int64("faucet-val", 500, "set the amount of fil to pay to each requester")
 	flag.Parse()
@@ -48,6 +49,12 @@ func main() {
 		return
 	}
 
+	if *filtoken == "" {
+		fmt.Println("ERROR: must provide api token of the filecoin node")
+		flag.Usage()
+		return
+	}
+
 	addrLimiter := limiter.NewLimiter(&timeImpl{})
 
 	// Clean the limiter every limiterCleanTick
@@ -84,7 +91,15 @@ func main() {
 		reqStr := fmt.Sprintf("http://%s/api/message/send?arg=%s&value=%d&from=%s&gas-price=1&gas-limit=0", *filapi, addr.String(), *faucetval, *filwal)
 		log.Infof("Request URL: %s", reqStr)
 
-		resp, err := http.Post(reqStr, "application/json", nil)
+		req, err := http.NewRequest("POST", reqStr, nil)
+		if err != nil {
+			log.Errorf("failed to create request: %s", err)
+			http.Error(w, err.Error(), 500)
+			return
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+*filtoken)
+		resp, err := http.DefaultClient.Do(req)
 		if err != nil {
 			log.Errorf("failed to Post request. Status: %s Error: %s", resp.Status, err)
 			http.Error(w, err.Error(), 500)
@@ -252,7 +252,7 @@ func (l *Localfilecoin) Start(ctx context.Context, wait bool, args ...string) (t
 		return nil, err
 	}
 	if wait {
-		if err := filecoin.WaitOnAPI(l); err != nil {
+		if err := filecoin.WaitOnAPI(l, l.repoPath); err != nil {
 			return nil, err
 		}
 	}
@@ -17,10 +17,10 @@ import (
 
 var log = logging.Logger("util")
 
-// WaitOnAPI waits for a nodes api to come up.
-func WaitOnAPI(l testbedi.Libp2p) error {
+// WaitOnAPI waits for a nodes api to come up, authorizing with the token in the nodes repo.
+func WaitOnAPI(l testbedi.Libp2p, repoDir string) error {
 	for i := 0; i < 50; i++ {
-		err := tryAPICheck(l)
+		err := tryAPICheck(l, repoDir)
 		if err == nil {
 			return nil
 		}
@@ -36,7 +36,7 @@ func WaitOnAPI(l testbedi.Libp2p) error {
 	return fmt.Errorf("node %s failed to come online in given time period", pcid)
 }
 
-func tryAPICheck(l testbedi.Libp2p) error {
+func tryAPICheck(l testbedi.Libp2p, repoDir string) error {
 	addrStr, err := l.APIAddr()
 	if err != nil {
 		return err
@@ -57,7 +57,17 @@ func tryAPICheck(l testbedi.Libp2p) error {
 		return err
 	}
 
-	resp, err := http.Get(fmt.Sprintf("http://%s:%s/api/id", ip, pt))
+	token, err := GetAPITokenFromRepo(repoDir)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequest("GET", fmt.Sprintf("http://%s:%s/api/id", ip, pt), nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -90,6 +100,15 @@ func tryAPICheck(l testbedi.Libp2p) error {
 	return nil
 }
 
+// GetAPITokenFromRepo reads the api admin token from the `token` file in a nodes repo.
+func GetAPITokenFromRepo(dir string) (string, error) {
+	token, err := ioutil.ReadFile(filepath.Join(dir, "token"))
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(token)), nil
+}
+
 // GetAPIAddrFromRepo reads the api address from the `api` file in a nodes repo.
 func GetAPIAddrFromRepo(dir string) (multiaddr.Multiaddr, error) {
 	addrStr, err := ioutil.ReadFile(filepath.Join(dir, "api"))
@@ -0,0 +1,103 @@
+// Command remote-signer is a stand-in for an external signer process, for testing nodes
+// configured with a remote signer. It holds its keys in memory and serves the remote
+// signer API over a unix socket or TCP.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/ipfs/go-datastore"
+	logging "github.com/ipfs/go-log"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/wallet"
+)
+
+var log = logging.Logger("remote-signer")
+
+func init() {
+	// Info level
+	logging.SetAllLoggers(4)
+}
+
+// keyFile matches the output of `go-filecoin wallet export`.
+type keyFile struct {
+	KeyInfo []*types.KeyInfo
+}
+
+func main() {
+	listen := flag.String("listen", "unix:/tmp/filecoin-remote-signer.sock", "set where to serve the signer API, unix:<path> or host:port")
+	keys := flag.String("key-file", "", "set a file of keys to sign with, as written by `go-filecoin wallet export`")
+	gen := flag.Int("gen", 1, "set the number of keys to generate if no key file is given")
+	flag.Parse()
+
+	backend, err := wallet.NewDSBackend(datastore.NewMapDatastore())
+	if err != nil {
+		log.Fatalf("failed to create backend: %s", err)
+	}
+
+	if *keys != "" {
+		if err := importKeys(backend, *keys); err != nil {
+			log.Fatalf("failed to import keys: %s", err)
+		}
+	} else {
+		for i := 0; i < *gen; i++ {
+			if _, err := backend.NewAddress(address.SECP256K1); err != nil {
+				log.Fatalf("failed to generate key: %s", err)
+			}
+		}
+	}
+
+	for _, addr := range backend.Addresses() {
+		fmt.Println(addr)
+	}
+
+	ln, err := listener(*listen)
+	if err != nil {
+		log.Fatalf("failed to listen on %s: %s", *listen, err)
+	}
+	log.Infof("serving remote signer API on %s", *listen)
+	log.Fatal(http.Serve(ln, wallet.NewRemoteSignerHandler(backend)))
+}
+
+func importKeys(backend *wallet.DSBackend, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close() // nolint: errcheck
+
+	var kf keyFile
+	if err := json.NewDecoder(f).Decode(&kf); err != nil {
+		return err
+	}
+	if len(kf.KeyInfo) == 0 {
+		return fmt.Errorf("no keys in %s", path)
+	}
+
+	for _, ki := range kf.KeyInfo {
+		if err := backend.ImportKey(ki); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func listener(listen string) (net.Listener, error) {
+	if strings.HasPrefix(listen, "unix:") {
+		path := strings.TrimPrefix(listen, "unix:")
+		// Remove a socket left over from an earlier run.
+		if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+			return nil, err
+		}
+		return net.Listen("unix", path)
+	}
+	return net.Listen("tcp", listen)
+}
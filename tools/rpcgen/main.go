@@ -0,0 +1,283 @@
+// rpcgen generates the Go client of a JSON-RPC API interface: a struct with a func field for
+// each method of the interface, which jsonrpc.NewClient sets to call the method on a server, and
+// methods implementing the interface with those funcs.
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	in := flag.String("in", "", "(required) the Go file declaring the interface")
+	iface := flag.String("iface", "Node", "the name of the interface")
+	out := flag.String("out", "", "(required) the file to write the client to")
+	flag.Parse()
+
+	if *in == "" || *out == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err := generate(*in, *iface, *out); err != nil {
+		fmt.Fprintf(os.Stderr, "rpcgen: %s\n", err)
+		os.Exit(1)
+	}
+}
+
+type method struct {
+	name    string
+	doc     string
+	fnType  string
+	params  string
+	args    string
+	results string
+}
+
+func generate(in, iface, out string) error {
+	fset := token.NewFileSet()
+	file, err := parser.ParseFile(fset, in, nil, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+	srcPkg := file.Name.Name
+	module, srcPath, err := importPath(filepath.Dir(in))
+	if err != nil {
+		return err
+	}
+
+	spec := findInterface(file, iface)
+	if spec == nil {
+		return fmt.Errorf("interface %s not found in %s", iface, in)
+	}
+
+	used := map[string]bool{"context": true, srcPkg: true}
+	var methods []method
+	for _, field := range spec.Methods.List {
+		fn, ok := field.Type.(*ast.FuncType)
+		if !ok || len(field.Names) != 1 {
+			return fmt.Errorf("embedded interfaces are not supported")
+		}
+		qualifyFields(fn.Params, srcPkg, used)
+		qualifyFields(fn.Results, srcPkg, used)
+
+		m := method{name: field.Names[0].Name, doc: field.Doc.Text()}
+		if m.fnType, err = printNode(fset, fn); err != nil {
+			return err
+		}
+		var params, args []string
+		for i, p := range fn.Params.List {
+			if _, ok := p.Type.(*ast.Ellipsis); ok {
+				return fmt.Errorf("method %s has variadic parameters, which are not supported", m.name)
+			}
+			typ, err := printNode(fset, p.Type)
+			if err != nil {
+				return err
+			}
+			names := p.Names
+			if len(names) == 0 {
+				names = []*ast.Ident{ast.NewIdent("p" + strconv.Itoa(i))}
+			}
+			for _, n := range names {
+				params = append(params, n.Name+" "+typ)
+				args = append(args, n.Name)
+			}
+		}
+		m.params = strings.Join(params, ", ")
+		m.args = strings.Join(args, ", ")
+		var results []string
+		for _, r := range fn.Results.List {
+			typ, err := printNode(fset, r.Type)
+			if err != nil {
+				return err
+			}
+			results = append(results, typ)
+		}
+		m.results = strings.Join(results, ", ")
+		if len(results) > 1 {
+			m.results = "(" + m.results + ")"
+		}
+		methods = append(methods, m)
+	}
+
+	// Imports are grouped as this repo groups them: the standard library, other modules, then
+	// packages of the source's module.
+	external, internal := []string{}, []string{strconv.Quote(srcPath)}
+	for _, imp := range file.Imports {
+		p, _ := strconv.Unquote(imp.Path.Value)
+		name := importName(imp)
+		if !used[name] || name == srcPkg || name == "context" {
+			continue
+		}
+		spec := imp.Path.Value
+		if imp.Name != nil {
+			spec = imp.Name.Name + " " + spec
+		}
+		if strings.HasPrefix(p, module+"/") {
+			internal = append(internal, spec)
+		} else {
+			external = append(external, spec)
+		}
+	}
+	sort.Strings(external)
+	sort.Strings(internal)
+	imports := [][]string{external, internal}
+
+	src := render(filepath.Base(in), path.Base(filepath.ToSlash(filepath.Dir(out))), srcPkg, iface, imports, methods)
+	formatted, err := format.Source(src)
+	if err != nil {
+		return fmt.Errorf("failed to format generated code: %s\n%s", err, src)
+	}
+	return ioutil.WriteFile(out, formatted, 0644)
+}
+
+func render(in, pkg, srcPkg, iface string, imports [][]string, methods []method) []byte {
+	var b bytes.Buffer
+	fmt.Fprintf(&b, "// Code generated by rpcgen from %s. DO NOT EDIT.\n\n", in)
+	fmt.Fprintf(&b, "package %s\n\n", pkg)
+	fmt.Fprintf(&b, "import (\n\t\"context\"\n")
+	for _, group := range imports {
+		if len(group) > 0 {
+			b.WriteString("\n")
+		}
+		for _, imp := range group {
+			fmt.Fprintf(&b, "\t%s\n", imp)
+		}
+	}
+	fmt.Fprintf(&b, ")\n\n")
+
+	structName := iface + "Struct"
+	fmt.Fprintf(&b, "// %s implements %s.%s with the funcs of Internal, which jsonrpc.NewClient\n", structName, srcPkg, iface)
+	fmt.Fprintf(&b, "// sets to call the methods of a server.\n")
+	fmt.Fprintf(&b, "type %s struct {\n\tInternal struct {\n", structName)
+	for _, m := range methods {
+		fmt.Fprintf(&b, "\t\t%s %s\n", m.name, m.fnType)
+	}
+	fmt.Fprintf(&b, "\t}\n}\n\n")
+	fmt.Fprintf(&b, "var _ %s.%s = (*%s)(nil)\n", srcPkg, iface, structName)
+
+	for _, m := range methods {
+		b.WriteString("\n")
+		scanner := bufio.NewScanner(strings.NewReader(m.doc))
+		for scanner.Scan() {
+			fmt.Fprintf(&b, "// %s\n", scanner.Text())
+		}
+		fmt.Fprintf(&b, "func (s *%s) %s(%s) %s {\n", structName, m.name, m.params, m.results)
+		fmt.Fprintf(&b, "\treturn s.Internal.%s(%s)\n}\n", m.name, m.args)
+	}
+	return b.Bytes()
+}
+
+func findInterface(file *ast.File, name string) *ast.InterfaceType {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.TYPE {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			ts := spec.(*ast.TypeSpec)
+			if it, ok := ts.Type.(*ast.InterfaceType); ok && ts.Name.Name == name {
+				return it
+			}
+		}
+	}
+	return nil
+}
+
+// qualifyFields qualifies the types declared in the source package that fields refer to with
+// the package's name, and records the names of the packages that they refer to in used.
+func qualifyFields(fields *ast.FieldList, srcPkg string, used map[string]bool) {
+	if fields == nil {
+		return
+	}
+	for _, f := range fields.List {
+		f.Type = qualify(f.Type, srcPkg, used)
+	}
+}
+
+func qualify(expr ast.Expr, srcPkg string, used map[string]bool) ast.Expr {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		if e.IsExported() {
+			return &ast.SelectorExpr{X: ast.NewIdent(srcPkg), Sel: e}
+		}
+	case *ast.SelectorExpr:
+		if x, ok := e.X.(*ast.Ident); ok {
+			used[x.Name] = true
+		}
+	case *ast.StarExpr:
+		e.X = qualify(e.X, srcPkg, used)
+	case *ast.ArrayType:
+		e.Elt = qualify(e.Elt, srcPkg, used)
+	case *ast.MapType:
+		e.Key = qualify(e.Key, srcPkg, used)
+		e.Value = qualify(e.Value, srcPkg, used)
+	case *ast.ChanType:
+		e.Value = qualify(e.Value, srcPkg, used)
+	case *ast.Ellipsis:
+		e.Elt = qualify(e.Elt, srcPkg, used)
+	case *ast.FuncType:
+		qualifyFields(e.Params, srcPkg, used)
+		qualifyFields(e.Results, srcPkg, used)
+	}
+	return expr
+}
+
+// importName returns the name by which a file refers to an import: its explicit name, or the
+// last element of its path without any "go-" prefix, by the convention that packages follow.
+func importName(imp *ast.ImportSpec) string {
+	if imp.Name != nil {
+		return imp.Name.Name
+	}
+	p, _ := strconv.Unquote(imp.Path.Value)
+	return strings.TrimPrefix(path.Base(p), "go-")
+}
+
+// importPath returns the path of the module that contains dir and the import path of the package
+// in dir.
+func importPath(dir string) (string, string, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return "", "", err
+	}
+	for root := dir; ; root = filepath.Dir(root) {
+		data, err := ioutil.ReadFile(filepath.Join(root, "go.mod"))
+		if err == nil {
+			for _, line := range strings.Split(string(data), "\n") {
+				if strings.HasPrefix(line, "module ") {
+					rel, err := filepath.Rel(root, dir)
+					if err != nil {
+						return "", "", err
+					}
+					module := strings.TrimSpace(strings.TrimPrefix(line, "module "))
+					return module, path.Join(module, filepath.ToSlash(rel)), nil
+				}
+			}
+			return "", "", fmt.Errorf("no module path in %s", filepath.Join(root, "go.mod"))
+		}
+		if filepath.Dir(root) == root {
+			return "", "", fmt.Errorf("no go.mod found above %s", dir)
+		}
+	}
+}
+
+func printNode(fset *token.FileSet, node interface{}) (string, error) {
+	var b bytes.Buffer
+	if err := printer.Fprint(&b, fset, node); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
@@ -52,6 +52,11 @@ type Block struct {
 	// The timestamp, in seconds since the Unix epoch, at which this block was created.
 	Timestamp Uint64 `json:"timestamp"`
 
+	// BLSAggregateSig is the aggregate of the signatures of the BLS-signed messages
+	// in the block, which the block carries without their own signatures. It is left
+	// out of the encoding of blocks that have no BLS-signed messages.
+	BLSAggregateSig Signature `json:"blsAggregateSig,omitempty" refmt:",omitempty"`
+
 	// The signature of the miner's worker key over the block
 	BlockSig Signature `json:"blocksig"`
 
@@ -157,6 +162,7 @@ func (b *Block) SignatureData() []byte {
 		MessageReceipts: b.MessageReceipts,
 		ElectionProof:   b.ElectionProof,
 		Timestamp:       b.Timestamp,
+		BLSAggregateSig: b.BLSAggregateSig,
 		// BlockSig omitted
 	}
 
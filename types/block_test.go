@@ -71,6 +71,7 @@ func TestTriangleEncoding(t *testing.T) {
 			ElectionProof:   NewTestPoSt(),
 			StateRoot:       CidFromString(t, "somecid"),
 			Timestamp:       Uint64(1),
+			BLSAggregateSig: []byte{0x2},
 			BlockSig:        []byte{0x3},
 		}
 		s := reflect.TypeOf(*b)
@@ -79,7 +80,7 @@ func TestTriangleEncoding(t *testing.T) {
 		// Also please add non zero fields to "b" and "diff" in TestSignatureData
 		// and add a new check that different values of the new field result in
 		// different output data.
-		require.Equal(t, 13, s.NumField()) // Note: this also counts private fields
+		require.Equal(t, 14, s.NumField()) // Note: this also counts private fields
 		testRoundTrip(t, b)
 	})
 }
@@ -249,6 +250,7 @@ func TestSignatureData(t *testing.T) {
 		ElectionProof:   []byte{0x1},
 		StateRoot:       CidFromString(t, "somecid"),
 		Timestamp:       Uint64(1),
+		BLSAggregateSig: []byte{0x2},
 		BlockSig:        []byte{0x3},
 	}
 
@@ -263,6 +265,7 @@ func TestSignatureData(t *testing.T) {
 		ElectionProof:   []byte{0x2},
 		StateRoot:       CidFromString(t, "someothercid"),
 		Timestamp:       Uint64(4),
+		BLSAggregateSig: []byte{0x5},
 		BlockSig:        []byte{0x4},
 	}
 
@@ -391,4 +394,15 @@ func TestSignatureData(t *testing.T) {
 		assert.False(t, bytes.Equal(before, after))
 	}()
 
+	func() {
+		before := b.SignatureData()
+
+		cpy := b.BLSAggregateSig
+		defer func() { b.BLSAggregateSig = cpy }()
+
+		b.BLSAggregateSig = diff.BLSAggregateSig
+		after := b.SignatureData()
+		assert.False(t, bytes.Equal(before, after))
+	}()
+
 }
@@ -3,4 +3,6 @@ package types
 const (
 	// SECP256K1 is a curve used to compute private keys
 	SECP256K1 = "secp256k1"
+	// BLS is a curve used to compute private keys for BLS signatures
+	BLS = "bls"
 )
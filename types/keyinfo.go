@@ -7,6 +7,7 @@ import (
 
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/crypto"
+	wutil "github.com/filecoin-project/go-filecoin/wallet/util"
 )
 
 func init() {
@@ -56,13 +57,24 @@ func (ki *KeyInfo) Equals(other *KeyInfo) bool {
 	return bytes.Equal(ki.PrivateKey, other.PrivateKey)
 }
 
-// Address returns the address for this keyinfo
+// Address returns the address for this keyinfo. BLS keys have BLS addresses, any
+// other key is treated as a secp256k1 key.
 func (ki *KeyInfo) Address() (address.Address, error) {
-	// TODO: Use the address type we are running on from the config.
+	if ki.Curve == BLS {
+		return address.NewBLSAddress(ki.PublicKey())
+	}
 	return address.NewSecp256k1Address(ki.PublicKey())
 }
 
-// PublicKey returns the public key part as uncompressed bytes.
+// PublicKey returns the public key part, as uncompressed bytes for secp256k1 keys.
+// It returns nil for a malformed BLS key.
 func (ki *KeyInfo) PublicKey() []byte {
+	if ki.Curve == BLS {
+		pk, err := wutil.BLSPublicKey(ki.PrivateKey)
+		if err != nil {
+			return nil
+		}
+		return pk
+	}
 	return crypto.PublicKey(ki.PrivateKey)
 }
@@ -13,8 +13,13 @@ var log = logging.Logger("types")
 type Signature []byte
 
 // IsValidSignature cryptographically verifies that 'sig' is the signed hash of 'data' with
-// the public key belonging to `addr`.
+// the public key belonging to `addr`. BLS addresses carry their public key, so BLS
+// signatures are verified against it directly.
 func IsValidSignature(data []byte, addr address.Address, sig Signature) bool {
+	if addr.Protocol() == address.BLS {
+		return wutil.VerifyBLS(addr.Payload(), data, sig)
+	}
+
 	maybePk, err := wutil.Ecrecover(data, sig)
 	if err != nil {
 		// Any error returned from Ecrecover means this signature is not valid.
@@ -65,9 +65,11 @@ func (smsg *SignedMessage) Marshal() ([]byte, error) {
 }
 
 // Cid returns the canonical CID for the SignedMessage.
+// Blocks carry BLS-signed messages without their signatures, so the CID of a message
+// from a BLS address leaves out the signature and is the same in the pool and in blocks.
 // TODO: can we avoid returning an error?
 func (smsg *SignedMessage) Cid() (cid.Cid, error) {
-	obj, err := cbor.WrapObject(smsg, DefaultHashFunction, -1)
+	obj, err := cbor.WrapObject(smsg.cidObject(), DefaultHashFunction, -1)
 	if err != nil {
 		return cid.Undef, errors.Wrap(err, "failed to marshal to cbor")
 	}
@@ -75,10 +77,19 @@ func (smsg *SignedMessage) Cid() (cid.Cid, error) {
 	return obj.Cid(), nil
 }
 
-// ToNode converts the SignedMessage to an IPLD node.
+// cidObject returns the object whose encoding determines the CID of the message.
+func (smsg *SignedMessage) cidObject() *SignedMessage {
+	if smsg.From.Protocol() != address.BLS || len(smsg.Signature) == 0 {
+		return smsg
+	}
+	return &SignedMessage{MeteredMessage: smsg.MeteredMessage}
+}
+
+// ToNode converts the SignedMessage to an IPLD node whose CID is the message's Cid(), so
+// the node of a BLS message leaves out its signature.
 func (smsg *SignedMessage) ToNode() (ipld.Node, error) {
 	// Use 32 byte / 256 bit digest.
-	obj, err := cbor.WrapObject(smsg, DefaultHashFunction, -1)
+	obj, err := cbor.WrapObject(smsg.cidObject(), DefaultHashFunction, -1)
 	if err != nil {
 		return nil, err
 	}
@@ -106,8 +117,8 @@ func (smsg *SignedMessage) RecoverAddress(r Recoverer) (address.Address, error)
 
 }
 
-// VerifySignature returns true iff the signature over the message as calculated
-// from EC recover matches the message sender address.
+// VerifySignature returns true iff the signature over the message was made with the
+// key of the message sender address, which may be a secp256k1 or a BLS address.
 func (smsg *SignedMessage) VerifySignature() bool {
 	bmsg, err := smsg.MeteredMessage.Marshal()
 	if err != nil {
@@ -76,6 +76,31 @@ func TestSignedMessageCid(t *testing.T) {
 
 }
 
+func TestSignedMessageCidBLS(t *testing.T) {
+	tf.UnitTest(t)
+
+	blsSigner := NewMockSigner(MustGenerateBLSKeyInfo(1))
+	smsg := makeMessage(t, blsSigner, 41)
+
+	c, err := smsg.Cid()
+	require.NoError(t, err)
+
+	unsigned := &SignedMessage{MeteredMessage: smsg.MeteredMessage}
+	cUnsigned, err := unsigned.Cid()
+	require.NoError(t, err)
+
+	// a BLS message keeps its CID when a block carries it without signature
+	assert.Equal(t, c, cUnsigned)
+
+	// secp256k1 messages do not
+	secpMsg := makeMessage(t, mockSigner, 41)
+	cSecp, err := secpMsg.Cid()
+	require.NoError(t, err)
+	cSecpUnsigned, err := (&SignedMessage{MeteredMessage: secpMsg.MeteredMessage}).Cid()
+	require.NoError(t, err)
+	assert.NotEqual(t, cSecp, cSecpUnsigned)
+}
+
 func TestSignedMessageCidToNode(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -89,6 +114,13 @@ func TestSignedMessageCidToNode(t *testing.T) {
 
 	assert.Equal(t, c, n.Cid())
 
+	// the node of a BLS message leaves out the signature, like its CID
+	blsMsg := makeMessage(t, NewMockSigner(MustGenerateBLSKeyInfo(1)), 41)
+	c, err = blsMsg.Cid()
+	require.NoError(t, err)
+	n, err = blsMsg.ToNode()
+	require.NoError(t, err)
+	assert.Equal(t, c, n.Cid())
 }
 
 func makeMessage(t *testing.T, signer MockSigner, nonce uint64) *SignedMessage {
@@ -48,7 +48,7 @@ func NewMockSigner(kis []KeyInfo) MockSigner {
 	for _, k := range kis {
 		// extract public key
 		pub := k.PublicKey()
-		newAddr, err := address.NewSecp256k1Address(pub)
+		newAddr, err := k.Address()
 		if err != nil {
 			panic(err)
 		}
@@ -88,6 +88,19 @@ func MustGenerateKeyInfo(n int, seed byte) []KeyInfo {
 	return keyinfos
 }
 
+// MustGenerateBLSKeyInfo generates `n` random BLS keyinfos. Unlike MustGenerateKeyInfo
+// the result is not deterministic.
+func MustGenerateBLSKeyInfo(n int) []KeyInfo {
+	var keyinfos []KeyInfo
+	for i := 0; i < n; i++ {
+		keyinfos = append(keyinfos, KeyInfo{
+			PrivateKey: wutil.NewBLSPrivateKey(),
+			Curve:      BLS,
+		})
+	}
+	return keyinfos
+}
+
 // SignBytes cryptographically signs `data` using the Address `addr`.
 func (ms MockSigner) SignBytes(data []byte, addr address.Address) (Signature, error) {
 	ki, ok := ms.AddrKeyInfo[addr]
@@ -95,6 +108,10 @@ func (ms MockSigner) SignBytes(data []byte, addr address.Address) (Signature, er
 		return nil, errors.New("Unknown address -- can't sign")
 	}
 
+	if ki.Type() == BLS {
+		return wutil.SignBLS(ki.Key(), data)
+	}
+
 	hash := blake2b.Sum256(data)
 	return crypto.Sign(ki.Key(), hash[:])
 }
@@ -19,6 +19,10 @@ const TEST = "go-filecoin-test"
 // Protocol0 is the first protocol version
 const Protocol0 = 0
 
+// Latest is the newest protocol version that this implementation supports. Blocks at heights
+// where a later version is in effect are rejected.
+const Latest = Protocol0
+
 // ConfigureProtocolVersions configures all protocol upgrades for all known networks.
 // TODO: support arbitrary network names at "latest" protocol version so that only coordinated
 // network upgrades need to be represented here. See #3491.
@@ -30,3 +34,9 @@ func ConfigureProtocolVersions(network string) (*ProtocolVersionTable, error) {
 		Add(TEST, Protocol0, types.NewBlockHeight(0)).
 		Build()
 }
+
+// NewSingleVersionTable returns a table in which protocol version v is in effect at every height,
+// for code that does not follow the upgrades of a network, such as genesis construction and tests.
+func NewSingleVersionTable(v uint64) *ProtocolVersionTable {
+	return &ProtocolVersionTable{versions: []protocolVersion{{Version: v, EffectiveAt: types.NewBlockHeight(0)}}}
+}
@@ -28,7 +28,13 @@ type Context struct {
 	storageMap  StorageMap
 	gasTracker  *GasTracker
 	blockHeight *types.BlockHeight
+	// protocolVersion is the protocol version in effect at blockHeight.
+	protocolVersion uint64
+	// gasSchedule is the gas schedule in effect at protocolVersion.
+	gasSchedule *GasSchedule
 	ancestors   []types.TipSet
+	// trace, if set, records the execution of message.
+	trace *ExecutionTrace
 
 	deps *deps // Inject external dependencies so we can unit test robustly.
 }
@@ -44,21 +50,28 @@ type NewContextParams struct {
 	StorageMap  StorageMap
 	GasTracker  *GasTracker
 	BlockHeight *types.BlockHeight
-	Ancestors   []types.TipSet
+	// ProtocolVersion is the protocol version in effect at BlockHeight.
+	ProtocolVersion uint64
+	Ancestors       []types.TipSet
+	// Trace, if set, records the execution of Message and the messages that it sends.
+	Trace *ExecutionTrace
 }
 
 // NewVMContext returns an initialized context.
 func NewVMContext(params NewContextParams) *Context {
 	return &Context{
-		from:        params.From,
-		to:          params.To,
-		message:     params.Message,
-		state:       params.State,
-		storageMap:  params.StorageMap,
-		gasTracker:  params.GasTracker,
-		blockHeight: params.BlockHeight,
-		ancestors:   params.Ancestors,
-		deps:        makeDeps(params.State),
+		from:            params.From,
+		to:              params.To,
+		message:         params.Message,
+		state:           params.State,
+		storageMap:      params.StorageMap,
+		gasTracker:      params.GasTracker,
+		blockHeight:     params.BlockHeight,
+		protocolVersion: params.ProtocolVersion,
+		gasSchedule:     GasScheduleAt(params.ProtocolVersion),
+		ancestors:       params.Ancestors,
+		trace:           params.Trace,
+		deps:            makeDeps(params.State),
 	}
 }
 
@@ -66,7 +79,16 @@ var _ exec.VMContext = (*Context)(nil)
 
 // Storage returns an implementation of the storage module for this context.
 func (ctx *Context) Storage() exec.Storage {
-	return ctx.storageMap.NewStorage(ctx.message.To, ctx.to)
+	return ctx.meteredStorage(ctx.message.To, ctx.to)
+}
+
+// meteredStorage returns the storage of the actor at addr, which charges the gas tracker of this
+// context for the bytes put in it.
+func (ctx *Context) meteredStorage(addr address.Address, act *actor.Actor) Storage {
+	storage := ctx.storageMap.NewStorage(addr, act)
+	storage.gasTracker = ctx.gasTracker
+	storage.gasPerByte = ctx.gasSchedule.StoragePerByte
+	return storage
 }
 
 // Message retrieves the message associated with this context.
@@ -89,6 +111,12 @@ func (ctx *Context) BlockHeight() *types.BlockHeight {
 	return ctx.blockHeight
 }
 
+// ProtocolVersion returns the protocol version in effect at the block height of the block
+// currently being processed.
+func (ctx *Context) ProtocolVersion() uint64 {
+	return ctx.protocolVersion
+}
+
 // MyBalance returns the balance of the associated actor.
 func (ctx *Context) MyBalance() types.AttoFIL {
 	return ctx.to.Balance
@@ -108,38 +136,58 @@ func (ctx *Context) Send(to address.Address, method string, value types.AttoFIL,
 	from := ctx.Message().To
 	fromActor := ctx.to
 
+	// Record the subcall before anything can fail, so that failures to send it are traced too.
+	var subcall *ExecutionTrace
+	if ctx.trace != nil {
+		subcall = &ExecutionTrace{From: from, To: to, Value: value, Method: method}
+		ctx.trace.Subcalls = append(ctx.trace.Subcalls, subcall)
+	}
+	fail := func(code uint8, err error) ([][]byte, uint8, error) {
+		if subcall != nil {
+			subcall.ExitCode = code
+			subcall.Error = err.Error()
+		}
+		return nil, code, err
+	}
+
+	if err := ctx.Charge(ctx.gasSchedule.Send); err != nil {
+		return fail(exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas"))
+	}
+
 	vals, err := deps.ToValues(params)
 	if err != nil {
-		return nil, 1, errors.FaultErrorWrap(err, "failed to convert inputs to abi values")
+		return fail(1, errors.FaultErrorWrap(err, "failed to convert inputs to abi values"))
 	}
 
 	paramData, err := deps.EncodeValues(vals)
 	if err != nil {
-		return nil, 1, errors.RevertErrorWrap(err, "encoding params failed")
+		return fail(1, errors.RevertErrorWrap(err, "encoding params failed"))
 	}
 
 	msg := types.NewMessage(from, to, 0, value, method, paramData)
 	if msg.From == msg.To {
 		// TODO: handle this
-		return nil, 1, errors.NewFaultErrorf("unhandled: sending to self (%s)", msg.From)
+		return fail(1, errors.NewFaultErrorf("unhandled: sending to self (%s)", msg.From))
 	}
 
 	toActor, err := deps.GetOrCreateActor(context.TODO(), msg.To, func() (*actor.Actor, error) {
 		return &actor.Actor{}, nil
 	})
 	if err != nil {
-		return nil, 1, errors.FaultErrorWrapf(err, "failed to get or create To actor %s", msg.To)
+		return fail(1, errors.FaultErrorWrapf(err, "failed to get or create To actor %s", msg.To))
 	}
 	// TODO(fritz) de-dup some of the logic between here and core.Send
 	innerParams := NewContextParams{
-		From:        fromActor,
-		To:          toActor,
-		Message:     msg,
-		State:       ctx.state,
-		StorageMap:  ctx.storageMap,
-		GasTracker:  ctx.gasTracker,
-		BlockHeight: ctx.blockHeight,
-		Ancestors:   ctx.ancestors,
+		From:            fromActor,
+		To:              toActor,
+		Message:         msg,
+		State:           ctx.state,
+		StorageMap:      ctx.storageMap,
+		GasTracker:      ctx.gasTracker,
+		BlockHeight:     ctx.blockHeight,
+		ProtocolVersion: ctx.protocolVersion,
+		Ancestors:       ctx.ancestors,
+		Trace:           subcall,
 	}
 	innerCtx := NewVMContext(innerParams)
 
@@ -176,6 +224,10 @@ func computeActorAddress(creator address.Address, nonce uint64) (address.Address
 // CreateNewActor creates and initializes an actor at the given address.
 // If the address is occupied by a non-empty actor, this method will fail.
 func (ctx *Context) CreateNewActor(addr address.Address, code cid.Cid, initializerData interface{}) error {
+	if err := ctx.Charge(ctx.gasSchedule.CreateActor); err != nil {
+		return errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
 	// Check existing address. If nothing there, create empty actor.
 	newActor, err := ctx.state.GetOrCreateActor(context.TODO(), addr, func() (*actor.Actor, error) {
 		return &actor.Actor{}, nil
@@ -192,7 +244,7 @@ func (ctx *Context) CreateNewActor(addr address.Address, code cid.Cid, initializ
 	// make this the right 'type' of actor
 	newActor.Code = code
 
-	childStorage := ctx.storageMap.NewStorage(addr, newActor)
+	childStorage := ctx.meteredStorage(addr, newActor)
 	execActor, err := ctx.state.GetBuiltinActorCode(code)
 	if err != nil {
 		return errors.NewRevertErrorf("attempt to create executable actor from non-existent code %s", code.String())
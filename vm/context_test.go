@@ -47,13 +47,15 @@ func TestVMContextStorage(t *testing.T) {
 
 	to, err := cstate.GetActor(ctx, toAddr)
 	assert.NoError(t, err)
+	gasTracker := NewGasTracker()
+	gasTracker.MsgGasLimit = types.BlockGasLimit
 	vmCtxParams := NewContextParams{
 		From:        nil,
 		To:          to,
 		Message:     msg,
 		State:       cstate,
 		StorageMap:  vms,
-		GasTracker:  NewGasTracker(),
+		GasTracker:  gasTracker,
 		BlockHeight: types.NewBlockHeight(0),
 	}
 	vmCtx := NewVMContext(vmCtxParams)
@@ -76,6 +78,82 @@ func TestVMContextStorage(t *testing.T) {
 	assert.Equal(t, storage, node.RawData())
 }
 
+func TestVMContextChargesGas(t *testing.T) {
+	tf.UnitTest(t)
+
+	schedule := GasScheduleAt(0)
+	bs := blockstore.NewBlockstore(datastore.NewMapDatastore())
+	newContext := func(gasLimit types.GasUnits) *Context {
+		gasTracker := NewGasTracker()
+		gasTracker.MsgGasLimit = gasLimit
+		to := actor.NewActor(types.NewCidForTestGetter()(), types.ZeroAttoFIL)
+		return NewVMContext(NewContextParams{
+			To:          to,
+			Message:     types.NewMessageForTestGetter()(),
+			StorageMap:  NewStorageMap(bs),
+			GasTracker:  gasTracker,
+			BlockHeight: types.NewBlockHeight(0),
+		})
+	}
+	node, err := cbor.WrapObject([]byte("hello"), types.DefaultHashFunction, -1)
+	require.NoError(t, err)
+
+	t.Run("charges for each byte put in storage", func(t *testing.T) {
+		ctx := newContext(types.BlockGasLimit)
+		_, err := ctx.Storage().Put(node.RawData())
+		require.NoError(t, err)
+		assert.Equal(t, schedule.StoragePerByte*types.GasUnits(len(node.RawData())), ctx.GasUnits())
+	})
+
+	t.Run("fails to put more bytes than the gas limit allows", func(t *testing.T) {
+		ctx := newContext(types.GasUnits(len(node.RawData()) - 1))
+		_, err := ctx.Storage().Put(node.RawData())
+		require.Error(t, err)
+		assert.True(t, errors.ShouldRevert(err))
+		assert.True(t, errors.IsOutOfGas(err))
+	})
+
+	t.Run("charges for sending a message", func(t *testing.T) {
+		ctx := newContext(types.BlockGasLimit)
+		ctx.deps = &deps{
+			EncodeValues: abi.EncodeValues,
+			GetOrCreateActor: func(_ context.Context, _ address.Address, f func() (*actor.Actor, error)) (*actor.Actor, error) {
+				return f()
+			},
+			Send: func(ctx context.Context, vmCtx *Context) ([][]byte, uint8, error) {
+				return nil, 0, nil
+			},
+			ToValues: abi.ToValues,
+		}
+		_, _, err := ctx.Send(address.NewForTestGetter()(), "foo", types.ZeroAttoFIL, []interface{}{})
+		require.NoError(t, err)
+		assert.Equal(t, schedule.Send, ctx.GasUnits())
+
+		ctx = newContext(schedule.Send - 1)
+		_, code, err := ctx.Send(address.NewForTestGetter()(), "foo", types.ZeroAttoFIL, []interface{}{})
+		require.Error(t, err)
+		assert.Equal(t, exec.ErrInsufficientGas, int(code))
+	})
+
+	t.Run("charges for bytes already in storage", func(t *testing.T) {
+		nodeCost := schedule.StoragePerByte * types.GasUnits(len(node.RawData()))
+
+		ctx := newContext(types.BlockGasLimit)
+		_, err := ctx.Storage().Put(node.RawData())
+		require.NoError(t, err)
+		_, err = ctx.Storage().Put(node.RawData())
+		require.NoError(t, err)
+		assert.Equal(t, 2*nodeCost, ctx.GasUnits())
+
+		// Gas does not depend on what the node's blockstore holds.
+		require.NoError(t, bs.Put(node))
+		ctx = newContext(types.BlockGasLimit)
+		_, err = ctx.Storage().Put(node.RawData())
+		require.NoError(t, err)
+		assert.Equal(t, nodeCost, ctx.GasUnits())
+	})
+}
+
 func TestVMContextSendFailures(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -93,13 +171,15 @@ func TestVMContextSendFailures(t *testing.T) {
 	bs := blockstore.NewBlockstore(datastore.NewMapDatastore())
 	vms := NewStorageMap(bs)
 
+	gasTracker := NewGasTracker()
+	gasTracker.MsgGasLimit = types.BlockGasLimit
 	vmCtxParams := NewContextParams{
 		From:        actor1,
 		To:          actor2,
 		Message:     newMsg(),
 		State:       tree,
 		StorageMap:  vms,
-		GasTracker:  NewGasTracker(),
+		GasTracker:  gasTracker,
 		BlockHeight: types.NewBlockHeight(0),
 	}
 
@@ -123,6 +203,30 @@ func TestVMContextSendFailures(t *testing.T) {
 		assert.Equal(t, []string{"ToValues"}, calls)
 	})
 
+	t.Run("failure to send is recorded in the trace", func(t *testing.T) {
+		deps := &deps{
+			ToValues: func(_ []interface{}) ([]*abi.Value, error) {
+				return nil, xerrors.New("error")
+			},
+		}
+
+		params := vmCtxParams
+		params.Trace = &ExecutionTrace{}
+		ctx := NewVMContext(params)
+		ctx.deps = deps
+
+		to := newAddress()
+		_, code, err := ctx.Send(to, "foo", types.ZeroAttoFIL, []interface{}{})
+		require.Error(t, err)
+
+		require.Len(t, params.Trace.Subcalls, 1)
+		sub := params.Trace.Subcalls[0]
+		assert.Equal(t, to, sub.To)
+		assert.Equal(t, "foo", sub.Method)
+		assert.Equal(t, code, sub.ExitCode)
+		assert.Equal(t, err.Error(), sub.Error)
+	})
+
 	t.Run("failure to encode ABI values to byte slice results in revert error", func(t *testing.T) {
 		var calls []string
 		deps := &deps{
@@ -91,3 +91,16 @@ func TestApplyErrorTemporary(t *testing.T) {
 		assert.NotContains(t, errors.Cause(e).Error(), "wrapper")
 	})
 }
+
+func TestIsOutOfGas(t *testing.T) {
+	tf.UnitTest(t)
+
+	assert.True(t, IsOutOfGas(ErrOutOfGas))
+	assert.True(t, IsOutOfGas(RevertErrorWrap(ErrOutOfGas, "revert")))
+	assert.True(t, IsOutOfGas(FaultErrorWrap(ErrOutOfGas, "fault")))
+	assert.True(t, IsOutOfGas(errors.Wrap(RevertErrorWrap(ErrOutOfGas, "revert"), "wrapped")))
+
+	assert.False(t, IsOutOfGas(nil))
+	assert.False(t, IsOutOfGas(NewRevertError("gas cost exceeds gas limit")))
+	assert.False(t, IsOutOfGas(FaultErrorWrap(errors.New("source"), "fault")))
+}
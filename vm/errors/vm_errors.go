@@ -26,6 +26,30 @@ var Errors = map[uint8]error{
 	ErrNoActorCode:                 NewCodedRevertError(ErrNoActorCode, "actor code not found"),
 }
 
+// ErrOutOfGas is returned when a charge exceeds the gas limit of the message being applied.
+var ErrOutOfGas = NewRevertError("gas cost exceeds gas limit")
+
+// IsOutOfGas returns true if err is, or wraps, ErrOutOfGas. Unlike ShouldRevert and IsFault
+// it looks through revert and fault errors as well as errors wrapped with a Cause().
+func IsOutOfGas(err error) bool {
+	for err != nil {
+		if err == ErrOutOfGas {
+			return true
+		}
+		switch e := err.(type) {
+		case *RevertError:
+			err = e.err
+		case *FaultError:
+			err = e.err
+		case interface{ Cause() error }:
+			err = e.Cause()
+		default:
+			return false
+		}
+	}
+	return false
+}
+
 // VMExitCodeToError tries to locate an error in either the VM errors or the provide error map
 // If it fails to locate the error it will return an unknown error
 func VMExitCodeToError(exitCode uint8, actorErrors map[uint8]error) error {
@@ -0,0 +1,74 @@
+package vm
+
+import (
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
+)
+
+// GasSchedule is the gas cost of each operation of the VM that consumes gas.
+type GasSchedule struct {
+	// Methods maps actor code and method name to the cost of calling the method, which is
+	// charged before the method runs.
+	Methods map[cid.Cid]map[string]types.GasUnits
+	// DefaultMethod is the cost of calling methods that Methods does not list.
+	DefaultMethod types.GasUnits
+	// StoragePerByte is the cost of each byte that an actor puts in storage.
+	StoragePerByte types.GasUnits
+	// Send is the cost of an actor sending a message to another actor, in addition to the cost
+	// of the method called.
+	Send types.GasUnits
+	// CreateActor is the cost of creating a new actor, in addition to the storage of its state.
+	CreateActor types.GasUnits
+}
+
+// MethodCost returns the cost of calling method on an actor with code code.
+func (s *GasSchedule) MethodCost(code cid.Cid, method string) types.GasUnits {
+	if cost, ok := s.Methods[code][method]; ok {
+		return cost
+	}
+	return s.DefaultMethod
+}
+
+// gasSchedules lists each gas schedule with the protocol version at which it takes effect, in
+// increasing order of version. A protocol upgrade that changes gas costs adds a schedule here.
+var gasSchedules = []struct {
+	version  uint64
+	schedule *GasSchedule
+}{
+	{version.Protocol0, &GasSchedule{
+		Methods: map[cid.Cid]map[string]types.GasUnits{
+			types.MinerActorCodeCid: {
+				"commitSector":         1000,
+				"submitPoSt":           1000,
+				"verifyPieceInclusion": 300,
+			},
+			types.BootstrapMinerActorCodeCid: {
+				"commitSector":         1000,
+				"submitPoSt":           1000,
+				"verifyPieceInclusion": 300,
+			},
+			types.PaymentBrokerActorCodeCid: {
+				"redeem": 200,
+				"close":  200,
+			},
+		},
+		DefaultMethod:  100,
+		StoragePerByte: 1,
+		Send:           10,
+		CreateActor:    100,
+	}},
+}
+
+// GasScheduleAt returns the gas schedule in effect at protocol version v.
+func GasScheduleAt(v uint64) *GasSchedule {
+	schedule := gasSchedules[0].schedule
+	for _, s := range gasSchedules {
+		if s.version > v {
+			break
+		}
+		schedule = s.schedule
+	}
+	return schedule
+}
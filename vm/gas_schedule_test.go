@@ -0,0 +1,30 @@
+package vm
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+	"github.com/filecoin-project/go-filecoin/version"
+)
+
+func TestGasSchedule(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("charges listed methods their own cost and others the default", func(t *testing.T) {
+		schedule := GasScheduleAt(version.Protocol0)
+
+		assert.True(t, schedule.MethodCost(types.MinerActorCodeCid, "commitSector") > schedule.MethodCost(types.MinerActorCodeCid, "getOwner"))
+		assert.Equal(t, schedule.DefaultMethod, schedule.MethodCost(types.MinerActorCodeCid, "getOwner"))
+		assert.Equal(t, schedule.DefaultMethod, schedule.MethodCost(types.NewCidForTestGetter()(), "commitSector"))
+	})
+
+	t.Run("returns the schedule that took effect most recently", func(t *testing.T) {
+		assert.Equal(t, gasSchedules[0].schedule, GasScheduleAt(version.Protocol0))
+
+		latest := gasSchedules[len(gasSchedules)-1]
+		assert.Equal(t, latest.schedule, GasScheduleAt(latest.version+1))
+	})
+}
@@ -27,12 +27,13 @@ func (gasTracker *GasTracker) ResetForNewMessage(message types.MeteredMessage) {
 	gasTracker.gasConsumedByMessage = types.NewGasUnits(0)
 }
 
-// Charge will add the gas charge to the current method gas context.
+// Charge will add the gas charge to the current method gas context. It returns
+// errors.ErrOutOfGas if the charge exceeds the gas limit of the current message.
 func (gasTracker *GasTracker) Charge(cost types.GasUnits) error {
 	if gasTracker.gasConsumedByMessage+cost > gasTracker.MsgGasLimit {
 		gasTracker.gasConsumedByMessage = gasTracker.MsgGasLimit
 		gasTracker.gasConsumedByBlock += gasTracker.MsgGasLimit
-		return errors.NewRevertError("gas cost exceeds gas limit")
+		return errors.ErrOutOfGas
 	}
 
 	gasTracker.gasConsumedByMessage += cost
@@ -91,6 +91,11 @@ type Storage struct {
 	actor      *actor.Actor
 	chunks     map[cid.Cid]ipld.Node
 	blockstore blockstore.Blockstore
+	// gasTracker, if set, is charged gasPerByte for each byte put in storage, whether or not the
+	// chunk is already stored. What a node happens to store must not affect gas, which is part
+	// of consensus.
+	gasTracker *GasTracker
+	gasPerByte types.GasUnits
 }
 
 var _ exec.Storage = (*Storage)(nil)
@@ -131,6 +136,12 @@ func (s Storage) Put(v interface{}) (cid.Cid, error) {
 		return cid.Undef, exec.Errors[exec.ErrDecode]
 	}
 
+	if s.gasTracker != nil {
+		if err := s.gasTracker.Charge(s.gasPerByte * types.GasUnits(len(nd.RawData()))); err != nil {
+			return cid.Undef, err
+		}
+	}
+
 	c := nd.Cid()
 	s.chunks[c] = nd
 
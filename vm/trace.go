@@ -0,0 +1,48 @@
+package vm
+
+import (
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+// ExecutionTrace records the execution of a message by the VM, including the messages that
+// actors sent as it executed.
+type ExecutionTrace struct {
+	From   address.Address
+	To     address.Address
+	Value  types.AttoFIL
+	Method string
+	Params []byte
+	// GasUsed is the gas charged for the execution, including that of the messages it sent.
+	GasUsed  types.GasUnits
+	ExitCode uint8
+	// Error is the error with which the execution failed, or empty if it succeeded.
+	Error string
+	// Subcalls are the traces of the messages sent during the execution, in the order sent.
+	Subcalls []*ExecutionTrace
+}
+
+// record records the message of vmCtx in the trace of vmCtx, if any, and returns a func that
+// records the result of executing it.
+func record(vmCtx *Context) func(exitCode uint8, err error) {
+	trace := vmCtx.trace
+	if trace == nil {
+		return func(uint8, error) {}
+	}
+
+	msg := vmCtx.message
+	trace.From = msg.From
+	trace.To = msg.To
+	trace.Value = msg.Value
+	trace.Method = msg.Method
+	trace.Params = msg.Params
+
+	gasBefore := vmCtx.GasUnits()
+	return func(exitCode uint8, err error) {
+		trace.GasUsed = vmCtx.GasUnits() - gasBefore
+		trace.ExitCode = exitCode
+		if err != nil {
+			trace.Error = err.Error()
+		}
+	}
+}
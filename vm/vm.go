@@ -8,6 +8,7 @@ import (
 	cbor "github.com/ipfs/go-ipld-cbor"
 
 	"github.com/filecoin-project/go-filecoin/actor"
+	"github.com/filecoin-project/go-filecoin/exec"
 	"github.com/filecoin-project/go-filecoin/types"
 	"github.com/filecoin-project/go-filecoin/vm/errors"
 )
@@ -18,7 +19,10 @@ func Send(ctx context.Context, vmCtx *Context) ([][]byte, uint8, error) {
 	deps := sendDeps{
 		transfer: Transfer,
 	}
-	return send(ctx, deps, vmCtx)
+	done := record(vmCtx)
+	ret, code, err := send(ctx, deps, vmCtx)
+	done(code, err)
+	return ret, code, err
 }
 
 type sendDeps struct {
@@ -47,11 +51,23 @@ func send(ctx context.Context, deps sendDeps, vmCtx *Context) ([][]byte, uint8,
 		return nil, errors.ErrNoActorCode, errors.Errors[errors.ErrNoActorCode]
 	}
 
-	if !toExecutable.Exports().Has(vmCtx.message.Method) {
+	if !toExecutable.Exports().HasAt(vmCtx.message.Method, vmCtx.protocolVersion) {
 		return nil, 1, errors.Errors[errors.ErrMissingExport]
 	}
 
+	if err := vmCtx.Charge(vmCtx.gasSchedule.MethodCost(vmCtx.to.Code, vmCtx.message.Method)); err != nil {
+		return nil, exec.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
 	r, code, err := actor.MakeTypedExport(toExecutable, vmCtx.message.Method)(vmCtx)
+	if errors.IsOutOfGas(err) {
+		// The method failed because it ran out of gas, perhaps while putting state in storage, and
+		// however it wrapped the error, it must revert the message rather than fault the block.
+		if !errors.ShouldRevert(err) {
+			err = errors.RevertErrorWrap(err, "Insufficient gas")
+		}
+		return nil, exec.ErrInsufficientGas, err
+	}
 	if r != nil {
 		var rv [][]byte
 		err = cbor.DecodeInto(r, &rv)
@@ -135,4 +135,84 @@ func TestSendErrorHandling(t *testing.T) {
 		assert.Equal(t, 1, int(code))
 		assert.True(t, errors.ShouldRevert(sendErr))
 	})
+
+	runGasActor := func(method string) (uint8, error) {
+		msg := newMsg()
+		msg.Value = types.ZeroAttoFIL // such that we don't transfer
+		msg.Method = method
+
+		tree := state.NewCachedStateTree(&state.MockStateTree{NoMocks: true, BuiltinActors: map[cid.Cid]exec.ExecutableActor{
+			actor2.Code: &gasActor{},
+		}})
+		gasTracker := NewGasTracker()
+		gasTracker.MsgGasLimit = types.NewGasUnits(1000)
+
+		vmCtxParams := NewContextParams{
+			From:        actor1,
+			To:          actor2,
+			Message:     msg,
+			State:       tree,
+			StorageMap:  vms,
+			GasTracker:  gasTracker,
+			BlockHeight: types.NewBlockHeight(0),
+		}
+		vmCtx := NewVMContext(vmCtxParams)
+		_, code, sendErr := send(context.Background(), sendDeps{}, vmCtx)
+		return code, sendErr
+	}
+
+	t.Run("returns insufficient gas and a revert error if the method runs out of gas", func(t *testing.T) {
+		code, sendErr := runGasActor("outOfGas")
+
+		assert.Error(t, sendErr)
+		assert.Equal(t, exec.ErrInsufficientGas, int(code))
+		assert.True(t, errors.ShouldRevert(sendErr))
+		assert.True(t, errors.IsOutOfGas(sendErr))
+	})
+
+	t.Run("keeps other errors of a method that ran out of gas", func(t *testing.T) {
+		code, sendErr := runGasActor("faultAfterOutOfGas")
+
+		assert.Error(t, sendErr)
+		assert.Equal(t, 1, int(code))
+		assert.True(t, errors.IsFault(sendErr))
+	})
+}
+
+// gasActor is an actor whose methods exceed their gas limit.
+type gasActor struct{}
+
+var _ exec.ExecutableActor = (*gasActor)(nil)
+
+var gasActorExports = exec.Exports{
+	"outOfGas": &exec.FunctionSignature{
+		Params: nil,
+		Return: nil,
+	},
+	"faultAfterOutOfGas": &exec.FunctionSignature{
+		Params: nil,
+		Return: nil,
+	},
+}
+
+func (a *gasActor) Exports() exec.Exports {
+	return gasActorExports
+}
+
+func (a *gasActor) InitializeState(storage exec.Storage, initializerData interface{}) error {
+	return nil
+}
+
+// OutOfGas wraps the error of a charge beyond the gas limit in a fault.
+func (a *gasActor) OutOfGas(ctx exec.VMContext) (uint8, error) {
+	if err := ctx.Charge(types.NewGasUnits(10000)); err != nil {
+		return 1, errors.FaultErrorWrap(err, "could not charge")
+	}
+	return 0, nil
+}
+
+// FaultAfterOutOfGas ignores the error of a charge beyond the gas limit and then faults.
+func (a *gasActor) FaultAfterOutOfGas(ctx exec.VMContext) (uint8, error) {
+	_ = ctx.Charge(types.NewGasUnits(10000))
+	return 1, errors.NewFaultError("boom")
 }
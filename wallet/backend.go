@@ -1,6 +1,8 @@
 package wallet
 
 import (
+	"time"
+
 	"github.com/filecoin-project/go-filecoin/address"
 	"github.com/filecoin-project/go-filecoin/types"
 )
@@ -34,3 +36,17 @@ type Importer interface {
 	// into the backend
 	ImportKey(ki *types.KeyInfo) error
 }
+
+// Locker is a specialization of a wallet backend that keeps its keys sealed
+// until it is unlocked with a passphrase.
+type Locker interface {
+	// Lock makes the keys unusable until the next Unlock.
+	Lock()
+
+	// Unlock makes the keys usable, until Lock is called or, if it is
+	// positive, the timeout expires.
+	Unlock(passphrase []byte, timeout time.Duration) error
+
+	// IsLocked returns true if the keys cannot be used.
+	IsLocked() bool
+}
@@ -18,7 +18,9 @@ import (
 
 const (
 	// SECP256K1 is a curve used to computer private keys
-	SECP256K1 = "secp256k1"
+	SECP256K1 = types.SECP256K1
+	// BLS is a curve used to compute private keys for BLS signatures
+	BLS = types.BLS
 )
 
 // DSBackendType is the reflect type of the DSBackend.
@@ -93,20 +95,15 @@ func (backend *DSBackend) HasAddress(addr address.Address) bool {
 	return ok
 }
 
-// NewAddress creates a new address and stores it.
+// NewAddress creates a new address using the given protocol and stores it.
+// Only the SECP256K1 and BLS protocols are supported.
 // Safe for concurrent access.
-func (backend *DSBackend) NewAddress() (address.Address, error) {
-	prv, err := crypto.GenerateKey()
+func (backend *DSBackend) NewAddress(protocol address.Protocol) (address.Address, error) {
+	ki, err := newKeyInfo(protocol)
 	if err != nil {
 		return address.Undef, err
 	}
 
-	// TODO: maybe the above call should just return a keyinfo?
-	ki := &types.KeyInfo{
-		PrivateKey: prv,
-		Curve:      SECP256K1,
-	}
-
 	if err := backend.putKeyInfo(ki); err != nil {
 		return address.Undef, err
 	}
@@ -114,6 +111,28 @@ func (backend *DSBackend) NewAddress() (address.Address, error) {
 	return ki.Address()
 }
 
+// newKeyInfo generates a new key for an address of the given protocol.
+func newKeyInfo(protocol address.Protocol) (*types.KeyInfo, error) {
+	switch protocol {
+	case address.SECP256K1:
+		prv, err := crypto.GenerateKey()
+		if err != nil {
+			return nil, err
+		}
+		return &types.KeyInfo{
+			PrivateKey: prv,
+			Curve:      SECP256K1,
+		}, nil
+	case address.BLS:
+		return &types.KeyInfo{
+			PrivateKey: wutil.NewBLSPrivateKey(),
+			Curve:      BLS,
+		}, nil
+	default:
+		return nil, errors.Errorf("cannot create address with protocol %d", protocol)
+	}
+}
+
 func (backend *DSBackend) putKeyInfo(ki *types.KeyInfo) error {
 	a, err := ki.Address()
 	if err != nil {
@@ -143,6 +162,9 @@ func (backend *DSBackend) SignBytes(data []byte, addr address.Address) (types.Si
 		return nil, err
 	}
 
+	if ki.Type() == BLS {
+		return wutil.SignBLS(ki.Key(), data)
+	}
 	return wutil.Sign(ki.Key(), data)
 }
 
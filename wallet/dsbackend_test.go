@@ -8,6 +8,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/filecoin-project/go-filecoin/address"
 	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
 )
 
@@ -26,7 +27,7 @@ func TestDSBackendSimple(t *testing.T) {
 	assert.Len(t, fs.Addresses(), 0)
 
 	t.Log("can create new address")
-	addr, err := fs.NewAddress()
+	addr, err := fs.NewAddress(address.SECP256K1)
 	assert.NoError(t, err)
 
 	t.Log("address is stored")
@@ -51,7 +52,7 @@ func TestDSBackendKeyPairMatchAddress(t *testing.T) {
 	assert.NoError(t, err)
 
 	t.Log("can create new address")
-	addr, err := fs.NewAddress()
+	addr, err := fs.NewAddress(address.SECP256K1)
 	assert.NoError(t, err)
 
 	t.Log("address is stored")
@@ -68,6 +69,33 @@ func TestDSBackendKeyPairMatchAddress(t *testing.T) {
 	assert.Equal(t, addr, dAddr)
 }
 
+func TestDSBackendBLSAddress(t *testing.T) {
+	tf.UnitTest(t)
+
+	ds := datastore.NewMapDatastore()
+	defer func() {
+		require.NoError(t, ds.Close())
+	}()
+
+	fs, err := NewDSBackend(ds)
+	require.NoError(t, err)
+
+	addr, err := fs.NewAddress(address.BLS)
+	require.NoError(t, err)
+	assert.Equal(t, address.BLS, addr.Protocol())
+
+	ki, err := fs.GetKeyInfo(addr)
+	require.NoError(t, err)
+	assert.Equal(t, BLS, ki.Type())
+
+	dAddr, err := ki.Address()
+	require.NoError(t, err)
+	assert.Equal(t, addr, dAddr)
+
+	_, err = fs.NewAddress(address.Actor)
+	assert.Error(t, err)
+}
+
 func TestDSBackendErrorsForUnknownAddress(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -87,7 +115,7 @@ func TestDSBackendErrorsForUnknownAddress(t *testing.T) {
 	assert.NoError(t, err)
 
 	t.Log("can create new address in fs1")
-	addr, err := fs1.NewAddress()
+	addr, err := fs1.NewAddress(address.SECP256K1)
 	assert.NoError(t, err)
 
 	t.Log("address is stored fs1")
@@ -123,7 +151,7 @@ func TestDSBackendParallel(t *testing.T) {
 	wg.Add(count)
 	for i := 0; i < count; i++ {
 		go func() {
-			_, err := fs.NewAddress()
+			_, err := fs.NewAddress(address.SECP256K1)
 			assert.NoError(t, err)
 			wg.Done()
 		}()
@@ -0,0 +1,478 @@
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/subtle"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	ds "github.com/ipfs/go-datastore"
+	dsq "github.com/ipfs/go-datastore/query"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/crypto"
+	"github.com/filecoin-project/go-filecoin/repo"
+	"github.com/filecoin-project/go-filecoin/types"
+	wutil "github.com/filecoin-project/go-filecoin/wallet/util"
+)
+
+func init() {
+	cbor.RegisterCborType(encryptionParams{})
+}
+
+var (
+	// ErrLocked is returned when a key is needed from an encrypted backend that is locked.
+	ErrLocked = errors.New("wallet is locked")
+
+	// ErrBadPassphrase is returned when unlocking an encrypted backend with the wrong passphrase.
+	ErrBadPassphrase = errors.New("incorrect wallet passphrase")
+)
+
+// EncryptedBackendType is the reflect type of the EncryptedBackend.
+var EncryptedBackendType = reflect.TypeOf(&EncryptedBackend{})
+
+// encryptionParamsKey is the datastore key of the encryptionParams of an encrypted wallet
+// datastore. It does not parse as an address, so a DSBackend refuses to open the datastore.
+var encryptionParamsKey = ds.NewKey("_encryption")
+
+// checkPlaintext is sealed into the encryptionParams to verify passphrases, even when
+// the wallet holds no keys.
+var checkPlaintext = []byte("filecoin wallet")
+
+const (
+	// scrypt parameters used when encrypting a wallet datastore. They are stored with the
+	// datastore so they can be raised later without breaking existing wallets.
+	scryptN = 1 << 15
+	scryptR = 8
+	scryptP = 1
+
+	saltLen = 32
+)
+
+// encryptionParams holds what is needed to derive the sealing key of an encrypted
+// wallet datastore from its passphrase.
+type encryptionParams struct {
+	Salt  []byte
+	N     int
+	R     int
+	P     int
+	Check []byte
+}
+
+// deriveKey derives the AES-256 sealing key from the passphrase.
+func (p *encryptionParams) deriveKey(passphrase []byte) ([]byte, error) {
+	return scrypt.Key(passphrase, p.Salt, p.N, p.R, p.P, 32)
+}
+
+// EncryptedBackend is a wallet backend that stores keys in a datastore sealed with AES-GCM
+// under a key derived from a passphrase. Addresses are stored in the clear, so they can be
+// listed while the backend is locked, but keys can only be used once it is unlocked.
+type EncryptedBackend struct {
+	lk sync.RWMutex
+
+	ds     repo.Datastore
+	params *encryptionParams
+
+	// cache holds the addresses of the stored keys.
+	cache map[address.Address]struct{}
+
+	// aead is the cipher sealing the keys, or nil while the backend is locked.
+	aead cipher.AEAD
+	// relock locks the backend again when an unlock with a timeout expires.
+	relock *time.Timer
+}
+
+var _ Backend = (*EncryptedBackend)(nil)
+var _ Importer = (*EncryptedBackend)(nil)
+var _ Locker = (*EncryptedBackend)(nil)
+
+// IsEncrypted returns true if the wallet datastore holds encrypted keys.
+func IsEncrypted(store repo.Datastore) (bool, error) {
+	return store.Has(encryptionParamsKey)
+}
+
+// NewEncryptedBackend opens the encrypted wallet datastore `store`. The backend starts out
+// locked.
+func NewEncryptedBackend(store repo.Datastore) (*EncryptedBackend, error) {
+	return openEncryptedBackend(store)
+}
+
+// openEncryptedBackend opens the encrypted wallet datastore `store`, ignoring the entries
+// under `other`, which the caller stores besides the keys.
+func openEncryptedBackend(store repo.Datastore, other ...ds.Key) (*EncryptedBackend, error) {
+	raw, err := store.Get(encryptionParamsKey)
+	if err == ds.ErrNotFound {
+		return nil, errors.New("wallet datastore is not encrypted")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read wallet encryption parameters")
+	}
+
+	var params encryptionParams
+	if err := cbor.DecodeInto(raw, &params); err != nil {
+		return nil, errors.Wrap(err, "failed to decode wallet encryption parameters")
+	}
+
+	result, err := store.Query(dsq.Query{
+		KeysOnly: true,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query datastore")
+	}
+
+	list, err := result.Rest()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read query results")
+	}
+
+	cache := make(map[address.Address]struct{})
+	for _, el := range list {
+		if key := ds.NewKey(el.Key); key == encryptionParamsKey || containsKey(other, key) {
+			continue
+		}
+		parsedAddr, err := address.NewFromString(strings.Trim(el.Key, "/"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "trying to restore invalid address: %s", el.Key)
+		}
+		cache[parsedAddr] = struct{}{}
+	}
+
+	return &EncryptedBackend{
+		ds:     store,
+		params: &params,
+		cache:  cache,
+	}, nil
+}
+
+// Unlock derives the sealing key from the passphrase and makes the keys usable. If timeout
+// is positive the backend locks itself again after that long, otherwise it stays unlocked
+// until Lock is called.
+func (backend *EncryptedBackend) Unlock(passphrase []byte, timeout time.Duration) error {
+	aead, err := newAEAD(backend.params, passphrase)
+	if err != nil {
+		return err
+	}
+
+	check, err := open(aead, backend.params.Check, encryptionParamsKey.Bytes())
+	if err != nil || subtle.ConstantTimeCompare(check, checkPlaintext) != 1 {
+		return ErrBadPassphrase
+	}
+
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+
+	backend.aead = aead
+	if backend.relock != nil {
+		backend.relock.Stop()
+		backend.relock = nil
+	}
+	if timeout > 0 {
+		backend.relock = time.AfterFunc(timeout, backend.Lock)
+	}
+	return nil
+}
+
+// Lock forgets the sealing key, after which keys cannot be used until the next Unlock.
+func (backend *EncryptedBackend) Lock() {
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+
+	backend.aead = nil
+	if backend.relock != nil {
+		backend.relock.Stop()
+		backend.relock = nil
+	}
+}
+
+// IsLocked returns true if the backend is locked.
+func (backend *EncryptedBackend) IsLocked() bool {
+	backend.lk.RLock()
+	defer backend.lk.RUnlock()
+
+	return backend.aead == nil
+}
+
+// Addresses returns a list of all addresses that are stored in this backend.
+func (backend *EncryptedBackend) Addresses() []address.Address {
+	backend.lk.RLock()
+	defer backend.lk.RUnlock()
+
+	var cpy []address.Address
+	for addr := range backend.cache {
+		cpy = append(cpy, addr)
+	}
+	return cpy
+}
+
+// HasAddress checks if the passed in address is stored in this backend.
+// Safe for concurrent access.
+func (backend *EncryptedBackend) HasAddress(addr address.Address) bool {
+	backend.lk.RLock()
+	defer backend.lk.RUnlock()
+
+	_, ok := backend.cache[addr]
+	return ok
+}
+
+// NewAddress creates a new address using the given protocol and stores it.
+// Only the SECP256K1 and BLS protocols are supported. Fails while the backend is locked.
+func (backend *EncryptedBackend) NewAddress(protocol address.Protocol) (address.Address, error) {
+	ki, err := newKeyInfo(protocol)
+	if err != nil {
+		return address.Undef, err
+	}
+
+	if err := backend.putKeyInfo(ki); err != nil {
+		return address.Undef, err
+	}
+
+	return ki.Address()
+}
+
+// ImportKey seals and stores the KeyInfo `ki`. Fails while the backend is locked.
+func (backend *EncryptedBackend) ImportKey(ki *types.KeyInfo) error {
+	return backend.putKeyInfo(ki)
+}
+
+func (backend *EncryptedBackend) putKeyInfo(ki *types.KeyInfo) error {
+	a, err := ki.Address()
+	if err != nil {
+		return err
+	}
+
+	kib, err := ki.Marshal()
+	if err != nil {
+		return err
+	}
+
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+
+	if backend.aead == nil {
+		return ErrLocked
+	}
+
+	key := ds.NewKey(a.String())
+	sealed, err := seal(backend.aead, kib, key.Bytes())
+	if err != nil {
+		return err
+	}
+
+	if err := backend.ds.Put(key, sealed); err != nil {
+		return errors.Wrap(err, "failed to store new address")
+	}
+
+	backend.cache[a] = struct{}{}
+	return nil
+}
+
+// sealValue seals `plaintext` to be stored under `key`. Fails while the backend is locked.
+func (backend *EncryptedBackend) sealValue(key ds.Key, plaintext []byte) ([]byte, error) {
+	backend.lk.RLock()
+	aead := backend.aead
+	backend.lk.RUnlock()
+	if aead == nil {
+		return nil, ErrLocked
+	}
+	return seal(aead, plaintext, key.Bytes())
+}
+
+// openValue opens what sealValue produced for `key`. Fails while the backend is locked.
+func (backend *EncryptedBackend) openValue(key ds.Key, sealed []byte) ([]byte, error) {
+	backend.lk.RLock()
+	aead := backend.aead
+	backend.lk.RUnlock()
+	if aead == nil {
+		return nil, ErrLocked
+	}
+
+	plaintext, err := open(aead, sealed, key.Bytes())
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to decrypt %s", key)
+	}
+	return plaintext, nil
+}
+
+// SignBytes cryptographically signs `data` using the private key of `addr`.
+// Fails while the backend is locked.
+func (backend *EncryptedBackend) SignBytes(data []byte, addr address.Address) (types.Signature, error) {
+	ki, err := backend.GetKeyInfo(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	if ki.Type() == BLS {
+		return wutil.SignBLS(ki.Key(), data)
+	}
+	return wutil.Sign(ki.Key(), data)
+}
+
+// Verify cryptographically verifies that 'sig' is the signed hash of 'data' with
+// the public key `pk`.
+func (backend *EncryptedBackend) Verify(data, pk []byte, sig types.Signature) bool {
+	return crypto.Verify(pk, data, sig)
+}
+
+// GetKeyInfo will return the private & public keys associated with address `addr`
+// iff backend contains the addr and is unlocked.
+func (backend *EncryptedBackend) GetKeyInfo(addr address.Address) (*types.KeyInfo, error) {
+	if !backend.HasAddress(addr) {
+		return nil, errors.New("backend does not contain address")
+	}
+
+	backend.lk.RLock()
+	aead := backend.aead
+	backend.lk.RUnlock()
+	if aead == nil {
+		return nil, ErrLocked
+	}
+
+	key := ds.NewKey(addr.String())
+	sealed, err := backend.ds.Get(key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to fetch private key from backend")
+	}
+
+	kib, err := open(aead, sealed, key.Bytes())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to decrypt private key")
+	}
+
+	ki := &types.KeyInfo{}
+	if err := ki.Unmarshal(kib); err != nil {
+		return nil, errors.Wrap(err, "failed to unmarshal keyinfo from backend")
+	}
+
+	return ki, nil
+}
+
+// encryptDatastore seals the plaintext keys of a DSBackend or HDBackend datastore in place
+// under passphrase, so that it can be opened with NewEncryptedBackend or NewHDBackend. Only
+// the seed of an HD wallet is sealed, its other state stays in the clear.
+func encryptDatastore(store repo.Datastore, passphrase []byte) error {
+	encrypted, err := IsEncrypted(store)
+	if err != nil {
+		return err
+	}
+	if encrypted {
+		return errors.New("wallet datastore is already encrypted")
+	}
+
+	params := &encryptionParams{
+		Salt: make([]byte, saltLen),
+		N:    scryptN,
+		R:    scryptR,
+		P:    scryptP,
+	}
+	if _, err := rand.Read(params.Salt); err != nil {
+		return err
+	}
+
+	aead, err := newAEAD(params, passphrase)
+	if err != nil {
+		return err
+	}
+	params.Check, err = seal(aead, checkPlaintext, encryptionParamsKey.Bytes())
+	if err != nil {
+		return err
+	}
+
+	result, err := store.Query(dsq.Query{})
+	if err != nil {
+		return errors.Wrap(err, "failed to query datastore")
+	}
+	entries, err := result.Rest()
+	if err != nil {
+		return errors.Wrap(err, "failed to read query results")
+	}
+
+	batch, err := store.Batch()
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		key := ds.NewKey(entry.Key)
+		if key == hdStateKey {
+			var state hdState
+			if err := cbor.DecodeInto(entry.Value, &state); err != nil {
+				return errors.Wrap(err, "failed to decode HD wallet state")
+			}
+			if state.Seed, err = seal(aead, state.Seed, key.Bytes()); err != nil {
+				return err
+			}
+			raw, err := cbor.DumpObject(&state)
+			if err != nil {
+				return err
+			}
+			if err := batch.Put(key, raw); err != nil {
+				return err
+			}
+			continue
+		}
+
+		sealed, err := seal(aead, entry.Value, key.Bytes())
+		if err != nil {
+			return err
+		}
+		if err := batch.Put(key, sealed); err != nil {
+			return err
+		}
+	}
+
+	rawParams, err := cbor.DumpObject(params)
+	if err != nil {
+		return err
+	}
+	if err := batch.Put(encryptionParamsKey, rawParams); err != nil {
+		return err
+	}
+
+	return batch.Commit()
+}
+
+func containsKey(keys []ds.Key, key ds.Key) bool {
+	for _, k := range keys {
+		if k == key {
+			return true
+		}
+	}
+	return false
+}
+
+func newAEAD(params *encryptionParams, passphrase []byte) (cipher.AEAD, error) {
+	key, err := params.deriveKey(passphrase)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to derive wallet key")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// seal encrypts plaintext, binding it to the datastore key it is stored under. The random
+// nonce is prepended to the result.
+func seal(aead cipher.AEAD, plaintext, dsKey []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, dsKey), nil
+}
+
+// open decrypts what seal produced for the same datastore key.
+func open(aead cipher.AEAD, sealed, dsKey []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("sealed data is too short")
+	}
+	nonce, ciphertext := sealed[:aead.NonceSize()], sealed[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, dsKey)
+}
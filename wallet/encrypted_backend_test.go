@@ -0,0 +1,152 @@
+package wallet
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+var testPassphrase = []byte("correct horse battery staple")
+
+// requireEncryptedWallet returns a wallet whose two keys were migrated from a plaintext
+// datastore to an encrypted backend, along with the datastore and the addresses.
+func requireEncryptedWallet(t *testing.T) (*Wallet, datastore.Batching, []address.Address) {
+	ds := datastore.NewMapDatastore()
+	fs, err := NewDSBackend(ds)
+	require.NoError(t, err)
+
+	secpAddr, err := fs.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+	blsAddr, err := fs.NewAddress(address.BLS)
+	require.NoError(t, err)
+
+	w := New(fs)
+	require.NoError(t, w.Encrypt(testPassphrase))
+	return w, ds, []address.Address{secpAddr, blsAddr}
+}
+
+func TestEncryptMigratesPlaintextKeys(t *testing.T) {
+	tf.UnitTest(t)
+
+	w, ds, addrs := requireEncryptedWallet(t)
+
+	assert.Len(t, w.Backends(DSBackendType), 0)
+	assert.Len(t, w.Backends(EncryptedBackendType), 1)
+	assert.ElementsMatch(t, addrs, w.Addresses())
+
+	encrypted, err := IsEncrypted(ds)
+	require.NoError(t, err)
+	assert.True(t, encrypted)
+
+	t.Log("plaintext keys are gone from the datastore")
+	kis, err := w.Export(addrs)
+	require.NoError(t, err)
+	for i, addr := range addrs {
+		raw, err := ds.Get(datastore.NewKey(addr.String()))
+		require.NoError(t, err)
+		assert.False(t, bytes.Contains(raw, kis[i].PrivateKey))
+	}
+
+	t.Log("a plaintext backend refuses the encrypted datastore")
+	_, err = NewDSBackend(ds)
+	assert.Error(t, err)
+
+	t.Log("migrated wallet is unlocked and signs")
+	data := []byte("data to sign")
+	for _, addr := range addrs {
+		sig, err := w.SignBytes(data, addr)
+		require.NoError(t, err)
+		assert.True(t, types.IsValidSignature(data, addr, sig))
+	}
+
+	t.Log("cannot encrypt twice")
+	assert.Error(t, w.Encrypt(testPassphrase))
+}
+
+func TestEncryptedBackendLockUnlock(t *testing.T) {
+	tf.UnitTest(t)
+
+	_, ds, addrs := requireEncryptedWallet(t)
+
+	backend, err := NewEncryptedBackend(ds)
+	require.NoError(t, err)
+	w := New(backend)
+
+	t.Log("reopened backend is locked but lists its addresses")
+	assert.True(t, backend.IsLocked())
+	assert.ElementsMatch(t, addrs, w.Addresses())
+
+	data := []byte("data to sign")
+	_, err = w.SignBytes(data, addrs[0])
+	assert.Equal(t, ErrLocked, errors.Cause(err))
+	_, err = NewAddress(w, address.SECP256K1)
+	assert.Equal(t, ErrLocked, err)
+	_, err = w.Export(addrs)
+	assert.Equal(t, ErrLocked, err)
+
+	t.Log("wrong passphrase does not unlock")
+	assert.Equal(t, ErrBadPassphrase, w.Unlock([]byte("wrong"), 0))
+	assert.True(t, backend.IsLocked())
+
+	t.Log("unlocked backend signs and creates addresses")
+	require.NoError(t, w.Unlock(testPassphrase, 0))
+	assert.False(t, backend.IsLocked())
+	sig, err := w.SignBytes(data, addrs[0])
+	require.NoError(t, err)
+	assert.True(t, types.IsValidSignature(data, addrs[0], sig))
+
+	newAddr, err := NewAddress(w, address.SECP256K1)
+	require.NoError(t, err)
+	kis, err := w.Export([]address.Address{newAddr})
+	require.NoError(t, err)
+	assert.Equal(t, SECP256K1, kis[0].Curve)
+
+	t.Log("lock makes keys unusable again")
+	require.NoError(t, w.Lock())
+	_, err = w.SignBytes(data, addrs[0])
+	assert.Equal(t, ErrLocked, errors.Cause(err))
+}
+
+func TestEncryptedBackendUnlockTimeout(t *testing.T) {
+	tf.UnitTest(t)
+
+	_, ds, _ := requireEncryptedWallet(t)
+
+	backend, err := NewEncryptedBackend(ds)
+	require.NoError(t, err)
+
+	require.NoError(t, backend.Unlock(testPassphrase, 10*time.Millisecond))
+	assert.False(t, backend.IsLocked())
+
+	deadline := time.Now().Add(5 * time.Second)
+	for !backend.IsLocked() && time.Now().Before(deadline) {
+		time.Sleep(5 * time.Millisecond)
+	}
+	assert.True(t, backend.IsLocked())
+
+	t.Log("unlocking without timeout cancels an earlier timeout")
+	require.NoError(t, backend.Unlock(testPassphrase, 10*time.Millisecond))
+	require.NoError(t, backend.Unlock(testPassphrase, 0))
+	time.Sleep(50 * time.Millisecond)
+	assert.False(t, backend.IsLocked())
+}
+
+func TestLockPlaintextWallet(t *testing.T) {
+	tf.UnitTest(t)
+
+	fs, err := NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	w := New(fs)
+
+	assert.Error(t, w.Lock())
+	assert.Error(t, w.Unlock(testPassphrase, 0))
+}
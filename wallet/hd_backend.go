@@ -0,0 +1,251 @@
+package wallet
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	ds "github.com/ipfs/go-datastore"
+	dsq "github.com/ipfs/go-datastore/query"
+	cbor "github.com/ipfs/go-ipld-cbor"
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/repo"
+	"github.com/filecoin-project/go-filecoin/types"
+	wutil "github.com/filecoin-project/go-filecoin/wallet/util"
+)
+
+func init() {
+	cbor.RegisterCborType(hdState{})
+}
+
+// HDBackendType is the reflect type of the HDBackend.
+var HDBackendType = reflect.TypeOf(&HDBackend{})
+
+// hdStateKey is the datastore key of the hdState of an HD wallet datastore. It does not
+// parse as an address, so a DSBackend refuses to open the datastore.
+var hdStateKey = ds.NewKey("_hd")
+
+// hdPathFormat is the BIP44 derivation path of the nth address of an HD wallet, using
+// the SLIP-44 coin type of filecoin.
+const hdPathFormat = "m/44'/461'/0'/0/%d"
+
+// mnemonicEntropyBits is the entropy of generated mnemonics, which have 24 words.
+const mnemonicEntropyBits = 256
+
+// hdState is what an HD wallet datastore stores besides its keys.
+type hdState struct {
+	// Seed is the seed of the wallet, sealed like the keys if the wallet is encrypted.
+	Seed []byte
+	// Next is the index of the next address to derive.
+	Next uint64
+	// Paths maps the string form of each derived address to its derivation path.
+	Paths map[string]string
+}
+
+// HDBackend is a datastore wallet backend whose new addresses are derived from a single
+// seed along BIP44 paths, so that the mnemonic of the seed backs up all of them. Keys it
+// imports are stored alongside the derived keys, without a derivation path. If the
+// wallet is encrypted, the keys and the seed are sealed by an EncryptedBackend, and new
+// addresses can only be derived while it is unlocked. Derivation paths, like addresses,
+// are stored in the clear.
+type HDBackend struct {
+	keyStore
+
+	ds repo.Datastore
+	// encrypted is the backend sealing the keys and the seed, or nil if the wallet is
+	// not encrypted.
+	encrypted *EncryptedBackend
+
+	// hdlk guards state.
+	hdlk  sync.Mutex
+	state hdState
+}
+
+var _ Backend = (*HDBackend)(nil)
+var _ Importer = (*HDBackend)(nil)
+
+// NewMnemonic generates a new random BIP39 mnemonic.
+func NewMnemonic() (string, error) {
+	entropy, err := bip39.NewEntropy(mnemonicEntropyBits)
+	if err != nil {
+		return "", err
+	}
+	return bip39.NewMnemonic(entropy)
+}
+
+// IsHD returns true if the wallet datastore holds an HD wallet.
+func IsHD(store repo.Datastore) (bool, error) {
+	return store.Has(hdStateKey)
+}
+
+// NewHDBackend opens the HD wallet datastore `store`. If the wallet is encrypted, the
+// backend starts out locked.
+func NewHDBackend(store repo.Datastore) (*HDBackend, error) {
+	encrypted, err := IsEncrypted(store)
+	if err != nil {
+		return nil, err
+	}
+	if encrypted {
+		keys, err := openEncryptedBackend(store, hdStateKey)
+		if err != nil {
+			return nil, err
+		}
+		return openHDBackend(store, keys, keys)
+	}
+
+	result, err := store.Query(dsq.Query{
+		KeysOnly: true,
+	})
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to query datastore")
+	}
+
+	list, err := result.Rest()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read query results")
+	}
+
+	cache := make(map[address.Address]struct{})
+	for _, el := range list {
+		if ds.NewKey(el.Key) == hdStateKey {
+			continue
+		}
+		parsedAddr, err := address.NewFromString(strings.Trim(el.Key, "/"))
+		if err != nil {
+			return nil, errors.Wrapf(err, "trying to restore invalid address: %s", el.Key)
+		}
+		cache[parsedAddr] = struct{}{}
+	}
+
+	keys := &DSBackend{
+		ds:    store,
+		cache: cache,
+	}
+	return openHDBackend(store, keys, nil)
+}
+
+// openHDBackend opens the HD wallet datastore `store` whose keys are stored by `keys`,
+// which is `encrypted` if the wallet is encrypted.
+func openHDBackend(store repo.Datastore, keys keyStore, encrypted *EncryptedBackend) (*HDBackend, error) {
+	raw, err := store.Get(hdStateKey)
+	if err == ds.ErrNotFound {
+		return nil, errors.New("wallet datastore is not an HD wallet")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read HD wallet state")
+	}
+
+	var state hdState
+	if err := cbor.DecodeInto(raw, &state); err != nil {
+		return nil, errors.Wrap(err, "failed to decode HD wallet state")
+	}
+	if state.Paths == nil {
+		state.Paths = make(map[string]string)
+	}
+
+	return &HDBackend{
+		keyStore:  keys,
+		ds:        store,
+		encrypted: encrypted,
+		state:     state,
+	}, nil
+}
+
+// locker returns the backend that seals the keys of an encrypted wallet, or false if the
+// wallet is not encrypted.
+func (backend *HDBackend) locker() (Locker, bool) {
+	if backend.encrypted == nil {
+		return nil, false
+	}
+	return backend.encrypted, true
+}
+
+// NewAddress derives the key at the next derivation path and stores it. Only the
+// SECP256K1 protocol is supported.
+// Safe for concurrent access.
+func (backend *HDBackend) NewAddress(protocol address.Protocol) (address.Address, error) {
+	if protocol != address.SECP256K1 {
+		return address.Undef, errors.Errorf("HD wallets can only derive secp256k1 addresses, not protocol %d", protocol)
+	}
+
+	backend.hdlk.Lock()
+	defer backend.hdlk.Unlock()
+
+	seed := backend.state.Seed
+	if backend.encrypted != nil {
+		var err error
+		if seed, err = backend.encrypted.openValue(hdStateKey, seed); err != nil {
+			return address.Undef, err
+		}
+	}
+
+	path := fmt.Sprintf(hdPathFormat, backend.state.Next)
+	prv, err := wutil.DeriveKey(seed, path)
+	if err != nil {
+		return address.Undef, errors.Wrapf(err, "failed to derive key at %s", path)
+	}
+
+	ki := &types.KeyInfo{
+		PrivateKey: prv,
+		Curve:      SECP256K1,
+	}
+	addr, err := ki.Address()
+	if err != nil {
+		return address.Undef, err
+	}
+
+	if err := backend.ImportKey(ki); err != nil {
+		return address.Undef, err
+	}
+
+	backend.state.Next++
+	backend.state.Paths[addr.String()] = path
+	if err := putHDState(backend.ds, &backend.state); err != nil {
+		return address.Undef, err
+	}
+	return addr, nil
+}
+
+// DerivationPath returns the path `addr` was derived along, or false if it was imported.
+func (backend *HDBackend) DerivationPath(addr address.Address) (string, bool) {
+	backend.hdlk.Lock()
+	defer backend.hdlk.Unlock()
+
+	path, ok := backend.state.Paths[addr.String()]
+	return path, ok
+}
+
+// initHDDatastore turns the wallet datastore `store` into an HD wallet with the seed of
+// `mnemonic`. Keys already in the datastore are kept as imported keys. If `encrypted` is
+// not nil, it seals the seed and must be unlocked.
+func initHDDatastore(store repo.Datastore, mnemonic string, encrypted *EncryptedBackend) error {
+	seed, err := bip39.NewSeedWithErrorChecking(mnemonic, "")
+	if err != nil {
+		return errors.Wrap(err, "invalid mnemonic")
+	}
+	if encrypted != nil {
+		if seed, err = encrypted.sealValue(hdStateKey, seed); err != nil {
+			return err
+		}
+	}
+
+	return putHDState(store, &hdState{
+		Seed:  seed,
+		Paths: make(map[string]string),
+	})
+}
+
+func putHDState(store repo.Datastore, state *hdState) error {
+	raw, err := cbor.DumpObject(state)
+	if err != nil {
+		return err
+	}
+	if err := store.Put(hdStateKey, raw); err != nil {
+		return errors.Wrap(err, "failed to store HD wallet state")
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package wallet
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/tyler-smith/go-bip39"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+)
+
+// testMnemonic is the all-zero entropy mnemonic of the BIP39 test vectors.
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestInitHDDerivesAddresses(t *testing.T) {
+	tf.UnitTest(t)
+
+	ds := datastore.NewMapDatastore()
+	fs, err := NewDSBackend(ds)
+	require.NoError(t, err)
+	imported, err := fs.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+
+	w := New(fs)
+	mnemonic, addrs, err := w.InitHD("", 2)
+	require.NoError(t, err)
+	assert.Len(t, addrs, 2)
+	assert.Len(t, w.Backends(DSBackendType), 0)
+	assert.Len(t, w.Backends(HDBackendType), 1)
+
+	t.Log("new addresses are derived along consecutive paths")
+	next, err := NewAddress(w, address.SECP256K1)
+	require.NoError(t, err)
+	for i, addr := range append(addrs, next) {
+		path, ok := w.DerivationPath(addr)
+		require.True(t, ok)
+		assert.Equal(t, []string{"m/44'/461'/0'/0/0", "m/44'/461'/0'/0/1", "m/44'/461'/0'/0/2"}[i], path)
+	}
+
+	t.Log("keys already in the wallet are kept without a path")
+	assert.ElementsMatch(t, []address.Address{imported, addrs[0], addrs[1], next}, w.Addresses())
+	_, ok := w.DerivationPath(imported)
+	assert.False(t, ok)
+
+	t.Log("only secp256k1 addresses can be derived")
+	_, err = NewAddress(w, address.BLS)
+	assert.Error(t, err)
+
+	t.Log("reopening the datastore continues where the wallet left off")
+	isHD, err := IsHD(ds)
+	require.NoError(t, err)
+	assert.True(t, isHD)
+	_, err = NewDSBackend(ds)
+	assert.Error(t, err)
+
+	hd, err := NewHDBackend(ds)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, w.Addresses(), hd.Addresses())
+	fourth, err := hd.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+	path, ok := hd.DerivationPath(fourth)
+	require.True(t, ok)
+	assert.Equal(t, "m/44'/461'/0'/0/3", path)
+
+	t.Log("the wallet cannot be initialized twice")
+	_, _, err = w.InitHD(mnemonic, 1)
+	assert.Error(t, err)
+}
+
+func TestInitHDRestoresFromMnemonic(t *testing.T) {
+	tf.UnitTest(t)
+
+	newWallet := func() *Wallet {
+		fs, err := NewDSBackend(datastore.NewMapDatastore())
+		require.NoError(t, err)
+		return New(fs)
+	}
+
+	w := newWallet()
+	mnemonic, addrs, err := w.InitHD(testMnemonic, 3)
+	require.NoError(t, err)
+	assert.Equal(t, testMnemonic, mnemonic)
+
+	t.Log("a wallet restored from the mnemonic derives the same addresses and keys")
+	restored := newWallet()
+	_, restoredAddrs, err := restored.InitHD(testMnemonic, 3)
+	require.NoError(t, err)
+	assert.Equal(t, addrs, restoredAddrs)
+
+	kis, err := w.Export(addrs)
+	require.NoError(t, err)
+	restoredKis, err := restored.Export(restoredAddrs)
+	require.NoError(t, err)
+	assert.Equal(t, kis, restoredKis)
+
+	t.Log("invalid mnemonics are refused")
+	_, _, err = newWallet().InitHD("abandon abandon abandon", 1)
+	assert.Error(t, err)
+}
+
+func TestEncryptHDWallet(t *testing.T) {
+	tf.UnitTest(t)
+
+	ds := datastore.NewMapDatastore()
+	fs, err := NewDSBackend(ds)
+	require.NoError(t, err)
+	imported, err := fs.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+
+	w := New(fs)
+	_, addrs, err := w.InitHD(testMnemonic, 2)
+	require.NoError(t, err)
+	require.NoError(t, w.Encrypt(testPassphrase))
+	assert.Len(t, w.Backends(HDBackendType), 1)
+
+	t.Log("the seed is no longer stored in the clear")
+	seed := bip39.NewSeed(testMnemonic, "")
+	raw, err := ds.Get(hdStateKey)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(raw, seed))
+
+	t.Log("the unlocked wallet keeps deriving along the same paths")
+	third, err := NewAddress(w, address.SECP256K1)
+	require.NoError(t, err)
+	path, ok := w.DerivationPath(third)
+	require.True(t, ok)
+	assert.Equal(t, "m/44'/461'/0'/0/2", path)
+
+	t.Log("the reopened wallet starts out locked")
+	hd, err := NewHDBackend(ds)
+	require.NoError(t, err)
+	reopened := New(hd)
+	assert.ElementsMatch(t, []address.Address{imported, addrs[0], addrs[1], third}, reopened.Addresses())
+	_, err = NewAddress(reopened, address.SECP256K1)
+	assert.Equal(t, ErrLocked, errors.Cause(err))
+	_, err = reopened.Export(addrs)
+	assert.Error(t, err)
+
+	t.Log("once unlocked it derives the next address and signs with every key")
+	require.NoError(t, reopened.Unlock(testPassphrase, 0))
+	fourth, err := NewAddress(reopened, address.SECP256K1)
+	require.NoError(t, err)
+	path, ok = reopened.DerivationPath(fourth)
+	require.True(t, ok)
+	assert.Equal(t, "m/44'/461'/0'/0/3", path)
+	for _, addr := range []address.Address{imported, addrs[0], fourth} {
+		_, err := reopened.SignBytes([]byte("data"), addr)
+		assert.NoError(t, err)
+	}
+
+	t.Log("the keys derived match those of a plaintext wallet restored from the mnemonic")
+	restored, err := NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	_, restoredAddrs, err := New(restored).InitHD(testMnemonic, 4)
+	require.NoError(t, err)
+	assert.Equal(t, []address.Address{addrs[0], addrs[1], third, fourth}, restoredAddrs)
+
+	t.Log("the wallet cannot be encrypted twice")
+	assert.Error(t, reopened.Encrypt(testPassphrase))
+}
+
+func TestInitHDOnEncryptedWallet(t *testing.T) {
+	tf.UnitTest(t)
+
+	w, ds, imported := requireEncryptedWallet(t)
+
+	t.Log("a locked wallet cannot be initialized")
+	require.NoError(t, w.Lock())
+	_, _, err := w.InitHD(testMnemonic, 1)
+	assert.Equal(t, ErrLocked, err)
+
+	require.NoError(t, w.Unlock(testPassphrase, 0))
+	_, addrs, err := w.InitHD(testMnemonic, 2)
+	require.NoError(t, err)
+	assert.Len(t, w.Backends(EncryptedBackendType), 0)
+	assert.Len(t, w.Backends(HDBackendType), 1)
+	assert.ElementsMatch(t, append(imported, addrs...), w.Addresses())
+
+	t.Log("the seed is sealed like the keys")
+	raw, err := ds.Get(hdStateKey)
+	require.NoError(t, err)
+	assert.False(t, bytes.Contains(raw, bip39.NewSeed(testMnemonic, "")))
+	_, err = NewEncryptedBackend(ds)
+	assert.Error(t, err)
+
+	t.Log("the wallet locks and unlocks as a whole")
+	require.NoError(t, w.Lock())
+	_, err = NewAddress(w, address.SECP256K1)
+	assert.Equal(t, ErrLocked, errors.Cause(err))
+	require.NoError(t, w.Unlock(testPassphrase, 0))
+	_, err = NewAddress(w, address.SECP256K1)
+	assert.NoError(t, err)
+
+	t.Log("the reopened wallet unlocks with the passphrase")
+	hd, err := NewHDBackend(ds)
+	require.NoError(t, err)
+	reopened := New(hd)
+	assert.Error(t, reopened.Unlock([]byte("wrong"), 0))
+	require.NoError(t, reopened.Unlock(testPassphrase, 0))
+	kis, err := reopened.Export(append(imported, addrs...))
+	require.NoError(t, err)
+	assert.Len(t, kis, 4)
+}
+
+func TestNewMnemonic(t *testing.T) {
+	tf.UnitTest(t)
+
+	a, err := NewMnemonic()
+	require.NoError(t, err)
+	b, err := NewMnemonic()
+	require.NoError(t, err)
+
+	assert.NotEqual(t, a, b)
+	assert.Len(t, strings.Fields(a), 24)
+}
@@ -0,0 +1,220 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/crypto"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+const (
+	// RemoteSignerAddressesPath is the path at which a remote signer lists the addresses
+	// it holds keys for, as a RemoteAddressesResponse.
+	RemoteSignerAddressesPath = "/addresses"
+
+	// RemoteSignerSignPath is the path at which a remote signer accepts a RemoteSignRequest
+	// and answers with a RemoteSignResponse.
+	RemoteSignerSignPath = "/sign"
+
+	// remoteSignerUnixPrefix marks a remote signer API that is a unix socket path.
+	remoteSignerUnixPrefix = "unix:"
+
+	// remoteSignerTimeout bounds each request to the remote signer.
+	remoteSignerTimeout = 30 * time.Second
+)
+
+// RemoteAddressesResponse lists the addresses a remote signer holds keys for.
+type RemoteAddressesResponse struct {
+	Addresses []address.Address `json:"addresses"`
+}
+
+// RemoteSignRequest asks a remote signer to sign data with the key of an address.
+type RemoteSignRequest struct {
+	Address address.Address `json:"address"`
+	Data    []byte          `json:"data"`
+}
+
+// RemoteSignResponse holds the signature made by a remote signer.
+type RemoteSignResponse struct {
+	Signature types.Signature `json:"signature"`
+}
+
+// RemoteBackendType is the reflect type of the RemoteBackend.
+var RemoteBackendType = reflect.TypeOf(&RemoteBackend{})
+
+// RemoteBackend is a wallet backend whose keys are held by an external signer process,
+// reached over a unix socket or HTTP. Only the addresses it is configured with are used
+// from the signer, and the keys never leave the signer.
+type RemoteBackend struct {
+	client  *http.Client
+	baseURL string
+	addrs   map[address.Address]struct{}
+}
+
+var _ Backend = (*RemoteBackend)(nil)
+
+// NewRemoteBackend creates a backend that signs for `addrs` with the remote signer at `api`,
+// which is either "unix:" followed by the path of a unix socket, or an http URL.
+func NewRemoteBackend(api string, addrs []address.Address) (*RemoteBackend, error) {
+	backend := &RemoteBackend{
+		client: &http.Client{Timeout: remoteSignerTimeout},
+		addrs:  make(map[address.Address]struct{}),
+	}
+
+	switch {
+	case strings.HasPrefix(api, remoteSignerUnixPrefix):
+		socket := strings.TrimPrefix(api, remoteSignerUnixPrefix)
+		backend.client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", socket)
+			},
+		}
+		// The host is ignored when dialing the socket.
+		backend.baseURL = "http://remote-signer"
+	case strings.HasPrefix(api, "http://") || strings.HasPrefix(api, "https://"):
+		backend.baseURL = strings.TrimSuffix(api, "/")
+	default:
+		return nil, fmt.Errorf("invalid remote signer API %q, must be unix:<path> or an http URL", api)
+	}
+
+	for _, a := range addrs {
+		backend.addrs[a] = struct{}{}
+	}
+	return backend, nil
+}
+
+// Addresses returns the configured addresses that the remote signer holds keys for.
+// It returns none if the signer cannot be reached.
+func (backend *RemoteBackend) Addresses() []address.Address {
+	var resp RemoteAddressesResponse
+	if err := backend.call(http.MethodGet, RemoteSignerAddressesPath, nil, &resp); err != nil {
+		log.Warningf("failed to list remote signer addresses: %s", err)
+		return nil
+	}
+
+	var out []address.Address
+	for _, a := range resp.Addresses {
+		if _, ok := backend.addrs[a]; ok {
+			out = append(out, a)
+		}
+	}
+	return out
+}
+
+// HasAddress checks if the address is configured to come from the remote signer.
+func (backend *RemoteBackend) HasAddress(addr address.Address) bool {
+	_, ok := backend.addrs[addr]
+	return ok
+}
+
+// SignBytes asks the remote signer to sign `data` with the key of `addr`.
+func (backend *RemoteBackend) SignBytes(data []byte, addr address.Address) (types.Signature, error) {
+	if !backend.HasAddress(addr) {
+		return nil, errors.New("backend does not contain address")
+	}
+
+	var resp RemoteSignResponse
+	if err := backend.call(http.MethodPost, RemoteSignerSignPath, &RemoteSignRequest{Address: addr, Data: data}, &resp); err != nil {
+		return nil, errors.Wrapf(err, "remote signer failed to sign for %s", addr)
+	}
+	return resp.Signature, nil
+}
+
+// Verify cryptographically verifies that 'sig' is the signed hash of 'data' with
+// the public key `pk`.
+func (backend *RemoteBackend) Verify(data, pk []byte, sig types.Signature) bool {
+	return crypto.Verify(pk, data, sig)
+}
+
+// GetKeyInfo always fails, since the keys never leave the remote signer.
+func (backend *RemoteBackend) GetKeyInfo(addr address.Address) (*types.KeyInfo, error) {
+	return nil, errors.New("keys held by a remote signer cannot be exported")
+}
+
+func (backend *RemoteBackend) call(method, path string, req, resp interface{}) error {
+	var body bytes.Buffer
+	if req != nil {
+		if err := json.NewEncoder(&body).Encode(req); err != nil {
+			return err
+		}
+	}
+
+	httpReq, err := http.NewRequest(method, backend.baseURL+path, &body)
+	if err != nil {
+		return err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	httpResp, err := backend.client.Do(httpReq)
+	if err != nil {
+		return err
+	}
+	defer httpResp.Body.Close() // nolint: errcheck
+
+	if httpResp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(httpResp.Body)
+		return fmt.Errorf("remote signer returned %s: %s", httpResp.Status, strings.TrimSpace(string(msg)))
+	}
+
+	return json.NewDecoder(httpResp.Body).Decode(resp)
+}
+
+// NewRemoteSignerHandler serves the remote signer API with the keys of `backend`. It lets
+// any wallet backend act as the external signer of a RemoteBackend.
+func NewRemoteSignerHandler(backend Backend) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc(RemoteSignerAddressesPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		writeJSON(w, &RemoteAddressesResponse{Addresses: backend.Addresses()})
+	})
+
+	mux.HandleFunc(RemoteSignerSignPath, func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var req RemoteSignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if !backend.HasAddress(req.Address) {
+			http.Error(w, ErrUnknownAddress.Error(), http.StatusNotFound)
+			return
+		}
+
+		sig, err := backend.SignBytes(req.Data, req.Address)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		writeJSON(w, &RemoteSignResponse{Signature: sig})
+	})
+
+	return mux
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.Warningf("failed to write remote signer response: %s", err)
+	}
+}
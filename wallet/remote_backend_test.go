@@ -0,0 +1,111 @@
+package wallet
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/address"
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/types"
+)
+
+func newTestSigner(t *testing.T, n int) (*DSBackend, []address.Address) {
+	signer, err := NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+
+	var addrs []address.Address
+	for i := 0; i < n; i++ {
+		addr, err := signer.NewAddress(address.SECP256K1)
+		require.NoError(t, err)
+		addrs = append(addrs, addr)
+	}
+	return signer, addrs
+}
+
+func TestRemoteBackendHTTP(t *testing.T) {
+	tf.UnitTest(t)
+
+	signer, addrs := newTestSigner(t, 2)
+	server := httptest.NewServer(NewRemoteSignerHandler(signer))
+	defer server.Close()
+
+	// Only the first of the signer's addresses is configured to be used remotely.
+	backend, err := NewRemoteBackend(server.URL, addrs[:1])
+	require.NoError(t, err)
+
+	assert.Equal(t, addrs[:1], backend.Addresses())
+	assert.True(t, backend.HasAddress(addrs[0]))
+	assert.False(t, backend.HasAddress(addrs[1]))
+
+	data := []byte("remote data")
+	sig, err := backend.SignBytes(data, addrs[0])
+	require.NoError(t, err)
+	assert.True(t, types.IsValidSignature(data, addrs[0], sig))
+
+	_, err = backend.SignBytes(data, addrs[1])
+	assert.Error(t, err)
+
+	_, err = backend.GetKeyInfo(addrs[0])
+	assert.Error(t, err)
+}
+
+func TestRemoteBackendUnixSocket(t *testing.T) {
+	tf.UnitTest(t)
+
+	dir, err := ioutil.TempDir("", "remote-signer")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	socket := filepath.Join(dir, "signer.sock")
+	ln, err := net.Listen("unix", socket)
+	require.NoError(t, err)
+	signer, addrs := newTestSigner(t, 1)
+	server := &http.Server{Handler: NewRemoteSignerHandler(signer)}
+	go server.Serve(ln)  // nolint: errcheck
+	defer server.Close() // nolint: errcheck
+
+	backend, err := NewRemoteBackend("unix:"+socket, addrs)
+	require.NoError(t, err)
+	assert.Equal(t, addrs, backend.Addresses())
+
+	t.Run("wallet signs with the remote backend", func(t *testing.T) {
+		w := New(backend)
+
+		data := []byte("socket data")
+		sig, err := w.SignBytes(data, addrs[0])
+		require.NoError(t, err)
+		assert.True(t, types.IsValidSignature(data, addrs[0], sig))
+	})
+}
+
+func TestRemoteBackendUnreachable(t *testing.T) {
+	tf.UnitTest(t)
+
+	_, addrs := newTestSigner(t, 1)
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	backend, err := NewRemoteBackend(url, addrs)
+	require.NoError(t, err)
+
+	assert.Empty(t, backend.Addresses())
+	_, err = backend.SignBytes([]byte("data"), addrs[0])
+	assert.Error(t, err)
+}
+
+func TestNewRemoteBackendInvalidAPI(t *testing.T) {
+	tf.UnitTest(t)
+
+	_, err := NewRemoteBackend("/ip4/127.0.0.1/tcp/1234", nil)
+	assert.Error(t, err)
+}
@@ -28,7 +28,7 @@ func requireSignerAddr(t *testing.T) (*DSBackend, address.Address) {
 	fs, err := NewDSBackend(ds)
 	require.NoError(t, err)
 
-	addr, err := fs.NewAddress()
+	addr, err := fs.NewAddress(address.SECP256K1)
 	require.NoError(t, err)
 	return fs, addr
 }
@@ -82,7 +82,7 @@ func TestInvalidAddress(t *testing.T) {
 	sig, err := fs.SignBytes(data, addr)
 	require.NoError(t, err)
 
-	badAddr, err := fs.NewAddress()
+	badAddr, err := fs.NewAddress(address.SECP256K1)
 	require.NoError(t, err)
 
 	assert.False(t, types.IsValidSignature(data, badAddr, sig))
@@ -102,6 +102,41 @@ func TestSignatureCorrupted(t *testing.T) {
 	assert.False(t, types.IsValidSignature(data, addr, sig))
 }
 
+// BLS signature is over the data being verified and was signed by the verifying
+// BLS address.
+func TestBLSSignatureOk(t *testing.T) {
+	tf.UnitTest(t)
+
+	fs, _ := requireSignerAddr(t)
+	addr, err := fs.NewAddress(address.BLS)
+	require.NoError(t, err)
+
+	data := []byte("THESE BYTES WILL BE SIGNED")
+	sig, err := fs.SignBytes(data, addr)
+	require.NoError(t, err)
+
+	assert.True(t, types.IsValidSignature(data, addr, sig))
+	assert.False(t, types.IsValidSignature([]byte("THESE BYTEZ WILL BE SIGNED"), addr, sig))
+}
+
+// secp256k1 signature does not verify for a BLS address and vice versa.
+func TestSignatureWrongProtocol(t *testing.T) {
+	tf.UnitTest(t)
+
+	fs, secpAddr := requireSignerAddr(t)
+	blsAddr, err := fs.NewAddress(address.BLS)
+	require.NoError(t, err)
+
+	data := []byte("THESE BYTES ARE SIGNED")
+	secpSig, err := fs.SignBytes(data, secpAddr)
+	require.NoError(t, err)
+	blsSig, err := fs.SignBytes(data, blsAddr)
+	require.NoError(t, err)
+
+	assert.False(t, types.IsValidSignature(data, blsAddr, secpSig))
+	assert.False(t, types.IsValidSignature(data, secpAddr, blsSig))
+}
+
 /* Test types.SignedMessage */
 
 // Valid SignedMessage verifies correctly.
@@ -117,12 +152,30 @@ func TestSignMessageOk(t *testing.T) {
 	assert.True(t, smsg.VerifySignature())
 }
 
+// Valid BLS SignedMessage verifies correctly.
+func TestBLSSignMessageOk(t *testing.T) {
+	tf.UnitTest(t)
+
+	fs, to := requireSignerAddr(t)
+	addr, err := fs.NewAddress(address.BLS)
+	require.NoError(t, err)
+
+	msg := types.NewMessage(addr, to, 1, types.ZeroAttoFIL, "", nil)
+	smsg, err := types.NewSignedMessage(*msg, fs, types.NewGasPrice(0), types.NewGasUnits(0))
+	require.NoError(t, err)
+
+	assert.True(t, smsg.VerifySignature())
+
+	smsg.Message.Nonce = types.Uint64(uint64(42))
+	assert.False(t, smsg.VerifySignature())
+}
+
 // Signature is valid but signer does not match From Address.
 func TestBadFrom(t *testing.T) {
 	tf.UnitTest(t)
 
 	fs, addr := requireSignerAddr(t)
-	addr2, err := fs.NewAddress()
+	addr2, err := fs.NewAddress(address.SECP256K1)
 	require.NoError(t, err)
 
 	msg := types.NewMessage(addr, addr, 1, types.ZeroAttoFIL, "", nil)
@@ -0,0 +1,91 @@
+package walletutil
+
+import (
+	"github.com/filecoin-project/go-bls-sigs"
+	"github.com/pkg/errors"
+)
+
+// NewBLSPrivateKey generates a new random BLS private key.
+func NewBLSPrivateKey() []byte {
+	priv := bls.PrivateKeyGenerate()
+	return priv[:]
+}
+
+// BLSPublicKey returns the public key belonging to the BLS private key `priv`.
+func BLSPublicKey(priv []byte) ([]byte, error) {
+	if len(priv) != bls.PrivateKeyBytes {
+		return nil, errors.Errorf("invalid BLS private key length %d", len(priv))
+	}
+
+	var pk bls.PrivateKey
+	copy(pk[:], priv)
+	pub := bls.PrivateKeyPublicKey(pk)
+	return pub[:], nil
+}
+
+// SignBLS cryptographically signs `data` using the BLS private key `priv`.
+func SignBLS(priv, data []byte) ([]byte, error) {
+	if len(priv) != bls.PrivateKeyBytes {
+		return nil, errors.Errorf("invalid BLS private key length %d", len(priv))
+	}
+
+	var pk bls.PrivateKey
+	copy(pk[:], priv)
+	sig := bls.PrivateKeySign(pk, data)
+	return sig[:], nil
+}
+
+// VerifyBLS cryptographically verifies that 'signature' is the BLS signature of 'data'
+// with the public key `pub`.
+func VerifyBLS(pub, data, signature []byte) bool {
+	if len(pub) != bls.PublicKeyBytes || len(signature) != bls.SignatureBytes {
+		return false
+	}
+
+	var pk bls.PublicKey
+	copy(pk[:], pub)
+	var sig bls.Signature
+	copy(sig[:], signature)
+
+	return bls.Verify(&sig, []bls.Digest{bls.Hash(data)}, []bls.PublicKey{pk})
+}
+
+// VerifyBLSAggregate verifies that 'signature' aggregates the BLS signatures of each
+// of `data` made with the public key at the same index in `pubs`.
+func VerifyBLSAggregate(pubs, data [][]byte, signature []byte) bool {
+	if len(pubs) != len(data) || len(signature) != bls.SignatureBytes {
+		return false
+	}
+
+	var sig bls.Signature
+	copy(sig[:], signature)
+
+	pks := make([]bls.PublicKey, len(pubs))
+	digests := make([]bls.Digest, len(data))
+	for i := range pubs {
+		if len(pubs[i]) != bls.PublicKeyBytes {
+			return false
+		}
+		copy(pks[i][:], pubs[i])
+		digests[i] = bls.Hash(data[i])
+	}
+
+	return bls.Verify(&sig, digests, pks)
+}
+
+// AggregateBLS combines BLS signatures into a single signature.
+func AggregateBLS(signatures [][]byte) ([]byte, error) {
+	sigs := make([]bls.Signature, len(signatures))
+	for i, s := range signatures {
+		if len(s) != bls.SignatureBytes {
+			return nil, errors.Errorf("invalid BLS signature length %d", len(s))
+		}
+		copy(sigs[i][:], s)
+	}
+
+	agg := bls.Aggregate(sigs)
+	if agg == nil {
+		return nil, errors.New("failed to aggregate BLS signatures")
+	}
+	return agg[:], nil
+}
@@ -0,0 +1,130 @@
+package walletutil
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"math/big"
+	"strconv"
+	"strings"
+
+	secp256k1 "github.com/ipsn/go-secp256k1"
+	"github.com/pkg/errors"
+)
+
+// HardenedOffset is added to a child index to derive a hardened child key.
+const HardenedOffset uint32 = 0x80000000
+
+// masterKeySecret is the HMAC key BIP32 derives master keys with.
+var masterKeySecret = []byte("Bitcoin seed")
+
+// ExtendedKey is a BIP32 extended secp256k1 private key.
+type ExtendedKey struct {
+	Key       []byte
+	ChainCode []byte
+}
+
+// NewMasterKey derives the BIP32 master key of `seed`.
+func NewMasterKey(seed []byte) (*ExtendedKey, error) {
+	mac := hmac.New(sha512.New, masterKeySecret)
+	mac.Write(seed) // nolint: errcheck
+	sum := mac.Sum(nil)
+
+	if !validPrivateKey(new(big.Int).SetBytes(sum[:32])) {
+		return nil, errors.New("seed derives an invalid master key")
+	}
+	return &ExtendedKey{Key: sum[:32], ChainCode: sum[32:]}, nil
+}
+
+// Child derives the child key at `index`, which is hardened if it is at least
+// HardenedOffset.
+func (k *ExtendedKey) Child(index uint32) (*ExtendedKey, error) {
+	var data []byte
+	if index >= HardenedOffset {
+		data = append([]byte{0}, k.Key...)
+	} else {
+		data = compressedPublicKey(k.Key)
+	}
+	var ib [4]byte
+	binary.BigEndian.PutUint32(ib[:], index)
+	data = append(data, ib[:]...)
+
+	mac := hmac.New(sha512.New, k.ChainCode)
+	mac.Write(data) // nolint: errcheck
+	sum := mac.Sum(nil)
+
+	n := secp256k1.S256().Params().N
+	tweak := new(big.Int).SetBytes(sum[:32])
+	if tweak.Cmp(n) >= 0 {
+		return nil, errors.Errorf("invalid child key at index %d", index)
+	}
+	child := tweak.Add(tweak, new(big.Int).SetBytes(k.Key))
+	child.Mod(child, n)
+	if !validPrivateKey(child) {
+		return nil, errors.Errorf("invalid child key at index %d", index)
+	}
+
+	key := make([]byte, 32)
+	blob := child.Bytes()
+	copy(key[32-len(blob):], blob)
+	return &ExtendedKey{Key: key, ChainCode: sum[32:]}, nil
+}
+
+// DeriveKey derives the secp256k1 private key of `seed` at the derivation `path`.
+func DeriveKey(seed []byte, path string) ([]byte, error) {
+	indexes, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	k, err := NewMasterKey(seed)
+	if err != nil {
+		return nil, err
+	}
+	for _, i := range indexes {
+		if k, err = k.Child(i); err != nil {
+			return nil, err
+		}
+	}
+	return k.Key, nil
+}
+
+// ParseDerivationPath parses a BIP32 derivation path such as m/44'/461'/0'/0/0 into
+// its child indexes. Hardened indexes are marked with ' or h.
+func ParseDerivationPath(path string) ([]uint32, error) {
+	parts := strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, errors.Errorf("derivation path %q must start with m", path)
+	}
+
+	indexes := make([]uint32, 0, len(parts)-1)
+	for _, p := range parts[1:] {
+		offset := uint32(0)
+		if strings.HasSuffix(p, "'") || strings.HasSuffix(p, "h") {
+			offset = HardenedOffset
+			p = p[:len(p)-1]
+		}
+
+		i, err := strconv.ParseUint(p, 10, 32)
+		if err != nil || uint32(i) >= HardenedOffset {
+			return nil, errors.Errorf("invalid index %q in derivation path %q", p, path)
+		}
+		indexes = append(indexes, uint32(i)+offset)
+	}
+	return indexes, nil
+}
+
+// compressedPublicKey returns the 33 byte compressed public key of the private key `priv`.
+func compressedPublicKey(priv []byte) []byte {
+	x, y := secp256k1.S256().ScalarBaseMult(priv)
+
+	pub := make([]byte, 33)
+	pub[0] = 2 + byte(y.Bit(0))
+	blob := x.Bytes()
+	copy(pub[33-len(blob):], blob)
+	return pub
+}
+
+func validPrivateKey(k *big.Int) bool {
+	return k.Sign() != 0 && k.Cmp(secp256k1.S256().Params().N) < 0
+}
@@ -0,0 +1,44 @@
+package walletutil
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
+)
+
+func TestDeriveKeyBIP32Vectors(t *testing.T) {
+	tf.UnitTest(t)
+
+	// Test vector 1 of BIP32.
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	require.NoError(t, err)
+
+	for path, expected := range map[string]string{
+		"m":           "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35",
+		"m/0'":        "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea",
+		"m/0'/1":      "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368",
+		"m/0h/1/2'":   "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca",
+		"m/0'/1/2'/2": "0f479245fb19a38a1954c5c7c0ebab2f9bdfd96a17563ef28a6a4b1a2a764ef4",
+	} {
+		key, err := DeriveKey(seed, path)
+		require.NoError(t, err)
+		assert.Equal(t, expected, hex.EncodeToString(key), path)
+	}
+}
+
+func TestParseDerivationPath(t *testing.T) {
+	tf.UnitTest(t)
+
+	indexes, err := ParseDerivationPath("m/44'/461'/0'/0/7")
+	require.NoError(t, err)
+	assert.Equal(t, []uint32{44 + HardenedOffset, 461 + HardenedOffset, HardenedOffset, 0, 7}, indexes)
+
+	for _, path := range []string{"", "44'/0", "m/x", "m/-1", "m/2147483648"} {
+		_, err := ParseDerivationPath(path)
+		assert.Error(t, err, path)
+	}
+}
@@ -6,14 +6,20 @@ import (
 	"reflect"
 	"sort"
 	"sync"
+	"time"
 
+	"github.com/filecoin-project/go-bls-sigs"
+	logging "github.com/ipfs/go-log"
 	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/go-filecoin/address"
+	"github.com/filecoin-project/go-filecoin/repo"
 	"github.com/filecoin-project/go-filecoin/types"
 	wutil "github.com/filecoin-project/go-filecoin/wallet/util"
 )
 
+var log = logging.Logger("wallet")
+
 var (
 	// ErrUnknownAddress is returned when the given address is not stored in this wallet.
 	ErrUnknownAddress = errors.New("unknown address")
@@ -125,8 +131,11 @@ func (w *Wallet) GetAddressForPubKey(pk []byte) (address.Address, error) {
 }
 
 // Verify cryptographically verifies that 'sig' is the signed hash of 'data' with
-// the public key `pk`.
+// the public key `pk`. BLS public keys are told apart from secp256k1 keys by their length.
 func (w *Wallet) Verify(data []byte, pk []byte, sig types.Signature) (bool, error) {
+	if len(pk) == bls.PublicKeyBytes {
+		return wutil.VerifyBLS(pk, data, sig), nil
+	}
 	return wutil.Verify(pk, data, sig)
 }
 
@@ -138,15 +147,205 @@ func (w *Wallet) Ecrecover(data []byte, sig types.Signature) ([]byte, error) {
 	return wutil.Ecrecover(data, sig)
 }
 
-// NewAddress creates a new account address on the default wallet backend.
-func NewAddress(w *Wallet) (address.Address, error) {
-	backends := w.Backends(DSBackendType)
-	if len(backends) == 0 {
-		return address.Undef, fmt.Errorf("missing default ds backend")
+// NewAddress creates a new account address using the given protocol on the default
+// wallet backend.
+func NewAddress(w *Wallet, protocol address.Protocol) (address.Address, error) {
+	backend, err := w.keyStore()
+	if err != nil {
+		return address.Undef, err
+	}
+	return backend.NewAddress(protocol)
+}
+
+// keyStore is a wallet backend that stores keys it generates or imports.
+type keyStore interface {
+	Backend
+	Importer
+	NewAddress(protocol address.Protocol) (address.Address, error)
+}
+
+// keyStore returns the default backend, which is either a datastore, an encrypted or an
+// HD backend.
+func (w *Wallet) keyStore() (keyStore, error) {
+	w.lk.Lock()
+	defer w.lk.Unlock()
+
+	for _, kind := range []reflect.Type{DSBackendType, EncryptedBackendType, HDBackendType} {
+		if backends := w.backends[kind]; len(backends) == 1 {
+			return backends[0].(keyStore), nil
+		}
+	}
+	return nil, fmt.Errorf("expected exactly one datastore wallet backend")
+}
+
+// Lock locks every backend that is protected by a passphrase.
+func (w *Wallet) Lock() error {
+	lockers := w.lockers()
+	if len(lockers) == 0 {
+		return errors.New("wallet is not encrypted")
+	}
+	for _, l := range lockers {
+		l.Lock()
+	}
+	return nil
+}
+
+// Unlock unlocks every backend that is protected by a passphrase. If timeout is positive
+// the backends lock themselves again after that long.
+func (w *Wallet) Unlock(passphrase []byte, timeout time.Duration) error {
+	lockers := w.lockers()
+	if len(lockers) == 0 {
+		return errors.New("wallet is not encrypted")
+	}
+	for _, l := range lockers {
+		if err := l.Unlock(passphrase, timeout); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+func (w *Wallet) lockers() []Locker {
+	w.lk.Lock()
+	defer w.lk.Unlock()
 
-	backend := (backends[0]).(*DSBackend)
-	return backend.NewAddress()
+	var out []Locker
+	for _, backends := range w.backends {
+		for _, backend := range backends {
+			if l, ok := backend.(Locker); ok {
+				out = append(out, l)
+			}
+			if hd, ok := backend.(*HDBackend); ok {
+				if l, ok := hd.locker(); ok {
+					out = append(out, l)
+				}
+			}
+		}
+	}
+	return out
+}
+
+// Encrypt migrates the keys of the datastore backend to an encrypted backend sealed under
+// passphrase. The keys are encrypted in place in the datastore, so the node opens the
+// encrypted backend from then on. The seed of an HD backend is sealed as well, and the HD
+// backend then derives its keys only while unlocked. The new backend is left unlocked.
+func (w *Wallet) Encrypt(passphrase []byte) error {
+	w.lk.Lock()
+	defer w.lk.Unlock()
+
+	if hds := w.backends[HDBackendType]; len(hds) == 1 {
+		hd := hds[0].(*HDBackend)
+		if hd.encrypted != nil {
+			return errors.New("wallet datastore is already encrypted")
+		}
+		if err := encryptDatastore(hd.ds, passphrase); err != nil {
+			return errors.Wrap(err, "failed to encrypt wallet datastore")
+		}
+
+		encrypted, err := NewHDBackend(hd.ds)
+		if err != nil {
+			return err
+		}
+		if err := encrypted.encrypted.Unlock(passphrase, 0); err != nil {
+			return err
+		}
+
+		w.backends[HDBackendType] = []Backend{encrypted}
+		return nil
+	}
+
+	dsbs := w.backends[DSBackendType]
+	if len(dsbs) != 1 {
+		return fmt.Errorf("expected exactly one datastore wallet backend")
+	}
+	dsb := dsbs[0].(*DSBackend)
+
+	if err := encryptDatastore(dsb.ds, passphrase); err != nil {
+		return errors.Wrap(err, "failed to encrypt wallet datastore")
+	}
+
+	encrypted, err := NewEncryptedBackend(dsb.ds)
+	if err != nil {
+		return err
+	}
+	if err := encrypted.Unlock(passphrase, 0); err != nil {
+		return err
+	}
+
+	delete(w.backends, DSBackendType)
+	w.backends[EncryptedBackendType] = append(w.backends[EncryptedBackendType], encrypted)
+	return nil
+}
+
+// InitHD turns the datastore or encrypted backend into an HD backend with the seed of
+// `mnemonic`, and derives its first `count` addresses. A new mnemonic is generated if none
+// is given, and the mnemonic used is returned. Keys already in the wallet are kept, without
+// a derivation path, and restoring from a mnemonic derives the same addresses again. An
+// encrypted wallet must be unlocked, and its seed is sealed like its keys.
+func (w *Wallet) InitHD(mnemonic string, count int) (string, []address.Address, error) {
+	w.lk.Lock()
+	defer w.lk.Unlock()
+
+	if len(w.backends[HDBackendType]) > 0 {
+		return "", nil, errors.New("wallet is already an HD wallet")
+	}
+
+	var store repo.Datastore
+	var keys keyStore
+	var encrypted *EncryptedBackend
+	if ebs := w.backends[EncryptedBackendType]; len(ebs) == 1 {
+		encrypted = ebs[0].(*EncryptedBackend)
+		if encrypted.IsLocked() {
+			return "", nil, ErrLocked
+		}
+		store, keys = encrypted.ds, encrypted
+	} else {
+		dsbs := w.backends[DSBackendType]
+		if len(dsbs) != 1 {
+			return "", nil, fmt.Errorf("expected exactly one datastore wallet backend")
+		}
+		dsb := dsbs[0].(*DSBackend)
+		store, keys = dsb.ds, dsb
+	}
+
+	if mnemonic == "" {
+		var err error
+		if mnemonic, err = NewMnemonic(); err != nil {
+			return "", nil, errors.Wrap(err, "failed to generate mnemonic")
+		}
+	}
+	if err := initHDDatastore(store, mnemonic, encrypted); err != nil {
+		return "", nil, err
+	}
+
+	hd, err := openHDBackend(store, keys, encrypted)
+	if err != nil {
+		return "", nil, err
+	}
+	delete(w.backends, DSBackendType)
+	delete(w.backends, EncryptedBackendType)
+	w.backends[HDBackendType] = append(w.backends[HDBackendType], hd)
+
+	var addrs []address.Address
+	for i := 0; i < count; i++ {
+		addr, err := hd.NewAddress(address.SECP256K1)
+		if err != nil {
+			return "", nil, err
+		}
+		addrs = append(addrs, addr)
+	}
+	return mnemonic, addrs, nil
+}
+
+// DerivationPath returns the path `addr` was derived along by an HD backend, or false if
+// it was not derived from a seed.
+func (w *Wallet) DerivationPath(addr address.Address) (string, bool) {
+	for _, backend := range w.Backends(HDBackendType) {
+		if path, ok := backend.(*HDBackend).DerivationPath(addr); ok {
+			return path, true
+		}
+	}
+	return "", false
 }
 
 // GetPubKeyForAddress returns the public key in the keystore associated with
@@ -160,9 +359,9 @@ func (w *Wallet) GetPubKeyForAddress(addr address.Address) ([]byte, error) {
 	return info.PublicKey(), nil
 }
 
-// NewKeyInfo creates a new KeyInfo struct in the wallet backend and returns it
+// NewKeyInfo creates a new secp256k1 KeyInfo struct in the wallet backend and returns it
 func (w *Wallet) NewKeyInfo() (*types.KeyInfo, error) {
-	newAddr, err := NewAddress(w)
+	newAddr, err := NewAddress(w, address.SECP256K1)
 	if err != nil {
 		return &types.KeyInfo{}, err
 	}
@@ -185,14 +384,9 @@ func (w *Wallet) keyInfoForAddr(addr address.Address) (*types.KeyInfo, error) {
 
 // Import adds the given keyinfos to the wallet
 func (w *Wallet) Import(kinfos ...*types.KeyInfo) ([]address.Address, error) {
-	dsb := w.Backends(DSBackendType)
-	if len(dsb) != 1 {
-		return nil, fmt.Errorf("expected exactly one datastore wallet backend")
-	}
-
-	imp, ok := dsb[0].(Importer)
-	if !ok {
-		return nil, fmt.Errorf("datastore backend wallets should implement importer")
+	imp, err := w.keyStore()
+	if err != nil {
+		return nil, err
 	}
 
 	var out []address.Address
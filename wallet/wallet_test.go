@@ -6,6 +6,7 @@ import (
 
 	"github.com/ipfs/go-datastore"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/go-filecoin/address"
 	tf "github.com/filecoin-project/go-filecoin/testhelpers/testflags"
@@ -27,7 +28,7 @@ func TestWalletSimple(t *testing.T) {
 	assert.Len(t, w.Backends(wallet.DSBackendType), 1)
 
 	t.Log("create a new address in the backend")
-	addr, err := fs.NewAddress()
+	addr, err := fs.NewAddress(address.SECP256K1)
 	assert.NoError(t, err)
 
 	t.Log("test HasAddress")
@@ -49,7 +50,7 @@ func TestWalletSimple(t *testing.T) {
 	assert.Equal(t, list[0], addr)
 
 	t.Log("addresses are sorted")
-	addr2, err := fs.NewAddress()
+	addr2, err := fs.NewAddress(address.SECP256K1)
 	assert.NoError(t, err)
 
 	if bytes.Compare(addr2.Bytes(), addr.Bytes()) < 0 {
@@ -78,7 +79,7 @@ func TestSimpleSignAndVerify(t *testing.T) {
 	assert.Len(t, w.Backends(wallet.DSBackendType), 1)
 
 	t.Log("create a new address in the backend")
-	addr, err := fs.NewAddress()
+	addr, err := fs.NewAddress(address.SECP256K1)
 	assert.NoError(t, err)
 
 	t.Log("test HasAddress")
@@ -146,9 +147,9 @@ func TestSignErrorCases(t *testing.T) {
 	assert.Len(t, w2.Backends(wallet.DSBackendType), 1)
 
 	t.Log("create a new address each backend")
-	addr1, err := fs1.NewAddress()
+	addr1, err := fs1.NewAddress(address.SECP256K1)
 	assert.NoError(t, err)
-	addr2, err := fs2.NewAddress()
+	addr2, err := fs2.NewAddress(address.SECP256K1)
 	assert.NoError(t, err)
 
 	t.Log("test HasAddress")
@@ -195,3 +196,38 @@ func TestGetAddressForPubKey(t *testing.T) {
 	}
 
 }
+
+func TestImportExportBLSKey(t *testing.T) {
+	tf.UnitTest(t)
+
+	fs1, err := wallet.NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	w1 := wallet.New(fs1)
+
+	addr, err := wallet.NewAddress(w1, address.BLS)
+	require.NoError(t, err)
+	assert.Equal(t, address.BLS, addr.Protocol())
+
+	kis, err := w1.Export([]address.Address{addr})
+	require.NoError(t, err)
+	require.Len(t, kis, 1)
+	assert.Equal(t, wallet.BLS, kis[0].Curve)
+
+	fs2, err := wallet.NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	w2 := wallet.New(fs2)
+
+	imported, err := w2.Import(kis...)
+	require.NoError(t, err)
+	assert.Equal(t, []address.Address{addr}, imported)
+
+	data := []byte("signed with an imported BLS key")
+	sig, err := w2.SignBytes(data, addr)
+	require.NoError(t, err)
+
+	pk, err := w2.GetPubKeyForAddress(addr)
+	require.NoError(t, err)
+	valid, err := w2.Verify(data, pk, sig)
+	require.NoError(t, err)
+	assert.True(t, valid)
+}